@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/log"
+	"paymentfc/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HandleListAnomalies godoc
+// @Summary 결제 이상 건 조회
+// @Description payment_anomalies를 유형/상태/주문/기간으로 필터링해 커서(id) 기반으로 조회합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param type query int false "유형 (1=invalid_amount, 2=illegal_transition)"
+// @Param status query int false "상태 (1=success, 2=retry, 3=resolving, 4=false_positive, 99=need_to_check)"
+// @Param order_id query int false "주문 ID"
+// @Param from query string false "생성 시작 시각(RFC3339 또는 YYYY-MM-DD)"
+// @Param to query string false "생성 종료 시각(RFC3339 또는 YYYY-MM-DD)"
+// @Param cursor query int false "이전 페이지 next_cursor"
+// @Param limit query int false "조회 개수 (기본 20, 최대 100)"
+// @Success 200 {object} models.PaymentAnomalyPage
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/anomalies [get]
+func (h *PaymentHandler) HandleListAnomalies(c *gin.Context) {
+	filter := models.PaymentAnomalyFilter{Limit: 20}
+	if v, err := strconv.Atoi(c.Query("type")); err == nil {
+		filter.AnomalyType = v
+	}
+	if v, err := strconv.Atoi(c.Query("status")); err == nil {
+		filter.Status = v
+	}
+	if v, err := strconv.ParseInt(c.Query("order_id"), 10, 64); err == nil {
+		filter.OrderID = v
+	}
+	if v, err := strconv.ParseInt(c.Query("cursor"), 10, 64); err == nil {
+		filter.Cursor = v
+	}
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		filter.Limit = min(v, 100)
+	}
+	if fromStr := c.Query("from"); fromStr != "" {
+		if t, err := parseTimeParam(fromStr); err == nil {
+			filter.From = t
+		}
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		if t, err := parseTimeParam(toStr); err == nil {
+			filter.To = t
+		}
+	}
+
+	page, err := h.AnomalyUsecase.ListAnomalies(c.Request.Context(), filter)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to list payment anomalies")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// HandleGetAnomaly godoc
+// @Summary 결제 이상 건 상세
+// @Description 이상 건과 해당 결제, 상태 변경 이력, 주문의 감사 로그를 함께 조회합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "payment_anomalies ID"
+// @Success 200 {object} models.PaymentAnomalyDetail
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/anomalies/{id} [get]
+func (h *PaymentHandler) HandleGetAnomaly(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid anomaly id"})
+		return
+	}
+
+	detail, err := h.AnomalyUsecase.GetAnomalyDetail(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payment anomaly not found"})
+		return
+	}
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("anomaly_id", id).Msg("Failed to get payment anomaly")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// HandleResolveAnomaly godoc
+// @Summary 결제 이상 건 조치
+// @Description ACCEPT_PAID_AMOUNT(결제 확정), REFUND_DIFFERENCE(결제 확정 후 초과 입금액 환불), FALSE_POSITIVE(문제 없음) 중 하나로 조치합니다. 조치가 실패하면 502와 함께 Retry 상태로 남긴 건을 돌려줍니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "payment_anomalies ID"
+// @Param body body models.ResolveAnomalyRequest true "조치 요청"
+// @Success 200 {object} models.PaymentAnomaly
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 502 {object} map[string]interface{}
+// @Router /api/v1/admin/anomalies/{id}/resolve [post]
+func (h *PaymentHandler) HandleResolveAnomaly(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid anomaly id"})
+		return
+	}
+	var req models.ResolveAnomalyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	anomaly, err := h.AnomalyUsecase.ResolveAnomaly(c.Request.Context(), id, req, int64(c.GetFloat64("user_id")))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, anomaly)
+	case errors.Is(err, service.ErrInvalidAnomalyAction):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "payment anomaly not found"})
+	case errors.Is(err, service.ErrAnomalyNotResolvable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "anomaly": anomaly})
+	case errors.Is(err, service.ErrAnomalyActionNotAllowed):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "anomaly": anomaly})
+	case errors.Is(err, service.ErrAnomalyActionFailed):
+		// 결제 확정/환불이 실패해 Retry 상태로 남았다.
+		log.Logger.Error().Err(err).Int64("anomaly_id", id).Msg("Failed to resolve payment anomaly")
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "anomaly": anomaly})
+	default:
+		log.Logger.Error().Err(err).Int64("anomaly_id", id).Msg("Failed to resolve payment anomaly")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/log"
+	"paymentfc/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HandleListFailedEvents godoc
+// @Summary 발행 실패 이벤트 조회
+// @Description failed_events를 상태/유형/주문/기간으로 필터링해 커서(id) 기반으로 조회합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param status query int false "상태 (1=success, 2=retry, 3=closed, 99=need_to_check)"
+// @Param failed_type query int false "유형 (1=success, 2=refunded, 3=failed, 4=expired)"
+// @Param order_id query int false "주문 ID"
+// @Param from query string false "생성 시작 시각(RFC3339 또는 YYYY-MM-DD)"
+// @Param to query string false "생성 종료 시각(RFC3339 또는 YYYY-MM-DD)"
+// @Param cursor query int false "이전 페이지 next_cursor"
+// @Param limit query int false "조회 개수 (기본 20, 최대 100)"
+// @Success 200 {object} models.FailedEventPage
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/failed-events [get]
+func (h *PaymentHandler) HandleListFailedEvents(c *gin.Context) {
+	filter := models.FailedEventFilter{Limit: 20}
+	if v, err := strconv.Atoi(c.Query("status")); err == nil {
+		filter.Status = v
+	}
+	if v, err := strconv.Atoi(c.Query("failed_type")); err == nil {
+		filter.FailedType = v
+	}
+	if v, err := strconv.ParseInt(c.Query("order_id"), 10, 64); err == nil {
+		filter.OrderID = v
+	}
+	if v, err := strconv.ParseInt(c.Query("cursor"), 10, 64); err == nil {
+		filter.Cursor = v
+	}
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		filter.Limit = min(v, 100)
+	}
+	if fromStr := c.Query("from"); fromStr != "" {
+		if t, err := parseTimeParam(fromStr); err == nil {
+			filter.From = t
+		}
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		if t, err := parseTimeParam(toStr); err == nil {
+			filter.To = t
+		}
+	}
+
+	page, err := h.PaymentUsecase.ListFailedEvents(c.Request.Context(), filter)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to list failed events")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// HandleRetryFailedEvent godoc
+// @Summary 발행 실패 이벤트 재발행
+// @Description 시도 횟수와 관계없이 즉시 재발행합니다. 재발행이 실패하면 502와 함께 갱신된 건을 돌려줍니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "failed_events ID"
+// @Success 200 {object} models.FailedEvent
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 502 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/failed-events/{id}/retry [post]
+func (h *PaymentHandler) HandleRetryFailedEvent(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid failed event id"})
+		return
+	}
+
+	event, err := h.PaymentUsecase.RetryFailedEvent(c.Request.Context(), id, int64(c.GetFloat64("user_id")))
+	if err != nil {
+		respondFailedEventError(c, id, event, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// HandleCloseFailedEvent godoc
+// @Summary 발행 실패 이벤트 종료
+// @Description 재발행 없이 수동 확인 완료로 닫습니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "failed_events ID"
+// @Param body body models.CloseFailedEventRequest true "종료 사유"
+// @Success 200 {object} models.FailedEvent
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/failed-events/{id}/close [post]
+func (h *PaymentHandler) HandleCloseFailedEvent(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid failed event id"})
+		return
+	}
+	var req models.CloseFailedEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	event, err := h.PaymentUsecase.CloseFailedEvent(c.Request.Context(), id, int64(c.GetFloat64("user_id")), req.Reason)
+	if err != nil {
+		respondFailedEventError(c, id, event, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+func respondFailedEventError(c *gin.Context, id int64, event *models.FailedEvent, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "failed event not found"})
+	case errors.Is(err, service.ErrFailedEventResolved):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "event": event})
+	case errors.Is(err, service.ErrFailedEventRepublish):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "event": event})
+	default:
+		log.Logger.Error().Err(err).Int64("failed_event_id", id).Msg("Failed to handle failed event action")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -2,29 +2,48 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/cmd/payment/service"
 	"paymentfc/cmd/payment/usecase"
+	"paymentfc/constant"
 	bizmetrics "paymentfc/infrastructure/metrics"
+	"paymentfc/kafka"
 	"paymentfc/log"
 	"paymentfc/models"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type PaymentHandler struct {
-	PaymentUsecase     usecase.PaymentUsecase
-	XenditUsecase      usecase.XenditUsecase
-	XenditWebhookToken string
+	PaymentUsecase        usecase.PaymentUsecase
+	XenditUsecase         usecase.XenditUsecase
+	RefundUsecase         usecase.RefundUsecase
+	AnomalyUsecase        usecase.AnomalyUsecase
+	ReconciliationUsecase usecase.ReconciliationUsecase
+	ReportUsecase         usecase.ReportUsecase
+	JobUsecase            usecase.JobUsecase
+	Leader                *service.LeaderElector
+	DLQReplayer           *kafka.DLQReplayer
 }
 
-func NewPaymentHandler(paymentUsecase usecase.PaymentUsecase, xenditUsecase usecase.XenditUsecase, xenditWebhookToken string) *PaymentHandler {
+func NewPaymentHandler(paymentUsecase usecase.PaymentUsecase, xenditUsecase usecase.XenditUsecase, refundUsecase usecase.RefundUsecase, anomalyUsecase usecase.AnomalyUsecase, reconciliationUsecase usecase.ReconciliationUsecase, reportUsecase usecase.ReportUsecase, jobUsecase usecase.JobUsecase, leader *service.LeaderElector, dlqReplayer *kafka.DLQReplayer) *PaymentHandler {
 	return &PaymentHandler{
-		PaymentUsecase:     paymentUsecase,
-		XenditUsecase:      xenditUsecase,
-		XenditWebhookToken: xenditWebhookToken,
+		PaymentUsecase:        paymentUsecase,
+		XenditUsecase:         xenditUsecase,
+		RefundUsecase:         refundUsecase,
+		AnomalyUsecase:        anomalyUsecase,
+		ReconciliationUsecase: reconciliationUsecase,
+		ReportUsecase:         reportUsecase,
+		JobUsecase:            jobUsecase,
+		Leader:                leader,
+		DLQReplayer:           dlqReplayer,
 	}
 }
 
@@ -48,44 +67,130 @@ func (h *PaymentHandler) Ping() gin.HandlerFunc {
 // @Failure 500 {object} map[string]interface{}
 // @Router /v1/payment/webhook [post]
 func (h *PaymentHandler) HandleXenditWebhook(c *gin.Context) {
-	if c.GetHeader("x-callback-token") != h.XenditWebhookToken {
-		bizmetrics.XenditWebhookProcessed.WithLabelValues("invalid_token").Inc()
-		log.Logger.Warn().Msg("Xendit webhook: invalid or missing callback token")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid callback token"})
+	h.handleGatewayWebhook(c, constant.PaymentProviderXendit)
+}
+
+// HandleGatewayWebhook godoc
+// @Summary 결제 게이트웨이 웹훅 처리
+// @Description provider별(xendit, midtrans) 웹훅 서명을 검증하고 공통 결제 웹훅 흐름으로 처리합니다.
+// @Tags PAYMENT
+// @Accept json
+// @Produce json
+// @Param provider path string true "게이트웨이 (xendit, midtrans)"
+// @Param body body object true "provider 원본 웹훅 페이로드"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /v1/payment/webhook/{provider} [post]
+func (h *PaymentHandler) HandleGatewayWebhook(c *gin.Context) {
+	h.handleGatewayWebhook(c, c.Param("provider"))
+}
+
+func (h *PaymentHandler) handleGatewayWebhook(c *gin.Context, provider string) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		observeWebhook(provider, "bind_error")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var payload models.XenditWebhookPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		bizmetrics.XenditWebhookProcessed.WithLabelValues("bind_error").Inc()
-		log.Logger.Error().Err(err).Msg("Failed to bind webhook payload")
+	event, err := h.XenditUsecase.ParseWebhook(c.Request.Context(), provider, c.Request.Header, body)
+	switch {
+	case errors.Is(err, repository.ErrUnknownGateway):
+		observeWebhook(provider, "unknown_provider")
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repository.ErrInvalidWebhookSignature):
+		observeWebhook(provider, "invalid_token")
+		log.Logger.Warn().Str("provider", provider).Msg("Payment webhook: invalid or missing signature")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid callback token"})
+		return
+	case err != nil:
+		observeWebhook(provider, "bind_error")
+		log.Logger.Error().Err(err).Str("provider", provider).Msg("Failed to bind webhook payload")
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	err := h.PaymentUsecase.ProcessPaymentWebhook(c.Request.Context(), payload)
+	// 처리는 inbox 워커가 비동기로 한다. 저장까지 성공하면 바로 200으로 응답해 게이트웨이 재전송을 멈춘다.
+	stored, duplicate, err := h.PaymentUsecase.ReceiveWebhook(c.Request.Context(), *event, c.Request.Header, body)
+	if err != nil {
+		observeWebhook(provider, "store_error")
+		log.Logger.Error().Err(err).Str("provider", provider).Str("external_id", event.ExternalID).Msg("Failed to store payment webhook")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if duplicate {
+		observeWebhook(provider, "duplicate")
+		c.JSON(http.StatusOK, gin.H{"message": "webhook duplicate", "id": stored.ID})
+		return
+	}
+
+	observeWebhook(provider, "accepted")
+	c.JSON(http.StatusOK, gin.H{"message": "webhook accepted", "id": stored.ID})
+}
+
+func observeWebhook(provider, outcome string) {
+	bizmetrics.ObserveWebhook(provider, outcome)
+}
+
+// HandleReplayWebhook godoc
+// @Summary 웹훅 재처리
+// @Description webhook inbox에 저장된 웹훅을 상태와 관계없이 즉시 다시 처리합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "webhook event ID"
+// @Success 200 {object} models.WebhookEvent
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/webhooks/{id}/replay [post]
+func (h *PaymentHandler) HandleReplayWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		bizmetrics.XenditWebhookProcessed.WithLabelValues("process_error").Inc()
-		log.Logger.Error().Err(err).Msg("Failed to process payment webhook")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook event id"})
+		return
+	}
+
+	event, err := h.PaymentUsecase.ReplayWebhookEvent(c.Request.Context(), id, int64(c.GetFloat64("user_id")))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook event not found"})
+		return
+	case errors.Is(err, service.ErrWebhookEventInProgress):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Logger.Error().Err(err).Int64("webhook_event_id", id).Msg("Failed to replay webhook event")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	bizmetrics.XenditWebhookProcessed.WithLabelValues("success").Inc()
-	c.JSON(http.StatusOK, gin.H{"message": "webhook processed"})
+	c.JSON(http.StatusOK, event)
 }
 
 // CreateInvoice godoc
 // @Summary 인보이스 생성
-// @Description 주문 정보로 Xendit 인보이스를 생성합니다.
+// @Description 주문 정보로 Xendit 인보이스를 생성합니다. 주문 소유자는 토큰의 user_id로 확인하고, 금액은 저장된 결제 요청(payment_requests)과 대조합니다. 품목과 게이트웨이는 저장된 결제 요청 값을 쓰며 본문의 products/provider는 무시합니다.
 // @Tags PAYMENT
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param body body models.OrderCreatedEvent true "인보이스 생성 요청"
+// @Param Idempotency-Key header string false "재시도 시 같은 응답을 돌려받기 위한 키 (24시간 보관)"
+// @Param body body models.OrderCreatedEvent true "인보이스 생성 요청 (user_id는 생략 가능, total_amount를 생략하면 저장된 금액)"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/payment/invoice [post]
 func (h *PaymentHandler) CreateInvoice(c *gin.Context) {
@@ -96,10 +201,20 @@ func (h *PaymentHandler) CreateInvoice(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.XenditUsecase.CreateInvoice(c.Request.Context(), req)
+	userID := int64(c.GetFloat64("user_id"))
+	resp, err := h.XenditUsecase.CreateInvoiceForUser(c.Request.Context(), req, userID)
 	if err != nil {
-		log.Logger.Error().Err(err).Msgf("Failed to create invoice for order_id: %d", req.OrderID)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		log.Logger.Error().Err(err).Int64("user_id", userID).Msgf("Failed to create invoice for order_id: %d", req.OrderID)
+		switch {
+		case errors.Is(err, service.ErrOrderForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment request not found"})
+		case errors.Is(err, service.ErrInvoiceAmountMismatch):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -107,19 +222,24 @@ func (h *PaymentHandler) CreateInvoice(c *gin.Context) {
 		"id":          resp.ID,
 		"invoice_url": resp.InvoiceURL,
 		"status":      resp.Status,
+		"provider":    resp.Provider,
 	})
 }
 
 // HandleDownloadInvoicePdf godoc
 // @Summary 인보이스 PDF 다운로드
-// @Description 주문 ID에 대한 인보이스 PDF 파일을 다운로드합니다.
+// @Description 본인 주문의 인보이스 PDF를 메모리에서 렌더링(또는 캐시)해 내려줍니다. If-None-Match가 ETag와 같으면 304를 돌려줍니다.
 // @Tags PAYMENT
 // @Security BearerAuth
 // @Produce application/pdf
 // @Param order_id path int true "주문 ID"
+// @Param If-None-Match header string false "이전 응답의 ETag"
 // @Success 200 {file} file
+// @Success 304 "Not Modified"
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/invoice/{order_id}/pdf [get]
 func (h *PaymentHandler) HandleDownloadInvoicePdf(c *gin.Context) {
@@ -132,28 +252,107 @@ func (h *PaymentHandler) HandleDownloadInvoicePdf(c *gin.Context) {
 		return
 	}
 
-	filePath, err := h.PaymentUsecase.DownloadInvoicePdf(c.Request.Context(), orderIdInt)
+	invoice, err := h.PaymentUsecase.GetInvoicePdf(c.Request.Context(), orderIdInt, int64(c.GetFloat64("user_id")), c.GetHeader("If-None-Match"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		return
+	}
+	if errors.Is(err, service.ErrOrderForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
-		log.Logger.Error().Err(err).Msgf("Failed to get payment by order id: %d", orderIdInt)
+		log.Logger.Error().Err(err).Msgf("Failed to render invoice pdf for order id: %d", orderIdInt)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.FileAttachment(filePath, "invoice_"+orderIdStr+".pdf")
+	c.Header("ETag", invoice.ETag)
+	c.Header("Cache-Control", "private, no-cache")
+	if !invoice.LastModified.IsZero() {
+		c.Header("Last-Modified", invoice.LastModified.UTC().Format(http.TimeFormat))
+	}
+	if invoice.NotModified {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="invoice_`+orderIdStr+`.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", invoice.Content)
+}
+
+// HandleCreateRefund godoc
+// @Summary 환불 요청
+// @Description 결제 완료된 주문에 대해 전액 또는 부분 환불을 요청합니다. amount를 생략(0)하면 남은 금액 전액을 환불합니다.
+// @Tags PAYMENT
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param order_id path int true "주문 ID"
+// @Param body body models.RefundRequest true "환불 요청"
+// @Success 200 {object} models.Refund
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/payment/{order_id}/refunds [post]
+func (h *PaymentHandler) HandleCreateRefund(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to parse order id")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to bind refund request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refund, err := h.RefundUsecase.CreateRefund(c.Request.Context(), orderID, req, int64(c.GetFloat64("user_id")))
+	if err != nil {
+		log.Logger.Error().Err(err).Msgf("Failed to create refund for order_id: %d", orderID)
+		switch {
+		case errors.Is(err, service.ErrInvalidRefundAmount), errors.Is(err, service.ErrInvalidRefundReason):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		case errors.Is(err, service.ErrPaymentNotRefundable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, repository.ErrRefundAmountExceeded):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, refund)
 }
 
 // HandleFailedPayments godoc
 // @Summary 실패 결제 목록 조회
-// @Description 실패한 결제 목록을 조회합니다.
+// @Description 재시도 잡이 다시 처리할 FAILED 결제 요청을 조회합니다. 재시도 한도와 조회 개수는 retry_failed_payment_requests 잡 설정(max_retries, batch_size)을 따르고 DEAD 요청은 빠집니다.
 // @Tags PAYMENT
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {array} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/failed_payments [get]
 func (h *PaymentHandler) HandleFailedPayments(c *gin.Context) {
-	paymentList, err := h.PaymentUsecase.GetFailedPaymentList(c.Request.Context())
+	spec, err := h.JobUsecase.JobSpec(constant.JobRetryFailedPaymentRequests)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to read retry job config")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	paymentList, err := h.PaymentUsecase.GetFailedPaymentList(c.Request.Context(), spec.MaxRetries, spec.BatchSize)
 	if err != nil {
 		log.Logger.Error().Err(err).Msg("Failed to get failed payment list")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -178,6 +377,7 @@ func (h *PaymentHandler) HandleFailedPayments(c *gin.Context) {
 // @Param to query string false "조회 종료 시각(RFC3339 또는 YYYY-MM-DD)"
 // @Success 200 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/audit-logs [get]
 func (h *PaymentHandler) HandleAuditLogs(c *gin.Context) {
@@ -232,6 +432,7 @@ func (h *PaymentHandler) HandleAuditLogs(c *gin.Context) {
 // @Param to query string false "조회 종료 시각(RFC3339 또는 YYYY-MM-DD)"
 // @Success 200 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/audit-report/daily [get]
 func (h *PaymentHandler) HandleAuditDailyReport(c *gin.Context) {
@@ -266,8 +467,11 @@ func (h *PaymentHandler) HandleAuditDailyReport(c *gin.Context) {
 // @Summary 감사 로그 스트림
 // @Description MongoDB Change Stream 기반의 감사 로그 SSE 스트림입니다.
 // @Tags PAYMENT
+// @Security BearerAuth
 // @Produce text/event-stream
 // @Success 200 {string} string "SSE stream"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /debug/mongo/stream [get]
 func (h *PaymentHandler) HandleAuditLogStream(c *gin.Context) {
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/log"
+	"paymentfc/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleListJobs godoc
+// @Summary 스케줄러 잡 목록
+// @Description 등록된 잡의 주기, 배치 크기, 타임아웃, 일시정지 여부와 이 인스턴스 기준 실행 상태를 조회합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.JobStatus
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/jobs [get]
+func (h *PaymentHandler) HandleListJobs(c *gin.Context) {
+	jobs, err := h.JobUsecase.ListJobs(c.Request.Context())
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to list jobs")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// HandleListJobRuns godoc
+// @Summary 잡 실행 이력
+// @Description job_runs를 상태로 필터링해 커서(id) 기반으로 조회합니다. 처리한 건이 없던 스케줄 실행은 기록하지 않습니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param name path string true "잡 이름"
+// @Param status query string false "상태 (SUCCEEDED, FAILED, TIMED_OUT)"
+// @Param cursor query int false "이전 페이지 next_cursor"
+// @Param limit query int false "조회 개수 (기본 20, 최대 100)"
+// @Success 200 {object} models.JobRunPage
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/jobs/{name}/runs [get]
+func (h *PaymentHandler) HandleListJobRuns(c *gin.Context) {
+	filter := models.JobRunFilter{JobName: c.Param("name"), Status: c.Query("status"), Limit: 20}
+	if v, err := strconv.ParseInt(c.Query("cursor"), 10, 64); err == nil {
+		filter.Cursor = v
+	}
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		filter.Limit = min(v, 100)
+	}
+
+	page, err := h.JobUsecase.ListJobRuns(c.Request.Context(), filter)
+	if errors.Is(err, service.ErrJobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Logger.Error().Err(err).Str("job", filter.JobName).Msg("Failed to list job runs")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// HandlePauseJob godoc
+// @Summary 잡 일시정지
+// @Description 모든 인스턴스에서 해당 잡의 스케줄 실행을 멈춥니다. 진행 중인 실행은 끝까지 돌고, 수동 실행은 계속 가능합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param name path string true "잡 이름"
+// @Success 200 {object} models.JobStatus
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/jobs/{name}/pause [post]
+func (h *PaymentHandler) HandlePauseJob(c *gin.Context) {
+	name := c.Param("name")
+	status, err := h.JobUsecase.PauseJob(c.Request.Context(), name, int64(c.GetFloat64("user_id")))
+	h.respondJobStatus(c, name, status, err, http.StatusOK)
+}
+
+// HandleResumeJob godoc
+// @Summary 잡 재개
+// @Description 일시정지한 잡의 스케줄 실행을 다시 시작합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param name path string true "잡 이름"
+// @Success 200 {object} models.JobStatus
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/jobs/{name}/resume [post]
+func (h *PaymentHandler) HandleResumeJob(c *gin.Context) {
+	name := c.Param("name")
+	status, err := h.JobUsecase.ResumeJob(c.Request.Context(), name, int64(c.GetFloat64("user_id")))
+	h.respondJobStatus(c, name, status, err, http.StatusOK)
+}
+
+// HandleTriggerJob godoc
+// @Summary 잡 수동 실행
+// @Description 다음 스케줄을 기다리지 않고 요청을 받은 인스턴스에서 잡을 바로 한 번 실행합니다. 리더 전용 잡은 리더 인스턴스에서만, 일시정지된 잡은 force=true일 때만 실행되며, 결과는 실행 이력에서 확인합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param name path string true "잡 이름"
+// @Param force query bool false "일시정지된 잡도 실행"
+// @Success 202 {object} models.JobStatus
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/jobs/{name}/trigger [post]
+func (h *PaymentHandler) HandleTriggerJob(c *gin.Context) {
+	name := c.Param("name")
+	force := c.Query("force") == "true"
+	status, err := h.JobUsecase.TriggerJob(c.Request.Context(), name, force, int64(c.GetFloat64("user_id")))
+	h.respondJobStatus(c, name, status, err, http.StatusAccepted)
+}
+
+func (h *PaymentHandler) respondJobStatus(c *gin.Context, name string, status *models.JobStatus, err error, okCode int) {
+	switch {
+	case err == nil:
+		c.JSON(okCode, status)
+	case errors.Is(err, service.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrJobRunning), errors.Is(err, service.ErrJobPaused), errors.Is(err, service.ErrJobNotLeader):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Logger.Error().Err(err).Str("job", name).Msg("Failed to update job")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
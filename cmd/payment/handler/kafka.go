@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+	"paymentfc/log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleReplayStockReservedDLQ godoc
+// @Summary stock.reserved DLQ 재처리
+// @Description stock.reserved.dlq 메시지를 최대 limit개 읽어 원래 토픽으로 다시 발행합니다. 재시도 헤더는 지워져 재시도 단계를 처음부터 밟고, 재처리한 메시지만 커밋됩니다. 중간에 실패하면 그때까지 재처리한 개수와 에러를 500으로 돌려줍니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "재처리 개수 (기본 100, 최대 1000)"
+// @Success 200 {object} models.DLQReplayResult
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} models.DLQReplayResult
+// @Router /api/v1/admin/kafka/stock-reserved/dlq/replay [post]
+func (h *PaymentHandler) HandleReplayStockReservedDLQ(c *gin.Context) {
+	limit := 100
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		limit = min(v, 1000)
+	}
+
+	result, err := h.DLQReplayer.Replay(c.Request.Context(), limit)
+	if err != nil {
+		log.Logger.Error().Err(err).Int("replayed", result.Replayed).Msg("Failed to replay stock.reserved DLQ")
+		result.Error = err.Error()
+		c.JSON(http.StatusInternalServerError, result)
+		return
+	}
+	log.Logger.Info().Int("replayed", result.Replayed).Int64("requested_by", int64(c.GetFloat64("user_id"))).Msg("Replayed stock.reserved DLQ")
+	c.JSON(http.StatusOK, result)
+}
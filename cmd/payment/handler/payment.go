@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"paymentfc/log"
+	"paymentfc/models"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HandleListMyPayments godoc
+// @Summary 내 결제 목록 조회
+// @Description 토큰의 user_id로 본인 결제만 상태/기간으로 필터링해 커서(id) 기반으로 조회합니다. invoice_url, expired_time, status를 포함합니다.
+// @Tags PAYMENT
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "상태 (PENDING, PAID, EXPIRED, FAILED, PARTIALLY_REFUNDED, REFUNDED)"
+// @Param from query string false "생성 시작 시각(RFC3339 또는 YYYY-MM-DD)"
+// @Param to query string false "생성 종료 시각(RFC3339 또는 YYYY-MM-DD)"
+// @Param cursor query int false "이전 페이지 next_cursor"
+// @Param limit query int false "조회 개수 (기본 20, 최대 100)"
+// @Success 200 {object} models.PaymentPage
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/payments [get]
+func (h *PaymentHandler) HandleListMyPayments(c *gin.Context) {
+	filter := models.PaymentFilter{
+		UserID: int64(c.GetFloat64("user_id")),
+		Status: strings.ToUpper(c.Query("status")),
+		Limit:  20,
+	}
+	if v, err := strconv.ParseInt(c.Query("cursor"), 10, 64); err == nil {
+		filter.Cursor = v
+	}
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		filter.Limit = min(v, 100)
+	}
+	if fromStr := c.Query("from"); fromStr != "" {
+		if t, err := parseTimeParam(fromStr); err == nil {
+			filter.From = t
+		}
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		if t, err := parseTimeParam(toStr); err == nil {
+			filter.To = t
+		}
+	}
+
+	page, err := h.PaymentUsecase.ListUserPayments(c.Request.Context(), filter)
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("user_id", filter.UserID).Msg("Failed to list user payments")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// HandleGetMyPayment godoc
+// @Summary 내 결제 상세 조회
+// @Description 주문 ID의 결제 상태를 조회합니다. 다른 사용자의 주문은 404로 응답합니다.
+// @Tags PAYMENT
+// @Security BearerAuth
+// @Produce json
+// @Param order_id path int true "주문 ID"
+// @Success 200 {object} models.Payment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/payments/{order_id} [get]
+func (h *PaymentHandler) HandleGetMyPayment(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		return
+	}
+	userID := int64(c.GetFloat64("user_id"))
+
+	payment, err := h.PaymentUsecase.GetUserPayment(c.Request.Context(), userID, orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		return
+	}
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("order_id", orderID).Int64("user_id", userID).Msg("Failed to get user payment")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, payment)
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/log"
+	"paymentfc/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HandleTriggerReconciliation godoc
+// @Summary Xendit 대사 실행
+// @Description 기간(from 이상, to 미만)에 생성된 Xendit 인보이스를 payments와 대사합니다. 백그라운드로 실행되며 run 조회로 결과를 확인합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.CreateReconciliationRequest true "대사 기간 (최대 31일)"
+// @Success 202 {object} models.ReconciliationRun
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/reconciliations [post]
+func (h *PaymentHandler) HandleTriggerReconciliation(c *gin.Context) {
+	var req models.CreateReconciliationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, err := parseTimeParam(req.From)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := parseTimeParam(req.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	run, err := h.ReconciliationUsecase.TriggerReconciliation(c.Request.Context(), from, to, req.AutoFix, int64(c.GetFloat64("user_id")))
+	if errors.Is(err, service.ErrInvalidReconciliationWindow) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to trigger reconciliation")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, run)
+}
+
+// HandleListReconciliationRuns godoc
+// @Summary Xendit 대사 실행 목록
+// @Description reconciliation run을 상태로 필터링해 커서(id) 기반으로 조회합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "상태 (RUNNING, COMPLETED, FAILED)"
+// @Param cursor query int false "이전 페이지 next_cursor"
+// @Param limit query int false "조회 개수 (기본 20, 최대 100)"
+// @Success 200 {object} models.ReconciliationRunPage
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/reconciliations [get]
+func (h *PaymentHandler) HandleListReconciliationRuns(c *gin.Context) {
+	filter := models.ReconciliationRunFilter{Limit: 20, Status: c.Query("status")}
+	if v, err := strconv.ParseInt(c.Query("cursor"), 10, 64); err == nil {
+		filter.Cursor = v
+	}
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		filter.Limit = min(v, 100)
+	}
+
+	page, err := h.ReconciliationUsecase.ListRuns(c.Request.Context(), filter)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to list reconciliation runs")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// HandleGetReconciliationRun godoc
+// @Summary Xendit 대사 실행 조회
+// @Description run 상태와 인보이스/일치/불일치/보정 건수를 조회합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "reconciliation run ID"
+// @Success 200 {object} models.ReconciliationRun
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/reconciliations/{id} [get]
+func (h *PaymentHandler) HandleGetReconciliationRun(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reconciliation run id"})
+		return
+	}
+
+	run, err := h.ReconciliationUsecase.GetRun(c.Request.Context(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "reconciliation run not found"})
+		return
+	}
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("reconciliation_run_id", id).Msg("Failed to get reconciliation run")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// HandleListReconciliationItems godoc
+// @Summary Xendit 대사 불일치 항목 조회
+// @Description run에서 발견한 불일치 항목을 유형/보정 결과로 필터링해 커서(id) 기반으로 조회합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "reconciliation run ID"
+// @Param type query string false "불일치 유형 (PAID_PENDING_LOCALLY, PAID_EXPIRED_LOCALLY, NOT_PAID_REMOTELY, AMOUNT_MISMATCH, MISSING_LOCAL)"
+// @Param fix_status query string false "보정 결과 (MANUAL, FIXABLE, FIXED, FAILED)"
+// @Param cursor query int false "이전 페이지 next_cursor"
+// @Param limit query int false "조회 개수 (기본 20, 최대 100)"
+// @Success 200 {object} models.ReconciliationItemPage
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/reconciliations/{id}/items [get]
+func (h *PaymentHandler) HandleListReconciliationItems(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reconciliation run id"})
+		return
+	}
+	filter := models.ReconciliationItemFilter{
+		RunID:           id,
+		DiscrepancyType: c.Query("type"),
+		FixStatus:       c.Query("fix_status"),
+		Limit:           20,
+	}
+	if v, err := strconv.ParseInt(c.Query("cursor"), 10, 64); err == nil {
+		filter.Cursor = v
+	}
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil && v > 0 {
+		filter.Limit = min(v, 100)
+	}
+
+	page, err := h.ReconciliationUsecase.ListItems(c.Request.Context(), filter)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "reconciliation run not found"})
+		return
+	}
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("reconciliation_run_id", id).Msg("Failed to list reconciliation items")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/log"
+	"paymentfc/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// settlementCSVFlushRows CSV 스트리밍 시 이 행 수마다 클라이언트로 flush한다.
+const settlementCSVFlushRows = 500
+
+var settlementCSVHeader = []string{
+	"period", "currency", "payment_count", "pending_count", "paid_count", "partially_refunded_count",
+	"refunded_count", "expired_count", "failed_count", "expired_ratio", "failed_ratio",
+	"gross_paid_amount", "tax_base_amount", "tax_amount", "refund_count", "refund_amount", "net_amount",
+}
+
+// HandleSettlementReport godoc
+// @Summary 정산/매출 리포트
+// @Description payments/refunds를 기간(from 이상, to 미만)과 timezone 기준 day/week/month 단위, 통화별로 집계합니다. format=csv 또는 Accept: text/csv면 CSV로 스트리밍합니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "시작(RFC3339 또는 YYYY-MM-DD, 날짜는 timezone 기준). 기본 to - 30일"
+// @Param to query string false "종료(RFC3339 또는 YYYY-MM-DD, 날짜는 timezone 기준). 기본 내일 0시"
+// @Param group_by query string false "집계 단위 (day, week, month). 기본 day"
+// @Param tz query string false "IANA timezone (예: Asia/Jakarta). 기본 UTC"
+// @Param format query string false "json 또는 csv"
+// @Success 200 {object} models.SettlementReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/reports/settlement [get]
+func (h *PaymentHandler) HandleSettlementReport(c *gin.Context) {
+	query := models.SettlementReportQuery{
+		GroupBy:  c.Query("group_by"),
+		Timezone: c.DefaultQuery("tz", "UTC"),
+	}
+	loc, err := time.LoadLocation(query.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tz: " + query.Timezone})
+		return
+	}
+	now := time.Now().In(loc)
+	query.To = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+	if toStr := c.Query("to"); toStr != "" {
+		if query.To, err = parseTimeParamIn(toStr, loc); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	query.From = query.To.AddDate(0, 0, -30)
+	if fromStr := c.Query("from"); fromStr != "" {
+		if query.From, err = parseTimeParamIn(fromStr, loc); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if c.Query("format") == "csv" || strings.Contains(c.GetHeader("Accept"), "text/csv") {
+		h.streamSettlementCSV(c, query)
+		return
+	}
+
+	report, err := h.ReportUsecase.GetSettlementReport(c.Request.Context(), query)
+	if errors.Is(err, service.ErrInvalidReportQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get settlement report")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// streamSettlementCSV 첫 행을 쓰기 전까지는 에러를 JSON으로 응답하고, 이후 에러는 로그만 남기고 스트림을 끊는다.
+func (h *PaymentHandler) streamSettlementCSV(c *gin.Context, query models.SettlementReportQuery) {
+	w := csv.NewWriter(c.Writer)
+	started := false
+	written := 0
+	start := func() error {
+		started = true
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="settlement_%s_%s.csv"`,
+			query.From.Format("20060102"), query.To.Format("20060102")))
+		c.Status(http.StatusOK)
+		return w.Write(settlementCSVHeader)
+	}
+
+	err := h.ReportUsecase.StreamSettlementReport(c.Request.Context(), query, func(row models.SettlementReportRow) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := w.Write(settlementCSVRecord(row)); err != nil {
+			return err
+		}
+		written++
+		if written%settlementCSVFlushRows == 0 {
+			w.Flush()
+			c.Writer.Flush()
+		}
+		return w.Error()
+	})
+	if err != nil && !started {
+		if errors.Is(err, service.ErrInvalidReportQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Logger.Error().Err(err).Msg("Failed to export settlement report")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Logger.Error().Err(err).Int("rows", written).Msg("Settlement report CSV stream aborted")
+		return
+	}
+	if !started {
+		if err := start(); err != nil {
+			log.Logger.Error().Err(err).Msg("Failed to write settlement report CSV header")
+			return
+		}
+	}
+	w.Flush()
+}
+
+func settlementCSVRecord(row models.SettlementReportRow) []string {
+	return []string{
+		row.Period,
+		row.Currency,
+		strconv.FormatInt(row.PaymentCount, 10),
+		strconv.FormatInt(row.PendingCount, 10),
+		strconv.FormatInt(row.PaidCount, 10),
+		strconv.FormatInt(row.PartiallyRefundedCount, 10),
+		strconv.FormatInt(row.RefundedCount, 10),
+		strconv.FormatInt(row.ExpiredCount, 10),
+		strconv.FormatInt(row.FailedCount, 10),
+		strconv.FormatFloat(row.ExpiredRatio, 'f', 4, 64),
+		strconv.FormatFloat(row.FailedRatio, 'f', 4, 64),
+		row.GrossPaidAmount.Decimal(),
+		row.TaxBaseAmount.Decimal(),
+		row.TaxAmount.Decimal(),
+		strconv.FormatInt(row.RefundCount, 10),
+		row.RefundAmount.Decimal(),
+		row.NetAmount.Decimal(),
+	}
+}
+
+// parseTimeParamIn parseTimeParam과 같지만 날짜(YYYY-MM-DD)는 loc의 0시로 해석한다.
+func parseTimeParamIn(raw string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", raw, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time format: %s", raw)
+}
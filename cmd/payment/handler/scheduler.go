@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+	"paymentfc/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleSchedulerLeaderStatus godoc
+// @Summary 스케줄러 리더 상태
+// @Description 이 인스턴스가 싱글톤 잡(미결제 인보이스 확인, 만료 처리, 일일 대사) 리더인지와, Redis lease 키에서 읽은 현재 리더(leader)와 남은 lease 시간(lease_ttl_ms)을 조회합니다. 리더 선출이 꺼져 있으면 enabled=false, is_leader=true입니다.
+// @Tags ADMIN
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.LeaderStatus
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/scheduler/leader [get]
+func (h *PaymentHandler) HandleSchedulerLeaderStatus(c *gin.Context) {
+	status, err := h.Leader.Status(c.Request.Context())
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to read scheduler leader lease")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"paymentfc/constant"
 	"paymentfc/log"
 	"paymentfc/models"
@@ -15,22 +17,62 @@ type PaymentDatabase interface {
 	SavePayment(ctx context.Context, param *models.Payment) error
 	SavePaymentAnomaly(ctx context.Context, param *models.PaymentAnomaly) error
 	SaveFailedPublishEvent(ctx context.Context, param *models.FailedEvent) error
-	MarkPaid(orderID int64) error
-	MarkFailed(orderID int64) error
+	TransitionPaymentStatus(ctx context.Context, param models.PaymentStatusTransition) error
+	GetPaymentStatusHistory(ctx context.Context, paymentID int64) ([]models.PaymentStatusHistory, error)
 	IsAlreadyPaid(ctx context.Context, orderID int64) (bool, error)
 	GetPaymentByOrderID(ctx context.Context, orderID int64) (*models.Payment, error)
-	GetPendingInvoices(ctx context.Context) ([]models.Payment, error)
+	GetUserPaymentByOrderID(ctx context.Context, userID, orderID int64) (*models.Payment, error)
+	ListUserPayments(ctx context.Context, filter models.PaymentFilter) ([]models.Payment, error)
+	GetPendingInvoices(ctx context.Context, limit int) ([]models.Payment, error)
 	SavePaymentRequest(ctx context.Context, param *models.PaymentRequest) error
-	GetPendingPaymentRequests(ctx context.Context) ([]models.PaymentRequest, error)
-	GetFailedPaymentRequests(ctx context.Context) ([]models.PaymentRequest, error)
+	GetPaymentRequestByOrderID(ctx context.Context, orderID int64) (*models.PaymentRequest, error)
+	GetPendingPaymentRequests(ctx context.Context, owner string, lease time.Duration, limit int) ([]models.PaymentRequest, error)
+	GetFailedPaymentRequests(ctx context.Context, owner string, lease time.Duration, limit, maxRetries int) ([]models.PaymentRequest, error)
 	UpdateSuccessPaymentRequest(ctx context.Context, paymentRequestID int64) error
-	UpdateFailedPaymentRequest(ctx context.Context, paymentRequestID int64, notes string) error
+	UpdateFailedPaymentRequest(ctx context.Context, paymentRequestID int64, notes string, nextAttemptAt time.Time) error
+	DeadLetterPaymentRequest(ctx context.Context, paymentRequestID int64, notes string, event models.OutboxEvent) error
 	UpdatePendingPaymentRequest(ctx context.Context, paymentRequestID int64) error
-	GetExpiredPendingPayments(ctx context.Context) ([]models.Payment, error)
-	MarkExpired(ctx context.Context, paymentID int64) error
-	GetFailedPaymentList(ctx context.Context) ([]models.PaymentRequest, error)
+	GetExpiredPendingPayments(ctx context.Context, owner string, lease time.Duration, limit int) ([]models.Payment, error)
+	GetFailedPaymentList(ctx context.Context, maxRetries, limit int) ([]models.PaymentRequest, error)
+	ReserveRefund(ctx context.Context, param *models.Refund) error
+	ReserveOverpaymentRefund(ctx context.Context, param *models.Refund, limit models.Money) (bool, error)
+	UpdateRefund(ctx context.Context, refundID int64, status, xenditRefundID, notes string) error
+	GetRefundedAmount(ctx context.Context, paymentID int64) (models.Money, error)
+	SaveWebhookEvent(ctx context.Context, param *models.WebhookEvent) (bool, error)
+	ClaimWebhookEvents(ctx context.Context, limit int) ([]models.WebhookEvent, error)
+	ClaimWebhookEvent(ctx context.Context, id int64) (*models.WebhookEvent, error)
+	GetWebhookEvent(ctx context.Context, id int64) (*models.WebhookEvent, error)
+	UpdateWebhookEventResult(ctx context.Context, id int64, status, lastError string, nextAttempt time.Time) error
+	ClaimOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	MarkOutboxEventSent(ctx context.Context, id int64) error
+	MarkOutboxEventFailed(ctx context.Context, id int64, status, lastError string, nextAttempt time.Time) error
+	GetOutboxStats(ctx context.Context) (models.OutboxStats, error)
+	GetOutboxEvent(ctx context.Context, id int64) (*models.OutboxEvent, error)
+	GetRetryableFailedEvents(ctx context.Context, limit int) ([]models.FailedEvent, error)
+	GetFailedEvent(ctx context.Context, id int64) (*models.FailedEvent, error)
+	ListFailedEvents(ctx context.Context, filter models.FailedEventFilter) ([]models.FailedEvent, error)
+	UpdateFailedEventResult(ctx context.Context, id int64, status, attempts int, notes string, nextAttempt time.Time) error
+	ListPaymentAnomalies(ctx context.Context, filter models.PaymentAnomalyFilter) ([]models.PaymentAnomaly, error)
+	GetPaymentAnomaly(ctx context.Context, id int64) (*models.PaymentAnomaly, error)
+	UpdatePaymentAnomalyStatus(ctx context.Context, id int64, fromStatuses []int, update models.PaymentAnomalyUpdate) error
+	CreateReconciliationRun(ctx context.Context, param *models.ReconciliationRun) (bool, error)
+	FinishReconciliationRun(ctx context.Context, param *models.ReconciliationRun) error
+	SaveReconciliationItems(ctx context.Context, items []models.ReconciliationItem) error
+	GetReconciliationRun(ctx context.Context, id int64) (*models.ReconciliationRun, error)
+	ListReconciliationRuns(ctx context.Context, filter models.ReconciliationRunFilter) ([]models.ReconciliationRun, error)
+	ListReconciliationItems(ctx context.Context, filter models.ReconciliationItemFilter) ([]models.ReconciliationItem, error)
+	GetPaymentsByExternalIDs(ctx context.Context, externalIDs []string) ([]models.Payment, error)
+	StreamSettlementReport(ctx context.Context, query models.SettlementReportQuery, fn func(models.SettlementReportRow) error) error
+	SaveJobRun(ctx context.Context, param *models.JobRun) error
+	ListJobRuns(ctx context.Context, filter models.JobRunFilter) ([]models.JobRun, error)
+	DeleteJobRunsBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+	GetJobStates(ctx context.Context) ([]models.JobState, error)
+	SetJobPaused(ctx context.Context, jobName string, paused bool, updatedBy int64) error
 }
 
+// ErrRefundAmountExceeded 환불 누적액이 결제 금액을 넘을 때 반환된다.
+var ErrRefundAmountExceeded = errors.New("refund amount exceeds remaining payment amount")
+
 type paymentDatabase struct {
 	DB *gorm.DB
 }
@@ -69,37 +111,111 @@ func (p *paymentDatabase) SaveFailedPublishEvent(ctx context.Context, param *mod
 	return nil
 }
 
-func (p *paymentDatabase) MarkPaid(orderID int64) error {
-	err := p.DB.Table("payments").Where("order_id = ?", orderID).Update("status", constant.PaymentStatusPaid).Error
-	if err != nil {
-		log.Logger.Error().Err(err).Msgf("Failed to mark payment as paid for order_id: %d", orderID)
-		return err
+// maxTransitionAttempts version 충돌 시 최신 상태를 다시 읽어 재시도하는 횟수
+const maxTransitionAttempts = 3
+
+// TransitionPaymentStatus 현재 상태가 전이 표상 허용될 때만 version compare-and-set으로 상태를 바꾸고,
+// 같은 트랜잭션에서 payment_status_history에 이력을 남긴다.
+func (p *paymentDatabase) TransitionPaymentStatus(ctx context.Context, param models.PaymentStatusTransition) error {
+	for attempt := 0; attempt < maxTransitionAttempts; attempt++ {
+		err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var current models.Payment
+			q := tx.Table("payments")
+			if param.PaymentID != 0 {
+				q = q.Where("id = ?", param.PaymentID)
+			} else {
+				q = q.Where("order_id = ?", param.OrderID)
+			}
+			if err := q.First(&current).Error; err != nil {
+				return err
+			}
+			if !CanTransitionPaymentStatus(current.Status, param.ToStatus) {
+				if param.SameStatusNoop && current.Status == param.ToStatus {
+					return insertOutboxEvents(tx, current.OrderID, param.Outbox)
+				}
+				return &IllegalTransitionError{PaymentID: current.ID, From: current.Status, To: param.ToStatus}
+			}
+
+			updates := map[string]interface{}{
+				"status":      param.ToStatus,
+				"version":     gorm.Expr("version + 1"),
+				"update_time": time.Now(),
+			}
+			outbox := param.Outbox
+			// PAID가 되는 순간 월별 인보이스 번호를 발급하고 payment.success에도 싣는다
+			if param.ToStatus == constant.PaymentStatusPaid && current.InvoiceNumber == nil {
+				invoiceNumber, err := allocateInvoiceNumber(tx)
+				if err != nil {
+					return err
+				}
+				updates["invoice_number"] = invoiceNumber
+				if outbox, err = withInvoiceNumber(outbox, invoiceNumber); err != nil {
+					return err
+				}
+			}
+
+			result := tx.Table("payments").
+				Where("id = ? AND version = ?", current.ID, current.Version).
+				Updates(updates)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrPaymentVersionConflict
+			}
+
+			err := tx.Table("payment_status_history").Create(&models.PaymentStatusHistory{
+				PaymentID:  current.ID,
+				OrderID:    current.OrderID,
+				FromStatus: current.Status,
+				ToStatus:   param.ToStatus,
+				Version:    current.Version + 1,
+				Actor:      param.Actor,
+				Reason:     param.Reason,
+			}).Error
+			if err != nil {
+				return err
+			}
+			return insertOutboxEvents(tx, current.OrderID, outbox)
+		})
+		if errors.Is(err, ErrPaymentVersionConflict) {
+			log.Logger.Warn().Int64("payment_id", param.PaymentID).Int64("order_id", param.OrderID).Int("attempt", attempt+1).Msg("Payment version conflict, retrying transition")
+			continue
+		}
+		if err != nil {
+			if IsIllegalTransition(err) {
+				log.Logger.Warn().Err(err).Int64("order_id", param.OrderID).Str("actor", param.Actor).Msg("Rejected payment status transition")
+			} else {
+				log.Logger.Error().Err(err).Int64("payment_id", param.PaymentID).Int64("order_id", param.OrderID).Str("to_status", param.ToStatus).Msg("Failed to transition payment status")
+			}
+			return err
+		}
+		return nil
 	}
-	return nil
+	log.Logger.Error().Int64("payment_id", param.PaymentID).Int64("order_id", param.OrderID).Str("to_status", param.ToStatus).Msg("Gave up payment transition after version conflicts")
+	return ErrPaymentVersionConflict
 }
 
-func (p *paymentDatabase) MarkFailed(orderID int64) error {
-	err := p.DB.Table("payments").Where("order_id = ?", orderID).Updates(
-		map[string]interface{}{
-			"status":      constant.PaymentStatusFailed,
-			"update_time": time.Now(),
-		}).Error
+func (p *paymentDatabase) GetPaymentStatusHistory(ctx context.Context, paymentID int64) ([]models.PaymentStatusHistory, error) {
+	var result []models.PaymentStatusHistory
+	err := p.DB.WithContext(ctx).Table("payment_status_history").Where("payment_id = ?", paymentID).Order("id ASC").Find(&result).Error
 	if err != nil {
-		log.Logger.Error().Err(err).Int64("order_id", orderID).Msg("Failed to mark payment as failed")
-		return err
+		return nil, err
 	}
-	return nil
+	return result, nil
 }
 
-func (p *paymentDatabase) GetPendingInvoices(ctx context.Context) ([]models.Payment, error) {
+func (p *paymentDatabase) GetPendingInvoices(ctx context.Context, limit int) ([]models.Payment, error) {
 	var result []models.Payment
-	err := p.DB.Table("payments").WithContext(ctx).Where("status = ? AND create_time >= now() - interval '1 day'", constant.PaymentStatusPending).Find(&result).Error
+	err := p.DB.Table("payments").WithContext(ctx).Where("status = ? AND create_time >= now() - interval '1 day'", constant.PaymentStatusPending).
+		Order("create_time ASC").Limit(limit).Find(&result).Error
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
+// IsAlreadyPaid PAID 이후 상태(부분/전액 환불 포함)면 true. 늦게 온 PAID 웹훅이 환불된 결제를 다시 전이시키지 않게 한다.
 func (p *paymentDatabase) IsAlreadyPaid(ctx context.Context, orderID int64) (bool, error) {
 	var result models.Payment
 	err := p.DB.Table("payments").WithContext(ctx).Where("order_id = ?", orderID).First(&result).Error
@@ -109,7 +225,7 @@ func (p *paymentDatabase) IsAlreadyPaid(ctx context.Context, orderID int64) (boo
 		}
 		return false, err
 	}
-	return result.Status == constant.PaymentStatusPaid, nil
+	return IsPaidPaymentStatus(result.Status), nil
 }
 
 func (p *paymentDatabase) GetPaymentByOrderID(ctx context.Context, orderID int64) (*models.Payment, error) {
@@ -121,6 +237,39 @@ func (p *paymentDatabase) GetPaymentByOrderID(ctx context.Context, orderID int64
 	return &result, nil
 }
 
+// GetUserPaymentByOrderID 다른 사용자의 주문이면 존재 여부를 숨기기 위해 ErrRecordNotFound를 돌려준다.
+func (p *paymentDatabase) GetUserPaymentByOrderID(ctx context.Context, userID, orderID int64) (*models.Payment, error) {
+	var result models.Payment
+	err := p.DB.Table("payments").WithContext(ctx).Where("order_id = ? AND user_id = ?", orderID, userID).First(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListUserPayments id 내림차순 커서 페이지네이션
+func (p *paymentDatabase) ListUserPayments(ctx context.Context, filter models.PaymentFilter) ([]models.Payment, error) {
+	q := p.DB.Table("payments").WithContext(ctx).Where("user_id = ?", filter.UserID)
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		q = q.Where("create_time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q = q.Where("create_time < ?", filter.To)
+	}
+	if filter.Cursor != 0 {
+		q = q.Where("id < ?", filter.Cursor)
+	}
+
+	var result []models.Payment
+	if err := q.Order("id DESC").Limit(filter.Limit).Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (p *paymentDatabase) SavePaymentRequest(ctx context.Context, param *models.PaymentRequest) error {
 	if err := p.DB.WithContext(ctx).
 		Table("payment_requests").
@@ -134,19 +283,54 @@ func (p *paymentDatabase) SavePaymentRequest(ctx context.Context, param *models.
 	}
 	return nil
 }
-func (p *paymentDatabase) GetPendingPaymentRequests(ctx context.Context) ([]models.PaymentRequest, error) {
-	var result []models.PaymentRequest
-	err := p.DB.Table("payment_requests").WithContext(ctx).Where("status = ?", constant.PaymentStatusPending).Limit(5).Order("create_time ASC").Find(&result).Error
+
+func (p *paymentDatabase) GetPaymentRequestByOrderID(ctx context.Context, orderID int64) (*models.PaymentRequest, error) {
+	var result models.PaymentRequest
+	err := p.DB.Table("payment_requests").WithContext(ctx).Where("order_id = ?", orderID).First(&result).Error
 	if err != nil {
 		return nil, err
 	}
-	return result, nil
+	return &result, nil
+}
+
+// GetPendingPaymentRequests PENDING 결제 요청을 owner 이름으로 lease 동안 잡아 온다.
+// 여러 인스턴스가 같은 건을 잡지 않도록 SKIP LOCKED, lease가 지난 건은 다른 인스턴스가 다시 잡는다.
+func (p *paymentDatabase) GetPendingPaymentRequests(ctx context.Context, owner string, lease time.Duration, limit int) ([]models.PaymentRequest, error) {
+	return p.claimPaymentRequests(ctx, owner, lease, limit, p.DB.Where("status = ?", constant.PaymentStatusPending))
 }
 
-func (p *paymentDatabase) GetFailedPaymentRequests(ctx context.Context) ([]models.PaymentRequest, error) {
+// GetFailedPaymentRequests 백오프가 끝난 FAILED 건과, 재시도 한도(maxRetries)를 넘겨 DEAD로 보낼 FAILED 건을 잡아 온다.
+func (p *paymentDatabase) GetFailedPaymentRequests(ctx context.Context, owner string, lease time.Duration, limit, maxRetries int) ([]models.PaymentRequest, error) {
+	return p.claimPaymentRequests(ctx, owner, lease, limit, p.DB.Where("status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ? OR retry_count > ?)",
+		constant.PaymentStatusFailed, time.Now(), maxRetries))
+}
+
+func (p *paymentDatabase) claimPaymentRequests(ctx context.Context, owner string, lease time.Duration, limit int, cond *gorm.DB) ([]models.PaymentRequest, error) {
 	var result []models.PaymentRequest
-	err := p.DB.Table("payment_requests").WithContext(ctx).Where("status = ? AND retry_count <= ?", constant.PaymentStatusFailed, 3).Limit(5).Order("create_time ASC").Find(&result).Error
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Table("payment_requests").
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where(cond).Where("(lease_until IS NULL OR lease_until < ?)", now).
+			Order("create_time ASC").Limit(limit).Find(&result).Error
+		if err != nil || len(result) == 0 {
+			return err
+		}
+
+		leaseUntil := now.Add(lease)
+		ids := make([]int64, 0, len(result))
+		for i := range result {
+			ids = append(ids, result[i].ID)
+			result[i].ClaimedBy = owner
+			result[i].LeaseUntil = &leaseUntil
+		}
+		return tx.Table("payment_requests").Where("id IN ?", ids).Updates(map[string]interface{}{
+			"claimed_by":  owner,
+			"lease_until": leaseUntil,
+		}).Error
+	})
 	if err != nil {
+		log.Logger.Error().Err(err).Str("owner", owner).Msg("Failed to claim payment requests")
 		return nil, err
 	}
 	return result, nil
@@ -157,6 +341,8 @@ func (p *paymentDatabase) UpdateSuccessPaymentRequest(ctx context.Context, payme
 		map[string]interface{}{
 			"status":      constant.PaymentStatusPaid,
 			"update_time": time.Now(),
+			"claimed_by":  nil,
+			"lease_until": nil,
 		}).Error
 	if err != nil {
 		log.Logger.Error().Err(err).Msgf("Failed to update payment request as success for payment_request_id: %d", paymentRequestID)
@@ -165,13 +351,17 @@ func (p *paymentDatabase) UpdateSuccessPaymentRequest(ctx context.Context, payme
 	return nil
 }
 
-func (p *paymentDatabase) UpdateFailedPaymentRequest(ctx context.Context, paymentRequestID int64, notes string) error {
+// UpdateFailedPaymentRequest 재시도할 수 있는 실패를 남긴다. nextAttemptAt 이후 재시도 잡이 다시 PENDING으로 돌린다.
+func (p *paymentDatabase) UpdateFailedPaymentRequest(ctx context.Context, paymentRequestID int64, notes string, nextAttemptAt time.Time) error {
 	err := p.DB.Table("payment_requests").Where("id = ?", paymentRequestID).Updates(
 		map[string]interface{}{
-			"status":      constant.PaymentStatusFailed,
-			"update_time": time.Now(),
-			"retry_count": gorm.Expr("retry_count + 1"),
-			"notes":       notes,
+			"status":          constant.PaymentStatusFailed,
+			"update_time":     time.Now(),
+			"retry_count":     gorm.Expr("retry_count + 1"),
+			"notes":           notes,
+			"next_attempt_at": nextAttemptAt,
+			"claimed_by":      nil,
+			"lease_until":     nil,
 		}).Error
 	if err != nil {
 		log.Logger.Error().Err(err).Msgf("Failed to update payment request as failed for payment_request_id: %d", paymentRequestID)
@@ -180,11 +370,40 @@ func (p *paymentDatabase) UpdateFailedPaymentRequest(ctx context.Context, paymen
 	return nil
 }
 
+// DeadLetterPaymentRequest 결제 요청을 DEAD로 닫고 같은 트랜잭션에서 payment.request_failed outbox 메시지를 남긴다.
+// 이미 DEAD인 건은 건드리지 않아 메시지가 두 번 쌓이지 않는다.
+func (p *paymentDatabase) DeadLetterPaymentRequest(ctx context.Context, paymentRequestID int64, notes string, event models.OutboxEvent) error {
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Table("payment_requests").
+			Where("id = ? AND status <> ?", paymentRequestID, constant.PaymentRequestStatusDead).
+			Updates(map[string]interface{}{
+				"status":          constant.PaymentRequestStatusDead,
+				"update_time":     time.Now(),
+				"notes":           notes,
+				"next_attempt_at": nil,
+				"claimed_by":      nil,
+				"lease_until":     nil,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return insertOutboxEvents(tx, event.OrderID, []models.OutboxEvent{event})
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("payment_request_id", paymentRequestID).Msg("Failed to dead-letter payment request")
+		return err
+	}
+	return nil
+}
+
 func (p *paymentDatabase) UpdatePendingPaymentRequest(ctx context.Context, paymentRequestID int64) error {
 	err := p.DB.Table("payment_requests").Where("id = ?", paymentRequestID).Updates(
 		map[string]interface{}{
-			"status":      constant.PaymentStatusPending,
-			"update_time": time.Now(),
+			"status":          constant.PaymentStatusPending,
+			"update_time":     time.Now(),
+			"next_attempt_at": nil,
+			"claimed_by":      nil,
+			"lease_until":     nil,
 		}).Error
 	if err != nil {
 		log.Logger.Error().Err(err).Msgf("Failed to update payment request as pending for payment_request_id: %d", paymentRequestID)
@@ -193,34 +412,179 @@ func (p *paymentDatabase) UpdatePendingPaymentRequest(ctx context.Context, payme
 	return nil
 }
 
-func (p *paymentDatabase) GetExpiredPendingPayments(ctx context.Context) ([]models.Payment, error) {
+// GetExpiredPendingPayments 만료된 PENDING 결제를 owner 이름으로 lease 동안 최대 limit건 잡아 온다 (SKIP LOCKED).
+func (p *paymentDatabase) GetExpiredPendingPayments(ctx context.Context, owner string, lease time.Duration, limit int) ([]models.Payment, error) {
 	var result []models.Payment
-	err := p.DB.Table("payments").WithContext(ctx).Where("status = ? AND expired_time < now()", constant.PaymentStatusPending).Find(&result).Error
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Table("payments").
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expired_time < now()", constant.PaymentStatusPending).
+			Where("(lease_until IS NULL OR lease_until < ?)", now).
+			Order("expired_time ASC").Limit(limit).Find(&result).Error
+		if err != nil || len(result) == 0 {
+			return err
+		}
+
+		leaseUntil := now.Add(lease)
+		ids := make([]int64, 0, len(result))
+		for i := range result {
+			ids = append(ids, result[i].ID)
+			result[i].ClaimedBy = owner
+			result[i].LeaseUntil = &leaseUntil
+		}
+		return tx.Table("payments").Where("id IN ?", ids).Updates(map[string]interface{}{
+			"claimed_by":  owner,
+			"lease_until": leaseUntil,
+		}).Error
+	})
 	if err != nil {
+		log.Logger.Error().Err(err).Str("owner", owner).Msg("Failed to claim expired pending payments")
 		return nil, err
 	}
 	return result, nil
 }
 
-func (p *paymentDatabase) MarkExpired(ctx context.Context, paymentID int64) error {
-	err := p.DB.Table("payments").Where("id = ?", paymentID).Updates(
+// GetFailedPaymentList 재시도 잡이 아직 다시 처리할 FAILED 요청. 한도를 넘겨 DEAD로 닫힌 요청은 빠진다.
+func (p *paymentDatabase) GetFailedPaymentList(ctx context.Context, maxRetries, limit int) ([]models.PaymentRequest, error) {
+	var result []models.PaymentRequest
+	err := p.DB.Table("payment_requests").WithContext(ctx).
+		Where("status = ? AND retry_count <= ?", constant.PaymentStatusFailed, maxRetries).
+		Limit(limit).Order("create_time ASC").Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReserveRefund payment row를 잠근 상태에서 누적 환불액을 확인하고 PENDING 환불 건을 생성한다.
+// param.Amount가 0이면 남은 금액 전액으로 채운다. 통화는 항상 payment 통화를 따른다.
+func (p *paymentDatabase) ReserveRefund(ctx context.Context, param *models.Refund) error {
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payment models.Payment
+		if err := tx.Table("payments").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", param.PaymentID).First(&payment).Error; err != nil {
+			return err
+		}
+
+		var refunded int64
+		if err := tx.Table("refunds").
+			Select("COALESCE(SUM(amount), 0)").
+			Where("payment_id = ? AND status <> ? AND reason <> ?", param.PaymentID, constant.RefundStatusFailed, constant.RefundReasonOverpayment).
+			Scan(&refunded).Error; err != nil {
+			return err
+		}
+
+		if param.Amount.Currency != "" && !param.Amount.SameCurrency(payment.Amount) {
+			return fmt.Errorf("refund currency %s does not match payment currency %s", param.Amount.Currency, payment.Amount.Currency)
+		}
+		remaining := payment.Amount.Minor - refunded
+		if param.Amount.IsZero() {
+			param.Amount.Minor = remaining
+		}
+		param.Amount.Currency = payment.Amount.Currency
+		if param.Amount.Minor <= 0 || param.Amount.Minor > remaining {
+			return ErrRefundAmountExceeded
+		}
+
+		param.Status = constant.RefundStatusPending
+		param.UpdateTime = time.Now()
+		return tx.Table("refunds").Create(param).Error
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("payment_id", param.PaymentID).Msg("Failed to reserve refund")
+		return err
+	}
+	return nil
+}
+
+// ReserveOverpaymentRefund 초과 입금 환불 건을 param.ExternalID로 하나만 만든다. payment row를 잠그고 실패하지 않은 초과 입금 환불 합계가 limit을 넘지 않는지 확인한다.
+// 같은 ExternalID가 이미 있으면 그 건을 param에 채우고, FAILED였을 때만 PENDING으로 되돌려 send=true를 돌려준다.
+// PENDING/SUCCEEDED 건은 게이트웨이에 이미 나갔을 수 있으므로 send=false.
+func (p *paymentDatabase) ReserveOverpaymentRefund(ctx context.Context, param *models.Refund, limit models.Money) (bool, error) {
+	send := false
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payment models.Payment
+		if err := tx.Table("payments").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", param.PaymentID).First(&payment).Error; err != nil {
+			return err
+		}
+
+		var existing models.Refund
+		err := tx.Table("refunds").Where("external_id = ?", param.ExternalID).First(&existing).Error
+		found := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if found && existing.Status != constant.RefundStatusFailed {
+			*param = existing
+			return nil
+		}
+
+		var refunded int64
+		if err := tx.Table("refunds").
+			Select("COALESCE(SUM(amount), 0)").
+			Where("payment_id = ? AND status <> ? AND reason = ?", param.PaymentID, constant.RefundStatusFailed, constant.RefundReasonOverpayment).
+			Scan(&refunded).Error; err != nil {
+			return err
+		}
+		if !param.Amount.SameCurrency(limit) || refunded+param.Amount.Minor > limit.Minor {
+			return ErrRefundAmountExceeded
+		}
+
+		send = true
+		now := time.Now()
+		if found {
+			existing.Status = constant.RefundStatusPending
+			existing.Amount = param.Amount
+			existing.UpdateTime = now
+			*param = existing
+			return tx.Table("refunds").Where("id = ?", existing.ID).Updates(map[string]interface{}{
+				"status":      constant.RefundStatusPending,
+				"amount":      param.Amount.Minor,
+				"notes":       "",
+				"update_time": now,
+			}).Error
+		}
+		param.Status = constant.RefundStatusPending
+		param.UpdateTime = now
+		return tx.Table("refunds").Create(param).Error
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("payment_id", param.PaymentID).Str("external_id", param.ExternalID).Msg("Failed to reserve overpayment refund")
+		return false, err
+	}
+	return send, nil
+}
+
+func (p *paymentDatabase) UpdateRefund(ctx context.Context, refundID int64, status, xenditRefundID, notes string) error {
+	err := p.DB.WithContext(ctx).Table("refunds").Where("id = ?", refundID).Updates(
 		map[string]interface{}{
-			"status":      constant.PaymentStatusExpired,
-			"update_time": time.Now(),
+			"status":           status,
+			"xendit_refund_id": xenditRefundID,
+			"notes":            notes,
+			"update_time":      time.Now(),
 		}).Error
 	if err != nil {
-		log.Logger.Error().Err(err).Int64("payment_id", paymentID).Msg("Failed to mark payment as expired")
+		log.Logger.Error().Err(err).Int64("refund_id", refundID).Msg("Failed to update refund")
 		return err
 	}
 	return nil
 }
 
-func (p *paymentDatabase) GetFailedPaymentList(ctx context.Context) ([]models.PaymentRequest, error) {
-	var result []models.PaymentRequest
-	err := p.DB.Table("payment_requests").WithContext(ctx).Where("status = ? and retry_count <= ?", constant.PaymentStatusFailed, 3).
-		Limit(5).Order("create_time ASC").Find(&result).Error
+// GetRefundedAmount 게이트웨이가 완료(SUCCEEDED)했다고 응답한 환불 합계. 진행 중인 환불과 초과 입금 환불은 빠진다.
+func (p *paymentDatabase) GetRefundedAmount(ctx context.Context, paymentID int64) (models.Money, error) {
+	var payment models.Payment
+	if err := p.DB.WithContext(ctx).Table("payments").Select("currency").Where("id = ?", paymentID).First(&payment).Error; err != nil {
+		return models.Money{}, err
+	}
+	var refunded int64
+	err := p.DB.WithContext(ctx).Table("refunds").
+		Select("COALESCE(SUM(amount), 0)").
+		Where("payment_id = ? AND status = ? AND reason <> ?", paymentID, constant.RefundStatusSucceeded, constant.RefundReasonOverpayment).
+		Scan(&refunded).Error
 	if err != nil {
-		return nil, err
+		return models.Money{}, err
 	}
-	return result, nil
+	return models.NewMoney(refunded, payment.Amount.Currency), nil
 }
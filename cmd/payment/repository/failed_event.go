@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"paymentfc/constant"
+	"paymentfc/log"
+	"paymentfc/models"
+)
+
+// GetRetryableFailedEvents 재처리 잡 대상 (NeedToCheck/Retry 중 시도 횟수가 남았고 다음 시도 시각이 된 건)
+func (p *paymentDatabase) GetRetryableFailedEvents(ctx context.Context, limit int) ([]models.FailedEvent, error) {
+	var result []models.FailedEvent
+	err := p.DB.Table("failed_events").WithContext(ctx).
+		Where("status IN ? AND attempts < ? AND next_attempt_time <= ?",
+			[]int{constant.FailedPublishEventStatusNeedToCheck, constant.FailedPublishEventStatusRetry},
+			constant.MaxFailedEventAttempts, time.Now()).
+		Order("id").Limit(limit).Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (p *paymentDatabase) GetFailedEvent(ctx context.Context, id int64) (*models.FailedEvent, error) {
+	var result models.FailedEvent
+	err := p.DB.Table("failed_events").WithContext(ctx).Where("id = ?", id).First(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListFailedEvents id 내림차순 커서 페이지네이션
+func (p *paymentDatabase) ListFailedEvents(ctx context.Context, filter models.FailedEventFilter) ([]models.FailedEvent, error) {
+	q := p.DB.Table("failed_events").WithContext(ctx)
+	if filter.Status != 0 {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.FailedType != 0 {
+		q = q.Where("failed_type = ?", filter.FailedType)
+	}
+	if filter.OrderID != 0 {
+		q = q.Where("order_id = ?", filter.OrderID)
+	}
+	if !filter.From.IsZero() {
+		q = q.Where("create_time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q = q.Where("create_time < ?", filter.To)
+	}
+	if filter.Cursor != 0 {
+		q = q.Where("id < ?", filter.Cursor)
+	}
+
+	var result []models.FailedEvent
+	if err := q.Order("id DESC").Limit(filter.Limit).Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (p *paymentDatabase) UpdateFailedEventResult(ctx context.Context, id int64, status, attempts int, notes string, nextAttempt time.Time) error {
+	err := p.DB.WithContext(ctx).Table("failed_events").Where("id = ?", id).Updates(map[string]interface{}{
+		"status":            status,
+		"attempts":          attempts,
+		"notes":             notes,
+		"next_attempt_time": nextAttempt,
+		"update_time":       time.Now(),
+	}).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("failed_event_id", id).Msg("Failed to update failed_event")
+		return err
+	}
+	return nil
+}
+
+func (p *paymentDatabase) GetOutboxEvent(ctx context.Context, id int64) (*models.OutboxEvent, error) {
+	var result models.OutboxEvent
+	err := p.DB.Table("outbox").WithContext(ctx).Where("id = ?", id).First(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"paymentfc/models"
+)
+
+var (
+	ErrUnknownGateway          = errors.New("unknown payment gateway")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrInvalidWebhookPayload   = errors.New("invalid webhook payload")
+)
+
+// GatewayStatusError 게이트웨이 API가 성공이 아닌 HTTP 상태를 돌려줬다. 호출한 쪽이 상태 코드로 재시도 여부를 판단한다.
+type GatewayStatusError struct {
+	API        string // 예: "xendit API", "midtrans snap API"
+	StatusCode int
+}
+
+func (e *GatewayStatusError) Error() string {
+	return fmt.Sprintf("%s returned status: %d", e.API, e.StatusCode)
+}
+
+// PaymentGateway provider 중립 결제 게이트웨이. 상태 값은 모두 constant.PaymentStatus*로 정규화해서 돌려준다.
+type PaymentGateway interface {
+	Name() string
+	CreateCheckout(ctx context.Context, request models.CheckoutRequest) (*models.CheckoutResponse, error)
+	GetCheckoutStatus(ctx context.Context, externalID string) (string, error)
+	ExpireCheckout(ctx context.Context, checkoutID, externalID string) error
+	Refund(ctx context.Context, request models.GatewayRefundRequest) (*models.GatewayRefundResponse, error)
+	VerifyWebhook(header http.Header, body []byte) (*models.PaymentWebhookEvent, error)
+}
+
+// GatewayRegistry provider 이름으로 게이트웨이를 찾는다. 이름이 비어 있으면 기본 게이트웨이.
+type GatewayRegistry struct {
+	defaultProvider string
+	gateways        map[string]PaymentGateway
+}
+
+func NewGatewayRegistry(defaultProvider string, gateways ...PaymentGateway) (*GatewayRegistry, error) {
+	r := &GatewayRegistry{
+		defaultProvider: defaultProvider,
+		gateways:        make(map[string]PaymentGateway, len(gateways)),
+	}
+	for _, gw := range gateways {
+		r.gateways[gw.Name()] = gw
+	}
+	if _, ok := r.gateways[defaultProvider]; !ok {
+		return nil, fmt.Errorf("%w: default provider %q is not registered", ErrUnknownGateway, defaultProvider)
+	}
+	return r, nil
+}
+
+func (r *GatewayRegistry) Get(provider string) (PaymentGateway, error) {
+	if provider == "" {
+		provider = r.defaultProvider
+	}
+	gw, ok := r.gateways[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownGateway, provider)
+	}
+	return gw, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"paymentfc/models"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyStore Idempotency-Key별 요청 지문/응답 저장소
+type IdempotencyStore interface {
+	// Reserve 키가 비어 있으면 처리 중 레코드를 만들고 nil을 돌려준다. 이미 있으면 저장된 레코드를 돌려준다.
+	Reserve(ctx context.Context, key string, record models.IdempotencyRecord, ttl time.Duration) (*models.IdempotencyRecord, error)
+	Save(ctx context.Context, key string, record models.IdempotencyRecord, ttl time.Duration) error
+	Release(ctx context.Context, key string) error
+}
+
+type redisIdempotencyStore struct {
+	client *redis.Client
+}
+
+func NewIdempotencyStore(client *redis.Client) IdempotencyStore {
+	return &redisIdempotencyStore{client: client}
+}
+
+func (s *redisIdempotencyStore) Reserve(ctx context.Context, key string, record models.IdempotencyRecord, ttl time.Duration) (*models.IdempotencyRecord, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	// SETNX와 GET 사이에 키가 만료될 수 있으므로 한 번 더 시도한다.
+	for attempt := 0; attempt < 2; attempt++ {
+		ok, err := s.client.SetNX(ctx, key, data, ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return nil, nil
+		}
+		raw, err := s.client.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var existing models.IdempotencyRecord
+		if err := json.Unmarshal(raw, &existing); err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+	return nil, errors.New("idempotency key expired while reserving")
+}
+
+func (s *redisIdempotencyStore) Save(ctx context.Context, key string, record models.IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, key, data, ttl).Err()
+}
+
+func (s *redisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// InvoiceCache 렌더링된 인보이스 PDF 바이트 캐시
+type InvoiceCache interface {
+	// Get 캐시에 없으면 nil, nil을 돌려준다.
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, data []byte, ttl time.Duration) error
+}
+
+type redisInvoiceCache struct {
+	client *redis.Client
+}
+
+func NewInvoiceCache(client *redis.Client) InvoiceCache {
+	return &redisInvoiceCache{client: client}
+}
+
+func (c *redisInvoiceCache) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (c *redisInvoiceCache) Set(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, key, data, ttl).Err()
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"paymentfc/constant"
+	"paymentfc/models"
+
+	"gorm.io/gorm"
+)
+
+// allocateInvoiceNumber TransitionPaymentStatus 트랜잭션 안에서 호출된다. 월별 카운터 행을 잠그고 증가시키므로
+// 동시 결제는 순서대로 번호를 받고, 트랜잭션이 롤백되면 번호도 함께 되돌아가 빈 번호가 생기지 않는다.
+func allocateInvoiceNumber(tx *gorm.DB) (string, error) {
+	var seq models.InvoiceSequence
+	err := tx.Raw(`
+		INSERT INTO invoice_sequences (period, last_number, update_time)
+		VALUES (to_char(now() AT TIME ZONE @tz, 'YYYY/MM'), 1, now())
+		ON CONFLICT (period) DO UPDATE
+		SET last_number = invoice_sequences.last_number + 1, update_time = now()
+		RETURNING period, last_number`,
+		map[string]interface{}{"tz": constant.InvoiceNumberTimezone},
+	).Scan(&seq).Error
+	if err != nil {
+		return "", err
+	}
+	if seq.Period == "" || seq.LastNumber == 0 {
+		return "", fmt.Errorf("invoice sequence allocation returned no row")
+	}
+	return FormatInvoiceNumber(seq.Period, seq.LastNumber), nil
+}
+
+// FormatInvoiceNumber period(YYYY/MM)와 월 내 순번으로 INV/YYYY/MM/000123 형식 번호를 만든다.
+func FormatInvoiceNumber(period string, number int64) string {
+	return fmt.Sprintf("%s/%s/%06d", constant.InvoiceNumberPrefix, period, number)
+}
+
+// withInvoiceNumber payment.success outbox payload에 발급된 인보이스 번호를 채운 복사본을 돌려준다.
+func withInvoiceNumber(events []models.OutboxEvent, invoiceNumber string) ([]models.OutboxEvent, error) {
+	out := make([]models.OutboxEvent, len(events))
+	for i, event := range events {
+		if event.EventType == constant.KafkaTopicPaymentSuccess {
+			var payload map[string]interface{}
+			if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+				return nil, err
+			}
+			payload["invoice_number"] = invoiceNumber
+			data, err := json.Marshal(payload)
+			if err != nil {
+				return nil, err
+			}
+			event.Payload = string(data)
+		}
+		out[i] = event
+	}
+	return out, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"paymentfc/log"
+	"paymentfc/models"
+
+	"gorm.io/gorm/clause"
+)
+
+func (p *paymentDatabase) SaveJobRun(ctx context.Context, param *models.JobRun) error {
+	if err := p.DB.WithContext(ctx).Table("job_runs").Create(param).Error; err != nil {
+		log.Logger.Error().Err(err).Str("job", param.JobName).Msg("Failed to save job run")
+		return err
+	}
+	return nil
+}
+
+// ListJobRuns id 내림차순 커서 페이지네이션
+func (p *paymentDatabase) ListJobRuns(ctx context.Context, filter models.JobRunFilter) ([]models.JobRun, error) {
+	q := p.DB.Table("job_runs").WithContext(ctx)
+	if filter.JobName != "" {
+		q = q.Where("job_name = ?", filter.JobName)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.Cursor != 0 {
+		q = q.Where("id < ?", filter.Cursor)
+	}
+
+	var result []models.JobRun
+	if err := q.Order("id DESC").Limit(filter.Limit).Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteJobRunsBefore start_time이 before보다 이른 실행 기록을 최대 limit건 지우고 지운 건수를 돌려준다.
+func (p *paymentDatabase) DeleteJobRunsBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	res := p.DB.WithContext(ctx).Exec(
+		"DELETE FROM job_runs WHERE id IN (SELECT id FROM job_runs WHERE start_time < ? ORDER BY id LIMIT ?)", before, limit)
+	if res.Error != nil {
+		log.Logger.Error().Err(res.Error).Time("before", before).Msg("Failed to delete old job runs")
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
+
+// GetJobStates 일시정지 상태가 기록된 잡만 돌려준다. 없는 잡은 실행 중인 것으로 본다.
+func (p *paymentDatabase) GetJobStates(ctx context.Context) ([]models.JobState, error) {
+	var result []models.JobState
+	if err := p.DB.Table("job_states").WithContext(ctx).Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (p *paymentDatabase) SetJobPaused(ctx context.Context, jobName string, paused bool, updatedBy int64) error {
+	state := models.JobState{JobName: jobName, Paused: paused, UpdatedBy: updatedBy, UpdateTime: time.Now()}
+	err := p.DB.WithContext(ctx).Table("job_states").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "job_name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"paused", "updated_by", "update_time"}),
+		}).
+		Create(&state).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Str("job", jobName).Bool("paused", paused).Msg("Failed to update job state")
+		return err
+	}
+	return nil
+}
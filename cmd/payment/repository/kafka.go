@@ -4,19 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"paymentfc/constant"
+	"paymentfc/models"
 
 	"github.com/segmentio/kafka-go"
 )
 
 type PaymentEventPublisher interface {
 	PublishPaymentStatus(ctx context.Context, orderID int64, status string, topic string) error
+	PublishPaymentRefunded(ctx context.Context, event models.PaymentRefundedEvent) error
+	PublishOutboxEvent(ctx context.Context, event models.OutboxEvent) error
+}
+
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
 }
 
 type kafkaPublisher struct {
-	writer *kafka.Writer
+	writer messageWriter
 }
 
 // NewKafkaPublisher new kafka publisher by given writer pointer of kafka.Writer.
+// The writer must not have a fixed Topic; each message carries its own topic.
 //
 // It returns PaymentEventPublisher when successful.
 // Otherwise, empty PaymentEventPublisher will be returned.
@@ -28,17 +37,69 @@ func NewKafkaPublisher(writer *kafka.Writer) PaymentEventPublisher {
 
 // PublishPaymentStatus publishes payment status event to kafka (e.g. "paid", "failed").
 func (k *kafkaPublisher) PublishPaymentStatus(ctx context.Context, orderID int64, status string, topic string) error {
-	payload := map[string]interface{}{
-		"order_id": orderID,
-		"status":   status,
-		"topic":    topic,
-	}
-	data, err := json.Marshal(payload)
+	data, err := json.Marshal(paymentStatusPayload(orderID, status, topic))
 	if err != nil {
 		return err
 	}
 	return k.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
 		Key:   []byte(fmt.Sprintf("order-%d", orderID)),
 		Value: data,
 	})
 }
+
+// PublishPaymentRefunded publishes payment.refunded event to kafka with refund amount details.
+func (k *kafkaPublisher) PublishPaymentRefunded(ctx context.Context, event models.PaymentRefundedEvent) error {
+	data, err := json.Marshal(paymentRefundedPayload(event))
+	if err != nil {
+		return err
+	}
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Topic: constant.KafkaTopicPaymentRefunded,
+		Key:   []byte(fmt.Sprintf("order-%d", event.OrderID)),
+		Value: data,
+	})
+}
+
+// PublishOutboxEvent outbox에 저장된 메시지를 그대로 발행한다. EventType이 곧 토픽이다.
+func (k *kafkaPublisher) PublishOutboxEvent(ctx context.Context, event models.OutboxEvent) error {
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Topic: event.EventType,
+		Key:   []byte(event.MessageKey),
+		Value: []byte(event.Payload),
+	})
+}
+
+func paymentStatusPayload(orderID int64, status string, topic string) map[string]interface{} {
+	return map[string]interface{}{
+		"order_id": orderID,
+		"status":   status,
+		"topic":    topic,
+	}
+}
+
+func paymentRefundedPayload(event models.PaymentRefundedEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"order_id":        event.OrderID,
+		"payment_id":      event.PaymentID,
+		"refund_id":       event.RefundID,
+		"amount":          event.Amount,
+		"refunded_amount": event.RefundedAmount,
+		"fully_refunded":  event.FullyRefunded,
+		"status":          event.Status,
+		"topic":           constant.KafkaTopicPaymentRefunded,
+	}
+}
+
+func paymentRequestFailedPayload(event models.PaymentRequestFailedEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"order_id":           event.OrderID,
+		"user_id":            event.UserID,
+		"payment_request_id": event.PaymentRequestID,
+		"amount":             event.Amount,
+		"retry_count":        event.RetryCount,
+		"reason":             event.Reason,
+		"status":             event.Status,
+		"topic":              constant.KafkaTopicPaymentRequestFailed,
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"paymentfc/constant"
+	"paymentfc/models"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingWriter struct {
+	messages []kafka.Message
+}
+
+func (w *recordingWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func TestKafkaPublisher_PublishOutboxEventRoutesByEventType(t *testing.T) {
+	paid, err := NewPaymentStatusOutboxEvent(1, constant.PaymentStatusPaid, constant.KafkaTopicPaymentSuccess)
+	require.NoError(t, err)
+	expired, err := NewPaymentStatusOutboxEvent(2, constant.PaymentStatusExpired, constant.KafkaTopicPaymentExpired)
+	require.NoError(t, err)
+	refunded, err := NewPaymentRefundedOutboxEvent(models.PaymentRefundedEvent{OrderID: 3, Status: constant.PaymentStatusRefunded})
+	require.NoError(t, err)
+	requestFailed, err := NewPaymentRequestFailedOutboxEvent(models.PaymentRequestFailedEvent{OrderID: 4, Status: constant.PaymentRequestStatusDead})
+	require.NoError(t, err)
+
+	w := &recordingWriter{}
+	publisher := &kafkaPublisher{writer: w}
+	for _, event := range []models.OutboxEvent{paid, expired, refunded, requestFailed} {
+		require.NoError(t, publisher.PublishOutboxEvent(context.Background(), event))
+	}
+
+	require.Len(t, w.messages, 4)
+	assert.Equal(t, constant.KafkaTopicPaymentSuccess, w.messages[0].Topic)
+	assert.Equal(t, constant.KafkaTopicPaymentExpired, w.messages[1].Topic)
+	assert.Equal(t, constant.KafkaTopicPaymentRefunded, w.messages[2].Topic)
+	assert.Equal(t, constant.KafkaTopicPaymentRequestFailed, w.messages[3].Topic)
+	assert.Equal(t, []byte("order-4"), w.messages[3].Key)
+}
+
+func TestKafkaPublisher_DirectPublishSetsTopic(t *testing.T) {
+	w := &recordingWriter{}
+	publisher := &kafkaPublisher{writer: w}
+
+	require.NoError(t, publisher.PublishPaymentStatus(context.Background(), 1, constant.PaymentStatusFailed, constant.KafkaTopicPaymentFailed))
+	require.NoError(t, publisher.PublishPaymentRefunded(context.Background(), models.PaymentRefundedEvent{OrderID: 1}))
+
+	require.Len(t, w.messages, 2)
+	assert.Equal(t, constant.KafkaTopicPaymentFailed, w.messages[0].Topic)
+	assert.Equal(t, constant.KafkaTopicPaymentRefunded, w.messages[1].Topic)
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LeaderLock 인스턴스 하나만 잡을 수 있는 lease. 만료 전에 Renew하지 않으면 다른 인스턴스가 가져간다.
+type LeaderLock interface {
+	// Acquire 키가 비어 있으면 owner로 ttl 동안 잡고 true를 돌려준다. 다른 owner가 잡고 있으면 false.
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	// Renew owner가 아직 잡고 있을 때만 ttl을 연장한다. 이미 lease를 잃었으면 false.
+	Renew(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	// Release owner가 잡고 있을 때만 키를 지운다.
+	Release(ctx context.Context, key, owner string) error
+	// Holder 지금 lease를 잡고 있는 owner와 남은 ttl. 아무도 잡고 있지 않으면 빈 owner를 돌려준다.
+	Holder(ctx context.Context, key string) (string, time.Duration, error)
+}
+
+// 다른 인스턴스가 잡은 lease를 연장/삭제하지 않도록 값 비교와 변경을 한 번에 수행한다.
+var (
+	renewLeaderScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseLeaderScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+type redisLeaderLock struct {
+	client *redis.Client
+}
+
+func NewLeaderLock(client *redis.Client) LeaderLock {
+	return &redisLeaderLock{client: client}
+}
+
+func (l *redisLeaderLock) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return l.client.SetNX(ctx, key, owner, ttl).Result()
+}
+
+func (l *redisLeaderLock) Renew(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	n, err := renewLeaderScript.Run(ctx, l.client, []string{key}, owner, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (l *redisLeaderLock) Release(ctx context.Context, key, owner string) error {
+	return releaseLeaderScript.Run(ctx, l.client, []string{key}, owner).Err()
+}
+
+func (l *redisLeaderLock) Holder(ctx context.Context, key string) (string, time.Duration, error) {
+	// 값과 ttl을 한 트랜잭션에서 읽어 그 사이 lease가 넘어가도 서로 다른 owner의 값이 섞이지 않게 한다.
+	pipe := l.client.TxPipeline()
+	get := pipe.Get(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return "", 0, err
+	}
+	owner, err := get.Result()
+	if errors.Is(err, redis.Nil) {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	// 만료가 없는 키(-1)는 ttl 0으로 보여준다.
+	return owner, max(pttl.Val(), 0), nil
+}
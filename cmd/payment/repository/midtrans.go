@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"paymentfc/constant"
+	"paymentfc/log"
+	"paymentfc/models"
+)
+
+const (
+	midtransDefaultAPIBaseURL  = "https://api.midtrans.com"
+	midtransDefaultSnapBaseURL = "https://app.midtrans.com/snap"
+
+	// Snap 결제 페이지 유효 시간 (Xendit 인보이스 기본값과 맞춘다)
+	midtransCheckoutExpiry = 24 * time.Hour
+
+	// client를 넘기지 않았을 때 쓰는 HTTP 타임아웃
+	midtransDefaultHTTPTimeout = 10 * time.Second
+)
+
+// midtransGateway Midtrans Snap(체크아웃) + Core API(상태/만료/환불) 어댑터
+type midtransGateway struct {
+	serverKey   string
+	apiBaseURL  string
+	snapBaseURL string
+	client      *http.Client
+}
+
+// NewMidtransGateway client가 nil이면 midtransDefaultHTTPTimeout을 가진 클라이언트를 쓴다.
+func NewMidtransGateway(serverKey, apiBaseURL, snapBaseURL string, client *http.Client) PaymentGateway {
+	if apiBaseURL == "" {
+		apiBaseURL = midtransDefaultAPIBaseURL
+	}
+	if snapBaseURL == "" {
+		snapBaseURL = midtransDefaultSnapBaseURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: midtransDefaultHTTPTimeout}
+	}
+	return &midtransGateway{
+		serverKey:   serverKey,
+		apiBaseURL:  strings.TrimRight(apiBaseURL, "/"),
+		snapBaseURL: strings.TrimRight(snapBaseURL, "/"),
+		client:      client,
+	}
+}
+
+func (g *midtransGateway) Name() string {
+	return constant.PaymentProviderMidtrans
+}
+
+func (g *midtransGateway) CreateCheckout(ctx context.Context, request models.CheckoutRequest) (*models.CheckoutResponse, error) {
+	if request.Amount.Currency != "IDR" {
+		return nil, fmt.Errorf("midtrans does not support currency: %s", request.Amount.Currency)
+	}
+	snapReq := models.MidtransSnapRequest{
+		TransactionDetails: models.MidtransTransactionDetails{
+			OrderID:     request.ExternalID,
+			GrossAmount: request.Amount.Number(),
+		},
+		CustomerDetails: models.MidtransCustomerDetails{Email: request.PayerEmail},
+		Expiry:          &models.MidtransExpiry{Unit: "minute", Duration: int(midtransCheckoutExpiry.Minutes())},
+	}
+
+	var snapResp models.MidtransSnapResponse
+	status, err := g.do(ctx, http.MethodPost, g.snapBaseURL+"/v1/transactions", snapReq, &snapResp)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusCreated && status != http.StatusOK {
+		log.Logger.Error().Strs("errors", snapResp.ErrorMessages).Msgf("Midtrans Snap API returned status: %d", status)
+		return nil, &GatewayStatusError{API: "midtrans snap API", StatusCode: status}
+	}
+
+	return &models.CheckoutResponse{
+		Provider:   g.Name(),
+		ID:         snapResp.Token,
+		InvoiceURL: snapResp.RedirectURL,
+		Status:     constant.PaymentStatusPending,
+		ExpireDate: time.Now().Add(midtransCheckoutExpiry),
+	}, nil
+}
+
+func (g *midtransGateway) GetCheckoutStatus(ctx context.Context, externalID string) (string, error) {
+	var notif models.MidtransNotification
+	status, err := g.do(ctx, http.MethodGet, fmt.Sprintf("%s/v2/%s/status", g.apiBaseURL, externalID), nil, &notif)
+	if err != nil {
+		return "", err
+	}
+	// Core API는 HTTP 200에 body의 status_code로 실패를 알려주기도 한다.
+	if status != http.StatusOK || notif.StatusCode == "404" {
+		return "", fmt.Errorf("midtrans status API returned status: %d (%s) for order_id: %s", status, notif.StatusCode, externalID)
+	}
+	return normalizeMidtransStatus(notif.TransactionStatus, notif.FraudStatus), nil
+}
+
+func (g *midtransGateway) ExpireCheckout(ctx context.Context, checkoutID, externalID string) error {
+	var notif models.MidtransNotification
+	status, err := g.do(ctx, http.MethodPost, fmt.Sprintf("%s/v2/%s/expire", g.apiBaseURL, externalID), nil, &notif)
+	if err != nil {
+		return err
+	}
+	// 407: 이미 만료된 거래 -> 성공으로 본다
+	if status != http.StatusOK || (notif.StatusCode != "" && notif.StatusCode != "200" && notif.StatusCode != "407") {
+		return fmt.Errorf("midtrans expire API returned status: %d (%s)", status, notif.StatusMessage)
+	}
+	return nil
+}
+
+func (g *midtransGateway) Refund(ctx context.Context, request models.GatewayRefundRequest) (*models.GatewayRefundResponse, error) {
+	var refundResp models.MidtransRefundResponse
+	status, err := g.do(ctx, http.MethodPost, fmt.Sprintf("%s/v2/%s/refund", g.apiBaseURL, request.ExternalID), models.MidtransRefundRequest{
+		RefundKey: request.ReferenceID,
+		Amount:    request.Amount.Number(),
+		Reason:    request.Reason,
+	}, &refundResp)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, &GatewayStatusError{API: "midtrans refund API", StatusCode: status}
+	}
+	if refundResp.StatusCode != "200" {
+		return &models.GatewayRefundResponse{
+			ID:          refundResp.RefundKey,
+			Status:      constant.RefundStatusFailed,
+			FailureCode: refundResp.StatusMessage,
+		}, nil
+	}
+	return &models.GatewayRefundResponse{
+		ID:     strconv.FormatInt(refundResp.RefundChargebackID, 10),
+		Status: constant.RefundStatusSucceeded,
+	}, nil
+}
+
+// VerifyWebhook signature_key = SHA512(order_id + status_code + gross_amount + server_key) 를 확인하고 정규화한다.
+func (g *midtransGateway) VerifyWebhook(header http.Header, body []byte) (*models.PaymentWebhookEvent, error) {
+	var notif models.MidtransNotification
+	if err := json.Unmarshal(body, &notif); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+	sum := sha512.Sum512([]byte(notif.OrderID + notif.StatusCode + notif.GrossAmount + g.serverKey))
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(notif.SignatureKey)) != 1 {
+		return nil, ErrInvalidWebhookSignature
+	}
+	eventID := ""
+	if notif.TransactionID != "" {
+		eventID = notif.TransactionID + ":" + notif.TransactionStatus
+	}
+	return &models.PaymentWebhookEvent{
+		Provider:   g.Name(),
+		EventID:    eventID,
+		ExternalID: notif.OrderID,
+		Status:     normalizeMidtransStatus(notif.TransactionStatus, notif.FraudStatus),
+		Amount:     json.Number(notif.GrossAmount),
+		Currency:   notif.Currency,
+	}, nil
+}
+
+func (g *midtransGateway) do(ctx context.Context, method, url string, payload any, out any) (int, error) {
+	body := &bytes.Buffer{}
+	if payload != nil {
+		if err := json.NewEncoder(body).Encode(payload); err != nil {
+			log.Logger.Error().Err(err).Msg("Failed to marshal midtrans request")
+			return 0, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create HTTP request")
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(g.serverKey, "")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to call Midtrans API")
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		log.Logger.Error().Err(err).Int("status", resp.StatusCode).Msg("Failed to decode Midtrans response")
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
+
+// normalizeMidtransStatus Midtrans transaction_status -> constant.PaymentStatus*
+func normalizeMidtransStatus(transactionStatus, fraudStatus string) string {
+	switch transactionStatus {
+	case "settlement":
+		return constant.PaymentStatusPaid
+	case "capture":
+		// 카드 결제: challenge는 수동 검토 대기
+		switch fraudStatus {
+		case "challenge":
+			return constant.PaymentStatusPending
+		case "deny":
+			return constant.PaymentStatusFailed
+		}
+		return constant.PaymentStatusPaid
+	case "pending", "authorize":
+		return constant.PaymentStatusPending
+	case "expire":
+		return constant.PaymentStatusExpired
+	case "deny", "cancel", "failure":
+		return constant.PaymentStatusFailed
+	case "refund":
+		return constant.PaymentStatusRefunded
+	case "partial_refund":
+		return constant.PaymentStatusPartiallyRefunded
+	}
+	return strings.ToUpper(transactionStatus)
+}
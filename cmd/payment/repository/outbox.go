@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"paymentfc/constant"
+	"paymentfc/log"
+	"paymentfc/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// outboxProcessingTimeout PROCESSING에 이 시간 이상 머문 건은 relay가 죽은 것으로 보고 다시 가져간다.
+const outboxProcessingTimeout = 5 * time.Minute
+
+// NewPaymentStatusOutboxEvent PublishPaymentStatus와 같은 메시지를 outbox 행으로 만든다.
+func NewPaymentStatusOutboxEvent(orderID int64, status string, topic string) (models.OutboxEvent, error) {
+	return newOutboxEvent(orderID, topic, paymentStatusPayload(orderID, status, topic))
+}
+
+// NewPaymentRefundedOutboxEvent PublishPaymentRefunded와 같은 메시지를 outbox 행으로 만든다.
+func NewPaymentRefundedOutboxEvent(event models.PaymentRefundedEvent) (models.OutboxEvent, error) {
+	return newOutboxEvent(event.OrderID, constant.KafkaTopicPaymentRefunded, paymentRefundedPayload(event))
+}
+
+// NewPaymentRequestFailedOutboxEvent 결제 요청 DEAD 처리와 같은 트랜잭션에 넣을 payment.request_failed 메시지
+func NewPaymentRequestFailedOutboxEvent(event models.PaymentRequestFailedEvent) (models.OutboxEvent, error) {
+	return newOutboxEvent(event.OrderID, constant.KafkaTopicPaymentRequestFailed, paymentRequestFailedPayload(event))
+}
+
+func newOutboxEvent(orderID int64, eventType string, payload map[string]interface{}) (models.OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return models.OutboxEvent{}, err
+	}
+	return models.OutboxEvent{
+		OrderID:    orderID,
+		EventType:  eventType,
+		MessageKey: fmt.Sprintf("order-%d", orderID),
+		Payload:    string(data),
+	}, nil
+}
+
+// insertOutboxEvents TransitionPaymentStatus 트랜잭션 안에서 호출된다. 버전 충돌 재시도 때 ID가 남지 않도록 복사본을 저장한다.
+func insertOutboxEvents(tx *gorm.DB, orderID int64, events []models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	now := time.Now()
+	rows := make([]models.OutboxEvent, len(events))
+	for i, event := range events {
+		event.ID = 0
+		if event.OrderID == 0 {
+			event.OrderID = orderID
+		}
+		event.Status = constant.OutboxStatusPending
+		event.Attempts = 0
+		event.NextAttemptTime = now
+		event.CreateTime = now
+		event.UpdateTime = now
+		rows[i] = event
+	}
+	return tx.Table("outbox").Create(&rows).Error
+}
+
+// ClaimOutboxEvents 발행할 outbox 건을 PROCESSING으로 바꾸며 가져온다.
+// 같은 주문의 앞선 메시지가 아직 발행되지 않았으면 뒤 메시지는 가져가지 않아 주문 단위 순서를 지킨다.
+func (p *paymentDatabase) ClaimOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	var result []models.OutboxEvent
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		staleBefore := now.Add(-outboxProcessingTimeout)
+		err := tx.Table("outbox").
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("((status = ? AND next_attempt_time <= ?) OR (status = ? AND update_time < ?))",
+				constant.OutboxStatusPending, now, constant.OutboxStatusProcessing, staleBefore).
+			Where("NOT EXISTS (SELECT 1 FROM outbox prev WHERE prev.order_id = outbox.order_id AND prev.id < outbox.id AND prev.status IN ?)",
+				[]string{constant.OutboxStatusPending, constant.OutboxStatusProcessing}).
+			Order("id").Limit(limit).Find(&result).Error
+		if err != nil || len(result) == 0 {
+			return err
+		}
+
+		ids := make([]int64, 0, len(result))
+		for i := range result {
+			ids = append(ids, result[i].ID)
+			result[i].Status = constant.OutboxStatusProcessing
+			result[i].Attempts++
+			result[i].UpdateTime = now
+		}
+		return tx.Table("outbox").Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":      constant.OutboxStatusProcessing,
+			"attempts":    gorm.Expr("attempts + 1"),
+			"update_time": now,
+		}).Error
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to claim outbox events")
+		return nil, err
+	}
+	return result, nil
+}
+
+func (p *paymentDatabase) MarkOutboxEventSent(ctx context.Context, id int64) error {
+	now := time.Now()
+	err := p.DB.WithContext(ctx).Table("outbox").Where("id = ?", id).Updates(map[string]interface{}{
+		"status":      constant.OutboxStatusSent,
+		"last_error":  "",
+		"sent_time":   now,
+		"update_time": now,
+	}).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("outbox_id", id).Msg("Failed to mark outbox event as sent")
+		return err
+	}
+	return nil
+}
+
+// MarkOutboxEventFailed 발행 실패를 남긴다. PENDING이면 nextAttempt 이후에 relay가 다시 가져간다.
+func (p *paymentDatabase) MarkOutboxEventFailed(ctx context.Context, id int64, status, lastError string, nextAttempt time.Time) error {
+	err := p.DB.WithContext(ctx).Table("outbox").Where("id = ?", id).Updates(map[string]interface{}{
+		"status":            status,
+		"last_error":        lastError,
+		"next_attempt_time": nextAttempt,
+		"update_time":       time.Now(),
+	}).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("outbox_id", id).Msg("Failed to update outbox event")
+		return err
+	}
+	return nil
+}
+
+func (p *paymentDatabase) GetOutboxStats(ctx context.Context) (models.OutboxStats, error) {
+	var row struct {
+		Backlog      int64
+		OldestCreate *time.Time
+	}
+	err := p.DB.WithContext(ctx).Table("outbox").
+		Select("COUNT(*) AS backlog, MIN(create_time) AS oldest_create").
+		Where("status IN ?", []string{constant.OutboxStatusPending, constant.OutboxStatusProcessing}).
+		Scan(&row).Error
+	if err != nil {
+		return models.OutboxStats{}, err
+	}
+	return models.OutboxStats{Backlog: row.Backlog, OldestCreate: row.OldestCreate}, nil
+}
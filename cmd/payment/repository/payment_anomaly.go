@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"paymentfc/constant"
+	"paymentfc/log"
+	"paymentfc/models"
+)
+
+// ErrPaymentAnomalyConflict 다른 요청이 먼저 상태를 바꿔 조건부 업데이트가 반영되지 않은 경우
+var ErrPaymentAnomalyConflict = errors.New("payment anomaly status was changed by another request")
+
+// ListPaymentAnomalies id 내림차순 커서 페이지네이션
+func (p *paymentDatabase) ListPaymentAnomalies(ctx context.Context, filter models.PaymentAnomalyFilter) ([]models.PaymentAnomaly, error) {
+	q := p.DB.Table("payment_anomalies").WithContext(ctx)
+	if filter.AnomalyType != 0 {
+		q = q.Where("anomaly_type = ?", filter.AnomalyType)
+	}
+	if filter.Status != 0 {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.OrderID != 0 {
+		q = q.Where("order_id = ?", filter.OrderID)
+	}
+	if !filter.From.IsZero() {
+		q = q.Where("create_time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q = q.Where("create_time < ?", filter.To)
+	}
+	if filter.Cursor != 0 {
+		q = q.Where("id < ?", filter.Cursor)
+	}
+
+	var result []models.PaymentAnomaly
+	if err := q.Order("id DESC").Limit(filter.Limit).Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (p *paymentDatabase) GetPaymentAnomaly(ctx context.Context, id int64) (*models.PaymentAnomaly, error) {
+	var result models.PaymentAnomaly
+	err := p.DB.Table("payment_anomalies").WithContext(ctx).Where("id = ?", id).First(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdatePaymentAnomalyStatus 현재 상태가 fromStatuses 중 하나일 때만 바꾼다. 아니면 ErrPaymentAnomalyConflict.
+func (p *paymentDatabase) UpdatePaymentAnomalyStatus(ctx context.Context, id int64, fromStatuses []int, update models.PaymentAnomalyUpdate) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      update.Status,
+		"update_time": now,
+	}
+	if update.Resolution != "" {
+		updates["resolution"] = update.Resolution
+		updates["resolved_by"] = update.ResolvedBy
+		updates["resolve_reason"] = update.ResolveReason
+	}
+	if update.RefundID != 0 {
+		updates["refund_id"] = update.RefundID
+	}
+	if update.Notes != "" {
+		updates["notes"] = update.Notes
+	}
+	if update.Status == constant.PaymentAnomalyStatusSuccess || update.Status == constant.PaymentAnomalyStatusFalsePositive {
+		updates["resolved_time"] = now
+	}
+
+	result := p.DB.WithContext(ctx).Table("payment_anomalies").
+		Where("id = ? AND status IN ?", id, fromStatuses).
+		Updates(updates)
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Int64("anomaly_id", id).Msg("Failed to update payment anomaly")
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPaymentAnomalyConflict
+	}
+	return nil
+}
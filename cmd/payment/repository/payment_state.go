@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"paymentfc/constant"
+)
+
+// PaymentStatusTransitions payments.status 허용 전이 표. 여기에 없는 전이는 IllegalTransitionError로 거절된다.
+var PaymentStatusTransitions = map[string][]string{
+	constant.PaymentStatusPending: {
+		constant.PaymentStatusPaid,
+		constant.PaymentStatusFailed,
+		constant.PaymentStatusExpired,
+	},
+	constant.PaymentStatusPaid: {
+		constant.PaymentStatusPartiallyRefunded,
+		constant.PaymentStatusRefunded,
+	},
+	constant.PaymentStatusPartiallyRefunded: {
+		constant.PaymentStatusPartiallyRefunded,
+		constant.PaymentStatusRefunded,
+	},
+}
+
+// CanTransitionPaymentStatus reports whether payment status may move from -> to.
+func CanTransitionPaymentStatus(from, to string) bool {
+	for _, next := range PaymentStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPaidPaymentStatus 결제가 한 번이라도 PAID가 된 상태인지. 환불된 결제도 포함되므로
+// 환불 뒤에 다시 온 PAID 웹훅은 중복으로 보고 건너뛴다.
+func IsPaidPaymentStatus(status string) bool {
+	switch status {
+	case constant.PaymentStatusPaid, constant.PaymentStatusPartiallyRefunded, constant.PaymentStatusRefunded:
+		return true
+	}
+	return false
+}
+
+// IllegalTransitionError 전이 표에 없는 상태 변경을 시도했을 때 반환된다.
+type IllegalTransitionError struct {
+	PaymentID int64
+	From      string
+	To        string
+}
+
+func (e *IllegalTransitionError) Error() string {
+	return fmt.Sprintf("illegal payment status transition %s -> %s (payment_id: %d)", e.From, e.To, e.PaymentID)
+}
+
+// IsIllegalTransition reports whether err (or any error it wraps) is an IllegalTransitionError.
+func IsIllegalTransition(err error) bool {
+	var target *IllegalTransitionError
+	return errors.As(err, &target)
+}
+
+// ErrPaymentVersionConflict 다른 요청이 먼저 상태를 바꿔 compare-and-set이 계속 실패할 때 반환된다.
+var ErrPaymentVersionConflict = errors.New("payment was modified concurrently")
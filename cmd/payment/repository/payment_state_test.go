@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"paymentfc/constant"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPaidPaymentStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		paid   bool
+	}{
+		{constant.PaymentStatusPending, false},
+		{constant.PaymentStatusPaid, true},
+		// PAID 웹훅이 환불 뒤에 재전송돼도 중복으로 보고 IllegalTransition 이상 건을 만들지 않는다.
+		{constant.PaymentStatusPartiallyRefunded, true},
+		{constant.PaymentStatusRefunded, true},
+		{constant.PaymentStatusFailed, false},
+		{constant.PaymentStatusExpired, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			assert.Equal(t, tt.paid, IsPaidPaymentStatus(tt.status))
+		})
+	}
+}
+
+func TestRedeliveredPaidWebhookAfterRefundIsNotATransition(t *testing.T) {
+	for _, status := range []string{constant.PaymentStatusPartiallyRefunded, constant.PaymentStatusRefunded} {
+		assert.True(t, IsPaidPaymentStatus(status), status)
+		// 이미 처리된 것으로 보지 않으면 이 전이가 거절되어 이상 건이 생긴다.
+		assert.False(t, CanTransitionPaymentStatus(status, constant.PaymentStatusPaid), status)
+	}
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+
+	"paymentfc/log"
+	"paymentfc/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// reconciliationItemBatchSize 한 번에 insert할 item 수
+const reconciliationItemBatchSize = 100
+
+// CreateReconciliationRun run을 만든다. 같은 schedule_key의 run이 이미 있으면 만들지 않고 false를 돌려준다.
+func (p *paymentDatabase) CreateReconciliationRun(ctx context.Context, param *models.ReconciliationRun) (bool, error) {
+	result := p.DB.WithContext(ctx).Table("reconciliation_runs").
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "schedule_key"}}, DoNothing: true}).
+		Create(param)
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Msg("Failed to create reconciliation run")
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FinishReconciliationRun 상태와 집계 값을 기록한다.
+func (p *paymentDatabase) FinishReconciliationRun(ctx context.Context, param *models.ReconciliationRun) error {
+	err := p.DB.WithContext(ctx).Table("reconciliation_runs").Where("id = ?", param.ID).Updates(map[string]interface{}{
+		"status":            param.Status,
+		"invoice_count":     param.InvoiceCount,
+		"matched_count":     param.MatchedCount,
+		"discrepancy_count": param.DiscrepancyCount,
+		"fixed_count":       param.FixedCount,
+		"error":             param.Error,
+		"finish_time":       param.FinishTime,
+	}).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("reconciliation_run_id", param.ID).Msg("Failed to finish reconciliation run")
+		return err
+	}
+	return nil
+}
+
+func (p *paymentDatabase) SaveReconciliationItems(ctx context.Context, items []models.ReconciliationItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if err := p.DB.WithContext(ctx).Table("reconciliation_items").CreateInBatches(&items, reconciliationItemBatchSize).Error; err != nil {
+		log.Logger.Error().Err(err).Int64("reconciliation_run_id", items[0].RunID).Msg("Failed to save reconciliation items")
+		return err
+	}
+	return nil
+}
+
+func (p *paymentDatabase) GetReconciliationRun(ctx context.Context, id int64) (*models.ReconciliationRun, error) {
+	var result models.ReconciliationRun
+	err := p.DB.Table("reconciliation_runs").WithContext(ctx).Where("id = ?", id).First(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListReconciliationRuns id 내림차순 커서 페이지네이션
+func (p *paymentDatabase) ListReconciliationRuns(ctx context.Context, filter models.ReconciliationRunFilter) ([]models.ReconciliationRun, error) {
+	q := p.DB.Table("reconciliation_runs").WithContext(ctx)
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.Cursor != 0 {
+		q = q.Where("id < ?", filter.Cursor)
+	}
+
+	var result []models.ReconciliationRun
+	if err := q.Order("id DESC").Limit(filter.Limit).Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListReconciliationItems id 내림차순 커서 페이지네이션
+func (p *paymentDatabase) ListReconciliationItems(ctx context.Context, filter models.ReconciliationItemFilter) ([]models.ReconciliationItem, error) {
+	q := p.DB.Table("reconciliation_items").WithContext(ctx).Where("run_id = ?", filter.RunID)
+	if filter.DiscrepancyType != "" {
+		q = q.Where("discrepancy_type = ?", filter.DiscrepancyType)
+	}
+	if filter.FixStatus != "" {
+		q = q.Where("fix_status = ?", filter.FixStatus)
+	}
+	if filter.Cursor != 0 {
+		q = q.Where("id < ?", filter.Cursor)
+	}
+
+	var result []models.ReconciliationItem
+	if err := q.Order("id DESC").Limit(filter.Limit).Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPaymentsByExternalIDs 대사용. 인보이스 external_id 목록에 해당하는 payments를 한 번에 읽는다.
+func (p *paymentDatabase) GetPaymentsByExternalIDs(ctx context.Context, externalIDs []string) ([]models.Payment, error) {
+	var result []models.Payment
+	if len(externalIDs) == 0 {
+		return result, nil
+	}
+	err := p.DB.Table("payments").WithContext(ctx).Where("external_id IN ?", externalIDs).Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
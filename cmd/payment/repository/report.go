@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"paymentfc/constant"
+	"paymentfc/log"
+	"paymentfc/models"
+)
+
+// settlementReportSQL payments 집계와 refunds 집계를 (period, currency)로 합친다.
+// 컬럼이 timestamp(UTC)이므로 UTC로 해석한 뒤 요청 timezone의 벽시계 시각으로 바꿔 자른다.
+const settlementReportSQL = `
+WITH p AS (
+	SELECT date_trunc(@group_by, create_time AT TIME ZONE 'UTC' AT TIME ZONE @tz) AS period, currency,
+		COUNT(*) AS payment_count,
+		COUNT(*) FILTER (WHERE status = @pending) AS pending_count,
+		COUNT(*) FILTER (WHERE status = @paid) AS paid_count,
+		COUNT(*) FILTER (WHERE status = @partially_refunded) AS partially_refunded_count,
+		COUNT(*) FILTER (WHERE status = @refunded) AS refunded_count,
+		COUNT(*) FILTER (WHERE status = @expired) AS expired_count,
+		COUNT(*) FILTER (WHERE status = @failed) AS failed_count,
+		COALESCE(SUM(amount) FILTER (WHERE status IN (@paid, @partially_refunded, @refunded)), 0) AS gross_paid_amount,
+		COALESCE(SUM(tax_base_amount) FILTER (WHERE status IN (@paid, @partially_refunded, @refunded)), 0) AS tax_base_amount,
+		COALESCE(SUM(tax_amount) FILTER (WHERE status IN (@paid, @partially_refunded, @refunded)), 0) AS tax_amount
+	FROM payments
+	WHERE create_time >= @from AND create_time < @to
+	GROUP BY 1, 2
+), r AS (
+	SELECT date_trunc(@group_by, create_time AT TIME ZONE 'UTC' AT TIME ZONE @tz) AS period, currency,
+		COUNT(*) AS refund_count,
+		COALESCE(SUM(amount), 0) AS refund_amount
+	FROM refunds
+	WHERE status = @refund_succeeded AND reason <> @overpayment AND create_time >= @from AND create_time < @to
+	GROUP BY 1, 2
+)
+SELECT COALESCE(p.period, r.period) AS period, COALESCE(p.currency, r.currency) AS currency,
+	COALESCE(p.payment_count, 0) AS payment_count,
+	COALESCE(p.pending_count, 0) AS pending_count,
+	COALESCE(p.paid_count, 0) AS paid_count,
+	COALESCE(p.partially_refunded_count, 0) AS partially_refunded_count,
+	COALESCE(p.refunded_count, 0) AS refunded_count,
+	COALESCE(p.expired_count, 0) AS expired_count,
+	COALESCE(p.failed_count, 0) AS failed_count,
+	COALESCE(p.gross_paid_amount, 0) AS gross_paid_amount,
+	COALESCE(p.tax_base_amount, 0) AS tax_base_amount,
+	COALESCE(p.tax_amount, 0) AS tax_amount,
+	COALESCE(r.refund_count, 0) AS refund_count,
+	COALESCE(r.refund_amount, 0) AS refund_amount
+FROM p FULL OUTER JOIN r ON p.period = r.period AND p.currency = r.currency
+ORDER BY 1, 2`
+
+type settlementReportScan struct {
+	Period                 time.Time
+	Currency               string
+	PaymentCount           int64
+	PendingCount           int64
+	PaidCount              int64
+	PartiallyRefundedCount int64
+	RefundedCount          int64
+	ExpiredCount           int64
+	FailedCount            int64
+	GrossPaidAmount        int64
+	TaxBaseAmount          int64
+	TaxAmount              int64
+	RefundCount            int64
+	RefundAmount           int64
+}
+
+// StreamSettlementReport 집계 결과를 한 행씩 fn에 넘긴다. 기간이 길어도 결과를 메모리에 모으지 않는다.
+func (p *paymentDatabase) StreamSettlementReport(ctx context.Context, query models.SettlementReportQuery, fn func(models.SettlementReportRow) error) error {
+	rows, err := p.DB.WithContext(ctx).Raw(settlementReportSQL, map[string]interface{}{
+		"group_by":           query.GroupBy,
+		"tz":                 query.Timezone,
+		"from":               query.From.UTC(),
+		"to":                 query.To.UTC(),
+		"pending":            constant.PaymentStatusPending,
+		"paid":               constant.PaymentStatusPaid,
+		"partially_refunded": constant.PaymentStatusPartiallyRefunded,
+		"refunded":           constant.PaymentStatusRefunded,
+		"expired":            constant.PaymentStatusExpired,
+		"failed":             constant.PaymentStatusFailed,
+		"refund_succeeded":   constant.RefundStatusSucceeded,
+		"overpayment":        constant.RefundReasonOverpayment,
+	}).Rows()
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to query settlement report")
+		return err
+	}
+	defer rows.Close()
+
+	periodLayout := "2006-01-02"
+	if query.GroupBy == constant.ReportGroupByMonth {
+		periodLayout = "2006-01"
+	}
+	for rows.Next() {
+		var scan settlementReportScan
+		if err := p.DB.ScanRows(rows, &scan); err != nil {
+			return err
+		}
+		row := models.SettlementReportRow{
+			Period:                 scan.Period.Format(periodLayout),
+			Currency:               scan.Currency,
+			PaymentCount:           scan.PaymentCount,
+			PendingCount:           scan.PendingCount,
+			PaidCount:              scan.PaidCount,
+			PartiallyRefundedCount: scan.PartiallyRefundedCount,
+			RefundedCount:          scan.RefundedCount,
+			ExpiredCount:           scan.ExpiredCount,
+			FailedCount:            scan.FailedCount,
+			GrossPaidAmount:        models.NewMoney(scan.GrossPaidAmount, scan.Currency),
+			TaxBaseAmount:          models.NewMoney(scan.TaxBaseAmount, scan.Currency),
+			TaxAmount:              models.NewMoney(scan.TaxAmount, scan.Currency),
+			RefundCount:            scan.RefundCount,
+			RefundAmount:           models.NewMoney(scan.RefundAmount, scan.Currency),
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
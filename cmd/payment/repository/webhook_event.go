@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"paymentfc/constant"
+	"paymentfc/log"
+	"paymentfc/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// webhookProcessingTimeout PROCESSING에 이 시간 이상 머문 건은 워커가 죽은 것으로 보고 다시 가져간다.
+const webhookProcessingTimeout = 5 * time.Minute
+
+// SaveWebhookEvent inbox에 저장한다. 같은 (provider, event_key)가 이미 있으면 기존 행을 채워 주고 duplicate=true.
+func (p *paymentDatabase) SaveWebhookEvent(ctx context.Context, param *models.WebhookEvent) (bool, error) {
+	now := time.Now()
+	param.Status = constant.WebhookEventStatusPending
+	param.ReceivedTime = now
+	param.NextAttemptTime = now
+	param.UpdateTime = now
+
+	result := p.DB.WithContext(ctx).Table("webhook_events").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "provider"}, {Name: "event_key"}},
+			DoNothing: true,
+		}).Create(param)
+	if result.Error != nil {
+		log.Logger.Error().Err(result.Error).Str("event_key", param.EventKey).Msg("Failed to save webhook event")
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return false, nil
+	}
+
+	err := p.DB.WithContext(ctx).Table("webhook_events").
+		Where("provider = ? AND event_key = ?", param.Provider, param.EventKey).First(param).Error
+	if err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// ClaimWebhookEvents 처리할 inbox 건을 PROCESSING으로 바꾸며 가져온다. 여러 인스턴스가 같은 건을 잡지 않도록 SKIP LOCKED.
+func (p *paymentDatabase) ClaimWebhookEvents(ctx context.Context, limit int) ([]models.WebhookEvent, error) {
+	var result []models.WebhookEvent
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Table("webhook_events").
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("((status IN ? AND next_attempt_time <= ?) OR (status = ? AND update_time < ?)) AND attempts < ?",
+				[]string{constant.WebhookEventStatusPending, constant.WebhookEventStatusFailed}, now,
+				constant.WebhookEventStatusProcessing, now.Add(-webhookProcessingTimeout),
+				constant.MaxWebhookEventAttempts).
+			Order("id").Limit(limit).Find(&result).Error
+		if err != nil || len(result) == 0 {
+			return err
+		}
+
+		ids := make([]int64, 0, len(result))
+		for i := range result {
+			ids = append(ids, result[i].ID)
+			result[i].Status = constant.WebhookEventStatusProcessing
+			result[i].Attempts++
+			result[i].UpdateTime = now
+		}
+		return tx.Table("webhook_events").Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":      constant.WebhookEventStatusProcessing,
+			"attempts":    gorm.Expr("attempts + 1"),
+			"update_time": now,
+		}).Error
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to claim webhook events")
+		return nil, err
+	}
+	return result, nil
+}
+
+// ClaimWebhookEvent admin replay용. 상태와 관계없이 한 건을 PROCESSING으로 바꾼다 (이미 처리 중이면 ErrRecordNotFound).
+func (p *paymentDatabase) ClaimWebhookEvent(ctx context.Context, id int64) (*models.WebhookEvent, error) {
+	var result models.WebhookEvent
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Table("webhook_events").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND (status <> ? OR update_time < ?)", id, constant.WebhookEventStatusProcessing, now.Add(-webhookProcessingTimeout)).
+			First(&result).Error
+		if err != nil {
+			return err
+		}
+		result.Status = constant.WebhookEventStatusProcessing
+		result.Attempts++
+		result.UpdateTime = now
+		return tx.Table("webhook_events").Where("id = ?", id).Updates(map[string]interface{}{
+			"status":      result.Status,
+			"attempts":    result.Attempts,
+			"update_time": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (p *paymentDatabase) GetWebhookEvent(ctx context.Context, id int64) (*models.WebhookEvent, error) {
+	var result models.WebhookEvent
+	err := p.DB.Table("webhook_events").WithContext(ctx).Where("id = ?", id).First(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateWebhookEventResult 처리 결과를 남긴다. FAILED면 nextAttempt 이후에 워커가 다시 가져간다.
+func (p *paymentDatabase) UpdateWebhookEventResult(ctx context.Context, id int64, status, lastError string, nextAttempt time.Time) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":            status,
+		"last_error":        lastError,
+		"next_attempt_time": nextAttempt,
+		"update_time":       now,
+	}
+	if status == constant.WebhookEventStatusProcessed || status == constant.WebhookEventStatusIgnored {
+		updates["processed_time"] = now
+	}
+	err := p.DB.WithContext(ctx).Table("webhook_events").Where("id = ?", id).Updates(updates).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("webhook_event_id", id).Msg("Failed to update webhook event")
+		return err
+	}
+	return nil
+}
@@ -6,6 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"paymentfc/log"
 	"paymentfc/models"
@@ -14,14 +18,24 @@ import (
 type XenditClient interface {
 	CreateInvoice(ctx context.Context, request models.XenditInvoiceRequest) (*models.XenditInvoiceResponse, error)
 	CheckInvoiceStatus(ctx context.Context, externalID string) (string, error)
+	CreateRefund(ctx context.Context, request models.XenditRefundRequest) (*models.XenditRefundResponse, error)
+	ExpireInvoice(ctx context.Context, invoiceID string) error
+	ListInvoices(ctx context.Context, request models.XenditInvoiceListRequest) ([]models.XenditInvoiceResponse, error)
 }
 
+// XenditDefaultBaseURL 운영 Xendit API. 로컬/CI에서는 xendit.base_url로 시뮬레이터를 가리킨다.
+const XenditDefaultBaseURL = "https://api.xendit.co"
+
 type xenditClient struct {
-	apiKey string
+	apiKey  string
+	baseURL string
 }
 
-func NewXenditClient(apiKey string) XenditClient {
-	return &xenditClient{apiKey: apiKey}
+func NewXenditClient(apiKey, baseURL string) XenditClient {
+	if baseURL == "" {
+		baseURL = XenditDefaultBaseURL
+	}
+	return &xenditClient{apiKey: apiKey, baseURL: strings.TrimRight(baseURL, "/")}
 }
 
 func (x *xenditClient) CreateInvoice(ctx context.Context, request models.XenditInvoiceRequest) (*models.XenditInvoiceResponse, error) {
@@ -31,7 +45,7 @@ func (x *xenditClient) CreateInvoice(ctx context.Context, request models.XenditI
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.xendit.co/v2/invoices", bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", x.baseURL+"/v2/invoices", bytes.NewBuffer(body))
 	if err != nil {
 		log.Logger.Error().Err(err).Msg("Failed to create HTTP request")
 		return nil, err
@@ -50,7 +64,7 @@ func (x *xenditClient) CreateInvoice(ctx context.Context, request models.XenditI
 
 	if resp.StatusCode != http.StatusOK {
 		log.Logger.Error().Msgf("Xendit API returned status: %d", resp.StatusCode)
-		return nil, fmt.Errorf("xendit API returned status: %d", resp.StatusCode)
+		return nil, &GatewayStatusError{API: "xendit API", StatusCode: resp.StatusCode}
 	}
 
 	var invoiceResponse models.XenditInvoiceResponse
@@ -63,9 +77,9 @@ func (x *xenditClient) CreateInvoice(ctx context.Context, request models.XenditI
 }
 
 func (x *xenditClient) CheckInvoiceStatus(ctx context.Context, externalID string) (string, error) {
-	url := fmt.Sprintf("https://api.xendit.co/v2/invoices?external_id=%s", externalID)
+	endpoint := fmt.Sprintf("%s/v2/invoices?external_id=%s", x.baseURL, url.QueryEscape(externalID))
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return "", err
 	}
@@ -96,3 +110,108 @@ func (x *xenditClient) CheckInvoiceStatus(ctx context.Context, externalID string
 
 	return invoiceResponse[0].Status, nil
 }
+
+func (x *xenditClient) CreateRefund(ctx context.Context, request models.XenditRefundRequest) (*models.XenditRefundResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to marshal refund request")
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, x.baseURL+"/refunds", bytes.NewBuffer(body))
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create HTTP request")
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	// reference_id 기준으로 Xendit 쪽에서도 중복 환불을 막는다.
+	req.Header.Set("Idempotency-key", request.ReferenceID)
+	req.SetBasicAuth(x.apiKey, "")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to call Xendit refund API")
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		log.Logger.Error().Msgf("Xendit refund API returned status: %d", resp.StatusCode)
+		return nil, fmt.Errorf("xendit refund API returned status: %d", resp.StatusCode)
+	}
+
+	var refundResponse models.XenditRefundResponse
+	if err := json.NewDecoder(resp.Body).Decode(&refundResponse); err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to decode Xendit refund response")
+		return nil, err
+	}
+
+	return &refundResponse, nil
+}
+
+func (x *xenditClient) ExpireInvoice(ctx context.Context, invoiceID string) error {
+	endpoint := fmt.Sprintf("%s/invoices/%s/expire!", x.baseURL, url.PathEscape(invoiceID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to create HTTP request")
+		return err
+	}
+
+	req.SetBasicAuth(x.apiKey, "")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to call Xendit expire API")
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Logger.Error().Msgf("Xendit expire API returned status: %d", resp.StatusCode)
+		return fmt.Errorf("xendit expire API returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (x *xenditClient) ListInvoices(ctx context.Context, request models.XenditInvoiceListRequest) ([]models.XenditInvoiceResponse, error) {
+	query := url.Values{}
+	if !request.CreatedAfter.IsZero() {
+		query.Set("created_after", request.CreatedAfter.UTC().Format(time.RFC3339))
+	}
+	if !request.CreatedBefore.IsZero() {
+		query.Set("created_before", request.CreatedBefore.UTC().Format(time.RFC3339))
+	}
+	if request.Limit > 0 {
+		query.Set("limit", strconv.Itoa(request.Limit))
+	}
+	if request.LastInvoiceID != "" {
+		query.Set("last_invoice_id", request.LastInvoiceID)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, x.baseURL+"/v2/invoices?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(x.apiKey, "")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to call Xendit list invoices API")
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Logger.Error().Msgf("Xendit list invoices API returned status: %d", resp.StatusCode)
+		return nil, fmt.Errorf("xendit list invoices API returned status: %d", resp.StatusCode)
+	}
+
+	var invoices []models.XenditInvoiceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&invoices); err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to decode Xendit list invoices response")
+		return nil, err
+	}
+	return invoices, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"paymentfc/constant"
+	"paymentfc/models"
+)
+
+// xenditGateway XenditClient를 PaymentGateway로 감싼 어댑터
+type xenditGateway struct {
+	client       XenditClient
+	webhookToken string
+}
+
+func NewXenditGateway(client XenditClient, webhookToken string) PaymentGateway {
+	return &xenditGateway{client: client, webhookToken: webhookToken}
+}
+
+func (g *xenditGateway) Name() string {
+	return constant.PaymentProviderXendit
+}
+
+func (g *xenditGateway) CreateCheckout(ctx context.Context, request models.CheckoutRequest) (*models.CheckoutResponse, error) {
+	resp, err := g.client.CreateInvoice(ctx, models.XenditInvoiceRequest{
+		ExternalID:  request.ExternalID,
+		Amount:      request.Amount.Number(),
+		Currency:    request.Amount.Currency,
+		Description: request.Description,
+		PayerEmail:  request.PayerEmail,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.CheckoutResponse{
+		Provider:   g.Name(),
+		ID:         resp.ID,
+		InvoiceURL: resp.InvoiceURL,
+		Status:     NormalizeXenditStatus(resp.Status),
+		ExpireDate: resp.ExpireDate,
+	}, nil
+}
+
+func (g *xenditGateway) GetCheckoutStatus(ctx context.Context, externalID string) (string, error) {
+	status, err := g.client.CheckInvoiceStatus(ctx, externalID)
+	if err != nil {
+		return "", err
+	}
+	return NormalizeXenditStatus(status), nil
+}
+
+func (g *xenditGateway) ExpireCheckout(ctx context.Context, checkoutID, externalID string) error {
+	if checkoutID == "" {
+		return fmt.Errorf("xendit invoice id is missing for external_id: %s", externalID)
+	}
+	return g.client.ExpireInvoice(ctx, checkoutID)
+}
+
+func (g *xenditGateway) Refund(ctx context.Context, request models.GatewayRefundRequest) (*models.GatewayRefundResponse, error) {
+	resp, err := g.client.CreateRefund(ctx, models.XenditRefundRequest{
+		InvoiceID:   request.CheckoutID,
+		ReferenceID: request.ReferenceID,
+		Amount:      request.Amount.Number(),
+		Currency:    request.Amount.Currency,
+		Reason:      request.Reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.GatewayRefundResponse{
+		ID:          resp.ID,
+		Status:      resp.Status,
+		FailureCode: resp.FailureCode,
+	}, nil
+}
+
+// VerifyWebhook x-callback-token 헤더를 확인하고 인보이스 콜백을 정규화한다.
+func (g *xenditGateway) VerifyWebhook(header http.Header, body []byte) (*models.PaymentWebhookEvent, error) {
+	if subtle.ConstantTimeCompare([]byte(header.Get("x-callback-token")), []byte(g.webhookToken)) != 1 {
+		return nil, ErrInvalidWebhookSignature
+	}
+	var payload models.XenditWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+	status := NormalizeXenditStatus(payload.Status)
+	eventID := ""
+	if payload.ID != "" {
+		// 같은 인보이스라도 상태가 바뀌면 다른 이벤트다. 재전송은 id+status가 같다.
+		eventID = payload.ID + ":" + status
+	}
+	return &models.PaymentWebhookEvent{
+		Provider:   g.Name(),
+		EventID:    eventID,
+		ExternalID: payload.ExternalID,
+		Status:     status,
+		Amount:     payload.Amount,
+		Currency:   payload.Currency,
+	}, nil
+}
+
+// NormalizeXenditStatus Xendit 인보이스 상태 중 SETTLED(정산 완료)는 결제 입장에서 PAID와 같다.
+func NormalizeXenditStatus(status string) string {
+	if status == "SETTLED" {
+		return constant.PaymentStatusPaid
+	}
+	return status
+}
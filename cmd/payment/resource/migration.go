@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"fmt"
+	"math"
+	"paymentfc/log"
+	"paymentfc/models"
+
+	"gorm.io/gorm"
+)
+
+// moneyTables numeric(major unit) amount 컬럼을 가지고 있던 테이블
+var moneyTables = []string{"payments", "payment_requests", "refunds"}
+
+// MigrateMoneyColumns 기존 numeric amount 컬럼을 bigint minor unit으로 변환하고 currency 컬럼을 채운다.
+// AutoMigrate 전에 호출해야 하며, 이미 bigint인 테이블은 건너뛰므로 여러 번 실행해도 안전하다.
+// 기존 행에는 통화 정보가 없으므로 모두 models.DefaultCurrency로 간주한다.
+func MigrateMoneyColumns(db *gorm.DB) error {
+	exp, err := models.CurrencyExponent(models.DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	factor := int64(math.Pow10(exp))
+
+	for _, table := range moneyTables {
+		var dataType string
+		err := db.Raw(`SELECT data_type FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = ? AND column_name = 'amount'`, table).
+			Scan(&dataType).Error
+		if err != nil {
+			return err
+		}
+		if dataType != "numeric" {
+			continue
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(fmt.Sprintf(
+				`ALTER TABLE %s ALTER COLUMN amount TYPE bigint USING ROUND(amount * %d)`, table, factor)).Error; err != nil {
+				return err
+			}
+			return tx.Exec(fmt.Sprintf(
+				`ALTER TABLE %s ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT '%s'`, table, models.DefaultCurrency)).Error
+		})
+		if err != nil {
+			log.Logger.Error().Err(err).Str("table", table).Msg("Failed to migrate amount column to minor units")
+			return err
+		}
+		log.Logger.Info().Str("table", table).Msg("Migrated amount column from numeric to bigint minor units")
+	}
+	return nil
+}
+
+// BackfillAnomalyAmounts AutoMigrate가 추가한 payment_anomalies 금액 컬럼은 기존 행에서 NULL이라
+// Money(int64)로 읽을 수 없으므로 0으로 채운다. AutoMigrate 후에 호출하며 여러 번 실행해도 안전하다.
+func BackfillAnomalyAmounts(db *gorm.DB) error {
+	err := db.Exec(`UPDATE payment_anomalies
+		SET expected_amount = COALESCE(expected_amount, 0), paid_amount = COALESCE(paid_amount, 0)
+		WHERE expected_amount IS NULL OR paid_amount IS NULL`).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to backfill payment_anomalies amount columns")
+		return err
+	}
+	return nil
+}
+
+// BackfillPaymentTaxAmounts AutoMigrate가 추가한 payments 세금 컬럼은 기존 행에서 NULL이므로 0으로 채운다.
+// 기존 결제는 세금 정보가 없던 시점에 만들어졌으므로 세액 0으로 본다. 여러 번 실행해도 안전하다.
+func BackfillPaymentTaxAmounts(db *gorm.DB) error {
+	err := db.Exec(`UPDATE payments
+		SET tax_amount = COALESCE(tax_amount, 0), tax_base_amount = COALESCE(tax_base_amount, 0)
+		WHERE tax_amount IS NULL OR tax_base_amount IS NULL`).Error
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to backfill payments tax columns")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/constant"
+	"paymentfc/log"
+	"paymentfc/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidAnomalyAction = errors.New("invalid anomaly resolution action")
+	// ErrAnomalyNotResolvable 이미 처리됐거나 다른 admin이 처리 중인 건
+	ErrAnomalyNotResolvable = errors.New("payment anomaly is already resolved or being resolved")
+	// ErrAnomalyActionNotAllowed 이상 건의 유형/금액/결제 상태상 요청한 조치를 할 수 없는 경우
+	ErrAnomalyActionNotAllowed = errors.New("anomaly resolution action is not allowed for this case")
+	// ErrAnomalyActionFailed 결제 확정/환불 중 실패해 Retry로 남긴 경우
+	ErrAnomalyActionFailed = errors.New("anomaly resolution action failed")
+)
+
+// anomalyAuditTrailLimit 상세 조회에 포함할 감사 로그 최대 건수
+const anomalyAuditTrailLimit = 100
+
+// resolvableAnomalyStatuses 조치를 시작할 수 있는 상태
+var resolvableAnomalyStatuses = []int{constant.PaymentAnomalyStatusNeedToCheck, constant.PaymentAnomalyStatusRetry}
+
+type AnomalyService interface {
+	ListAnomalies(ctx context.Context, filter models.PaymentAnomalyFilter) (models.PaymentAnomalyPage, error)
+	GetAnomalyDetail(ctx context.Context, id int64) (*models.PaymentAnomalyDetail, error)
+	ResolveAnomaly(ctx context.Context, id int64, req models.ResolveAnomalyRequest, resolvedBy int64) (*models.PaymentAnomaly, error)
+}
+
+type anomalyService struct {
+	database       repository.PaymentDatabase
+	paymentService PaymentService
+	refundService  RefundService
+	auditLog       repository.AuditLogRepository
+}
+
+func NewAnomalyService(db repository.PaymentDatabase, paymentService PaymentService, refundService RefundService, auditLog repository.AuditLogRepository) AnomalyService {
+	return &anomalyService{
+		database:       db,
+		paymentService: paymentService,
+		refundService:  refundService,
+		auditLog:       auditLog,
+	}
+}
+
+func (s *anomalyService) ListAnomalies(ctx context.Context, filter models.PaymentAnomalyFilter) (models.PaymentAnomalyPage, error) {
+	anomalies, err := s.database.ListPaymentAnomalies(ctx, filter)
+	if err != nil {
+		return models.PaymentAnomalyPage{}, err
+	}
+	page := models.PaymentAnomalyPage{Anomalies: anomalies}
+	if filter.Limit > 0 && len(anomalies) == filter.Limit {
+		page.NextCursor = anomalies[len(anomalies)-1].ID
+	}
+	return page, nil
+}
+
+// GetAnomalyDetail 이상 건과 결제, 상태 변경 이력, 주문의 감사 로그를 함께 돌려준다.
+func (s *anomalyService) GetAnomalyDetail(ctx context.Context, id int64) (*models.PaymentAnomalyDetail, error) {
+	anomaly, err := s.database.GetPaymentAnomaly(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	detail := &models.PaymentAnomalyDetail{
+		Anomaly:       *anomaly,
+		StatusHistory: []models.PaymentStatusHistory{},
+		AuditTrail:    []models.PaymentAuditLog{},
+	}
+
+	payment, err := s.database.GetPaymentByOrderID(ctx, anomaly.OrderID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if payment != nil {
+		detail.Payment = payment
+		history, err := s.database.GetPaymentStatusHistory(ctx, payment.ID)
+		if err != nil {
+			return nil, err
+		}
+		detail.StatusHistory = history
+	}
+
+	auditPage, err := s.auditLog.ListAuditLogs(ctx, models.AuditLogFilter{OrderID: anomaly.OrderID, Limit: anomalyAuditTrailLimit})
+	if err != nil {
+		// 감사 로그(Mongo) 장애가 상세 조회 전체를 막지 않도록 한다.
+		log.Logger.Warn().Err(err).Int64("anomaly_id", id).Msg("Failed to load audit trail for payment anomaly")
+	} else if auditPage.Logs != nil {
+		detail.AuditTrail = auditPage.Logs
+	}
+	return detail, nil
+}
+
+// ResolveAnomaly admin 조치. 먼저 Resolving으로 선점한 뒤 조치를 실행하고,
+// 성공하면 Success/FalsePositive, 실패하면 Retry로 남겨 다시 조치할 수 있게 한다.
+func (s *anomalyService) ResolveAnomaly(ctx context.Context, id int64, req models.ResolveAnomalyRequest, resolvedBy int64) (*models.PaymentAnomaly, error) {
+	switch req.Action {
+	case constant.AnomalyResolutionAcceptPaidAmount, constant.AnomalyResolutionRefundDifference, constant.AnomalyResolutionFalsePositive:
+	default:
+		return nil, ErrInvalidAnomalyAction
+	}
+
+	anomaly, err := s.database.GetPaymentAnomaly(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAnomalyAction(anomaly, req.Action); err != nil {
+		return anomaly, err
+	}
+
+	err = s.database.UpdatePaymentAnomalyStatus(ctx, id, resolvableAnomalyStatuses, models.PaymentAnomalyUpdate{
+		Status: constant.PaymentAnomalyStatusResolving,
+	})
+	if errors.Is(err, repository.ErrPaymentAnomalyConflict) {
+		return anomaly, ErrAnomalyNotResolvable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	update := models.PaymentAnomalyUpdate{
+		Status:        constant.PaymentAnomalyStatusSuccess,
+		Resolution:    req.Action,
+		ResolvedBy:    resolvedBy,
+		ResolveReason: req.Reason,
+	}
+	actionErr := s.runAnomalyAction(ctx, anomaly, req.Action, resolvedBy, &update)
+	if actionErr != nil {
+		update.Status = constant.PaymentAnomalyStatusRetry
+		update.Notes = fmt.Sprintf("%s failed: %v", req.Action, actionErr)
+	}
+	if err := s.database.UpdatePaymentAnomalyStatus(ctx, id, []int{constant.PaymentAnomalyStatusResolving}, update); err != nil {
+		return nil, err
+	}
+
+	event := "PAYMENT_ANOMALY_RESOLVED"
+	metadata := map[string]any{
+		"anomaly_id":   id,
+		"anomaly_type": anomaly.AnomalyType,
+		"action":       req.Action,
+		"reason":       req.Reason,
+		"status":       update.Status,
+	}
+	if update.RefundID != 0 {
+		metadata["refund_id"] = update.RefundID
+	}
+	if actionErr != nil {
+		event = "PAYMENT_ANOMALY_RESOLVE_FAILED"
+		metadata["error"] = actionErr.Error()
+	}
+	if err := s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+		OrderID:    anomaly.OrderID,
+		UserID:     resolvedBy,
+		ExternalID: anomaly.ExternalID,
+		Event:      event,
+		Actor:      "admin",
+		Metadata:   metadata,
+	}); err != nil {
+		log.Logger.Error().Err(err).Int64("anomaly_id", id).Msg("Failed to save audit log")
+	}
+
+	resolved, err := s.database.GetPaymentAnomaly(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if actionErr != nil {
+		return resolved, fmt.Errorf("%w: %w", ErrAnomalyActionFailed, actionErr)
+	}
+	return resolved, nil
+}
+
+func (s *anomalyService) runAnomalyAction(ctx context.Context, anomaly *models.PaymentAnomaly, action string, resolvedBy int64, update *models.PaymentAnomalyUpdate) error {
+	switch action {
+	case constant.AnomalyResolutionFalsePositive:
+		update.Status = constant.PaymentAnomalyStatusFalsePositive
+		return nil
+	case constant.AnomalyResolutionAcceptPaidAmount:
+		return s.confirmAnomalyPayment(ctx, anomaly.OrderID)
+	case constant.AnomalyResolutionRefundDifference:
+		if err := s.confirmAnomalyPayment(ctx, anomaly.OrderID); err != nil {
+			return err
+		}
+		diff, err := anomaly.PaidAmount.Sub(anomaly.ExpectedAmount)
+		if err != nil {
+			return err
+		}
+		// 환불은 anomaly id로 한 건만 만들어지므로 Retry 후 다시 실행해도 두 번 환불되지 않는다.
+		refund, err := s.refundService.RefundOverpayment(ctx, anomaly.ID, anomaly.OrderID, diff, resolvedBy)
+		if err != nil {
+			return err
+		}
+		update.RefundID = refund.ID
+		return nil
+	}
+	return ErrInvalidAnomalyAction
+}
+
+// confirmAnomalyPayment 결제를 PAID로 확정한다 (이미 PAID면 그대로 둔다). 만료/실패된 결제는 전이 표상 확정할 수 없다.
+func (s *anomalyService) confirmAnomalyPayment(ctx context.Context, orderID int64) error {
+	err := s.paymentService.ProcessPaymentSuccess(ctx, orderID)
+	if repository.IsIllegalTransition(err) {
+		return fmt.Errorf("%w: %v", ErrAnomalyActionNotAllowed, err)
+	}
+	return err
+}
+
+// checkAnomalyAction 금액 기반 조치는 금액 불일치 건에만, 차액 환불은 초과 입금일 때만 허용한다.
+func checkAnomalyAction(anomaly *models.PaymentAnomaly, action string) error {
+	if action == constant.AnomalyResolutionFalsePositive {
+		return nil
+	}
+	if anomaly.AnomalyType != constant.AnomalyTypeInvalidAmount {
+		return fmt.Errorf("%w: %s requires an amount mismatch anomaly", ErrAnomalyActionNotAllowed, action)
+	}
+	if action == constant.AnomalyResolutionRefundDifference {
+		if !anomaly.PaidAmount.SameCurrency(anomaly.ExpectedAmount) || anomaly.PaidAmount.Minor <= anomaly.ExpectedAmount.Minor {
+			return fmt.Errorf("%w: paid amount %s does not exceed expected amount %s", ErrAnomalyActionNotAllowed, anomaly.PaidAmount, anomaly.ExpectedAmount)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/constant"
+	"paymentfc/mocks"
+	"paymentfc/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestAnomalyService_ResolveAnomaly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockPaymentService := NewMockPaymentService(ctrl)
+	mockRefundService := NewMockRefundService(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	svc := NewAnomalyService(mockDB, mockPaymentService, mockRefundService, mockAuditLog)
+	ctx := context.Background()
+
+	amountAnomaly := func(id int64, expected, paid int64) *models.PaymentAnomaly {
+		return &models.PaymentAnomaly{
+			ID:             id,
+			OrderID:        100,
+			ExternalID:     "order-100",
+			AnomalyType:    constant.AnomalyTypeInvalidAmount,
+			ExpectedAmount: models.NewMoney(expected, "IDR"),
+			PaidAmount:     models.NewMoney(paid, "IDR"),
+			Status:         constant.PaymentAnomalyStatusNeedToCheck,
+		}
+	}
+
+	t.Run("rejects unknown action", func(t *testing.T) {
+		_, err := svc.ResolveAnomaly(ctx, 1, models.ResolveAnomalyRequest{Action: "DELETE", Reason: "x"}, 7)
+		assert.ErrorIs(t, err, ErrInvalidAnomalyAction)
+	})
+
+	t.Run("marks false positive", func(t *testing.T) {
+		anomaly := &models.PaymentAnomaly{ID: 1, OrderID: 100, AnomalyType: constant.AnomalyTypeIllegalTransition, Status: constant.PaymentAnomalyStatusNeedToCheck}
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(1)).Return(anomaly, nil)
+		mockDB.EXPECT().UpdatePaymentAnomalyStatus(ctx, int64(1), resolvableAnomalyStatuses, models.PaymentAnomalyUpdate{Status: constant.PaymentAnomalyStatusResolving}).Return(nil)
+		mockDB.EXPECT().UpdatePaymentAnomalyStatus(ctx, int64(1), []int{constant.PaymentAnomalyStatusResolving}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ int64, _ []int, update models.PaymentAnomalyUpdate) error {
+				assert.Equal(t, constant.PaymentAnomalyStatusFalsePositive, update.Status)
+				assert.Equal(t, constant.AnomalyResolutionFalsePositive, update.Resolution)
+				assert.Equal(t, int64(7), update.ResolvedBy)
+				assert.Equal(t, "duplicate alert", update.ResolveReason)
+				return nil
+			})
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, log *models.PaymentAuditLog) error {
+			assert.Equal(t, "PAYMENT_ANOMALY_RESOLVED", log.Event)
+			assert.Equal(t, "admin", log.Actor)
+			assert.Equal(t, int64(7), log.UserID)
+			return nil
+		})
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(1)).Return(&models.PaymentAnomaly{ID: 1, Status: constant.PaymentAnomalyStatusFalsePositive}, nil)
+
+		resolved, err := svc.ResolveAnomaly(ctx, 1, models.ResolveAnomalyRequest{Action: constant.AnomalyResolutionFalsePositive, Reason: "duplicate alert"}, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, constant.PaymentAnomalyStatusFalsePositive, resolved.Status)
+	})
+
+	t.Run("accepts paid amount", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(2)).Return(amountAnomaly(2, 10000, 9000), nil)
+		mockDB.EXPECT().UpdatePaymentAnomalyStatus(ctx, int64(2), resolvableAnomalyStatuses, gomock.Any()).Return(nil)
+		mockPaymentService.EXPECT().ProcessPaymentSuccess(ctx, int64(100)).Return(nil)
+		mockDB.EXPECT().UpdatePaymentAnomalyStatus(ctx, int64(2), []int{constant.PaymentAnomalyStatusResolving}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ int64, _ []int, update models.PaymentAnomalyUpdate) error {
+				assert.Equal(t, constant.PaymentAnomalyStatusSuccess, update.Status)
+				assert.Equal(t, constant.AnomalyResolutionAcceptPaidAmount, update.Resolution)
+				return nil
+			})
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(2)).Return(&models.PaymentAnomaly{ID: 2, Status: constant.PaymentAnomalyStatusSuccess}, nil)
+
+		_, err := svc.ResolveAnomaly(ctx, 2, models.ResolveAnomalyRequest{Action: constant.AnomalyResolutionAcceptPaidAmount, Reason: "customer paid short, accepted"}, 7)
+		assert.NoError(t, err)
+	})
+
+	t.Run("refunds overpaid difference", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(3)).Return(amountAnomaly(3, 10000, 12500), nil)
+		mockDB.EXPECT().UpdatePaymentAnomalyStatus(ctx, int64(3), resolvableAnomalyStatuses, gomock.Any()).Return(nil)
+		mockPaymentService.EXPECT().ProcessPaymentSuccess(ctx, int64(100)).Return(nil)
+		mockRefundService.EXPECT().RefundOverpayment(ctx, int64(3), int64(100), models.NewMoney(2500, "IDR"), int64(7)).Return(&models.Refund{ID: 55}, nil)
+		mockDB.EXPECT().UpdatePaymentAnomalyStatus(ctx, int64(3), []int{constant.PaymentAnomalyStatusResolving}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ int64, _ []int, update models.PaymentAnomalyUpdate) error {
+				assert.Equal(t, constant.PaymentAnomalyStatusSuccess, update.Status)
+				assert.Equal(t, int64(55), update.RefundID)
+				return nil
+			})
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, log *models.PaymentAuditLog) error {
+			metadata := log.Metadata.(map[string]any)
+			assert.Equal(t, int64(55), metadata["refund_id"])
+			return nil
+		})
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(3)).Return(&models.PaymentAnomaly{ID: 3, Status: constant.PaymentAnomalyStatusSuccess, RefundID: 55}, nil)
+
+		resolved, err := svc.ResolveAnomaly(ctx, 3, models.ResolveAnomalyRequest{Action: constant.AnomalyResolutionRefundDifference, Reason: "overpaid"}, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(55), resolved.RefundID)
+	})
+
+	t.Run("refund difference requires overpayment", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(4)).Return(amountAnomaly(4, 10000, 9000), nil)
+
+		_, err := svc.ResolveAnomaly(ctx, 4, models.ResolveAnomalyRequest{Action: constant.AnomalyResolutionRefundDifference, Reason: "x"}, 7)
+		assert.ErrorIs(t, err, ErrAnomalyActionNotAllowed)
+	})
+
+	t.Run("amount actions require amount mismatch anomaly", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(5)).Return(&models.PaymentAnomaly{ID: 5, AnomalyType: constant.AnomalyTypeIllegalTransition}, nil)
+
+		_, err := svc.ResolveAnomaly(ctx, 5, models.ResolveAnomalyRequest{Action: constant.AnomalyResolutionAcceptPaidAmount, Reason: "x"}, 7)
+		assert.ErrorIs(t, err, ErrAnomalyActionNotAllowed)
+	})
+
+	t.Run("already claimed by another admin", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(6)).Return(amountAnomaly(6, 10000, 9000), nil)
+		mockDB.EXPECT().UpdatePaymentAnomalyStatus(ctx, int64(6), resolvableAnomalyStatuses, gomock.Any()).Return(repository.ErrPaymentAnomalyConflict)
+
+		_, err := svc.ResolveAnomaly(ctx, 6, models.ResolveAnomalyRequest{Action: constant.AnomalyResolutionAcceptPaidAmount, Reason: "x"}, 7)
+		assert.ErrorIs(t, err, ErrAnomalyNotResolvable)
+	})
+
+	t.Run("failed refund leaves anomaly for retry", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(8)).Return(amountAnomaly(8, 10000, 12500), nil)
+		mockDB.EXPECT().UpdatePaymentAnomalyStatus(ctx, int64(8), resolvableAnomalyStatuses, gomock.Any()).Return(nil)
+		mockPaymentService.EXPECT().ProcessPaymentSuccess(ctx, int64(100)).Return(nil)
+		mockRefundService.EXPECT().RefundOverpayment(ctx, int64(8), int64(100), gomock.Any(), int64(7)).Return(nil, errors.New("gateway timeout"))
+		mockDB.EXPECT().UpdatePaymentAnomalyStatus(ctx, int64(8), []int{constant.PaymentAnomalyStatusResolving}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ int64, _ []int, update models.PaymentAnomalyUpdate) error {
+				assert.Equal(t, constant.PaymentAnomalyStatusRetry, update.Status)
+				assert.Contains(t, update.Notes, "gateway timeout")
+				return nil
+			})
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, log *models.PaymentAuditLog) error {
+			assert.Equal(t, "PAYMENT_ANOMALY_RESOLVE_FAILED", log.Event)
+			return nil
+		})
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(8)).Return(&models.PaymentAnomaly{ID: 8, Status: constant.PaymentAnomalyStatusRetry}, nil)
+
+		resolved, err := svc.ResolveAnomaly(ctx, 8, models.ResolveAnomalyRequest{Action: constant.AnomalyResolutionRefundDifference, Reason: "overpaid"}, 7)
+		assert.ErrorIs(t, err, ErrAnomalyActionFailed)
+		assert.Equal(t, constant.PaymentAnomalyStatusRetry, resolved.Status)
+	})
+}
+
+func TestAnomalyService_GetAnomalyDetail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	svc := NewAnomalyService(mockDB, nil, nil, mockAuditLog)
+	ctx := context.Background()
+
+	t.Run("joins payment, history and audit trail", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(1)).Return(&models.PaymentAnomaly{ID: 1, OrderID: 100}, nil)
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, int64(100)).Return(&models.Payment{ID: 10, OrderID: 100}, nil)
+		mockDB.EXPECT().GetPaymentStatusHistory(ctx, int64(10)).Return([]models.PaymentStatusHistory{{ID: 1, PaymentID: 10}}, nil)
+		mockAuditLog.EXPECT().ListAuditLogs(ctx, models.AuditLogFilter{OrderID: 100, Limit: anomalyAuditTrailLimit}).
+			Return(models.AuditLogPage{Logs: []models.PaymentAuditLog{{OrderID: 100, Event: "PAYMENT_SUCCESS"}}}, nil)
+
+		detail, err := svc.GetAnomalyDetail(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), detail.Payment.ID)
+		assert.Len(t, detail.StatusHistory, 1)
+		assert.Len(t, detail.AuditTrail, 1)
+	})
+
+	t.Run("tolerates missing payment and audit store errors", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentAnomaly(ctx, int64(2)).Return(&models.PaymentAnomaly{ID: 2, OrderID: 200}, nil)
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, int64(200)).Return(nil, gorm.ErrRecordNotFound)
+		mockAuditLog.EXPECT().ListAuditLogs(ctx, gomock.Any()).Return(models.AuditLogPage{}, errors.New("mongo down"))
+
+		detail, err := svc.GetAnomalyDetail(ctx, 2)
+		assert.NoError(t, err)
+		assert.Nil(t, detail.Payment)
+		assert.Empty(t, detail.AuditTrail)
+	})
+}
+
+func TestAnomalyService_ListAnomalies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	svc := NewAnomalyService(mockDB, nil, nil, nil)
+	ctx := context.Background()
+
+	filter := models.PaymentAnomalyFilter{Status: constant.PaymentAnomalyStatusNeedToCheck, Limit: 2}
+	mockDB.EXPECT().ListPaymentAnomalies(ctx, filter).Return([]models.PaymentAnomaly{{ID: 9}, {ID: 7}}, nil)
+
+	page, err := svc.ListAnomalies(ctx, filter)
+	assert.NoError(t, err)
+	assert.Len(t, page.Anomalies, 2)
+	assert.Equal(t, int64(7), page.NextCursor)
+}
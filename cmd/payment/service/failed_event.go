@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"paymentfc/constant"
+	"paymentfc/log"
+	"paymentfc/models"
+	"time"
+)
+
+var (
+	// ErrFailedEventResolved 이미 재발행됐거나 종료된 건에 retry/close를 요청한 경우
+	ErrFailedEventResolved = errors.New("failed event is already resolved")
+	// ErrFailedEventNotRebuildable outbox 원본이 없고 failed_type만으로는 메시지를 다시 만들 수 없는 건
+	ErrFailedEventNotRebuildable = errors.New("failed event cannot be rebuilt for republish")
+	// ErrFailedEventRepublish 재발행 시도가 실패한 경우 (결과는 failed_events에 반영된다)
+	ErrFailedEventRepublish = errors.New("failed event republish failed")
+)
+
+// failedEventRetryBaseDelay 재처리 실패 후 다음 시도 간격 = base * 2^(attempts-1)
+const failedEventRetryBaseDelay = 1 * time.Minute
+
+func (s *paymentService) ListFailedEvents(ctx context.Context, filter models.FailedEventFilter) (models.FailedEventPage, error) {
+	events, err := s.database.ListFailedEvents(ctx, filter)
+	if err != nil {
+		return models.FailedEventPage{}, err
+	}
+	page := models.FailedEventPage{Events: events}
+	if filter.Limit > 0 && len(events) == filter.Limit {
+		page.NextCursor = events[len(events)-1].ID
+	}
+	return page, nil
+}
+
+// RetryFailedEvent admin 요청으로 시도 횟수와 관계없이 즉시 재발행한다.
+func (s *paymentService) RetryFailedEvent(ctx context.Context, id int64, requestedBy int64) (*models.FailedEvent, error) {
+	event, err := s.database.GetFailedEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if isFailedEventResolved(event.Status) {
+		return event, ErrFailedEventResolved
+	}
+	s.auditFailedEvent(ctx, event, requestedBy, "FAILED_EVENT_RETRY_REQUESTED", "admin", nil)
+
+	if err := s.ReprocessFailedEvent(ctx, event, "admin"); err != nil {
+		return event, err
+	}
+	return event, nil
+}
+
+// CloseFailedEvent 재발행 없이 수동 확인 완료로 닫는다.
+func (s *paymentService) CloseFailedEvent(ctx context.Context, id int64, requestedBy int64, reason string) (*models.FailedEvent, error) {
+	event, err := s.database.GetFailedEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if isFailedEventResolved(event.Status) {
+		return event, ErrFailedEventResolved
+	}
+	previousStatus := event.Status
+	event.Status = constant.FailedPublishEventStatusClosed
+	event.Notes = reason
+	if err := s.database.UpdateFailedEventResult(ctx, event.ID, event.Status, event.Attempts, event.Notes, event.NextAttemptTime); err != nil {
+		return nil, err
+	}
+	s.auditFailedEvent(ctx, event, requestedBy, "FAILED_EVENT_CLOSED", "admin", map[string]any{
+		"previous_status": previousStatus,
+		"reason":          reason,
+	})
+	return event, nil
+}
+
+// ReprocessFailedEvent 한 건을 재발행하고 결과(성공/재시도 예약/수동 확인)를 failed_events에 남긴다.
+func (s *paymentService) ReprocessFailedEvent(ctx context.Context, event *models.FailedEvent, actor string) error {
+	event.Attempts++
+	publishErr := s.republishFailedEvent(ctx, event)
+	if publishErr == nil {
+		event.Status = constant.FailedPublishEventStatusSuccess
+		event.Notes = fmt.Sprintf("republished by %s", actor)
+	} else {
+		event.Status = constant.FailedPublishEventStatusRetry
+		if event.Attempts >= constant.MaxFailedEventAttempts || errors.Is(publishErr, ErrFailedEventNotRebuildable) {
+			event.Status = constant.FailedPublishEventStatusNeedToCheck
+		}
+		event.Notes = publishErr.Error()
+		event.NextAttemptTime = time.Now().Add(failedEventRetryBaseDelay << min(event.Attempts-1, 10))
+	}
+	if err := s.database.UpdateFailedEventResult(ctx, event.ID, event.Status, event.Attempts, event.Notes, event.NextAttemptTime); err != nil {
+		return err
+	}
+
+	if publishErr != nil {
+		log.Logger.Error().Err(publishErr).Int64("failed_event_id", event.ID).Int("attempts", event.Attempts).Msg("Failed to republish failed event")
+		s.auditFailedEvent(ctx, event, 0, "FAILED_EVENT_RETRY_FAILED", actor, map[string]any{"error": publishErr.Error()})
+		return fmt.Errorf("%w: %v", ErrFailedEventRepublish, publishErr)
+	}
+	s.auditFailedEvent(ctx, event, 0, "FAILED_EVENT_REPUBLISHED", actor, nil)
+	return nil
+}
+
+// republishFailedEvent outbox 원본이 있으면 그대로, 없으면 failed_type으로 상태 이벤트를 다시 만들어 발행한다.
+func (s *paymentService) republishFailedEvent(ctx context.Context, event *models.FailedEvent) error {
+	if event.OutboxID != 0 {
+		outbox, err := s.database.GetOutboxEvent(ctx, event.OutboxID)
+		if err != nil {
+			return err
+		}
+		if err := s.publisher.PublishOutboxEvent(ctx, *outbox); err != nil {
+			return err
+		}
+		if err := s.database.MarkOutboxEventSent(ctx, outbox.ID); err != nil {
+			log.Logger.Warn().Err(err).Int64("outbox_id", outbox.ID).Msg("Republished outbox event but failed to mark it sent")
+		}
+		return nil
+	}
+
+	switch event.FailedType {
+	case constant.FailedPublishEventPaymentSuccess:
+		return s.publisher.PublishPaymentStatus(ctx, event.OrderID, constant.PaymentStatusPaid, constant.KafkaTopicPaymentSuccess)
+	case constant.FailedPublishEventPaymentFailed:
+		return s.publisher.PublishPaymentStatus(ctx, event.OrderID, constant.PaymentStatusFailed, constant.KafkaTopicPaymentFailed)
+	case constant.FailedPublishEventPaymentExpired:
+		return s.publisher.PublishPaymentStatus(ctx, event.OrderID, constant.PaymentStatusExpired, constant.KafkaTopicPaymentExpired)
+	}
+	// payment.refunded는 환불 건별 금액이, payment.request_failed는 실패 사유가 필요해 outbox 원본 없이는 다시 만들 수 없다.
+	return ErrFailedEventNotRebuildable
+}
+
+func (s *paymentService) auditFailedEvent(ctx context.Context, event *models.FailedEvent, userID int64, name, actor string, extra map[string]any) {
+	metadata := map[string]any{
+		"failed_event_id": event.ID,
+		"failed_type":     event.FailedType,
+		"status":          event.Status,
+		"attempts":        event.Attempts,
+	}
+	for k, v := range extra {
+		metadata[k] = v
+	}
+	if err := s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+		OrderID:    event.OrderID,
+		UserID:     userID,
+		ExternalID: event.ExternalID,
+		Event:      name,
+		Actor:      actor,
+		Metadata:   metadata,
+	}); err != nil {
+		log.Logger.Error().Err(err).Int64("failed_event_id", event.ID).Msg("Failed to save audit log")
+	}
+}
+
+func isFailedEventResolved(status int) bool {
+	return status == constant.FailedPublishEventStatusSuccess || status == constant.FailedPublishEventStatusClosed
+}
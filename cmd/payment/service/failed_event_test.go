@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"paymentfc/constant"
+	"paymentfc/mocks"
+	"paymentfc/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestPaymentService_ReprocessFailedEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockPublisher := mocks.NewMockPaymentEventPublisher(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	svc := NewPaymentService(mockDB, mockPublisher, nil, mockAuditLog)
+	ctx := context.Background()
+
+	t.Run("republishes original outbox message", func(t *testing.T) {
+		event := &models.FailedEvent{ID: 1, OrderID: 100, FailedType: constant.FailedPublishEventPaymentRefunded, OutboxID: 9, Status: constant.FailedPublishEventStatusNeedToCheck}
+		outbox := &models.OutboxEvent{ID: 9, OrderID: 100, EventType: constant.KafkaTopicPaymentRefunded, MessageKey: "order-100", Payload: `{}`}
+		mockDB.EXPECT().GetOutboxEvent(ctx, int64(9)).Return(outbox, nil)
+		mockPublisher.EXPECT().PublishOutboxEvent(ctx, *outbox).Return(nil)
+		mockDB.EXPECT().MarkOutboxEventSent(ctx, int64(9)).Return(nil)
+		mockDB.EXPECT().UpdateFailedEventResult(ctx, int64(1), constant.FailedPublishEventStatusSuccess, 1, gomock.Any(), gomock.Any()).Return(nil)
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, log *models.PaymentAuditLog) error {
+			assert.Equal(t, "FAILED_EVENT_REPUBLISHED", log.Event)
+			assert.Equal(t, "failed_event_reprocessor", log.Actor)
+			return nil
+		})
+
+		err := svc.ReprocessFailedEvent(ctx, event, "failed_event_reprocessor")
+		assert.NoError(t, err)
+		assert.Equal(t, constant.FailedPublishEventStatusSuccess, event.Status)
+	})
+
+	t.Run("schedules retry when legacy status event publish fails", func(t *testing.T) {
+		event := &models.FailedEvent{ID: 2, OrderID: 200, FailedType: constant.FailedPublishEventPaymentExpired, Attempts: 1, Status: constant.FailedPublishEventStatusRetry}
+		mockPublisher.EXPECT().PublishPaymentStatus(ctx, int64(200), constant.PaymentStatusExpired, constant.KafkaTopicPaymentExpired).Return(errors.New("kafka unavailable"))
+		mockDB.EXPECT().UpdateFailedEventResult(ctx, int64(2), constant.FailedPublishEventStatusRetry, 2, "kafka unavailable", gomock.Any()).Return(nil)
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
+
+		err := svc.ReprocessFailedEvent(ctx, event, "failed_event_reprocessor")
+		assert.ErrorIs(t, err, ErrFailedEventRepublish)
+	})
+
+	t.Run("leaves exhausted event for manual check", func(t *testing.T) {
+		event := &models.FailedEvent{ID: 3, OrderID: 300, FailedType: constant.FailedPublishEventPaymentSuccess, Attempts: constant.MaxFailedEventAttempts - 1, Status: constant.FailedPublishEventStatusRetry}
+		mockPublisher.EXPECT().PublishPaymentStatus(ctx, int64(300), constant.PaymentStatusPaid, constant.KafkaTopicPaymentSuccess).Return(errors.New("kafka unavailable"))
+		mockDB.EXPECT().UpdateFailedEventResult(ctx, int64(3), constant.FailedPublishEventStatusNeedToCheck, constant.MaxFailedEventAttempts, "kafka unavailable", gomock.Any()).Return(nil)
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
+
+		err := svc.ReprocessFailedEvent(ctx, event, "failed_event_reprocessor")
+		assert.Error(t, err)
+	})
+
+	t.Run("refunded event without outbox cannot be rebuilt", func(t *testing.T) {
+		event := &models.FailedEvent{ID: 4, OrderID: 400, FailedType: constant.FailedPublishEventPaymentRefunded, Status: constant.FailedPublishEventStatusNeedToCheck}
+		mockDB.EXPECT().UpdateFailedEventResult(ctx, int64(4), constant.FailedPublishEventStatusNeedToCheck, 1, ErrFailedEventNotRebuildable.Error(), gomock.Any()).Return(nil)
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
+
+		err := svc.ReprocessFailedEvent(ctx, event, "failed_event_reprocessor")
+		assert.ErrorIs(t, err, ErrFailedEventRepublish)
+	})
+}
+
+func TestPaymentService_FailedEventAdminActions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockPublisher := mocks.NewMockPaymentEventPublisher(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	svc := NewPaymentService(mockDB, mockPublisher, nil, mockAuditLog)
+	ctx := context.Background()
+
+	t.Run("retry audits request and republishes", func(t *testing.T) {
+		mockDB.EXPECT().GetFailedEvent(ctx, int64(10)).Return(&models.FailedEvent{ID: 10, OrderID: 1, FailedType: constant.FailedPublishEventPaymentFailed, Attempts: constant.MaxFailedEventAttempts, Status: constant.FailedPublishEventStatusNeedToCheck}, nil)
+		var events []string
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, log *models.PaymentAuditLog) error {
+			events = append(events, log.Event)
+			return nil
+		}).Times(2)
+		mockPublisher.EXPECT().PublishPaymentStatus(ctx, int64(1), constant.PaymentStatusFailed, constant.KafkaTopicPaymentFailed).Return(nil)
+		mockDB.EXPECT().UpdateFailedEventResult(ctx, int64(10), constant.FailedPublishEventStatusSuccess, constant.MaxFailedEventAttempts+1, gomock.Any(), gomock.Any()).Return(nil)
+
+		event, err := svc.RetryFailedEvent(ctx, 10, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, constant.FailedPublishEventStatusSuccess, event.Status)
+		assert.Equal(t, []string{"FAILED_EVENT_RETRY_REQUESTED", "FAILED_EVENT_REPUBLISHED"}, events)
+	})
+
+	t.Run("retry rejects resolved event", func(t *testing.T) {
+		mockDB.EXPECT().GetFailedEvent(ctx, int64(11)).Return(&models.FailedEvent{ID: 11, Status: constant.FailedPublishEventStatusClosed}, nil)
+
+		_, err := svc.RetryFailedEvent(ctx, 11, 7)
+		assert.ErrorIs(t, err, ErrFailedEventResolved)
+	})
+
+	t.Run("retry returns not found", func(t *testing.T) {
+		mockDB.EXPECT().GetFailedEvent(ctx, int64(12)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.RetryFailedEvent(ctx, 12, 7)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("close marks event closed with reason", func(t *testing.T) {
+		mockDB.EXPECT().GetFailedEvent(ctx, int64(13)).Return(&models.FailedEvent{ID: 13, OrderID: 5, Status: constant.FailedPublishEventStatusNeedToCheck}, nil)
+		mockDB.EXPECT().UpdateFailedEventResult(ctx, int64(13), constant.FailedPublishEventStatusClosed, 0, "published manually", gomock.Any()).Return(nil)
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, log *models.PaymentAuditLog) error {
+			assert.Equal(t, "FAILED_EVENT_CLOSED", log.Event)
+			assert.Equal(t, int64(7), log.UserID)
+			return nil
+		})
+
+		event, err := svc.CloseFailedEvent(ctx, 13, 7, "published manually")
+		assert.NoError(t, err)
+		assert.Equal(t, constant.FailedPublishEventStatusClosed, event.Status)
+	})
+
+	t.Run("list returns next cursor on full page", func(t *testing.T) {
+		filter := models.FailedEventFilter{Limit: 2}
+		mockDB.EXPECT().ListFailedEvents(ctx, filter).Return([]models.FailedEvent{{ID: 9}, {ID: 8}}, nil)
+
+		page, err := svc.ListFailedEvents(ctx, filter)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(8), page.NextCursor)
+	})
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"paymentfc/cmd/payment/repository"
+	usergrpc "paymentfc/grpc"
+	"paymentfc/log"
+	"paymentfc/models"
+	"paymentfc/pdf"
+	"strings"
+	"time"
+)
+
+const (
+	invoicePdfCacheTTL = 24 * time.Hour
+	// invoiceTemplateVersion 레이아웃을 바꾸면 올려서 기존 캐시/ETag를 무효화한다.
+	invoiceTemplateVersion = "v3"
+)
+
+type InvoiceService interface {
+	GetInvoicePdf(ctx context.Context, orderID, userID int64, ifNoneMatch string) (*models.InvoicePdf, error)
+}
+
+type invoiceService struct {
+	database   repository.PaymentDatabase
+	cache      repository.InvoiceCache
+	userClient usergrpc.UserClientInterface
+	company    pdf.Company
+	location   *time.Location
+}
+
+func NewInvoiceService(db repository.PaymentDatabase, cache repository.InvoiceCache, userClient usergrpc.UserClientInterface, company pdf.Company, location *time.Location) InvoiceService {
+	return &invoiceService{
+		database:   db,
+		cache:      cache,
+		userClient: userClient,
+		company:    company,
+		location:   location,
+	}
+}
+
+// GetInvoicePdf 결제 id + 상태 + 수정 시각으로 캐시 키/ETag를 만든다. 결제가 바뀌지 않았으면 캐시된 바이트를 돌려주고,
+// If-None-Match가 ETag와 같으면 렌더링 없이 NotModified로 돌려준다. 요청자가 결제 소유자가 아니면 ErrOrderForbidden.
+func (s *invoiceService) GetInvoicePdf(ctx context.Context, orderID, userID int64, ifNoneMatch string) (*models.InvoicePdf, error) {
+	payment, err := s.database.GetPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.UserID != userID {
+		return nil, ErrOrderForbidden
+	}
+	key := invoiceCacheKey(payment)
+	result := &models.InvoicePdf{
+		ETag:         invoiceETag(key),
+		LastModified: payment.UpdateTime,
+	}
+	if result.LastModified.IsZero() {
+		result.LastModified = payment.CreateTime
+	}
+	if etagMatches(ifNoneMatch, result.ETag) {
+		result.NotModified = true
+		return result, nil
+	}
+
+	if s.cache != nil {
+		content, err := s.cache.Get(ctx, key)
+		if err != nil {
+			log.Logger.Warn().Err(err).Int64("order_id", orderID).Msg("Failed to read invoice pdf cache")
+		}
+		if len(content) > 0 {
+			result.Content = content
+			return result, nil
+		}
+	}
+
+	var buf bytes.Buffer
+	invoice := pdf.Invoice{
+		Company:  s.company,
+		Customer: s.customer(ctx, payment.UserID),
+		Payment:  payment,
+		Location: s.location,
+		IssuedAt: payment.CreateTime,
+	}
+	if err := pdf.WriteInvoicePdf(&buf, invoice); err != nil {
+		return nil, err
+	}
+	result.Content = buf.Bytes()
+
+	if s.cache != nil {
+		if err := s.cache.Set(ctx, key, result.Content, invoicePdfCacheTTL); err != nil {
+			log.Logger.Warn().Err(err).Int64("order_id", orderID).Msg("Failed to write invoice pdf cache")
+		}
+	}
+	return result, nil
+}
+
+// customer user 서비스에서 이름/이메일을 가져온다. 조회 실패로 인보이스 발급을 막지는 않는다.
+func (s *invoiceService) customer(ctx context.Context, userID int64) pdf.Customer {
+	if s.userClient == nil {
+		return pdf.Customer{}
+	}
+	userInfo, err := s.userClient.GetUserInfoByUserId(ctx, userID)
+	if err != nil {
+		log.Logger.Warn().Err(err).Int64("user_id", userID).Msg("Failed to get user info for invoice, rendering without customer details")
+		return pdf.Customer{}
+	}
+	return pdf.Customer{Name: userInfo.GetName(), Email: userInfo.GetEmail()}
+}
+
+func invoiceCacheKey(payment *models.Payment) string {
+	return fmt.Sprintf("invoice:pdf:%s:%d:%s:%d", invoiceTemplateVersion, payment.ID, payment.Status, payment.UpdateTime.UnixNano())
+}
+
+// invoiceETag 고객 정보는 user 서비스에서 바뀔 수 있으므로 weak ETag로 둔다.
+func invoiceETag(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// etagMatches If-None-Match 헤더(쉼표 구분 목록 또는 *)를 weak 비교한다.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
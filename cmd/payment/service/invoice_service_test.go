@@ -0,0 +1,114 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"paymentfc/constant"
+	"paymentfc/mocks"
+	"paymentfc/models"
+	pb "paymentfc/pb/proto"
+	"paymentfc/pdf"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestInvoiceService_GetInvoicePdf(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockCache := mocks.NewMockInvoiceCache(ctrl)
+	mockUserClient := mocks.NewMockUserClientInterface(ctrl)
+	svc := NewInvoiceService(mockDB, mockCache, mockUserClient, pdf.Company{Name: "GO-Commerce"}, time.FixedZone("WIB", 7*60*60))
+	ctx := context.Background()
+
+	payment := &models.Payment{
+		ID:         1,
+		OrderID:    12345,
+		UserID:     100,
+		ExternalID: "order-12345",
+		Amount:     models.NewMoney(150000, "IDR"),
+		Status:     constant.PaymentStatusPaid,
+		CreateTime: time.Date(2026, 1, 30, 3, 0, 0, 0, time.UTC),
+		UpdateTime: time.Date(2026, 1, 30, 3, 5, 0, 0, time.UTC),
+		Items: []models.LineItem{
+			{ProductID: 7, Name: "Kopi Arabika Gayo 250g", Quantity: 2, UnitPrice: models.NewMoney(75000, "IDR")},
+		},
+	}
+	key := invoiceCacheKey(payment)
+
+	t.Run("renders and caches on miss", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, int64(12345)).Return(payment, nil)
+		mockCache.EXPECT().Get(ctx, key).Return(nil, nil)
+		mockUserClient.EXPECT().GetUserInfoByUserId(ctx, int64(100)).Return(&pb.GetUserInfoByUserIdResponse{Name: "Budi", Email: "budi@example.com"}, nil)
+		mockCache.EXPECT().Set(ctx, key, gomock.Any(), invoicePdfCacheTTL).Return(nil)
+
+		got, err := svc.GetInvoicePdf(ctx, 12345, 100, "")
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(got.Content, []byte("%PDF")))
+		assert.Equal(t, invoiceETag(key), got.ETag)
+		assert.Equal(t, payment.UpdateTime, got.LastModified)
+		assert.False(t, got.NotModified)
+	})
+
+	t.Run("serves cached bytes without rendering", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, int64(12345)).Return(payment, nil)
+		mockCache.EXPECT().Get(ctx, key).Return([]byte("%PDF-cached"), nil)
+
+		got, err := svc.GetInvoicePdf(ctx, 12345, 100, `W/"stale"`)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("%PDF-cached"), got.Content)
+	})
+
+	t.Run("returns not modified when etag matches", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, int64(12345)).Return(payment, nil)
+
+		got, err := svc.GetInvoicePdf(ctx, 12345, 100, `"other", `+invoiceETag(key))
+		require.NoError(t, err)
+		assert.True(t, got.NotModified)
+		assert.Empty(t, got.Content)
+	})
+
+	t.Run("still renders when cache and user lookup fail", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, int64(12345)).Return(payment, nil)
+		mockCache.EXPECT().Get(ctx, key).Return(nil, errors.New("redis down"))
+		mockUserClient.EXPECT().GetUserInfoByUserId(ctx, int64(100)).Return(nil, errors.New("grpc unavailable"))
+		mockCache.EXPECT().Set(ctx, key, gomock.Any(), invoicePdfCacheTTL).Return(errors.New("redis down"))
+
+		got, err := svc.GetInvoicePdf(ctx, 12345, 100, "")
+		require.NoError(t, err)
+		assert.NotEmpty(t, got.Content)
+	})
+
+	t.Run("rejects other user's invoice", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, int64(12345)).Return(payment, nil)
+
+		_, err := svc.GetInvoicePdf(ctx, 12345, 200, "")
+		assert.ErrorIs(t, err, ErrOrderForbidden)
+	})
+
+	t.Run("returns not found", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, int64(404)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.GetInvoicePdf(ctx, 404, 100, "")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestInvoiceCacheKey_ChangesWithPaymentState(t *testing.T) {
+	payment := &models.Payment{ID: 1, Status: constant.PaymentStatusPending, UpdateTime: time.Unix(100, 0)}
+	pending := invoiceCacheKey(payment)
+
+	payment.Status = constant.PaymentStatusPaid
+	paid := invoiceCacheKey(payment)
+	assert.NotEqual(t, pending, paid)
+
+	payment.UpdateTime = time.Unix(200, 0)
+	assert.NotEqual(t, paid, invoiceCacheKey(payment))
+}
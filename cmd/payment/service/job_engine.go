@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/constant"
+	bizmetrics "paymentfc/infrastructure/metrics"
+	"paymentfc/infrastructure/schedule"
+	"paymentfc/log"
+	"paymentfc/models"
+	"sync"
+	"time"
+)
+
+var (
+	ErrJobNotFound  = errors.New("job not found")
+	ErrJobRunning   = errors.New("job is already running")
+	ErrJobPaused    = errors.New("job is paused")
+	ErrJobNotLeader = errors.New("job runs only on the scheduler leader")
+)
+
+// JobFunc 잡 배치 한 번. ctx에는 spec.Timeout이 걸려 있다.
+type JobFunc func(ctx context.Context, spec JobSpec) (models.JobResult, error)
+
+// JobSpec 잡 실행 설정. Schedule은 "@every 5s" 또는 5필드 cron이다.
+type JobSpec struct {
+	Name       string
+	Schedule   string
+	BatchSize  int
+	MaxRetries int
+	Timeout    time.Duration
+	LeaderOnly bool // true면 리더 인스턴스에서만 스케줄 실행된다
+}
+
+type Job struct {
+	Spec JobSpec
+	Run  JobFunc
+	Wake <-chan struct{} // 선택. 신호가 오면 다음 스케줄을 기다리지 않고 스케줄 실행과 같은 조건으로 돈다
+}
+
+type JobService interface {
+	ListJobs(ctx context.Context) ([]models.JobStatus, error)
+	ListJobRuns(ctx context.Context, filter models.JobRunFilter) (models.JobRunPage, error)
+	PauseJob(ctx context.Context, name string, requestedBy int64) (*models.JobStatus, error)
+	ResumeJob(ctx context.Context, name string, requestedBy int64) (*models.JobStatus, error)
+	TriggerJob(ctx context.Context, name string, force bool, requestedBy int64) (*models.JobStatus, error)
+	JobSpec(name string) (JobSpec, error)
+}
+
+// JobEngine 등록된 잡을 스케줄대로 실행하고 job_runs에 기록한다.
+// 일시정지 상태는 job_states로 모든 인스턴스가 공유하고, 수동 실행은 요청을 받은 인스턴스에서 돈다.
+type JobEngine struct {
+	database   repository.PaymentDatabase
+	leader     *LeaderElector
+	instanceID string
+
+	jobs   []*registeredJob
+	byName map[string]*registeredJob
+}
+
+type registeredJob struct {
+	spec     JobSpec
+	schedule schedule.Schedule
+	run      JobFunc
+	trigger  chan struct{}
+	wake     <-chan struct{}
+
+	mu      sync.Mutex
+	running bool
+	nextRun time.Time
+	lastRun *models.JobRun
+}
+
+func NewJobEngine(database repository.PaymentDatabase, leader *LeaderElector, instanceID string) *JobEngine {
+	return &JobEngine{
+		database:   database,
+		leader:     leader,
+		instanceID: instanceID,
+		byName:     make(map[string]*registeredJob),
+	}
+}
+
+// Register Start 전에 호출한다.
+func (e *JobEngine) Register(job Job) error {
+	if _, ok := e.byName[job.Spec.Name]; ok {
+		return fmt.Errorf("job %s already registered", job.Spec.Name)
+	}
+	sched, err := schedule.Parse(job.Spec.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Spec.Name, err)
+	}
+	if job.Spec.Timeout <= 0 {
+		return fmt.Errorf("job %s: timeout must be positive", job.Spec.Name)
+	}
+	j := &registeredJob{spec: job.Spec, schedule: sched, run: job.Run, trigger: make(chan struct{}, 1), wake: job.Wake}
+	e.jobs = append(e.jobs, j)
+	e.byName[job.Spec.Name] = j
+	return nil
+}
+
+// Start 잡마다 스케줄 루프를 띄운다. ctx가 취소되면 진행 중인 실행을 끝낸 뒤 모든 루프가 멈추면 done이 닫힌다.
+func (e *JobEngine) Start(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, j := range e.jobs {
+		wg.Add(1)
+		go func(j *registeredJob) {
+			defer wg.Done()
+			e.loop(ctx, j)
+		}(j)
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return done
+}
+
+func (e *JobEngine) loop(ctx context.Context, j *registeredJob) {
+	for {
+		next := j.schedule.Next(time.Now())
+		j.mu.Lock()
+		j.nextRun = next
+		j.mu.Unlock()
+
+		// 맞는 시각이 없는 cron이면 수동 실행만 기다린다.
+		var fire <-chan time.Time
+		var timer *time.Timer
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			fire = timer.C
+		}
+
+		trigger := constant.JobTriggerSchedule
+		select {
+		case <-ctx.Done():
+		case <-fire:
+		case <-j.wake:
+		case <-j.trigger:
+			trigger = constant.JobTriggerManual
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if trigger == constant.JobTriggerSchedule && !e.shouldRun(ctx, j) {
+			continue
+		}
+		e.execute(ctx, j, trigger)
+	}
+}
+
+func (e *JobEngine) shouldRun(ctx context.Context, j *registeredJob) bool {
+	if j.spec.LeaderOnly && !e.leader.IsLeader() {
+		log.Logger.Debug().Str("job", j.spec.Name).Msg("Not the scheduler leader, skipping singleton job")
+		return false
+	}
+	paused, err := e.isPaused(ctx, j.spec.Name)
+	if err != nil {
+		// 상태를 못 읽으면 잡도 DB를 못 쓸 가능성이 높으므로 이번 주기는 건너뛴다.
+		log.Logger.Error().Err(err).Str("job", j.spec.Name).Msg("Failed to read job state, skipping run")
+		return false
+	}
+	return !paused
+}
+
+func (e *JobEngine) isPaused(ctx context.Context, name string) (bool, error) {
+	states, err := e.database.GetJobStates(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, state := range states {
+		if state.JobName == name {
+			return state.Paused, nil
+		}
+	}
+	return false, nil
+}
+
+// execute 잡을 한 번 실행한다. 종료 신호가 와도 실행 중인 배치는 timeout까지 끝낼 수 있게 부모 취소는 끊는다.
+func (e *JobEngine) execute(ctx context.Context, j *registeredJob, trigger string) {
+	j.mu.Lock()
+	if j.running {
+		j.mu.Unlock()
+		return
+	}
+	j.running = true
+	j.mu.Unlock()
+
+	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), j.spec.Timeout)
+	defer cancel()
+
+	run := &models.JobRun{
+		JobName:    j.spec.Name,
+		Trigger:    trigger,
+		InstanceID: e.instanceID,
+		StartTime:  time.Now(),
+	}
+	result, err := j.run(runCtx, j.spec)
+	run.EndTime = time.Now()
+	run.ItemsProcessed = result.Processed
+	run.ItemsFailed = result.Failed
+	switch {
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		run.Status = constant.JobRunStatusTimedOut
+		run.Error = fmt.Sprintf("timed out after %s", j.spec.Timeout)
+	case err != nil:
+		run.Status = constant.JobRunStatusFailed
+		run.Error = err.Error()
+	default:
+		run.Status = constant.JobRunStatusSucceeded
+	}
+	bizmetrics.JobRuns.WithLabelValues(j.spec.Name, run.Status).Inc()
+
+	// 할 일이 없던 실행도 남긴다. 쌓이는 기록은 prune_job_runs 잡이 보관 기간이 지나면 지운다.
+	saveCtx, saveCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	if saveErr := e.database.SaveJobRun(saveCtx, run); saveErr != nil {
+		log.Logger.Error().Err(saveErr).Str("job", j.spec.Name).Msg("Failed to record job run")
+	}
+	saveCancel()
+
+	j.mu.Lock()
+	j.running = false
+	j.lastRun = run
+	j.mu.Unlock()
+}
+
+func (e *JobEngine) ListJobs(ctx context.Context) ([]models.JobStatus, error) {
+	states, err := e.database.GetJobStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paused := make(map[string]bool, len(states))
+	for _, state := range states {
+		paused[state.JobName] = state.Paused
+	}
+	result := make([]models.JobStatus, 0, len(e.jobs))
+	for _, j := range e.jobs {
+		result = append(result, j.status(paused[j.spec.Name]))
+	}
+	return result, nil
+}
+
+func (e *JobEngine) ListJobRuns(ctx context.Context, filter models.JobRunFilter) (models.JobRunPage, error) {
+	if filter.JobName != "" {
+		if _, ok := e.byName[filter.JobName]; !ok {
+			return models.JobRunPage{}, ErrJobNotFound
+		}
+	}
+	runs, err := e.database.ListJobRuns(ctx, filter)
+	if err != nil {
+		return models.JobRunPage{}, err
+	}
+	page := models.JobRunPage{Runs: runs}
+	if filter.Limit > 0 && len(runs) == filter.Limit {
+		page.NextCursor = runs[len(runs)-1].ID
+	}
+	return page, nil
+}
+
+func (e *JobEngine) PauseJob(ctx context.Context, name string, requestedBy int64) (*models.JobStatus, error) {
+	return e.setPaused(ctx, name, true, requestedBy)
+}
+
+func (e *JobEngine) ResumeJob(ctx context.Context, name string, requestedBy int64) (*models.JobStatus, error) {
+	return e.setPaused(ctx, name, false, requestedBy)
+}
+
+func (e *JobEngine) setPaused(ctx context.Context, name string, paused bool, requestedBy int64) (*models.JobStatus, error) {
+	j, ok := e.byName[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	if err := e.database.SetJobPaused(ctx, name, paused, requestedBy); err != nil {
+		return nil, err
+	}
+	log.Logger.Info().Str("job", name).Bool("paused", paused).Int64("requested_by", requestedBy).Msg("Job state changed")
+	status := j.status(paused)
+	return &status, nil
+}
+
+// TriggerJob 다음 스케줄을 기다리지 않고 이 인스턴스에서 바로 실행한다. 이미 실행 중이면 ErrJobRunning.
+// 싱글톤 잡은 리더에서만 실행할 수 있고(ErrJobNotLeader), 일시정지된 잡은 force일 때만 실행한다(ErrJobPaused).
+func (e *JobEngine) TriggerJob(ctx context.Context, name string, force bool, requestedBy int64) (*models.JobStatus, error) {
+	j, ok := e.byName[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	if j.spec.LeaderOnly && !e.leader.IsLeader() {
+		return nil, ErrJobNotLeader
+	}
+	paused, err := e.isPaused(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if paused && !force {
+		return nil, ErrJobPaused
+	}
+	j.mu.Lock()
+	running := j.running
+	j.mu.Unlock()
+	if running {
+		return nil, ErrJobRunning
+	}
+	select {
+	case j.trigger <- struct{}{}:
+	default:
+		// 이미 수동 실행이 대기 중
+	}
+	log.Logger.Info().Str("job", name).Bool("force", force).Int64("requested_by", requestedBy).Msg("Job triggered manually")
+
+	status := j.status(paused)
+	return &status, nil
+}
+
+// JobSpec config가 반영된 잡 설정
+func (e *JobEngine) JobSpec(name string) (JobSpec, error) {
+	j, ok := e.byName[name]
+	if !ok {
+		return JobSpec{}, ErrJobNotFound
+	}
+	return j.spec, nil
+}
+
+func (j *registeredJob) status(paused bool) models.JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	status := models.JobStatus{
+		Name:           j.spec.Name,
+		Schedule:       j.schedule.String(),
+		BatchSize:      j.spec.BatchSize,
+		TimeoutSeconds: int(j.spec.Timeout / time.Second),
+		LeaderOnly:     j.spec.LeaderOnly,
+		Paused:         paused,
+		Running:        j.running,
+		LastRun:        j.lastRun,
+	}
+	if !j.nextRun.IsZero() {
+		next := j.nextRun
+		status.NextRunTime = &next
+	}
+	return status
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"paymentfc/constant"
+	"paymentfc/mocks"
+	"paymentfc/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJobEngine_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := NewJobEngine(mocks.NewMockPaymentDatabase(ctrl), nil, "pod-a")
+	noop := func(context.Context, JobSpec) (models.JobResult, error) { return models.JobResult{}, nil }
+
+	assert.NoError(t, e.Register(Job{Spec: JobSpec{Name: "a", Schedule: "*/5 * * * *", Timeout: time.Minute}, Run: noop}))
+	assert.Error(t, e.Register(Job{Spec: JobSpec{Name: "a", Schedule: "@every 1m", Timeout: time.Minute}, Run: noop}))
+	assert.Error(t, e.Register(Job{Spec: JobSpec{Name: "b", Schedule: "every minute", Timeout: time.Minute}, Run: noop}))
+	assert.Error(t, e.Register(Job{Spec: JobSpec{Name: "c", Schedule: "@every 1m"}, Run: noop}))
+
+	spec, err := e.JobSpec("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "*/5 * * * *", spec.Schedule)
+	_, err = e.JobSpec("b")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestJobEngine_Execute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	t.Run("records idle scheduled run", func(t *testing.T) {
+		mockDB := mocks.NewMockPaymentDatabase(ctrl)
+		e := NewJobEngine(mockDB, nil, "pod-a")
+		assert.NoError(t, e.Register(Job{
+			Spec: JobSpec{Name: "idle", Schedule: "@every 5s", Timeout: time.Second},
+			Run:  func(context.Context, JobSpec) (models.JobResult, error) { return models.JobResult{}, nil },
+		}))
+
+		mockDB.EXPECT().SaveJobRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, run *models.JobRun) error {
+			assert.Equal(t, 0, run.ItemsProcessed)
+			assert.Equal(t, 0, run.ItemsFailed)
+			return nil
+		})
+		e.execute(ctx, e.byName["idle"], constant.JobTriggerSchedule)
+		assert.Equal(t, constant.JobRunStatusSucceeded, e.byName["idle"].lastRun.Status)
+	})
+
+	t.Run("records processed items and errors", func(t *testing.T) {
+		mockDB := mocks.NewMockPaymentDatabase(ctrl)
+		e := NewJobEngine(mockDB, nil, "pod-a")
+		assert.NoError(t, e.Register(Job{
+			Spec: JobSpec{Name: "batch", Schedule: "@every 5s", BatchSize: 5, Timeout: time.Second},
+			Run: func(_ context.Context, spec JobSpec) (models.JobResult, error) {
+				assert.Equal(t, 5, spec.BatchSize)
+				return models.JobResult{Processed: 3, Failed: 1}, errors.New("gateway down")
+			},
+		}))
+
+		mockDB.EXPECT().SaveJobRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, run *models.JobRun) error {
+			assert.Equal(t, "batch", run.JobName)
+			assert.Equal(t, "pod-a", run.InstanceID)
+			assert.Equal(t, constant.JobRunStatusFailed, run.Status)
+			assert.Equal(t, 3, run.ItemsProcessed)
+			assert.Equal(t, 1, run.ItemsFailed)
+			assert.Equal(t, "gateway down", run.Error)
+			return nil
+		})
+		e.execute(ctx, e.byName["batch"], constant.JobTriggerSchedule)
+	})
+
+	t.Run("marks run timed out after spec timeout", func(t *testing.T) {
+		mockDB := mocks.NewMockPaymentDatabase(ctrl)
+		e := NewJobEngine(mockDB, nil, "pod-a")
+		assert.NoError(t, e.Register(Job{
+			Spec: JobSpec{Name: "slow", Schedule: "@every 1m", Timeout: 10 * time.Millisecond},
+			Run: func(ctx context.Context, _ JobSpec) (models.JobResult, error) {
+				<-ctx.Done()
+				return models.JobResult{}, ctx.Err()
+			},
+		}))
+
+		mockDB.EXPECT().SaveJobRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, run *models.JobRun) error {
+			assert.Equal(t, constant.JobRunStatusTimedOut, run.Status)
+			return nil
+		})
+		e.execute(ctx, e.byName["slow"], constant.JobTriggerSchedule)
+	})
+}
+
+func TestJobEngine_PauseAndTrigger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	e := NewJobEngine(mockDB, nil, "pod-a")
+	ran := make(chan string, 1)
+	assert.NoError(t, e.Register(Job{
+		Spec: JobSpec{Name: "sweep", Schedule: "@every 1h", Timeout: time.Second},
+		Run: func(context.Context, JobSpec) (models.JobResult, error) {
+			ran <- "sweep"
+			return models.JobResult{}, nil
+		},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := e.Start(ctx)
+
+	_, err := e.PauseJob(ctx, "missing", 1)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	mockDB.EXPECT().SetJobPaused(ctx, "sweep", true, int64(1)).Return(nil)
+	status, err := e.PauseJob(ctx, "sweep", 1)
+	assert.NoError(t, err)
+	assert.True(t, status.Paused)
+
+	// 일시정지된 잡은 force 없이는 수동 실행도 거절한다.
+	mockDB.EXPECT().GetJobStates(ctx).Return([]models.JobState{{JobName: "sweep", Paused: true}}, nil)
+	_, err = e.TriggerJob(ctx, "sweep", false, 1)
+	assert.ErrorIs(t, err, ErrJobPaused)
+
+	mockDB.EXPECT().GetJobStates(ctx).Return([]models.JobState{{JobName: "sweep", Paused: true}}, nil)
+	mockDB.EXPECT().SaveJobRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, run *models.JobRun) error {
+		assert.Equal(t, constant.JobTriggerManual, run.Trigger)
+		return nil
+	})
+	status, err = e.TriggerJob(ctx, "sweep", true, 1)
+	assert.NoError(t, err)
+	assert.True(t, status.Paused)
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("manual trigger did not run the job")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job engine did not stop after context cancel")
+	}
+}
+
+func TestJobEngine_ShouldRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	follower := NewLeaderElector(mocks.NewMockLeaderLock(ctrl), "", "pod-b", 30*time.Second)
+	e := NewJobEngine(mockDB, follower, "pod-b")
+	noop := func(context.Context, JobSpec) (models.JobResult, error) { return models.JobResult{}, nil }
+	assert.NoError(t, e.Register(Job{Spec: JobSpec{Name: "singleton", Schedule: "@every 1m", Timeout: time.Second, LeaderOnly: true}, Run: noop}))
+	assert.NoError(t, e.Register(Job{Spec: JobSpec{Name: "shared", Schedule: "@every 1m", Timeout: time.Second}, Run: noop}))
+
+	// 리더가 아니면 상태를 조회하지 않고 건너뛴다.
+	assert.False(t, e.shouldRun(ctx, e.byName["singleton"]))
+
+	mockDB.EXPECT().GetJobStates(ctx).Return([]models.JobState{{JobName: "shared", Paused: true}}, nil)
+	assert.False(t, e.shouldRun(ctx, e.byName["shared"]))
+
+	mockDB.EXPECT().GetJobStates(ctx).Return(nil, nil)
+	assert.True(t, e.shouldRun(ctx, e.byName["shared"]))
+
+	// 수동 실행도 싱글톤 잡은 리더에서만 받는다.
+	_, err := e.TriggerJob(ctx, "singleton", true, 1)
+	assert.ErrorIs(t, err, ErrJobNotLeader)
+}
+
+func TestJobEngine_Wake(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	e := NewJobEngine(mockDB, nil, "pod-a")
+	wake := make(chan struct{}, 1)
+	ran := make(chan struct{}, 1)
+	assert.NoError(t, e.Register(Job{
+		Spec: JobSpec{Name: "webhooks", Schedule: "@every 1h", Timeout: time.Second},
+		Run: func(context.Context, JobSpec) (models.JobResult, error) {
+			ran <- struct{}{}
+			return models.JobResult{Processed: 1}, nil
+		},
+		Wake: wake,
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := e.Start(ctx)
+
+	// 깨우는 신호는 스케줄 실행으로 취급해 일시정지 상태를 확인한다.
+	mockDB.EXPECT().GetJobStates(ctx).Return(nil, nil)
+	mockDB.EXPECT().SaveJobRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, run *models.JobRun) error {
+		assert.Equal(t, constant.JobTriggerSchedule, run.Trigger)
+		return nil
+	})
+	wake <- struct{}{}
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("wake signal did not run the job")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job engine did not stop after context cancel")
+	}
+}
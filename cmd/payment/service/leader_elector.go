@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"paymentfc/cmd/payment/repository"
+	bizmetrics "paymentfc/infrastructure/metrics"
+	"paymentfc/log"
+	"paymentfc/models"
+	"sync"
+	"time"
+)
+
+// LeaderElector Redis lease로 싱글톤 잡을 돌릴 인스턴스 하나를 고른다.
+// 리더는 ttl/3마다 lease를 연장하고, 연장에 실패하면 바로 팔로워로 내려가 다른 인스턴스가 lease 만료 후 넘겨받는다.
+type LeaderElector struct {
+	lock       repository.LeaderLock
+	key        string
+	instanceID string
+	ttl        time.Duration
+
+	mu          sync.RWMutex
+	isLeader    bool
+	leaderSince time.Time
+	lastErr     string
+}
+
+// defaultLeaderKey 설정에 key가 없을 때 쓰는 Redis 키
+const defaultLeaderKey = "paymentfc:scheduler:leader"
+
+func NewLeaderElector(lock repository.LeaderLock, key, instanceID string, ttl time.Duration) *LeaderElector {
+	if key == "" {
+		key = defaultLeaderKey
+	}
+	bizmetrics.SchedulerLeader.WithLabelValues(instanceID).Set(0)
+	return &LeaderElector{
+		lock:       lock,
+		key:        key,
+		instanceID: instanceID,
+		ttl:        ttl,
+	}
+}
+
+// Start lease 획득/연장 루프를 띄운다. ctx가 취소되면 lease를 반납해 다른 인스턴스가 바로 넘겨받게 한다.
+func (e *LeaderElector) Start(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+		for {
+			e.tick(ctx)
+			select {
+			case <-ctx.Done():
+				e.resign()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return done
+}
+
+func (e *LeaderElector) resign() {
+	if !e.IsLeader() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.lock.Release(ctx, e.key, e.instanceID); err != nil {
+		log.Logger.Warn().Err(err).Str("instance_id", e.instanceID).Msg("Failed to release scheduler leader lease, it will expire on its own")
+	}
+	e.setLeader(false, nil)
+}
+
+func (e *LeaderElector) tick(ctx context.Context) {
+	var (
+		held bool
+		err  error
+	)
+	if e.IsLeader() {
+		held, err = e.lock.Renew(ctx, e.key, e.instanceID, e.ttl)
+	} else {
+		held, err = e.lock.Acquire(ctx, e.key, e.instanceID, e.ttl)
+		if err == nil && !held {
+			// 재시작 직후처럼 이전 lease가 아직 이 인스턴스 이름으로 남아 있으면 그대로 이어받는다.
+			held, err = e.lock.Renew(ctx, e.key, e.instanceID, e.ttl)
+		}
+	}
+	if err != nil {
+		// lease 상태를 확인할 수 없으면 중복 실행보다 잠시 비는 쪽을 택한다.
+		log.Logger.Error().Err(err).Str("instance_id", e.instanceID).Msg("Failed to refresh scheduler leader lease")
+		held = false
+	}
+	e.setLeader(held, err)
+}
+
+func (e *LeaderElector) setLeader(held bool, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastErr = ""
+	if err != nil {
+		e.lastErr = err.Error()
+	}
+	if held == e.isLeader {
+		return
+	}
+	e.isLeader = held
+	if held {
+		e.leaderSince = time.Now()
+		bizmetrics.SchedulerLeader.WithLabelValues(e.instanceID).Set(1)
+		bizmetrics.SchedulerLeaderTransitions.WithLabelValues("acquired").Inc()
+		log.Logger.Info().Str("instance_id", e.instanceID).Str("key", e.key).Msg("Acquired scheduler leadership")
+		return
+	}
+	bizmetrics.SchedulerLeader.WithLabelValues(e.instanceID).Set(0)
+	bizmetrics.SchedulerLeaderTransitions.WithLabelValues("lost").Inc()
+	log.Logger.Warn().Str("instance_id", e.instanceID).Str("key", e.key).Dur("held_for", time.Since(e.leaderSince)).Msg("Lost scheduler leadership")
+}
+
+// IsLeader 리더 선출을 쓰지 않으면(nil) 단일 인스턴스로 보고 항상 true다.
+func (e *LeaderElector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isLeader
+}
+
+// Status 이 인스턴스의 상태에 Redis lease 키의 현재 owner와 남은 ttl을 더한다.
+// 리더 선출을 쓰지 않으면(nil) Redis를 조회하지 않는다.
+func (e *LeaderElector) Status(ctx context.Context) (models.LeaderStatus, error) {
+	if e == nil {
+		return models.LeaderStatus{IsLeader: true}, nil
+	}
+	status := e.localStatus()
+	holder, ttl, err := e.lock.Holder(ctx, e.key)
+	if err != nil {
+		return status, err
+	}
+	status.Leader = holder
+	status.LeaseTTLMs = ttl.Milliseconds()
+	return status, nil
+}
+
+func (e *LeaderElector) localStatus() models.LeaderStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	status := models.LeaderStatus{
+		Enabled:    true,
+		InstanceID: e.instanceID,
+		IsLeader:   e.isLeader,
+		LastError:  e.lastErr,
+	}
+	if e.isLeader {
+		since := e.leaderSince
+		status.LeaderSince = &since
+	}
+	return status
+}
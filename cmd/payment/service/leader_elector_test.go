@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"paymentfc/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLeaderElector_Tick(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	ttl := 30 * time.Second
+
+	t.Run("acquires then renews", func(t *testing.T) {
+		mockLock := mocks.NewMockLeaderLock(ctrl)
+		e := NewLeaderElector(mockLock, "", "pod-a", ttl)
+
+		mockLock.EXPECT().Acquire(ctx, defaultLeaderKey, "pod-a", ttl).Return(true, nil)
+		e.tick(ctx)
+		assert.True(t, e.IsLeader())
+		assert.NotNil(t, e.localStatus().LeaderSince)
+
+		mockLock.EXPECT().Renew(ctx, defaultLeaderKey, "pod-a", ttl).Return(true, nil)
+		e.tick(ctx)
+		assert.True(t, e.IsLeader())
+	})
+
+	t.Run("stays follower while another instance holds the lease", func(t *testing.T) {
+		mockLock := mocks.NewMockLeaderLock(ctrl)
+		e := NewLeaderElector(mockLock, "leader-key", "pod-b", ttl)
+
+		mockLock.EXPECT().Acquire(ctx, "leader-key", "pod-b", ttl).Return(false, nil)
+		mockLock.EXPECT().Renew(ctx, "leader-key", "pod-b", ttl).Return(false, nil)
+		e.tick(ctx)
+		assert.False(t, e.IsLeader())
+		assert.Nil(t, e.localStatus().LeaderSince)
+	})
+
+	t.Run("resumes own lease left from before restart", func(t *testing.T) {
+		mockLock := mocks.NewMockLeaderLock(ctrl)
+		e := NewLeaderElector(mockLock, "leader-key", "pod-c", ttl)
+
+		mockLock.EXPECT().Acquire(ctx, "leader-key", "pod-c", ttl).Return(false, nil)
+		mockLock.EXPECT().Renew(ctx, "leader-key", "pod-c", ttl).Return(true, nil)
+		e.tick(ctx)
+		assert.True(t, e.IsLeader())
+	})
+
+	t.Run("steps down when renew fails", func(t *testing.T) {
+		mockLock := mocks.NewMockLeaderLock(ctrl)
+		e := NewLeaderElector(mockLock, "leader-key", "pod-d", ttl)
+
+		mockLock.EXPECT().Acquire(ctx, "leader-key", "pod-d", ttl).Return(true, nil)
+		e.tick(ctx)
+
+		mockLock.EXPECT().Renew(ctx, "leader-key", "pod-d", ttl).Return(false, errors.New("redis down"))
+		e.tick(ctx)
+		assert.False(t, e.IsLeader())
+		assert.Equal(t, "redis down", e.localStatus().LastError)
+	})
+}
+
+func TestLeaderElector_NilRunsEverywhere(t *testing.T) {
+	var e *LeaderElector
+	assert.True(t, e.IsLeader())
+	status, err := e.Status(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, status.Enabled)
+	assert.True(t, status.IsLeader)
+}
+
+func TestLeaderElector_Status(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	ttl := 30 * time.Second
+	mockLock := mocks.NewMockLeaderLock(ctrl)
+	e := NewLeaderElector(mockLock, "leader-key", "pod-b", ttl)
+
+	t.Run("reports the instance holding the lease", func(t *testing.T) {
+		mockLock.EXPECT().Holder(ctx, "leader-key").Return("pod-a", 12500*time.Millisecond, nil)
+		status, err := e.Status(ctx)
+		assert.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.Equal(t, "pod-b", status.InstanceID)
+		assert.False(t, status.IsLeader)
+		assert.Equal(t, "pod-a", status.Leader)
+		assert.Equal(t, int64(12500), status.LeaseTTLMs)
+	})
+
+	t.Run("no holder means no leader", func(t *testing.T) {
+		mockLock.EXPECT().Holder(ctx, "leader-key").Return("", time.Duration(0), nil)
+		status, err := e.Status(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, status.Leader)
+		assert.Zero(t, status.LeaseTTLMs)
+	})
+
+	t.Run("returns lookup error", func(t *testing.T) {
+		mockLock.EXPECT().Holder(ctx, "leader-key").Return("", time.Duration(0), errors.New("redis down"))
+		_, err := e.Status(ctx)
+		assert.Error(t, err)
+	})
+}
@@ -36,10 +36,10 @@ func (m *MockPaymentService) EXPECT() *MockPaymentServiceMockRecorder {
 }
 
 // GetAmountByOrderID mocks base method.
-func (m *MockPaymentService) GetAmountByOrderID(ctx context.Context, orderID int64) (float64, error) {
+func (m *MockPaymentService) GetAmountByOrderID(ctx context.Context, orderID int64) (models.Money, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAmountByOrderID", ctx, orderID)
-	ret0, _ := ret[0].(float64)
+	ret0, _ := ret[0].(models.Money)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -151,18 +151,18 @@ func (mr *MockPaymentServiceMockRecorder) SavePaymentRequestFromEvent(ctx, event
 }
 
 // GetFailedPaymentList mocks base method.
-func (m *MockPaymentService) GetFailedPaymentList(ctx context.Context) ([]models.PaymentRequest, error) {
+func (m *MockPaymentService) GetFailedPaymentList(ctx context.Context, maxRetries, limit int) ([]models.PaymentRequest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFailedPaymentList", ctx)
+	ret := m.ctrl.Call(m, "GetFailedPaymentList", ctx, maxRetries, limit)
 	ret0, _ := ret[0].([]models.PaymentRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFailedPaymentList indicates an expected call of GetFailedPaymentList.
-func (mr *MockPaymentServiceMockRecorder) GetFailedPaymentList(ctx interface{}) *gomock.Call {
+func (mr *MockPaymentServiceMockRecorder) GetFailedPaymentList(ctx, maxRetries, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailedPaymentList", reflect.TypeOf((*MockPaymentService)(nil).GetFailedPaymentList), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailedPaymentList", reflect.TypeOf((*MockPaymentService)(nil).GetFailedPaymentList), ctx, maxRetries, limit)
 }
 
 // ListAuditLogs mocks base method.
@@ -208,3 +208,151 @@ func (mr *MockPaymentServiceMockRecorder) WatchAuditInsertStream(ctx, out interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchAuditInsertStream", reflect.TypeOf((*MockPaymentService)(nil).WatchAuditInsertStream), ctx, out)
 }
+
+// ClaimWebhookEventForReplay mocks base method.
+func (m *MockPaymentService) ClaimWebhookEventForReplay(ctx context.Context, id, requestedBy int64) (*models.WebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookEventForReplay", ctx, id, requestedBy)
+	ret0, _ := ret[0].(*models.WebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookEventForReplay indicates an expected call of ClaimWebhookEventForReplay.
+func (mr *MockPaymentServiceMockRecorder) ClaimWebhookEventForReplay(ctx, id, requestedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookEventForReplay", reflect.TypeOf((*MockPaymentService)(nil).ClaimWebhookEventForReplay), ctx, id, requestedBy)
+}
+
+// ClaimWebhookEvents mocks base method.
+func (m *MockPaymentService) ClaimWebhookEvents(ctx context.Context, limit int) ([]models.WebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookEvents", ctx, limit)
+	ret0, _ := ret[0].([]models.WebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookEvents indicates an expected call of ClaimWebhookEvents.
+func (mr *MockPaymentServiceMockRecorder) ClaimWebhookEvents(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookEvents", reflect.TypeOf((*MockPaymentService)(nil).ClaimWebhookEvents), ctx, limit)
+}
+
+// CompleteWebhookEvent mocks base method.
+func (m *MockPaymentService) CompleteWebhookEvent(ctx context.Context, event *models.WebhookEvent, processErr error, retryable bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteWebhookEvent", ctx, event, processErr, retryable)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteWebhookEvent indicates an expected call of CompleteWebhookEvent.
+func (mr *MockPaymentServiceMockRecorder) CompleteWebhookEvent(ctx, event, processErr, retryable interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteWebhookEvent", reflect.TypeOf((*MockPaymentService)(nil).CompleteWebhookEvent), ctx, event, processErr, retryable)
+}
+
+// SaveWebhookEvent mocks base method.
+func (m *MockPaymentService) SaveWebhookEvent(ctx context.Context, param *models.WebhookEvent) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookEvent", ctx, param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveWebhookEvent indicates an expected call of SaveWebhookEvent.
+func (mr *MockPaymentServiceMockRecorder) SaveWebhookEvent(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookEvent", reflect.TypeOf((*MockPaymentService)(nil).SaveWebhookEvent), ctx, param)
+}
+
+// CloseFailedEvent mocks base method.
+func (m *MockPaymentService) CloseFailedEvent(ctx context.Context, id, requestedBy int64, reason string) (*models.FailedEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseFailedEvent", ctx, id, requestedBy, reason)
+	ret0, _ := ret[0].(*models.FailedEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseFailedEvent indicates an expected call of CloseFailedEvent.
+func (mr *MockPaymentServiceMockRecorder) CloseFailedEvent(ctx, id, requestedBy, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseFailedEvent", reflect.TypeOf((*MockPaymentService)(nil).CloseFailedEvent), ctx, id, requestedBy, reason)
+}
+
+// ListFailedEvents mocks base method.
+func (m *MockPaymentService) ListFailedEvents(ctx context.Context, filter models.FailedEventFilter) (models.FailedEventPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFailedEvents", ctx, filter)
+	ret0, _ := ret[0].(models.FailedEventPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFailedEvents indicates an expected call of ListFailedEvents.
+func (mr *MockPaymentServiceMockRecorder) ListFailedEvents(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFailedEvents", reflect.TypeOf((*MockPaymentService)(nil).ListFailedEvents), ctx, filter)
+}
+
+// ReprocessFailedEvent mocks base method.
+func (m *MockPaymentService) ReprocessFailedEvent(ctx context.Context, event *models.FailedEvent, actor string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReprocessFailedEvent", ctx, event, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReprocessFailedEvent indicates an expected call of ReprocessFailedEvent.
+func (mr *MockPaymentServiceMockRecorder) ReprocessFailedEvent(ctx, event, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReprocessFailedEvent", reflect.TypeOf((*MockPaymentService)(nil).ReprocessFailedEvent), ctx, event, actor)
+}
+
+// RetryFailedEvent mocks base method.
+func (m *MockPaymentService) RetryFailedEvent(ctx context.Context, id, requestedBy int64) (*models.FailedEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryFailedEvent", ctx, id, requestedBy)
+	ret0, _ := ret[0].(*models.FailedEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryFailedEvent indicates an expected call of RetryFailedEvent.
+func (mr *MockPaymentServiceMockRecorder) RetryFailedEvent(ctx, id, requestedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryFailedEvent", reflect.TypeOf((*MockPaymentService)(nil).RetryFailedEvent), ctx, id, requestedBy)
+}
+
+// GetUserPayment mocks base method.
+func (m *MockPaymentService) GetUserPayment(ctx context.Context, userID, orderID int64) (*models.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPayment", ctx, userID, orderID)
+	ret0, _ := ret[0].(*models.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserPayment indicates an expected call of GetUserPayment.
+func (mr *MockPaymentServiceMockRecorder) GetUserPayment(ctx, userID, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPayment", reflect.TypeOf((*MockPaymentService)(nil).GetUserPayment), ctx, userID, orderID)
+}
+
+// ListUserPayments mocks base method.
+func (m *MockPaymentService) ListUserPayments(ctx context.Context, filter models.PaymentFilter) (models.PaymentPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserPayments", ctx, filter)
+	ret0, _ := ret[0].(models.PaymentPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserPayments indicates an expected call of ListUserPayments.
+func (mr *MockPaymentServiceMockRecorder) ListUserPayments(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserPayments", reflect.TypeOf((*MockPaymentService)(nil).ListUserPayments), ctx, filter)
+}
@@ -5,6 +5,7 @@ package service
 
 import (
 	context "context"
+	http "net/http"
 	models "paymentfc/models"
 	reflect "reflect"
 
@@ -35,25 +36,25 @@ func (m *MockXenditService) EXPECT() *MockXenditServiceMockRecorder {
 }
 
 // CheckInvoiceStatus mocks base method.
-func (m *MockXenditService) CheckInvoiceStatus(ctx context.Context, externalID string) (string, error) {
+func (m *MockXenditService) CheckInvoiceStatus(ctx context.Context, provider, externalID string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckInvoiceStatus", ctx, externalID)
+	ret := m.ctrl.Call(m, "CheckInvoiceStatus", ctx, provider, externalID)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CheckInvoiceStatus indicates an expected call of CheckInvoiceStatus.
-func (mr *MockXenditServiceMockRecorder) CheckInvoiceStatus(ctx, externalID interface{}) *gomock.Call {
+func (mr *MockXenditServiceMockRecorder) CheckInvoiceStatus(ctx, provider, externalID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckInvoiceStatus", reflect.TypeOf((*MockXenditService)(nil).CheckInvoiceStatus), ctx, externalID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckInvoiceStatus", reflect.TypeOf((*MockXenditService)(nil).CheckInvoiceStatus), ctx, provider, externalID)
 }
 
 // CreateInvoice mocks base method.
-func (m *MockXenditService) CreateInvoice(ctx context.Context, param models.OrderCreatedEvent) (*models.XenditInvoiceResponse, error) {
+func (m *MockXenditService) CreateInvoice(ctx context.Context, param models.OrderCreatedEvent) (*models.CheckoutResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateInvoice", ctx, param)
-	ret0, _ := ret[0].(*models.XenditInvoiceResponse)
+	ret0, _ := ret[0].(*models.CheckoutResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -65,10 +66,10 @@ func (mr *MockXenditServiceMockRecorder) CreateInvoice(ctx, param interface{}) *
 }
 
 // CreateInvoiceFromPaymentRequest mocks base method.
-func (m *MockXenditService) CreateInvoiceFromPaymentRequest(ctx context.Context, pr *models.PaymentRequest) (*models.XenditInvoiceResponse, error) {
+func (m *MockXenditService) CreateInvoiceFromPaymentRequest(ctx context.Context, pr *models.PaymentRequest) (*models.CheckoutResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateInvoiceFromPaymentRequest", ctx, pr)
-	ret0, _ := ret[0].(*models.XenditInvoiceResponse)
+	ret0, _ := ret[0].(*models.CheckoutResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -78,3 +79,33 @@ func (mr *MockXenditServiceMockRecorder) CreateInvoiceFromPaymentRequest(ctx, pr
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvoiceFromPaymentRequest", reflect.TypeOf((*MockXenditService)(nil).CreateInvoiceFromPaymentRequest), ctx, pr)
 }
+
+// ParseWebhook mocks base method.
+func (m *MockXenditService) ParseWebhook(ctx context.Context, provider string, header http.Header, body []byte) (*models.PaymentWebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseWebhook", ctx, provider, header, body)
+	ret0, _ := ret[0].(*models.PaymentWebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseWebhook indicates an expected call of ParseWebhook.
+func (mr *MockXenditServiceMockRecorder) ParseWebhook(ctx, provider, header, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseWebhook", reflect.TypeOf((*MockXenditService)(nil).ParseWebhook), ctx, provider, header, body)
+}
+
+// CreateInvoiceForUser mocks base method.
+func (m *MockXenditService) CreateInvoiceForUser(ctx context.Context, param models.OrderCreatedEvent, userID int64) (*models.CheckoutResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvoiceForUser", ctx, param, userID)
+	ret0, _ := ret[0].(*models.CheckoutResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvoiceForUser indicates an expected call of CreateInvoiceForUser.
+func (mr *MockXenditServiceMockRecorder) CreateInvoiceForUser(ctx, param, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvoiceForUser", reflect.TypeOf((*MockXenditService)(nil).CreateInvoiceForUser), ctx, param, userID)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"paymentfc/constant"
+	bizmetrics "paymentfc/infrastructure/metrics"
+	"paymentfc/log"
+	"paymentfc/models"
+	"strings"
+	"time"
+)
+
+const (
+	outboxRetryBaseDelay = 1 * time.Second
+	outboxRetryMaxDelay  = 5 * time.Minute
+)
+
+// outboxFailedTypes outbox 이벤트 타입 -> failed_events.failed_type
+var outboxFailedTypes = map[string]int{
+	constant.KafkaTopicPaymentSuccess:       constant.FailedPublishEventPaymentSuccess,
+	constant.KafkaTopicPaymentRefunded:      constant.FailedPublishEventPaymentRefunded,
+	constant.KafkaTopicPaymentFailed:        constant.FailedPublishEventPaymentFailed,
+	constant.KafkaTopicPaymentExpired:       constant.FailedPublishEventPaymentExpired,
+	constant.KafkaTopicPaymentRequestFailed: constant.FailedPublishEventPaymentRequestFailed,
+}
+
+// RelayOutboxEvents outbox에 쌓인 메시지를 BatchSize건씩 Kafka로 발행하고 SENT로 바꾼다. 실패하면 지수 백오프로 다시 시도한다.
+// 짧은 배치가 나올 때까지 이어서 잡고, timeout이 지나면 남은 건은 다음 실행으로 넘긴다.
+func (s *SchedulerService) RelayOutboxEvents(ctx context.Context, spec JobSpec) (models.JobResult, error) {
+	var result models.JobResult
+	defer s.observeOutboxStats(context.WithoutCancel(ctx))
+	for ctx.Err() == nil {
+		events, err := s.Database.ClaimOutboxEvents(ctx, spec.BatchSize)
+		if err != nil {
+			log.Logger.Error().Err(err).Msg("Failed to claim outbox events")
+			return result, err
+		}
+		for i := range events {
+			if s.relayOutboxEvent(ctx, &events[i]) {
+				result.Processed++
+			} else {
+				result.Failed++
+			}
+		}
+		if len(events) < spec.BatchSize {
+			break
+		}
+	}
+	return result, nil
+}
+
+// relayOutboxEvent 발행에 성공하면 true. 실패한 건은 재시도 예약 또는 DEAD로 넘긴다.
+func (s *SchedulerService) relayOutboxEvent(ctx context.Context, event *models.OutboxEvent) bool {
+	publishErr := s.Publisher.PublishOutboxEvent(ctx, *event)
+	if publishErr == nil {
+		bizmetrics.OutboxPublished.WithLabelValues(event.EventType, "sent").Inc()
+		if err := s.Database.MarkOutboxEventSent(ctx, event.ID); err != nil {
+			// 발행은 됐으므로 PROCESSING 타임아웃 후 한 번 더 나갈 수 있다 (컨슈머는 order_id 기준으로 멱등 처리).
+			return true
+		}
+		if err := s.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+			OrderID: event.OrderID,
+			Event:   "PUBLISH_" + strings.ToUpper(strings.ReplaceAll(event.EventType, ".", "_")),
+			Actor:   "outbox_relay",
+			Metadata: map[string]any{
+				"outbox_id": event.ID,
+				"attempts":  event.Attempts,
+			},
+		}); err != nil {
+			log.Logger.Error().Err(err).Int64("order_id", event.OrderID).Msg("Failed to save audit log")
+		}
+		return true
+	}
+
+	if event.Attempts < constant.MaxOutboxAttempts {
+		bizmetrics.OutboxPublished.WithLabelValues(event.EventType, "retry").Inc()
+		delay := min(outboxRetryBaseDelay<<min(event.Attempts-1, 20), outboxRetryMaxDelay)
+		log.Logger.Warn().Err(publishErr).Int64("outbox_id", event.ID).Int("attempts", event.Attempts).Str("wait", delay.String()).Msg("Kafka publish failed, retrying")
+		_ = s.Database.MarkOutboxEventFailed(ctx, event.ID, constant.OutboxStatusPending, publishErr.Error(), time.Now().Add(delay))
+		return false
+	}
+
+	// 재시도 한도 초과: DEAD로 닫고 failed_events에 남겨 수동 확인/재처리 대상으로 넘긴다.
+	bizmetrics.OutboxPublished.WithLabelValues(event.EventType, "dead").Inc()
+	log.Logger.Error().Err(publishErr).Int64("outbox_id", event.ID).Int64("order_id", event.OrderID).Msg("Outbox event exhausted retries")
+	if err := s.Database.MarkOutboxEventFailed(ctx, event.ID, constant.OutboxStatusDead, publishErr.Error(), time.Now()); err != nil {
+		return false
+	}
+	if err := s.PaymentService.SaveFailedPublishEvent(ctx, &models.FailedEvent{
+		OrderID:    event.OrderID,
+		ExternalID: fmt.Sprintf("order-%d", event.OrderID),
+		FailedType: outboxFailedTypes[event.EventType],
+		OutboxID:   event.ID,
+		Notes:      publishErr.Error(),
+		Status:     constant.FailedPublishEventStatusNeedToCheck,
+		UpdateTime: time.Now(),
+	}); err != nil {
+		log.Logger.Error().Err(err).Int64("order_id", event.OrderID).Msg("Failed to save failed_event")
+	}
+	return false
+}
+
+func (s *SchedulerService) observeOutboxStats(ctx context.Context) {
+	stats, err := s.Database.GetOutboxStats(ctx)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get outbox stats")
+		return
+	}
+	bizmetrics.OutboxBacklog.Set(float64(stats.Backlog))
+	lag := 0.0
+	if stats.OldestCreate != nil {
+		lag = time.Since(*stats.OldestCreate).Seconds()
+	}
+	bizmetrics.OutboxLagSeconds.Set(lag)
+}
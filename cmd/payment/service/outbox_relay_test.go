@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"paymentfc/constant"
+	"paymentfc/mocks"
+	"paymentfc/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSchedulerService_RelayOutboxEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockPublisher := mocks.NewMockPaymentEventPublisher(ctrl)
+	mockPaymentService := NewMockPaymentService(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	s := createTestSchedulerService(ctrl, mockDB, mocks.NewMockXenditClient(ctrl), mockPublisher, mockPaymentService, mockAuditLog, mocks.NewMockUserClientInterface(ctrl))
+	ctx := context.Background()
+
+	newEvent := func(attempts int) *models.OutboxEvent {
+		return &models.OutboxEvent{
+			ID:         1,
+			OrderID:    100,
+			EventType:  constant.KafkaTopicPaymentSuccess,
+			MessageKey: "order-100",
+			Payload:    `{"order_id":100,"status":"PAID","topic":"payment.success"}`,
+			Status:     constant.OutboxStatusProcessing,
+			Attempts:   attempts,
+		}
+	}
+
+	t.Run("marks sent and audits on publish success", func(t *testing.T) {
+		event := newEvent(1)
+		mockPublisher.EXPECT().PublishOutboxEvent(ctx, *event).Return(nil)
+		mockDB.EXPECT().MarkOutboxEventSent(ctx, int64(1)).Return(nil)
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, log *models.PaymentAuditLog) error {
+			assert.Equal(t, "PUBLISH_PAYMENT_SUCCESS", log.Event)
+			assert.Equal(t, "outbox_relay", log.Actor)
+			return nil
+		})
+
+		assert.True(t, s.relayOutboxEvent(ctx, event))
+	})
+
+	t.Run("reschedules with backoff on publish failure", func(t *testing.T) {
+		event := newEvent(3)
+		mockPublisher.EXPECT().PublishOutboxEvent(ctx, *event).Return(errors.New("kafka unavailable"))
+		mockDB.EXPECT().MarkOutboxEventFailed(ctx, int64(1), constant.OutboxStatusPending, "kafka unavailable", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ int64, _, _ string, next time.Time) error {
+				// attempts=3 -> 1s * 2^2
+				assert.WithinDuration(t, time.Now().Add(4*time.Second), next, time.Second)
+				return nil
+			})
+
+		assert.False(t, s.relayOutboxEvent(ctx, event))
+	})
+
+	t.Run("moves exhausted event to failed_events", func(t *testing.T) {
+		event := newEvent(constant.MaxOutboxAttempts)
+		mockPublisher.EXPECT().PublishOutboxEvent(ctx, *event).Return(errors.New("kafka unavailable"))
+		mockDB.EXPECT().MarkOutboxEventFailed(ctx, int64(1), constant.OutboxStatusDead, "kafka unavailable", gomock.Any()).Return(nil)
+		mockPaymentService.EXPECT().SaveFailedPublishEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, failed *models.FailedEvent) error {
+			assert.Equal(t, int64(100), failed.OrderID)
+			assert.Equal(t, constant.FailedPublishEventPaymentSuccess, failed.FailedType)
+			assert.Equal(t, constant.FailedPublishEventStatusNeedToCheck, failed.Status)
+			return nil
+		})
+
+		assert.False(t, s.relayOutboxEvent(ctx, event))
+	})
+}
+
+func TestSchedulerService_RelayOutboxEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockPublisher := mocks.NewMockPaymentEventPublisher(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	s := createTestSchedulerService(ctrl, mockDB, mocks.NewMockXenditClient(ctrl), mockPublisher, NewMockPaymentService(ctrl), mockAuditLog, mocks.NewMockUserClientInterface(ctrl))
+	ctx := context.Background()
+
+	event := func(id int64) models.OutboxEvent {
+		return models.OutboxEvent{ID: id, OrderID: id, EventType: constant.KafkaTopicPaymentSuccess, Attempts: 1}
+	}
+	// 꽉 찬 배치 뒤에는 이어서 잡고, 짧은 배치가 나오면 멈춘다.
+	gomock.InOrder(
+		mockDB.EXPECT().ClaimOutboxEvents(ctx, 2).Return([]models.OutboxEvent{event(1), event(2)}, nil),
+		mockDB.EXPECT().ClaimOutboxEvents(ctx, 2).Return([]models.OutboxEvent{event(3)}, nil),
+	)
+	mockPublisher.EXPECT().PublishOutboxEvent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, e models.OutboxEvent) error {
+		if e.ID == 2 {
+			return errors.New("kafka unavailable")
+		}
+		return nil
+	}).Times(3)
+	mockDB.EXPECT().MarkOutboxEventSent(ctx, gomock.Any()).Return(nil).Times(2)
+	mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil).Times(2)
+	mockDB.EXPECT().MarkOutboxEventFailed(ctx, int64(2), constant.OutboxStatusPending, "kafka unavailable", gomock.Any()).Return(nil)
+	mockDB.EXPECT().GetOutboxStats(gomock.Any()).Return(models.OutboxStats{}, nil)
+
+	result, err := s.RelayOutboxEvents(ctx, JobSpec{Name: constant.JobRelayOutboxEvents, BatchSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobResult{Processed: 2, Failed: 1}, result)
+}
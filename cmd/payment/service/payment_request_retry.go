@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/constant"
+	bizmetrics "paymentfc/infrastructure/metrics"
+	"paymentfc/log"
+	"paymentfc/models"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	paymentRequestRetryBaseDelay = 1 * time.Minute
+	paymentRequestRetryMaxDelay  = 30 * time.Minute
+)
+
+// paymentRequestRetryDelay retryCount번째 실패 뒤 기다릴 시간. 지수 백오프에 절반 폭의 jitter를 더해
+// 게이트웨이 장애가 끝났을 때 쌓인 요청이 한꺼번에 몰리지 않게 한다.
+func paymentRequestRetryDelay(retryCount int) time.Duration {
+	delay := min(paymentRequestRetryBaseDelay<<min(max(retryCount-1, 0), 20), paymentRequestRetryMaxDelay)
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// isRetryablePaymentRequestError 다시 시도하면 성공할 수 있는 에러인지.
+// 게이트웨이 5xx/429, user 서비스 gRPC 일시 장애, 네트워크/타임아웃은 재시도하고 4xx, 잘못된 요청, 없는 사용자는 바로 DEAD로 보낸다.
+func isRetryablePaymentRequestError(err error) bool {
+	var statusErr *repository.GatewayStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	if errors.Is(err, repository.ErrUnknownGateway) {
+		return false
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		switch s.Code() {
+		case codes.InvalidArgument, codes.NotFound, codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange, codes.Unimplemented:
+			return false
+		}
+		return true
+	}
+	// 네트워크 오류, 타임아웃, 분류할 수 없는 에러는 재시도하되 재시도 한도에서 멈춘다.
+	return true
+}
+
+// failPaymentRequest 처리 실패를 남긴다. 재시도할 수 있으면 백오프 뒤 재시도 잡이 다시 잡고, 아니면 바로 DEAD로 보낸다.
+func (s *SchedulerService) failPaymentRequest(ctx context.Context, pr models.PaymentRequest, notes string, cause error) {
+	if cause != nil && !isRetryablePaymentRequestError(cause) {
+		s.deadLetterPaymentRequest(ctx, pr, notes)
+		return
+	}
+	nextAttemptAt := time.Now().Add(paymentRequestRetryDelay(pr.RetryCount + 1))
+	if err := s.Database.UpdateFailedPaymentRequest(ctx, pr.ID, notes, nextAttemptAt); err != nil {
+		log.Logger.Error().Err(err).Int64("payment_request_id", pr.ID).Msg("Failed to update payment_request as failed")
+		return
+	}
+	bizmetrics.PaymentRequestFailures.WithLabelValues("retry").Inc()
+}
+
+// deadLetterPaymentRequest 결제 요청을 DEAD로 닫고 payment.request_failed를 outbox로 발행해 주문/재고 예약을 풀게 한다.
+func (s *SchedulerService) deadLetterPaymentRequest(ctx context.Context, pr models.PaymentRequest, reason string) {
+	event, err := repository.NewPaymentRequestFailedOutboxEvent(models.PaymentRequestFailedEvent{
+		OrderID:          pr.OrderID,
+		UserID:           pr.UserID,
+		PaymentRequestID: pr.ID,
+		Amount:           pr.Amount,
+		RetryCount:       pr.RetryCount,
+		Reason:           reason,
+		Status:           constant.PaymentRequestStatusDead,
+	})
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("payment_request_id", pr.ID).Msg("Failed to build payment request failed event")
+		return
+	}
+	if err := s.Database.DeadLetterPaymentRequest(ctx, pr.ID, reason, event); err != nil {
+		return
+	}
+	bizmetrics.PaymentRequestFailures.WithLabelValues("dead").Inc()
+	log.Logger.Warn().Int64("order_id", pr.OrderID).Int64("payment_request_id", pr.ID).Int("retry_count", pr.RetryCount).Str("reason", reason).Msg("Payment request moved to DEAD")
+	if err := s.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+		OrderID: pr.OrderID,
+		UserID:  pr.UserID,
+		Event:   "PAYMENT_REQUEST_DEAD",
+		Actor:   "payment_request_processor",
+		Metadata: map[string]any{
+			"payment_request_id": pr.ID,
+			"retry_count":        pr.RetryCount,
+			"reason":             reason,
+		},
+	}); err != nil {
+		log.Logger.Error().Err(err).Int64("order_id", pr.OrderID).Msg("Failed to save audit log")
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"paymentfc/cmd/payment/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPaymentRequestRetryDelay(t *testing.T) {
+	for retryCount, want := range map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 3: 4 * time.Minute, 10: paymentRequestRetryMaxDelay} {
+		for range 20 {
+			got := paymentRequestRetryDelay(retryCount)
+			assert.GreaterOrEqual(t, got, want/2)
+			assert.LessOrEqual(t, got, want)
+		}
+	}
+}
+
+func TestIsRetryablePaymentRequestError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"xendit 5xx", &repository.GatewayStatusError{API: "xendit API", StatusCode: 503}, true},
+		{"rate limited", &repository.GatewayStatusError{API: "xendit API", StatusCode: 429}, true},
+		{"xendit 4xx", &repository.GatewayStatusError{API: "xendit API", StatusCode: 400}, false},
+		{"wrapped 4xx", fmt.Errorf("create checkout: %w", &repository.GatewayStatusError{API: "midtrans snap API", StatusCode: 401}), false},
+		{"unknown gateway", fmt.Errorf("%w: paypal", repository.ErrUnknownGateway), false},
+		{"grpc unavailable", status.Error(codes.Unavailable, "connection refused"), true},
+		{"grpc deadline", status.Error(codes.DeadlineExceeded, "timeout"), true},
+		{"grpc user not found", status.Error(codes.NotFound, "user not found"), false},
+		{"grpc invalid argument", status.Error(codes.InvalidArgument, "bad user id"), false},
+		{"timeout", context.DeadlineExceeded, true},
+		{"unclassified", errors.New("connection reset by peer"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetryablePaymentRequestError(tt.err))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"paymentfc/cmd/payment/repository"
 	"paymentfc/constant"
 	"paymentfc/mocks"
 	"paymentfc/models"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
 )
 
 func TestPaymentService_ProcessPaymentSuccess(t *testing.T) {
@@ -34,38 +36,98 @@ func TestPaymentService_ProcessPaymentSuccess(t *testing.T) {
 
 	t.Run("success flow", func(t *testing.T) {
 		mockDB.EXPECT().IsAlreadyPaid(ctx, orderID).Return(false, nil)
-		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
-		mockPublisher.EXPECT().PublishPaymentStatus(ctx, orderID, constant.PaymentStatusPaid, "payment.success").Return(nil)
-		mockDB.EXPECT().MarkPaid(orderID).Return(nil)
+		mockDB.EXPECT().TransitionPaymentStatus(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, param models.PaymentStatusTransition) error {
+			assert.Equal(t, orderID, param.OrderID)
+			assert.Equal(t, constant.PaymentStatusPaid, param.ToStatus)
+			// payment.success는 직접 발행하지 않고 전이와 같은 트랜잭션의 outbox로 넘긴다.
+			if assert.Len(t, param.Outbox, 1) {
+				assert.Equal(t, constant.KafkaTopicPaymentSuccess, param.Outbox[0].EventType)
+				assert.Equal(t, "order-12345", param.Outbox[0].MessageKey)
+				assert.JSONEq(t, `{"order_id":12345,"status":"PAID","topic":"payment.success"}`, param.Outbox[0].Payload)
+			}
+			return nil
+		})
 		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
 
 		err := svc.ProcessPaymentSuccess(ctx, orderID)
 		assert.NoError(t, err)
 	})
 
+	t.Run("illegal transition does not publish", func(t *testing.T) {
+		illegal := &repository.IllegalTransitionError{PaymentID: 1, From: constant.PaymentStatusExpired, To: constant.PaymentStatusPaid}
+		mockDB.EXPECT().IsAlreadyPaid(ctx, orderID).Return(false, nil)
+		mockDB.EXPECT().TransitionPaymentStatus(ctx, gomock.Any()).Return(illegal)
+
+		err := svc.ProcessPaymentSuccess(ctx, orderID)
+		assert.True(t, repository.IsIllegalTransition(err))
+	})
+
 	t.Run("db error on IsAlreadyPaid", func(t *testing.T) {
 		mockDB.EXPECT().IsAlreadyPaid(ctx, orderID).Return(false, errors.New("db error"))
 
 		err := svc.ProcessPaymentSuccess(ctx, orderID)
 		assert.Error(t, err)
 	})
+}
 
-	t.Run("publish success failure records failed event without publishing payment failed", func(t *testing.T) {
-		publishErr := errors.New("kafka unavailable")
+func TestPaymentService_ProcessPaymentFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-		mockDB.EXPECT().IsAlreadyPaid(ctx, orderID).Return(false, nil)
-		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil).Times(constant.MaxRetryPublish)
-		mockPublisher.EXPECT().
-			PublishPaymentStatus(ctx, orderID, constant.PaymentStatusPaid, "payment.success").
-			Return(publishErr).
-			Times(constant.MaxRetryPublish)
-		mockDB.EXPECT().SaveFailedPublishEvent(ctx, gomock.Any()).Return(nil)
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockPublisher := mocks.NewMockPaymentEventPublisher(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	mockXenditService := NewMockXenditService(ctrl)
 
-		err := svc.ProcessPaymentSuccess(ctx, orderID)
-		assert.ErrorIs(t, err, publishErr)
+	svc := NewPaymentService(mockDB, mockPublisher, mockXenditService, mockAuditLog)
+	ctx := context.Background()
+	orderID := int64(12345)
+
+	t.Run("already failed - should skip", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(&models.Payment{ID: 1, Status: constant.PaymentStatusFailed}, nil)
+
+		err := svc.ProcessPaymentFailed(ctx, orderID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("paid payment cannot become failed", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(&models.Payment{ID: 1, Status: constant.PaymentStatusPaid}, nil)
+		mockDB.EXPECT().TransitionPaymentStatus(ctx, gomock.Any()).Return(&repository.IllegalTransitionError{
+			PaymentID: 1, From: constant.PaymentStatusPaid, To: constant.PaymentStatusFailed,
+		})
+
+		err := svc.ProcessPaymentFailed(ctx, orderID)
+		assert.True(t, repository.IsIllegalTransition(err))
+	})
+
+	t.Run("pending payment is marked failed with outbox event", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(&models.Payment{ID: 1, Status: constant.PaymentStatusPending}, nil)
+		mockDB.EXPECT().TransitionPaymentStatus(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, param models.PaymentStatusTransition) error {
+			if assert.Len(t, param.Outbox, 1) {
+				assert.Equal(t, constant.KafkaTopicPaymentFailed, param.Outbox[0].EventType)
+			}
+			return nil
+		})
+
+		err := svc.ProcessPaymentFailed(ctx, orderID)
+		assert.NoError(t, err)
 	})
 }
 
+func TestCanTransitionPaymentStatus(t *testing.T) {
+	assert.True(t, repository.CanTransitionPaymentStatus(constant.PaymentStatusPending, constant.PaymentStatusPaid))
+	assert.True(t, repository.CanTransitionPaymentStatus(constant.PaymentStatusPending, constant.PaymentStatusExpired))
+	assert.True(t, repository.CanTransitionPaymentStatus(constant.PaymentStatusPaid, constant.PaymentStatusRefunded))
+	assert.False(t, repository.CanTransitionPaymentStatus(constant.PaymentStatusExpired, constant.PaymentStatusPaid))
+	assert.False(t, repository.CanTransitionPaymentStatus(constant.PaymentStatusPaid, constant.PaymentStatusFailed))
+	assert.False(t, repository.CanTransitionPaymentStatus(constant.PaymentStatusRefunded, constant.PaymentStatusPaid))
+}
+
+func TestFormatInvoiceNumber(t *testing.T) {
+	assert.Equal(t, "INV/2026/10/000123", repository.FormatInvoiceNumber("2026/10", 123))
+	assert.Equal(t, "INV/2026/01/1234567", repository.FormatInvoiceNumber("2026/01", 1234567))
+}
+
 func TestPaymentService_IsAlreadyPaid(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -110,7 +172,7 @@ func TestPaymentService_GetAmountByOrderID(t *testing.T) {
 	orderID := int64(12345)
 
 	t.Run("returns amount successfully", func(t *testing.T) {
-		expectedAmount := 100000.0
+		expectedAmount := models.NewMoney(100000, "IDR")
 		mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(&models.Payment{
 			OrderID: orderID,
 			Amount:  expectedAmount,
@@ -129,6 +191,67 @@ func TestPaymentService_GetAmountByOrderID(t *testing.T) {
 	})
 }
 
+func TestPaymentService_ListUserPayments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockPublisher := mocks.NewMockPaymentEventPublisher(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	mockXenditService := NewMockXenditService(ctrl)
+
+	svc := NewPaymentService(mockDB, mockPublisher, mockXenditService, mockAuditLog)
+	ctx := context.Background()
+
+	t.Run("sets next cursor when page is full", func(t *testing.T) {
+		filter := models.PaymentFilter{UserID: 100, Status: constant.PaymentStatusPending, Limit: 2}
+		mockDB.EXPECT().ListUserPayments(ctx, filter).Return([]models.Payment{{ID: 9, UserID: 100}, {ID: 7, UserID: 100}}, nil)
+
+		page, err := svc.ListUserPayments(ctx, filter)
+		assert.NoError(t, err)
+		assert.Len(t, page.Payments, 2)
+		assert.Equal(t, int64(7), page.NextCursor)
+	})
+
+	t.Run("omits next cursor on last page", func(t *testing.T) {
+		filter := models.PaymentFilter{UserID: 100, Limit: 20}
+		mockDB.EXPECT().ListUserPayments(ctx, filter).Return([]models.Payment{{ID: 3, UserID: 100}}, nil)
+
+		page, err := svc.ListUserPayments(ctx, filter)
+		assert.NoError(t, err)
+		assert.Zero(t, page.NextCursor)
+	})
+}
+
+func TestPaymentService_GetUserPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockPublisher := mocks.NewMockPaymentEventPublisher(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	mockXenditService := NewMockXenditService(ctrl)
+
+	svc := NewPaymentService(mockDB, mockPublisher, mockXenditService, mockAuditLog)
+	ctx := context.Background()
+
+	t.Run("returns caller's payment", func(t *testing.T) {
+		expected := &models.Payment{OrderID: 12345, UserID: 100, InvoiceURL: "https://xendit.co/invoice/inv-12345"}
+		mockDB.EXPECT().GetUserPaymentByOrderID(ctx, int64(100), int64(12345)).Return(expected, nil)
+
+		payment, err := svc.GetUserPayment(ctx, 100, 12345)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, payment)
+	})
+
+	t.Run("hides other user's payment as not found", func(t *testing.T) {
+		mockDB.EXPECT().GetUserPaymentByOrderID(ctx, int64(200), int64(12345)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.GetUserPayment(ctx, 200, 12345)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
 func TestPaymentService_SavePaymentAnomaly(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -172,7 +295,7 @@ func TestPaymentService_SavePaymentRequestFromEvent(t *testing.T) {
 	event := models.OrderCreatedEvent{
 		OrderID:     12345,
 		UserID:      100,
-		TotalAmount: 50000,
+		TotalAmount: "50000",
 	}
 
 	t.Run("saves payment request from event", func(t *testing.T) {
@@ -183,6 +306,23 @@ func TestPaymentService_SavePaymentRequestFromEvent(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("stores exact minor-unit amount", func(t *testing.T) {
+		usdEvent := models.OrderCreatedEvent{OrderID: 12346, UserID: 100, TotalAmount: "19.99", Currency: "USD"}
+		mockDB.EXPECT().SavePaymentRequest(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, pr *models.PaymentRequest) error {
+			assert.Equal(t, models.NewMoney(1999, "USD"), pr.Amount)
+			return nil
+		})
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
+
+		err := svc.SavePaymentRequestFromEvent(ctx, usdEvent)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects amount with more decimals than currency allows", func(t *testing.T) {
+		err := svc.SavePaymentRequestFromEvent(ctx, models.OrderCreatedEvent{OrderID: 12347, TotalAmount: "1000.5"})
+		assert.Error(t, err)
+	})
+
 	t.Run("returns error when save fails", func(t *testing.T) {
 		mockDB.EXPECT().SavePaymentRequest(ctx, gomock.Any()).Return(errors.New("db error"))
 
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/constant"
+	bizmetrics "paymentfc/infrastructure/metrics"
+	"paymentfc/log"
+	"paymentfc/models"
+	"time"
+)
+
+var ErrInvalidReconciliationWindow = errors.New("invalid reconciliation window")
+
+const (
+	// reconciliationPageSize Xendit 인보이스 목록 API 한 페이지 크기 (최대 100)
+	reconciliationPageSize = 100
+	// maxReconciliationWindow admin 수동 대사 한 번에 허용하는 최대 기간
+	maxReconciliationWindow = 31 * 24 * time.Hour
+)
+
+type ReconciliationService interface {
+	TriggerReconciliation(ctx context.Context, windowStart, windowEnd time.Time, autoFix bool, requestedBy int64) (*models.ReconciliationRun, error)
+	RunScheduledReconciliation(ctx context.Context, windowStart, windowEnd time.Time, autoFix bool) (*models.ReconciliationRun, error)
+	ListRuns(ctx context.Context, filter models.ReconciliationRunFilter) (models.ReconciliationRunPage, error)
+	GetRun(ctx context.Context, id int64) (*models.ReconciliationRun, error)
+	ListItems(ctx context.Context, filter models.ReconciliationItemFilter) (models.ReconciliationItemPage, error)
+}
+
+type reconciliationService struct {
+	database       repository.PaymentDatabase
+	xendit         repository.XenditClient
+	paymentService PaymentService
+	auditLog       repository.AuditLogRepository
+}
+
+func NewReconciliationService(db repository.PaymentDatabase, xendit repository.XenditClient, paymentService PaymentService, auditLog repository.AuditLogRepository) ReconciliationService {
+	return &reconciliationService{
+		database:       db,
+		xendit:         xendit,
+		paymentService: paymentService,
+		auditLog:       auditLog,
+	}
+}
+
+// TriggerReconciliation admin 요청으로 run을 만들고 백그라운드에서 대사한다. 진행 상황은 run 조회로 확인한다.
+func (s *reconciliationService) TriggerReconciliation(ctx context.Context, windowStart, windowEnd time.Time, autoFix bool, requestedBy int64) (*models.ReconciliationRun, error) {
+	if !windowEnd.After(windowStart) || windowEnd.Sub(windowStart) > maxReconciliationWindow {
+		return nil, fmt.Errorf("%w: window must be positive and at most %s", ErrInvalidReconciliationWindow, maxReconciliationWindow)
+	}
+	run := newReconciliationRun(windowStart, windowEnd, autoFix, constant.ReconciliationTriggerAdmin)
+	run.RequestedBy = requestedBy
+	if _, err := s.database.CreateReconciliationRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	started := *run
+	go func() {
+		if err := s.reconcile(context.Background(), run); err != nil {
+			log.Logger.Error().Err(err).Int64("reconciliation_run_id", run.ID).Msg("Reconciliation run failed")
+		}
+	}()
+	return &started, nil
+}
+
+// RunScheduledReconciliation 일일 잡. 같은 window의 스케줄 run이 이미 있으면(다른 인스턴스가 실행) nil을 돌려준다.
+func (s *reconciliationService) RunScheduledReconciliation(ctx context.Context, windowStart, windowEnd time.Time, autoFix bool) (*models.ReconciliationRun, error) {
+	run := newReconciliationRun(windowStart, windowEnd, autoFix, constant.ReconciliationTriggerScheduler)
+	key := "daily:" + windowStart.Format("2006-01-02")
+	run.ScheduleKey = &key
+	created, err := s.database.CreateReconciliationRun(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		log.Logger.Info().Str("schedule_key", key).Msg("Reconciliation already run for window, skipping")
+		return nil, nil
+	}
+	return run, s.reconcile(ctx, run)
+}
+
+func (s *reconciliationService) ListRuns(ctx context.Context, filter models.ReconciliationRunFilter) (models.ReconciliationRunPage, error) {
+	runs, err := s.database.ListReconciliationRuns(ctx, filter)
+	if err != nil {
+		return models.ReconciliationRunPage{}, err
+	}
+	page := models.ReconciliationRunPage{Runs: runs}
+	if filter.Limit > 0 && len(runs) == filter.Limit {
+		page.NextCursor = runs[len(runs)-1].ID
+	}
+	return page, nil
+}
+
+func (s *reconciliationService) GetRun(ctx context.Context, id int64) (*models.ReconciliationRun, error) {
+	return s.database.GetReconciliationRun(ctx, id)
+}
+
+func (s *reconciliationService) ListItems(ctx context.Context, filter models.ReconciliationItemFilter) (models.ReconciliationItemPage, error) {
+	if _, err := s.database.GetReconciliationRun(ctx, filter.RunID); err != nil {
+		return models.ReconciliationItemPage{}, err
+	}
+	items, err := s.database.ListReconciliationItems(ctx, filter)
+	if err != nil {
+		return models.ReconciliationItemPage{}, err
+	}
+	page := models.ReconciliationItemPage{Items: items}
+	if filter.Limit > 0 && len(items) == filter.Limit {
+		page.NextCursor = items[len(items)-1].ID
+	}
+	return page, nil
+}
+
+func newReconciliationRun(windowStart, windowEnd time.Time, autoFix bool, trigger string) *models.ReconciliationRun {
+	return &models.ReconciliationRun{
+		Provider:    constant.PaymentProviderXendit,
+		Trigger:     trigger,
+		WindowStart: windowStart,
+		WindowEnd:   windowEnd,
+		AutoFix:     autoFix,
+		Status:      constant.ReconciliationRunStatusRunning,
+		StartTime:   time.Now(),
+	}
+}
+
+// reconcile window에 생성된 Xendit 인보이스를 페이지 단위로 읽어 payments와 비교하고 불일치 건을 저장한다.
+func (s *reconciliationService) reconcile(ctx context.Context, run *models.ReconciliationRun) error {
+	req := models.XenditInvoiceListRequest{
+		CreatedAfter:  run.WindowStart,
+		CreatedBefore: run.WindowEnd,
+		Limit:         reconciliationPageSize,
+	}
+	for {
+		invoices, err := s.xendit.ListInvoices(ctx, req)
+		if err != nil {
+			return s.finishRun(ctx, run, err)
+		}
+		if err := s.reconcilePage(ctx, run, invoices); err != nil {
+			return s.finishRun(ctx, run, err)
+		}
+		if len(invoices) < reconciliationPageSize {
+			break
+		}
+		req.LastInvoiceID = invoices[len(invoices)-1].ID
+	}
+	return s.finishRun(ctx, run, nil)
+}
+
+func (s *reconciliationService) reconcilePage(ctx context.Context, run *models.ReconciliationRun, invoices []models.XenditInvoiceResponse) error {
+	externalIDs := make([]string, 0, len(invoices))
+	for _, inv := range invoices {
+		externalIDs = append(externalIDs, inv.ExternalID)
+	}
+	payments, err := s.database.GetPaymentsByExternalIDs(ctx, externalIDs)
+	if err != nil {
+		return err
+	}
+	byExternalID := make(map[string]*models.Payment, len(payments))
+	for i := range payments {
+		byExternalID[payments[i].ExternalID] = &payments[i]
+	}
+
+	items := make([]models.ReconciliationItem, 0)
+	for _, inv := range invoices {
+		run.InvoiceCount++
+		item := classifyInvoice(inv, byExternalID[inv.ExternalID])
+		if item == nil {
+			run.MatchedCount++
+			continue
+		}
+		item.RunID = run.ID
+		if run.AutoFix && item.FixStatus == constant.ReconciliationFixAvailable {
+			s.fixItem(ctx, run, item)
+		}
+		run.DiscrepancyCount++
+		if item.FixStatus == constant.ReconciliationFixFixed {
+			run.FixedCount++
+		}
+		bizmetrics.ReconciliationDiscrepancies.WithLabelValues(item.DiscrepancyType, item.FixStatus).Inc()
+		items = append(items, *item)
+	}
+	return s.database.SaveReconciliationItems(ctx, items)
+}
+
+// classifyInvoice 인보이스와 로컬 결제를 비교해 불일치 item을 만든다. 일치하면 nil.
+// 자동 보정은 Xendit PAID + 로컬 PENDING + 금액 일치인 경우만 안전하다고 본다.
+func classifyInvoice(inv models.XenditInvoiceResponse, payment *models.Payment) *models.ReconciliationItem {
+	remoteStatus := repository.NormalizeXenditStatus(inv.Status)
+	item := &models.ReconciliationItem{
+		ExternalID:     inv.ExternalID,
+		InvoiceID:      inv.ID,
+		ProviderStatus: remoteStatus,
+		FixStatus:      constant.ReconciliationFixManual,
+	}
+	currency := inv.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	amount, amountErr := models.ParseMoney(inv.Amount.String(), currency)
+	if amountErr == nil {
+		item.ProviderAmount = amount
+	}
+
+	if payment == nil {
+		item.DiscrepancyType = constant.ReconciliationMissingLocal
+		return item
+	}
+	item.OrderID = payment.OrderID
+	item.PaymentID = payment.ID
+	item.LocalStatus = payment.Status
+	item.LocalAmount = payment.Amount
+
+	remotePaid := remoteStatus == constant.PaymentStatusPaid
+	switch {
+	case amountErr != nil || !amount.Equal(payment.Amount):
+		item.DiscrepancyType = constant.ReconciliationAmountMismatch
+	case remotePaid && payment.Status == constant.PaymentStatusPending:
+		item.DiscrepancyType = constant.ReconciliationPaidPendingLocally
+		item.FixStatus = constant.ReconciliationFixAvailable
+	case remotePaid && (payment.Status == constant.PaymentStatusExpired || payment.Status == constant.PaymentStatusFailed):
+		item.DiscrepancyType = constant.ReconciliationPaidExpiredLocally
+	case !remotePaid && isPaidLocally(payment.Status):
+		item.DiscrepancyType = constant.ReconciliationNotPaidRemotely
+	default:
+		return nil
+	}
+	return item
+}
+
+func isPaidLocally(status string) bool {
+	return repository.IsPaidPaymentStatus(status)
+}
+
+func (s *reconciliationService) fixItem(ctx context.Context, run *models.ReconciliationRun, item *models.ReconciliationItem) {
+	if err := s.paymentService.ProcessPaymentSuccess(ctx, item.OrderID); err != nil {
+		log.Logger.Error().Err(err).Int64("order_id", item.OrderID).Int64("reconciliation_run_id", run.ID).Msg("Failed to auto-fix reconciliation item")
+		item.FixStatus = constant.ReconciliationFixFailed
+		item.FixError = err.Error()
+		return
+	}
+	item.FixStatus = constant.ReconciliationFixFixed
+	if err := s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+		OrderID:    item.OrderID,
+		PaymentID:  item.PaymentID,
+		ExternalID: item.ExternalID,
+		Event:      "RECONCILIATION_FIXED",
+		Actor:      "reconciliation",
+		Metadata: map[string]any{
+			"reconciliation_run_id": run.ID,
+			"invoice_id":            item.InvoiceID,
+			"discrepancy_type":      item.DiscrepancyType,
+			"local_status":          item.LocalStatus,
+		},
+	}); err != nil {
+		log.Logger.Error().Err(err).Int64("order_id", item.OrderID).Msg("Failed to save audit log")
+	}
+}
+
+// finishRun run 결과를 기록한다. runErr가 있으면 FAILED로 남기고 그대로 돌려준다.
+func (s *reconciliationService) finishRun(ctx context.Context, run *models.ReconciliationRun, runErr error) error {
+	now := time.Now()
+	run.FinishTime = &now
+	run.Status = constant.ReconciliationRunStatusCompleted
+	if runErr != nil {
+		run.Status = constant.ReconciliationRunStatusFailed
+		run.Error = runErr.Error()
+	}
+	if err := s.database.FinishReconciliationRun(ctx, run); err != nil && runErr == nil {
+		return err
+	}
+	log.Logger.Info().
+		Int64("reconciliation_run_id", run.ID).
+		Str("status", run.Status).
+		Int("invoices", run.InvoiceCount).
+		Int("discrepancies", run.DiscrepancyCount).
+		Int("fixed", run.FixedCount).
+		Msg("Reconciliation run finished")
+	return runErr
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"paymentfc/constant"
+	"paymentfc/mocks"
+	"paymentfc/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReconciliationService_Reconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockXendit := mocks.NewMockXenditClient(ctrl)
+	mockPaymentService := NewMockPaymentService(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	svc := NewReconciliationService(mockDB, mockXendit, mockPaymentService, mockAuditLog).(*reconciliationService)
+	ctx := context.Background()
+
+	windowStart := time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)
+	windowEnd := windowStart.AddDate(0, 0, 1)
+	invoices := []models.XenditInvoiceResponse{
+		{ID: "inv-1", ExternalID: "order-1", Status: "SETTLED", Amount: "150000", Currency: "IDR"},
+		{ID: "inv-2", ExternalID: "order-2", Status: "PAID", Amount: "150000", Currency: "IDR"},
+		{ID: "inv-3", ExternalID: "order-3", Status: "PAID", Amount: "150000", Currency: "IDR"},
+		{ID: "inv-4", ExternalID: "order-4", Status: "PAID", Amount: "175000", Currency: "IDR"},
+		{ID: "inv-5", ExternalID: "order-5", Status: "PENDING", Amount: "150000", Currency: "IDR"},
+		{ID: "inv-6", ExternalID: "order-6", Status: "EXPIRED", Amount: "150000", Currency: "IDR"},
+	}
+	payments := []models.Payment{
+		{ID: 1, OrderID: 1, ExternalID: "order-1", Status: constant.PaymentStatusPaid, Amount: models.NewMoney(150000, "IDR")},
+		{ID: 2, OrderID: 2, ExternalID: "order-2", Status: constant.PaymentStatusPending, Amount: models.NewMoney(150000, "IDR")},
+		{ID: 3, OrderID: 3, ExternalID: "order-3", Status: constant.PaymentStatusExpired, Amount: models.NewMoney(150000, "IDR")},
+		{ID: 4, OrderID: 4, ExternalID: "order-4", Status: constant.PaymentStatusPending, Amount: models.NewMoney(150000, "IDR")},
+		{ID: 6, OrderID: 6, ExternalID: "order-6", Status: constant.PaymentStatusPaid, Amount: models.NewMoney(150000, "IDR")},
+	}
+
+	expectPage := func() {
+		mockXendit.EXPECT().ListInvoices(ctx, models.XenditInvoiceListRequest{CreatedAfter: windowStart, CreatedBefore: windowEnd, Limit: reconciliationPageSize}).Return(invoices, nil)
+		mockDB.EXPECT().GetPaymentsByExternalIDs(ctx, []string{"order-1", "order-2", "order-3", "order-4", "order-5", "order-6"}).Return(payments, nil)
+	}
+
+	t.Run("classifies discrepancies and auto-fixes safe cases", func(t *testing.T) {
+		run := newReconciliationRun(windowStart, windowEnd, true, constant.ReconciliationTriggerScheduler)
+		run.ID = 10
+		expectPage()
+		mockPaymentService.EXPECT().ProcessPaymentSuccess(ctx, int64(2)).Return(nil)
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, log *models.PaymentAuditLog) error {
+			assert.Equal(t, "RECONCILIATION_FIXED", log.Event)
+			assert.Equal(t, "reconciliation", log.Actor)
+			assert.Equal(t, int64(2), log.OrderID)
+			return nil
+		})
+		mockDB.EXPECT().SaveReconciliationItems(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, items []models.ReconciliationItem) error {
+			byType := map[string]models.ReconciliationItem{}
+			for _, item := range items {
+				assert.Equal(t, int64(10), item.RunID)
+				byType[item.DiscrepancyType] = item
+			}
+			require.Len(t, items, 5)
+			assert.Equal(t, constant.ReconciliationFixFixed, byType[constant.ReconciliationPaidPendingLocally].FixStatus)
+			assert.Equal(t, constant.ReconciliationFixManual, byType[constant.ReconciliationPaidExpiredLocally].FixStatus)
+			assert.Equal(t, models.NewMoney(175000, "IDR"), byType[constant.ReconciliationAmountMismatch].ProviderAmount)
+			assert.Equal(t, models.NewMoney(150000, "IDR"), byType[constant.ReconciliationAmountMismatch].LocalAmount)
+			assert.Equal(t, "order-5", byType[constant.ReconciliationMissingLocal].ExternalID)
+			assert.Equal(t, constant.PaymentStatusExpired, byType[constant.ReconciliationNotPaidRemotely].ProviderStatus)
+			return nil
+		})
+		mockDB.EXPECT().FinishReconciliationRun(ctx, run).Return(nil)
+
+		err := svc.reconcile(ctx, run)
+		assert.NoError(t, err)
+		assert.Equal(t, constant.ReconciliationRunStatusCompleted, run.Status)
+		assert.Equal(t, 6, run.InvoiceCount)
+		assert.Equal(t, 1, run.MatchedCount)
+		assert.Equal(t, 5, run.DiscrepancyCount)
+		assert.Equal(t, 1, run.FixedCount)
+		assert.NotNil(t, run.FinishTime)
+	})
+
+	t.Run("leaves safe cases fixable when auto-fix is off", func(t *testing.T) {
+		run := newReconciliationRun(windowStart, windowEnd, false, constant.ReconciliationTriggerAdmin)
+		expectPage()
+		mockDB.EXPECT().SaveReconciliationItems(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, items []models.ReconciliationItem) error {
+			for _, item := range items {
+				if item.DiscrepancyType == constant.ReconciliationPaidPendingLocally {
+					assert.Equal(t, constant.ReconciliationFixAvailable, item.FixStatus)
+				}
+			}
+			return nil
+		})
+		mockDB.EXPECT().FinishReconciliationRun(ctx, run).Return(nil)
+
+		err := svc.reconcile(ctx, run)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, run.FixedCount)
+	})
+
+	t.Run("records failed fix on the item", func(t *testing.T) {
+		run := newReconciliationRun(windowStart, windowEnd, true, constant.ReconciliationTriggerScheduler)
+		expectPage()
+		mockPaymentService.EXPECT().ProcessPaymentSuccess(ctx, int64(2)).Return(errors.New("db timeout"))
+		mockDB.EXPECT().SaveReconciliationItems(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, items []models.ReconciliationItem) error {
+			for _, item := range items {
+				if item.DiscrepancyType == constant.ReconciliationPaidPendingLocally {
+					assert.Equal(t, constant.ReconciliationFixFailed, item.FixStatus)
+					assert.Equal(t, "db timeout", item.FixError)
+				}
+			}
+			return nil
+		})
+		mockDB.EXPECT().FinishReconciliationRun(ctx, run).Return(nil)
+
+		err := svc.reconcile(ctx, run)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, run.FixedCount)
+	})
+
+	t.Run("pages through invoices", func(t *testing.T) {
+		run := newReconciliationRun(windowStart, windowEnd, false, constant.ReconciliationTriggerScheduler)
+		fullPage := make([]models.XenditInvoiceResponse, 0, reconciliationPageSize)
+		pagePayments := make([]models.Payment, 0, reconciliationPageSize)
+		for i := 1; i <= reconciliationPageSize; i++ {
+			externalID := fmt.Sprintf("order-%d", 100+i)
+			fullPage = append(fullPage, models.XenditInvoiceResponse{ID: fmt.Sprintf("inv-%d", 100+i), ExternalID: externalID, Status: "PENDING", Amount: "1000", Currency: "IDR"})
+			pagePayments = append(pagePayments, models.Payment{ID: int64(100 + i), ExternalID: externalID, Status: constant.PaymentStatusPending, Amount: models.NewMoney(1000, "IDR")})
+		}
+		firstReq := models.XenditInvoiceListRequest{CreatedAfter: windowStart, CreatedBefore: windowEnd, Limit: reconciliationPageSize}
+		secondReq := firstReq
+		secondReq.LastInvoiceID = fmt.Sprintf("inv-%d", 100+reconciliationPageSize)
+
+		mockXendit.EXPECT().ListInvoices(ctx, firstReq).Return(fullPage, nil)
+		mockDB.EXPECT().GetPaymentsByExternalIDs(ctx, gomock.Any()).Return(pagePayments, nil)
+		mockDB.EXPECT().SaveReconciliationItems(ctx, gomock.Len(0)).Return(nil)
+		mockXendit.EXPECT().ListInvoices(ctx, secondReq).Return([]models.XenditInvoiceResponse{}, nil)
+		mockDB.EXPECT().GetPaymentsByExternalIDs(ctx, []string{}).Return(nil, nil)
+		mockDB.EXPECT().SaveReconciliationItems(ctx, gomock.Len(0)).Return(nil)
+		mockDB.EXPECT().FinishReconciliationRun(ctx, run).Return(nil)
+
+		err := svc.reconcile(ctx, run)
+		assert.NoError(t, err)
+		assert.Equal(t, reconciliationPageSize, run.InvoiceCount)
+		assert.Equal(t, reconciliationPageSize, run.MatchedCount)
+	})
+
+	t.Run("marks run failed when Xendit is unavailable", func(t *testing.T) {
+		run := newReconciliationRun(windowStart, windowEnd, false, constant.ReconciliationTriggerScheduler)
+		mockXendit.EXPECT().ListInvoices(ctx, gomock.Any()).Return(nil, errors.New("xendit list invoices API returned status: 503"))
+		mockDB.EXPECT().FinishReconciliationRun(ctx, run).Return(nil)
+
+		err := svc.reconcile(ctx, run)
+		assert.Error(t, err)
+		assert.Equal(t, constant.ReconciliationRunStatusFailed, run.Status)
+		assert.Contains(t, run.Error, "503")
+	})
+}
+
+func TestReconciliationService_RunScheduledReconciliation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	svc := NewReconciliationService(mockDB, nil, nil, nil)
+	ctx := context.Background()
+	windowStart := time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)
+
+	mockDB.EXPECT().CreateReconciliationRun(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, run *models.ReconciliationRun) (bool, error) {
+		require.NotNil(t, run.ScheduleKey)
+		assert.Equal(t, "daily:2026-01-30", *run.ScheduleKey)
+		return false, nil
+	})
+
+	run, err := svc.RunScheduledReconciliation(ctx, windowStart, windowStart.AddDate(0, 0, 1), true)
+	assert.NoError(t, err)
+	assert.Nil(t, run)
+}
+
+func TestReconciliationService_TriggerReconciliationRejectsInvalidWindow(t *testing.T) {
+	svc := NewReconciliationService(nil, nil, nil, nil)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := svc.TriggerReconciliation(context.Background(), from, from, false, 1)
+	assert.ErrorIs(t, err, ErrInvalidReconciliationWindow)
+
+	_, err = svc.TriggerReconciliation(context.Background(), from, from.AddDate(0, 2, 0), false, 1)
+	assert.ErrorIs(t, err, ErrInvalidReconciliationWindow)
+}
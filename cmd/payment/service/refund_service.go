@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/constant"
+	"paymentfc/log"
+	"paymentfc/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPaymentNotRefundable = errors.New("payment is not refundable in current status")
+	ErrInvalidRefundAmount  = errors.New("invalid refund amount")
+	ErrInvalidRefundReason  = errors.New("invalid refund reason")
+)
+
+var refundReasons = map[string]bool{
+	constant.RefundReasonFraudulent:          true,
+	constant.RefundReasonDuplicate:           true,
+	constant.RefundReasonRequestedByCustomer: true,
+	constant.RefundReasonCancellation:        true,
+	constant.RefundReasonOthers:              true,
+}
+
+type RefundService interface {
+	CreateRefund(ctx context.Context, orderID int64, req models.RefundRequest, requestedBy int64) (*models.Refund, error)
+	RefundOverpayment(ctx context.Context, anomalyID, orderID int64, amount models.Money, requestedBy int64) (*models.Refund, error)
+}
+
+type refundService struct {
+	database  repository.PaymentDatabase
+	gateways  *repository.GatewayRegistry
+	publisher repository.PaymentEventPublisher
+	auditLog  repository.AuditLogRepository
+}
+
+func NewRefundService(db repository.PaymentDatabase, gateways *repository.GatewayRegistry, publisher repository.PaymentEventPublisher, auditLog repository.AuditLogRepository) RefundService {
+	return &refundService{
+		database:  db,
+		gateways:  gateways,
+		publisher: publisher,
+		auditLog:  auditLog,
+	}
+}
+
+// CreateRefund 결제 완료 건에 대해 전액(Amount == 0) 또는 부분 환불을 요청한다.
+// 누적 환불액은 payment 금액을 넘을 수 없다.
+func (s *refundService) CreateRefund(ctx context.Context, orderID int64, req models.RefundRequest, requestedBy int64) (*models.Refund, error) {
+	reason := req.Reason
+	if reason == "" {
+		reason = constant.RefundReasonRequestedByCustomer
+	}
+	if !refundReasons[reason] {
+		return nil, ErrInvalidRefundReason
+	}
+
+	payment, err := s.database.GetPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != constant.PaymentStatusPaid && payment.Status != constant.PaymentStatusPartiallyRefunded {
+		return nil, ErrPaymentNotRefundable
+	}
+	if payment.InvoiceID == "" {
+		return nil, fmt.Errorf("checkout id is missing for order_id: %d", orderID)
+	}
+	gateway, err := s.gateways.Get(payment.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// 금액 미지정 시 zero Money -> ReserveRefund가 남은 금액 전액으로 채운다.
+	amount := models.NewMoney(0, payment.Amount.Currency)
+	if req.Amount != "" {
+		amount, err = models.ParseMoney(req.Amount.String(), payment.Amount.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRefundAmount, err)
+		}
+		if amount.Minor < 0 {
+			return nil, ErrInvalidRefundAmount
+		}
+	}
+
+	refund := &models.Refund{
+		PaymentID:   payment.ID,
+		OrderID:     orderID,
+		ExternalID:  fmt.Sprintf("refund-%d-%s", orderID, uuid.NewString()),
+		Amount:      amount,
+		Reason:      reason,
+		RequestedBy: requestedBy,
+	}
+	if err := s.database.ReserveRefund(ctx, refund); err != nil {
+		return nil, err
+	}
+	s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+		OrderID:    orderID,
+		PaymentID:  payment.ID,
+		UserID:     requestedBy,
+		ExternalID: refund.ExternalID,
+		Event:      "REFUND_REQUESTED",
+		Actor:      "refund_service",
+		Metadata: map[string]any{
+			"refund_id": refund.ID,
+			"amount":    refund.Amount,
+			"reason":    reason,
+		},
+	})
+
+	resp, err := gateway.Refund(ctx, models.GatewayRefundRequest{
+		CheckoutID:  payment.InvoiceID,
+		ExternalID:  payment.ExternalID,
+		ReferenceID: refund.ExternalID,
+		Amount:      refund.Amount,
+		Reason:      reason,
+	})
+	if err == nil && resp.Status == constant.RefundStatusFailed {
+		err = fmt.Errorf("%s refund failed: %s", gateway.Name(), resp.FailureCode)
+	}
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("order_id", orderID).Int64("refund_id", refund.ID).Str("provider", gateway.Name()).Msg("Failed to create gateway refund")
+		refund.Status = constant.RefundStatusFailed
+		if updateErr := s.database.UpdateRefund(ctx, refund.ID, refund.Status, "", err.Error()); updateErr != nil {
+			log.Logger.Error().Err(updateErr).Int64("refund_id", refund.ID).Msg("Failed to update refund as failed")
+		}
+		s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+			OrderID:    orderID,
+			PaymentID:  payment.ID,
+			ExternalID: refund.ExternalID,
+			Event:      "REFUND_FAILED",
+			Actor:      "refund_service",
+			Metadata: map[string]any{
+				"refund_id": refund.ID,
+				"error":     err.Error(),
+			},
+		})
+		return nil, err
+	}
+
+	refund.Status = resp.Status
+	refund.XenditRefundID = resp.ID
+	if err := s.database.UpdateRefund(ctx, refund.ID, refund.Status, refund.XenditRefundID, ""); err != nil {
+		return nil, err
+	}
+
+	refunded, paymentStatus, err := s.settleRefund(ctx, payment, refund, reason)
+	if err != nil {
+		return nil, err
+	}
+	s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+		OrderID:    orderID,
+		PaymentID:  payment.ID,
+		ExternalID: refund.ExternalID,
+		Event:      "MARK_REFUNDED",
+		Actor:      "refund_service",
+		Metadata: map[string]any{
+			"refund_id":        refund.ID,
+			"xendit_refund_id": refund.XenditRefundID,
+			"provider":         gateway.Name(),
+			"amount":           refund.Amount,
+			"refunded_amount":  refunded,
+			"payment_status":   paymentStatus,
+		},
+	})
+
+	return refund, nil
+}
+
+// settleRefund 완료된 환불 합계로 PARTIALLY_REFUNDED/REFUNDED를 정하고 payment.refunded와 함께 전이한다.
+// 동시에 끝난 다른 환불이 먼저 REFUNDED로 바꿨으면 합계를 다시 읽어 한 번 더 시도한다. 이미 REFUNDED면 outbox만 남긴다.
+func (s *refundService) settleRefund(ctx context.Context, payment *models.Payment, refund *models.Refund, reason string) (models.Money, string, error) {
+	for attempt := 0; ; attempt++ {
+		refunded, err := s.database.GetRefundedAmount(ctx, payment.ID)
+		if err != nil {
+			return models.Money{}, "", err
+		}
+		paymentStatus := constant.PaymentStatusPartiallyRefunded
+		if refunded.Minor >= payment.Amount.Minor {
+			paymentStatus = constant.PaymentStatusRefunded
+		}
+		// payment.refunded는 전이와 같은 트랜잭션으로 outbox에 기록되고 relay가 발행한다.
+		outbox, err := repository.NewPaymentRefundedOutboxEvent(models.PaymentRefundedEvent{
+			OrderID:        payment.OrderID,
+			PaymentID:      payment.ID,
+			RefundID:       refund.ID,
+			Amount:         refund.Amount,
+			RefundedAmount: refunded,
+			FullyRefunded:  paymentStatus == constant.PaymentStatusRefunded,
+			Status:         paymentStatus,
+		})
+		if err != nil {
+			return models.Money{}, "", err
+		}
+		err = s.database.TransitionPaymentStatus(ctx, models.PaymentStatusTransition{
+			PaymentID:      payment.ID,
+			OrderID:        payment.OrderID,
+			ToStatus:       paymentStatus,
+			Actor:          "refund_service",
+			Reason:         fmt.Sprintf("refund %d (%s)", refund.ID, reason),
+			Outbox:         []models.OutboxEvent{outbox},
+			SameStatusNoop: true,
+		})
+		if attempt == 0 && repository.IsIllegalTransition(err) {
+			continue
+		}
+		if err != nil {
+			return models.Money{}, "", err
+		}
+		return refunded, paymentStatus, nil
+	}
+}
+
+// OverpaymentRefundReference 금액 불일치 anomaly 하나당 하나인 초과 입금 환불 reference. 게이트웨이 멱등 키로도 쓰인다.
+func OverpaymentRefundReference(anomalyID int64) string {
+	return fmt.Sprintf("overpay-anomaly-%d", anomalyID)
+}
+
+// RefundOverpayment anomaly에서 주문 금액보다 더 들어온 amount를 돌려준다. 결제 상태와 누적 환불액은 바꾸지 않는다.
+// 환불은 anomaly마다 한 건이라 재시도해도 다시 보내지 않고, 결과를 모르는(PENDING) 건은 그대로 돌려준다.
+// 실패하지 않은 초과 입금 환불 합계는 amount를 넘을 수 없다.
+func (s *refundService) RefundOverpayment(ctx context.Context, anomalyID, orderID int64, amount models.Money, requestedBy int64) (*models.Refund, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidRefundAmount
+	}
+	payment, err := s.database.GetPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != constant.PaymentStatusPaid && payment.Status != constant.PaymentStatusPartiallyRefunded {
+		return nil, ErrPaymentNotRefundable
+	}
+	if !amount.SameCurrency(payment.Amount) {
+		return nil, fmt.Errorf("%w: currency %s does not match payment currency %s", ErrInvalidRefundAmount, amount.Currency, payment.Amount.Currency)
+	}
+	gateway, err := s.gateways.Get(payment.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := &models.Refund{
+		PaymentID:   payment.ID,
+		OrderID:     orderID,
+		ExternalID:  OverpaymentRefundReference(anomalyID),
+		Amount:      amount,
+		Reason:      constant.RefundReasonOverpayment,
+		RequestedBy: requestedBy,
+	}
+	send, err := s.database.ReserveOverpaymentRefund(ctx, refund, amount)
+	if err != nil {
+		return nil, err
+	}
+	if !send {
+		log.Logger.Info().Int64("anomaly_id", anomalyID).Int64("refund_id", refund.ID).Str("status", refund.Status).Msg("Overpayment refund already issued, not sending again")
+		return refund, nil
+	}
+
+	resp, err := gateway.Refund(ctx, models.GatewayRefundRequest{
+		CheckoutID:  payment.InvoiceID,
+		ExternalID:  payment.ExternalID,
+		ReferenceID: refund.ExternalID,
+		Amount:      amount,
+		Reason:      constant.RefundReasonOthers,
+	})
+	if err == nil && resp.Status == constant.RefundStatusFailed {
+		err = fmt.Errorf("%s refund failed: %s", gateway.Name(), resp.FailureCode)
+	}
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("order_id", orderID).Int64("refund_id", refund.ID).Msg("Failed to refund overpayment")
+		refund.Status = constant.RefundStatusFailed
+		if updateErr := s.database.UpdateRefund(ctx, refund.ID, refund.Status, "", err.Error()); updateErr != nil {
+			log.Logger.Error().Err(updateErr).Int64("refund_id", refund.ID).Msg("Failed to update refund as failed")
+		}
+		return nil, err
+	}
+
+	refund.Status = resp.Status
+	refund.XenditRefundID = resp.ID
+	if err := s.database.UpdateRefund(ctx, refund.ID, refund.Status, refund.XenditRefundID, ""); err != nil {
+		return nil, err
+	}
+	s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+		OrderID:    orderID,
+		PaymentID:  payment.ID,
+		UserID:     requestedBy,
+		ExternalID: refund.ExternalID,
+		Event:      "OVERPAYMENT_REFUNDED",
+		Actor:      "refund_service",
+		Metadata: map[string]any{
+			"refund_id":        refund.ID,
+			"xendit_refund_id": refund.XenditRefundID,
+			"provider":         gateway.Name(),
+			"amount":           amount,
+		},
+	})
+	return refund, nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/constant"
+	"paymentfc/mocks"
+	"paymentfc/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRefundService_CreateRefund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockXendit := mocks.NewMockXenditClient(ctrl)
+	mockPublisher := mocks.NewMockPaymentEventPublisher(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+
+	svc := NewRefundService(mockDB, newTestGateways(mockXendit), mockPublisher, mockAuditLog)
+	ctx := context.Background()
+	orderID := int64(12345)
+
+	paidPayment := func() *models.Payment {
+		return &models.Payment{
+			ID:        1,
+			OrderID:   orderID,
+			InvoiceID: "inv-12345",
+			Amount:    models.NewMoney(100000, "IDR"),
+			Status:    constant.PaymentStatusPaid,
+		}
+	}
+
+	t.Run("rejects negative amount", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(paidPayment(), nil)
+
+		_, err := svc.CreateRefund(ctx, orderID, models.RefundRequest{Amount: "-1"}, 10)
+		assert.ErrorIs(t, err, ErrInvalidRefundAmount)
+	})
+
+	t.Run("rejects fractional amount for IDR", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(paidPayment(), nil)
+
+		_, err := svc.CreateRefund(ctx, orderID, models.RefundRequest{Amount: "100.5"}, 10)
+		assert.ErrorIs(t, err, ErrInvalidRefundAmount)
+	})
+
+	t.Run("rejects unknown reason", func(t *testing.T) {
+		_, err := svc.CreateRefund(ctx, orderID, models.RefundRequest{Reason: "BECAUSE"}, 10)
+		assert.ErrorIs(t, err, ErrInvalidRefundReason)
+	})
+
+	t.Run("rejects payment that is not paid", func(t *testing.T) {
+		payment := paidPayment()
+		payment.Status = constant.PaymentStatusPending
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(payment, nil)
+
+		_, err := svc.CreateRefund(ctx, orderID, models.RefundRequest{}, 10)
+		assert.ErrorIs(t, err, ErrPaymentNotRefundable)
+	})
+
+	t.Run("returns error when refund exceeds remaining amount", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(paidPayment(), nil)
+		mockDB.EXPECT().ReserveRefund(ctx, gomock.Any()).Return(repository.ErrRefundAmountExceeded)
+
+		_, err := svc.CreateRefund(ctx, orderID, models.RefundRequest{Amount: "200000"}, 10)
+		assert.ErrorIs(t, err, repository.ErrRefundAmountExceeded)
+	})
+
+	t.Run("full refund marks payment refunded with outbox event", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(paidPayment(), nil)
+		mockDB.EXPECT().ReserveRefund(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, r *models.Refund) error {
+			r.ID = 7
+			r.Amount = models.NewMoney(100000, "IDR")
+			r.Status = constant.RefundStatusPending
+			return nil
+		})
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil).Times(2)
+		mockXendit.EXPECT().CreateRefund(ctx, gomock.Any()).Return(&models.XenditRefundResponse{
+			ID:     "rfd-1",
+			Status: constant.RefundStatusSucceeded,
+		}, nil)
+		mockDB.EXPECT().UpdateRefund(ctx, int64(7), constant.RefundStatusSucceeded, "rfd-1", "").Return(nil)
+		mockDB.EXPECT().GetRefundedAmount(ctx, int64(1)).Return(models.NewMoney(100000, "IDR"), nil)
+		mockDB.EXPECT().TransitionPaymentStatus(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, param models.PaymentStatusTransition) error {
+			assert.Equal(t, constant.PaymentStatusRefunded, param.ToStatus)
+			if assert.Len(t, param.Outbox, 1) {
+				assert.Equal(t, constant.KafkaTopicPaymentRefunded, param.Outbox[0].EventType)
+				assert.Contains(t, param.Outbox[0].Payload, `"fully_refunded":true`)
+				assert.Contains(t, param.Outbox[0].Payload, `"refund_id":7`)
+			}
+			return nil
+		})
+
+		refund, err := svc.CreateRefund(ctx, orderID, models.RefundRequest{}, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "rfd-1", refund.XenditRefundID)
+	})
+
+	t.Run("partial refund marks payment partially refunded", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(paidPayment(), nil)
+		mockDB.EXPECT().ReserveRefund(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, r *models.Refund) error {
+			r.ID = 8
+			return nil
+		})
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil).Times(2)
+		mockXendit.EXPECT().CreateRefund(ctx, gomock.Any()).Return(&models.XenditRefundResponse{
+			ID:     "rfd-2",
+			Status: constant.RefundStatusPending,
+		}, nil)
+		mockDB.EXPECT().UpdateRefund(ctx, int64(8), constant.RefundStatusPending, "rfd-2", "").Return(nil)
+		mockDB.EXPECT().GetRefundedAmount(ctx, int64(1)).Return(models.NewMoney(30000, "IDR"), nil)
+		mockDB.EXPECT().TransitionPaymentStatus(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, param models.PaymentStatusTransition) error {
+			assert.Equal(t, constant.PaymentStatusPartiallyRefunded, param.ToStatus)
+			assert.Len(t, param.Outbox, 1)
+			return nil
+		})
+
+		_, err := svc.CreateRefund(ctx, orderID, models.RefundRequest{Amount: "30000"}, 10)
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrent partial refunds reaching the full amount both succeed", func(t *testing.T) {
+		// A(60000)와 B(40000)가 같이 PAID를 읽고 예약한 뒤, B가 먼저 REFUNDED로 전이한 상황.
+		settle := func(refundID int64, xenditID string, refunded ...int64) {
+			mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(paidPayment(), nil)
+			mockDB.EXPECT().ReserveRefund(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, r *models.Refund) error {
+				r.ID = refundID
+				return nil
+			})
+			mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil).Times(2)
+			mockXendit.EXPECT().CreateRefund(ctx, gomock.Any()).Return(&models.XenditRefundResponse{ID: xenditID, Status: constant.RefundStatusSucceeded}, nil)
+			mockDB.EXPECT().UpdateRefund(ctx, refundID, constant.RefundStatusSucceeded, xenditID, "").Return(nil)
+			for _, minor := range refunded {
+				mockDB.EXPECT().GetRefundedAmount(ctx, int64(1)).Return(models.NewMoney(minor, "IDR"), nil)
+			}
+		}
+
+		settle(11, "rfd-b", 100000)
+		mockDB.EXPECT().TransitionPaymentStatus(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, param models.PaymentStatusTransition) error {
+			assert.Equal(t, constant.PaymentStatusRefunded, param.ToStatus)
+			assert.True(t, param.SameStatusNoop)
+			return nil
+		})
+		_, err := svc.CreateRefund(ctx, orderID, models.RefundRequest{Amount: "40000"}, 10)
+		assert.NoError(t, err)
+
+		// A는 B의 완료 전에 합계를 읽어 PARTIALLY_REFUNDED를 시도했다가 거절되고, 다시 읽은 합계로 REFUNDED(no-op)가 된다.
+		settle(10, "rfd-a", 60000, 100000)
+		gomock.InOrder(
+			mockDB.EXPECT().TransitionPaymentStatus(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, param models.PaymentStatusTransition) error {
+				assert.Equal(t, constant.PaymentStatusPartiallyRefunded, param.ToStatus)
+				return &repository.IllegalTransitionError{PaymentID: 1, From: constant.PaymentStatusRefunded, To: param.ToStatus}
+			}),
+			mockDB.EXPECT().TransitionPaymentStatus(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, param models.PaymentStatusTransition) error {
+				assert.Equal(t, constant.PaymentStatusRefunded, param.ToStatus)
+				assert.True(t, param.SameStatusNoop)
+				if assert.Len(t, param.Outbox, 1) {
+					assert.Contains(t, param.Outbox[0].Payload, `"refund_id":10`)
+					assert.Contains(t, param.Outbox[0].Payload, `"fully_refunded":true`)
+				}
+				return nil
+			}),
+		)
+		refund, err := svc.CreateRefund(ctx, orderID, models.RefundRequest{Amount: "60000"}, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "rfd-a", refund.XenditRefundID)
+	})
+
+	t.Run("xendit failure marks refund failed", func(t *testing.T) {
+		xenditErr := errors.New("xendit API returned status: 500")
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, orderID).Return(paidPayment(), nil)
+		mockDB.EXPECT().ReserveRefund(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, r *models.Refund) error {
+			r.ID = 9
+			return nil
+		})
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil).Times(2)
+		mockXendit.EXPECT().CreateRefund(ctx, gomock.Any()).Return(nil, xenditErr)
+		mockDB.EXPECT().UpdateRefund(ctx, int64(9), constant.RefundStatusFailed, "", xenditErr.Error()).Return(nil)
+
+		_, err := svc.CreateRefund(ctx, orderID, models.RefundRequest{Amount: "1000"}, 10)
+		assert.ErrorIs(t, err, xenditErr)
+	})
+}
+
+func TestRefundService_RefundOverpayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockXendit := mocks.NewMockXenditClient(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	svc := NewRefundService(mockDB, newTestGateways(mockXendit), mocks.NewMockPaymentEventPublisher(ctrl), mockAuditLog)
+	ctx := context.Background()
+	diff := models.NewMoney(2500, "IDR")
+	payment := &models.Payment{ID: 1, OrderID: 100, InvoiceID: "inv-100", Amount: models.NewMoney(10000, "IDR"), Status: constant.PaymentStatusPaid}
+
+	t.Run("refunds once under the anomaly reference capped at the difference", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, int64(100)).Return(payment, nil)
+		mockDB.EXPECT().ReserveOverpaymentRefund(ctx, gomock.Any(), diff).DoAndReturn(func(_ context.Context, r *models.Refund, _ models.Money) (bool, error) {
+			assert.Equal(t, "overpay-anomaly-3", r.ExternalID)
+			assert.Equal(t, constant.RefundReasonOverpayment, r.Reason)
+			r.ID = 21
+			return true, nil
+		})
+		mockXendit.EXPECT().CreateRefund(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, req models.XenditRefundRequest) (*models.XenditRefundResponse, error) {
+			assert.Equal(t, "overpay-anomaly-3", req.ReferenceID)
+			return &models.XenditRefundResponse{ID: "rfd-over", Status: constant.RefundStatusSucceeded}, nil
+		})
+		mockDB.EXPECT().UpdateRefund(ctx, int64(21), constant.RefundStatusSucceeded, "rfd-over", "").Return(nil)
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
+
+		refund, err := svc.RefundOverpayment(ctx, 3, 100, diff, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(21), refund.ID)
+	})
+
+	t.Run("retry after a sent refund does not refund again", func(t *testing.T) {
+		// 이전 시도에서 게이트웨이 환불은 나갔지만 UpdateRefund가 실패해 PENDING으로 남은 경우
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, int64(100)).Return(payment, nil)
+		mockDB.EXPECT().ReserveOverpaymentRefund(ctx, gomock.Any(), diff).DoAndReturn(func(_ context.Context, r *models.Refund, _ models.Money) (bool, error) {
+			r.ID = 21
+			r.Status = constant.RefundStatusPending
+			return false, nil
+		})
+
+		refund, err := svc.RefundOverpayment(ctx, 3, 100, diff, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(21), refund.ID)
+	})
+
+	t.Run("rejects refund over the overpaid difference", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, int64(100)).Return(payment, nil)
+		mockDB.EXPECT().ReserveOverpaymentRefund(ctx, gomock.Any(), diff).Return(false, repository.ErrRefundAmountExceeded)
+
+		_, err := svc.RefundOverpayment(ctx, 4, 100, diff, 7)
+		assert.ErrorIs(t, err, repository.ErrRefundAmountExceeded)
+	})
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/constant"
+	"paymentfc/models"
+	"sort"
+	"time"
+)
+
+var ErrInvalidReportQuery = errors.New("invalid report query")
+
+type ReportService interface {
+	GetSettlementReport(ctx context.Context, query models.SettlementReportQuery) (*models.SettlementReport, error)
+	StreamSettlementReport(ctx context.Context, query models.SettlementReportQuery, fn func(models.SettlementReportRow) error) error
+}
+
+type reportService struct {
+	database repository.PaymentDatabase
+}
+
+func NewReportService(db repository.PaymentDatabase) ReportService {
+	return &reportService{database: db}
+}
+
+// GetSettlementReport JSON 응답용. 행과 통화별 합계를 함께 돌려준다.
+func (s *reportService) GetSettlementReport(ctx context.Context, query models.SettlementReportQuery) (*models.SettlementReport, error) {
+	query, err := normalizeSettlementQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.SettlementReport{
+		From:     query.From,
+		To:       query.To,
+		GroupBy:  query.GroupBy,
+		Timezone: query.Timezone,
+		Rows:     []models.SettlementReportRow{},
+		Totals:   []models.SettlementReportRow{},
+	}
+	totals := make(map[string]*models.SettlementReportRow)
+	err = s.streamSettlementRows(ctx, query, func(row models.SettlementReportRow) error {
+		report.Rows = append(report.Rows, row)
+		total, ok := totals[row.Currency]
+		if !ok {
+			total = &models.SettlementReportRow{Currency: row.Currency}
+			totals[row.Currency] = total
+		}
+		return addSettlementRow(total, row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, total := range totals {
+		completeSettlementRow(total)
+		report.Totals = append(report.Totals, *total)
+	}
+	sort.Slice(report.Totals, func(i, j int) bool { return report.Totals[i].Currency < report.Totals[j].Currency })
+	return report, nil
+}
+
+// StreamSettlementReport CSV 내보내기용. 행을 모으지 않고 바로 fn에 넘긴다.
+func (s *reportService) StreamSettlementReport(ctx context.Context, query models.SettlementReportQuery, fn func(models.SettlementReportRow) error) error {
+	query, err := normalizeSettlementQuery(query)
+	if err != nil {
+		return err
+	}
+	return s.streamSettlementRows(ctx, query, fn)
+}
+
+func (s *reportService) streamSettlementRows(ctx context.Context, query models.SettlementReportQuery, fn func(models.SettlementReportRow) error) error {
+	return s.database.StreamSettlementReport(ctx, query, func(row models.SettlementReportRow) error {
+		completeSettlementRow(&row)
+		return fn(row)
+	})
+}
+
+// normalizeSettlementQuery 기본값(day, UTC)을 채우고 집계 단위, timezone, 기간을 검증한다.
+func normalizeSettlementQuery(query models.SettlementReportQuery) (models.SettlementReportQuery, error) {
+	if query.GroupBy == "" {
+		query.GroupBy = constant.ReportGroupByDay
+	}
+	switch query.GroupBy {
+	case constant.ReportGroupByDay, constant.ReportGroupByWeek, constant.ReportGroupByMonth:
+	default:
+		return query, fmt.Errorf("%w: group_by must be one of day, week, month", ErrInvalidReportQuery)
+	}
+	if query.Timezone == "" {
+		query.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(query.Timezone); err != nil {
+		return query, fmt.Errorf("%w: unknown timezone %s", ErrInvalidReportQuery, query.Timezone)
+	}
+	if !query.To.After(query.From) {
+		return query, fmt.Errorf("%w: to must be after from", ErrInvalidReportQuery)
+	}
+	return query, nil
+}
+
+// completeSettlementRow 순매출과 만료/실패 비율을 채운다.
+func completeSettlementRow(row *models.SettlementReportRow) {
+	row.NetAmount = models.NewMoney(row.GrossPaidAmount.Minor-row.RefundAmount.Minor, row.Currency)
+	row.ExpiredRatio, row.FailedRatio = 0, 0
+	if row.PaymentCount > 0 {
+		row.ExpiredRatio = float64(row.ExpiredCount) / float64(row.PaymentCount)
+		row.FailedRatio = float64(row.FailedCount) / float64(row.PaymentCount)
+	}
+}
+
+func addSettlementRow(total *models.SettlementReportRow, row models.SettlementReportRow) error {
+	total.PaymentCount += row.PaymentCount
+	total.PendingCount += row.PendingCount
+	total.PaidCount += row.PaidCount
+	total.PartiallyRefundedCount += row.PartiallyRefundedCount
+	total.RefundedCount += row.RefundedCount
+	total.ExpiredCount += row.ExpiredCount
+	total.FailedCount += row.FailedCount
+	total.RefundCount += row.RefundCount
+	// 세금 합계는 같은 통화 행끼리만 더해지므로 minor 값을 그대로 합산한다
+	total.TaxBaseAmount = models.NewMoney(total.TaxBaseAmount.Minor+row.TaxBaseAmount.Minor, row.Currency)
+	total.TaxAmount = models.NewMoney(total.TaxAmount.Minor+row.TaxAmount.Minor, row.Currency)
+	if total.GrossPaidAmount.Currency == "" {
+		total.GrossPaidAmount = models.NewMoney(0, row.Currency)
+		total.RefundAmount = models.NewMoney(0, row.Currency)
+	}
+	var err error
+	if total.GrossPaidAmount, err = total.GrossPaidAmount.Add(row.GrossPaidAmount); err != nil {
+		return err
+	}
+	if total.RefundAmount, err = total.RefundAmount.Add(row.RefundAmount); err != nil {
+		return err
+	}
+	return nil
+}
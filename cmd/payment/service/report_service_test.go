@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"paymentfc/constant"
+	"paymentfc/mocks"
+	"paymentfc/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReportService_GetSettlementReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	svc := NewReportService(mockDB)
+	ctx := context.Background()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+
+	t.Run("computes ratios, net amount and per-currency totals", func(t *testing.T) {
+		rows := []models.SettlementReportRow{
+			{Period: "2026-01-01", Currency: "IDR", PaymentCount: 4, PaidCount: 2, ExpiredCount: 1, FailedCount: 1,
+				GrossPaidAmount: models.NewMoney(300000, "IDR"), RefundCount: 1, RefundAmount: models.NewMoney(50000, "IDR"),
+				TaxBaseAmount: models.NewMoney(270270, "IDR"), TaxAmount: models.NewMoney(29730, "IDR")},
+			{Period: "2026-01-01", Currency: "USD", PaymentCount: 1, PaidCount: 1, GrossPaidAmount: models.NewMoney(1234, "USD"), RefundAmount: models.NewMoney(0, "USD")},
+			{Period: "2026-01-02", Currency: "IDR", PaymentCount: 2, PaidCount: 1, ExpiredCount: 1,
+				GrossPaidAmount: models.NewMoney(100000, "IDR"), RefundAmount: models.NewMoney(0, "IDR"),
+				TaxBaseAmount: models.NewMoney(90090, "IDR"), TaxAmount: models.NewMoney(9910, "IDR")},
+		}
+		expectedQuery := models.SettlementReportQuery{From: from, To: to, GroupBy: constant.ReportGroupByDay, Timezone: "UTC"}
+		mockDB.EXPECT().StreamSettlementReport(ctx, expectedQuery, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ models.SettlementReportQuery, fn func(models.SettlementReportRow) error) error {
+				for _, row := range rows {
+					if err := fn(row); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+		report, err := svc.GetSettlementReport(ctx, models.SettlementReportQuery{From: from, To: to})
+		require.NoError(t, err)
+		require.Len(t, report.Rows, 3)
+		assert.Equal(t, 0.25, report.Rows[0].ExpiredRatio)
+		assert.Equal(t, 0.25, report.Rows[0].FailedRatio)
+		assert.Equal(t, models.NewMoney(250000, "IDR"), report.Rows[0].NetAmount)
+
+		require.Len(t, report.Totals, 2)
+		idr := report.Totals[0]
+		assert.Equal(t, "IDR", idr.Currency)
+		assert.Equal(t, int64(6), idr.PaymentCount)
+		assert.Equal(t, models.NewMoney(400000, "IDR"), idr.GrossPaidAmount)
+		assert.Equal(t, models.NewMoney(350000, "IDR"), idr.NetAmount)
+		assert.InDelta(t, 2.0/6.0, idr.ExpiredRatio, 1e-9)
+		assert.Equal(t, models.NewMoney(360360, "IDR"), idr.TaxBaseAmount)
+		assert.Equal(t, models.NewMoney(39640, "IDR"), idr.TaxAmount)
+		assert.Equal(t, "USD", report.Totals[1].Currency)
+	})
+
+	t.Run("rejects invalid query", func(t *testing.T) {
+		_, err := svc.GetSettlementReport(ctx, models.SettlementReportQuery{From: from, To: to, GroupBy: "year"})
+		assert.ErrorIs(t, err, ErrInvalidReportQuery)
+
+		_, err = svc.GetSettlementReport(ctx, models.SettlementReportQuery{From: from, To: to, Timezone: "Mars/Olympus"})
+		assert.ErrorIs(t, err, ErrInvalidReportQuery)
+
+		err = svc.StreamSettlementReport(ctx, models.SettlementReportQuery{From: to, To: from}, func(models.SettlementReportRow) error { return nil })
+		assert.ErrorIs(t, err, ErrInvalidReportQuery)
+	})
+}
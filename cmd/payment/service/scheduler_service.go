@@ -14,245 +14,406 @@ import (
 )
 
 type SchedulerService struct {
-	Database       repository.PaymentDatabase
-	Xendit         repository.XenditClient
-	Publisher      repository.PaymentEventPublisher
-	PaymentService PaymentService
-	AuditLog       repository.AuditLogRepository
-	UserClient     usergrpc.UserClientInterface
+	Database        repository.PaymentDatabase
+	Gateways        *repository.GatewayRegistry
+	Publisher       repository.PaymentEventPublisher
+	PaymentService  PaymentService
+	AuditLog        repository.AuditLogRepository
+	UserClient      usergrpc.UserClientInterface
+	Reconciliation  ReconciliationService
+	TaxRule         models.TaxRule
+	InstanceID      string         // row claim 시 claimed_by에 기록되는 인스턴스 이름
+	ClaimLease      time.Duration  // 0이면 defaultClaimLease
+	Leader          *LeaderElector // nil이면 싱글톤 잡도 모든 인스턴스에서 실행된다
+	JobRunRetention time.Duration  // job_runs 보관 기간. 0이면 defaultJobRunRetention
+
+	ReconciliationEnabled bool // false면 daily_reconciliation 잡을 등록하지 않는다
+	ReconciliationRunHour int  // daily_reconciliation 기본 스케줄의 실행 시각(서버 로컬)
+	ReconciliationAutoFix bool
 }
 
-func (s *SchedulerService) StartSweepingExpiredPendingPayments() {
-	go func() {
-		for {
-			ctx := context.Background()
-			pendingExpiredPayments, err := s.Database.GetExpiredPendingPayments(ctx)
-			if err != nil && err != gorm.ErrRecordNotFound {
-				log.Logger.Error().Err(err).Msg("Failed to get expired pending payments")
-				time.Sleep(5 * time.Second) // DB 이슈 시 잠시 대기 후 재시도
-				continue
-			}
-			for _, payment := range pendingExpiredPayments {
+// defaultClaimLease 잡아 온 배치를 처리하는 동안 다른 인스턴스가 같은 건을 가져가지 못하게 막는 시간
+const defaultClaimLease = 5 * time.Minute
 
-				paymentInfo, err := s.PaymentService.GetPaymentByOrderID(ctx, payment.OrderID)
-				if err != nil {
-					log.Logger.Error().Err(err).Int64("order_id", payment.OrderID).Msg("Failed to get payment by order_id")
-					continue
-				}
-				if paymentInfo.Status != constant.PaymentStatusPending {
-					continue
-				}
+func (s *SchedulerService) claimLease() time.Duration {
+	if s.ClaimLease <= 0 {
+		return defaultClaimLease
+	}
+	return s.ClaimLease
+}
 
-				err = retryPublishPayment(constant.MaxRetryPublish, func() error {
-					return s.Publisher.PublishPaymentStatus(ctx, payment.OrderID, constant.PaymentStatusExpired, "payment.expired")
-				})
-				if err != nil {
-					log.Logger.Error().Err(err).Int64("order_id", payment.OrderID).Msg("Failed to publish payment expired")
-					continue
-				}
+// defaultJobRunRetention 할 일이 없던 실행까지 기록하므로 오래 두지 않는다.
+const defaultJobRunRetention = 14 * 24 * time.Hour
 
-				if markErr := s.Database.MarkExpired(ctx, payment.ID); markErr != nil {
-					log.Logger.Error().Err(markErr).Int64("payment_id", payment.ID).Msg("Failed to mark payment as expired")
-				} else {
-					s.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
-						OrderID:    payment.OrderID,
-						PaymentID:  payment.ID,
-						ExternalID: payment.ExternalID,
-						Event:      "PAYMENT_EXPIRED",
-						Actor:      "expired_sweeper",
-					})
-				}
-			}
-			time.Sleep(1 * time.Minute)
-		}
-	}()
+func (s *SchedulerService) jobRunRetention() time.Duration {
+	if s.JobRunRetention <= 0 {
+		return defaultJobRunRetention
+	}
+	return s.JobRunRetention
 }
 
-func (s *SchedulerService) StartProcessFailedPaymentRequests() {
-	go func() {
-		for {
-			ctx := context.Background()
-			pendingPaymentRequests, err := s.Database.GetFailedPaymentRequests(ctx)
-			if err != nil {
-				log.Logger.Error().Err(err).Msg("Failed to get failed payment requests")
-
-				time.Sleep(5 * time.Second) // DB 이슈 시 잠시 대기 후 재시도
-				continue
-			}
+// Jobs 잡 엔진에 등록할 스케줄러 잡과 기본 설정. config의 jobs.<name>으로 덮어쓸 수 있다.
+func (s *SchedulerService) Jobs() []Job {
+	jobs := []Job{
+		{
+			Spec: JobSpec{Name: constant.JobCheckPendingInvoices, Schedule: "@every 10m", BatchSize: 100, Timeout: 5 * time.Minute, LeaderOnly: true},
+			Run:  s.CheckPendingInvoices,
+		},
+		{
+			Spec: JobSpec{Name: constant.JobProcessPaymentRequests, Schedule: "@every 5s", BatchSize: 5, Timeout: 2 * time.Minute},
+			Run:  s.ProcessPendingPaymentRequests,
+		},
+		{
+			Spec: JobSpec{Name: constant.JobRetryFailedPaymentRequests, Schedule: "@every 1m", BatchSize: 5, MaxRetries: 3, Timeout: time.Minute},
+			Run:  s.RetryFailedPaymentRequests,
+		},
+		{
+			Spec: JobSpec{Name: constant.JobSweepExpiredPayments, Schedule: "@every 1m", BatchSize: 100, Timeout: 2 * time.Minute, LeaderOnly: true},
+			Run:  s.SweepExpiredPendingPayments,
+		},
+		{
+			Spec: JobSpec{Name: constant.JobRelayOutboxEvents, Schedule: "@every 1s", BatchSize: 50, Timeout: time.Minute},
+			Run:  s.RelayOutboxEvents,
+		},
+		{
+			Spec: JobSpec{Name: constant.JobReprocessFailedEvents, Schedule: "@every 1m", BatchSize: 50, Timeout: 2 * time.Minute},
+			Run:  s.ReprocessFailedEvents,
+		},
+		{
+			Spec: JobSpec{Name: constant.JobPruneJobRuns, Schedule: "30 3 * * *", BatchSize: 5000, Timeout: 10 * time.Minute, LeaderOnly: true},
+			Run:  s.PruneJobRuns,
+		},
+	}
+	if s.ReconciliationEnabled {
+		jobs = append(jobs, Job{
+			Spec: JobSpec{Name: constant.JobDailyReconciliation, Schedule: fmt.Sprintf("0 %d * * *", s.ReconciliationRunHour), Timeout: 30 * time.Minute, LeaderOnly: true},
+			Run:  s.RunDailyReconciliation,
+		})
+	}
+	return jobs
+}
 
-			for _, pr := range pendingPaymentRequests {
-				updateErr := s.Database.UpdatePendingPaymentRequest(ctx, pr.ID)
-				if updateErr != nil {
-					log.Logger.Error().Err(updateErr).Int64("payment_request_id", pr.ID).Msg("Failed to update payment_request as pending")
+// SweepExpiredPendingPayments 만료된 PENDING 결제를 최대 BatchSize건 잡아 EXPIRED로 바꾼다.
+// 게이트웨이 체크아웃을 먼저 만료시켜, EXPIRED로 닫은 뒤에 고객이 결제하는 일을 막는다.
+func (s *SchedulerService) SweepExpiredPendingPayments(ctx context.Context, spec JobSpec) (models.JobResult, error) {
+	var result models.JobResult
+	pendingExpiredPayments, err := s.Database.GetExpiredPendingPayments(ctx, s.InstanceID, s.claimLease(), spec.BatchSize)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		log.Logger.Error().Err(err).Msg("Failed to get expired pending payments")
+		return result, err
+	}
+	for _, payment := range pendingExpiredPayments {
+		gateway, err := s.Gateways.Get(payment.Provider)
+		if err != nil {
+			log.Logger.Error().Err(err).Msgf("Unknown payment provider for external_id: %s", payment.ExternalID)
+			result.Failed++
+			continue
+		}
+		// 실패하면 EXPIRED로 바꾸지 않는다. 클레임 리스가 끝나면 다음 실행에서 다시 시도한다.
+		if err := gateway.ExpireCheckout(ctx, payment.InvoiceID, payment.ExternalID); err != nil {
+			log.Logger.Error().Err(err).Int64("payment_id", payment.ID).Msg("Failed to expire checkout at payment gateway")
+			result.Failed++
+			continue
+		}
+		outbox, err := repository.NewPaymentStatusOutboxEvent(payment.OrderID, constant.PaymentStatusExpired, constant.KafkaTopicPaymentExpired)
+		if err != nil {
+			log.Logger.Error().Err(err).Int64("payment_id", payment.ID).Msg("Failed to build payment expired event")
+			result.Failed++
+			continue
+		}
+		// 그 사이 PAID가 됐다면 IllegalTransitionError로 거절되고 outbox도 롤백되어 expired 이벤트가 나가지 않는다.
+		err = s.Database.TransitionPaymentStatus(ctx, models.PaymentStatusTransition{
+			PaymentID: payment.ID,
+			OrderID:   payment.OrderID,
+			ToStatus:  constant.PaymentStatusExpired,
+			Actor:     "expired_sweeper",
+			Reason:    "invoice expired",
+			Outbox:    []models.OutboxEvent{outbox},
+		})
+		if repository.IsIllegalTransition(err) {
+			log.Logger.Info().Int64("order_id", payment.OrderID).Msg("Payment changed before expiry, skipping")
+			continue
+		}
+		if err != nil {
+			log.Logger.Error().Err(err).Int64("payment_id", payment.ID).Msg("Failed to mark payment as expired")
+			result.Failed++
+			continue
+		}
+		result.Processed++
+		s.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+			OrderID:    payment.OrderID,
+			PaymentID:  payment.ID,
+			ExternalID: payment.ExternalID,
+			Event:      "PAYMENT_EXPIRED",
+			Actor:      "expired_sweeper",
+		})
+	}
+	return result, nil
+}
 
-					err := s.Database.UpdateFailedPaymentRequest(ctx, pr.ID, updateErr.Error())
-					if err != nil {
-						log.Logger.Error().Err(err).Int64("payment_request_id", pr.ID).Msg("Failed to update payment_request as failed")
-					}
-					continue
-				}
-				s.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
-					OrderID: pr.OrderID,
-					UserID:  pr.UserID,
-					Event:   "PAYMENT_REQUEST_RETRY",
-					Actor:   "failed_request_processor",
-					Metadata: map[string]any{
-						"retry_count": pr.RetryCount,
-					},
-				})
-			}
+// RetryFailedPaymentRequests 백오프(next_attempt_at)가 끝난 FAILED payment_requests를 최대 BatchSize건 다시 PENDING으로 돌린다.
+// retry_count가 MaxRetries를 넘긴 건은 DEAD로 닫고 payment.request_failed를 발행한다.
+func (s *SchedulerService) RetryFailedPaymentRequests(ctx context.Context, spec JobSpec) (models.JobResult, error) {
+	var result models.JobResult
+	pendingPaymentRequests, err := s.Database.GetFailedPaymentRequests(ctx, s.InstanceID, s.claimLease(), spec.BatchSize, spec.MaxRetries)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get failed payment requests")
+		return result, err
+	}
 
-			time.Sleep(1 * time.Minute)
+	for _, pr := range pendingPaymentRequests {
+		if pr.RetryCount > spec.MaxRetries {
+			s.deadLetterPaymentRequest(ctx, pr, "retry limit exceeded: "+pr.Notes)
+			result.Failed++
+			continue
 		}
-
-	}()
+		updateErr := s.Database.UpdatePendingPaymentRequest(ctx, pr.ID)
+		if updateErr != nil {
+			log.Logger.Error().Err(updateErr).Int64("payment_request_id", pr.ID).Msg("Failed to update payment_request as pending")
+			s.failPaymentRequest(ctx, pr, updateErr.Error(), updateErr)
+			result.Failed++
+			continue
+		}
+		result.Processed++
+		s.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+			OrderID: pr.OrderID,
+			UserID:  pr.UserID,
+			Event:   "PAYMENT_REQUEST_RETRY",
+			Actor:   "failed_request_processor",
+			Metadata: map[string]any{
+				"retry_count": pr.RetryCount,
+			},
+		})
+	}
+	return result, nil
 }
 
-func (s *SchedulerService) StartCheckPendingInvoices() {
-	ticker := time.NewTicker(10 * time.Minute)
-
-	go func() {
-		for range ticker.C {
-			pendingInvoices, err := s.Database.GetPendingInvoices(context.Background())
-			if err != nil {
-				log.Logger.Error().Err(err).Msg("Failed to get pending invoices")
+// CheckPendingInvoices 최근 하루 PENDING 인보이스 최대 BatchSize건의 상태를 게이트웨이에 조회해 PAID 건을 반영한다.
+func (s *SchedulerService) CheckPendingInvoices(ctx context.Context, spec JobSpec) (models.JobResult, error) {
+	var result models.JobResult
+	pendingInvoices, err := s.Database.GetPendingInvoices(ctx, spec.BatchSize)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get pending invoices")
+		return result, err
+	}
+	for _, pendingInvoice := range pendingInvoices {
+		gateway, err := s.Gateways.Get(pendingInvoice.Provider)
+		if err != nil {
+			log.Logger.Error().Err(err).Msgf("Unknown payment provider for external_id: %s", pendingInvoice.ExternalID)
+			result.Failed++
+			continue
+		}
+		invoiceStatus, err := gateway.GetCheckoutStatus(ctx, pendingInvoice.ExternalID)
+		if err != nil {
+			log.Logger.Error().Err(err).Msgf("Failed to check invoice status for external_id: %s", pendingInvoice.ExternalID)
+			result.Failed++
+			continue
+		}
+		if invoiceStatus == constant.PaymentStatusPaid {
+			err = s.PaymentService.ProcessPaymentSuccess(ctx, pendingInvoice.OrderID)
+			if repository.IsIllegalTransition(err) {
+				log.Logger.Warn().Err(err).Int64("order_id", pendingInvoice.OrderID).Msg("Skipping paid invoice, payment is no longer pending")
 				continue
 			}
-			for _, pendingInvoice := range pendingInvoices {
-				invoiceStatus, err := s.Xendit.CheckInvoiceStatus(context.Background(), pendingInvoice.ExternalID)
-				if err != nil {
-					log.Logger.Error().Err(err).Msgf("Failed to check invoice status for external_id: %s", pendingInvoice.ExternalID)
-					continue
-				}
-				if invoiceStatus == constant.PaymentStatusPaid {
-					err = s.PaymentService.ProcessPaymentSuccess(context.Background(), pendingInvoice.OrderID)
-					if err != nil {
-						log.Logger.Error().Err(err).Msgf("Failed to process payment success for order_id: %d", pendingInvoice.OrderID)
-						continue
-					}
-				}
+			if err != nil {
+				log.Logger.Error().Err(err).Msgf("Failed to process payment success for order_id: %d", pendingInvoice.OrderID)
+				result.Failed++
+				continue
 			}
 		}
-	}()
+		result.Processed++
+	}
+	return result, nil
 }
 
-// StartProcessPendingPaymentRequests 주기적으로 PENDING payment_requests를 읽어 인보이스 생성 (배치). 강의 방식: 스케줄러 안에서 직접 DB·Xendit 호출.
-func (s *SchedulerService) StartProcessPendingPaymentRequests() {
-	go func() {
-		for {
-			ctx := context.Background()
+// RunDailyReconciliation 전날 하루치(서버 로컬 자정 기준) Xendit 인보이스를 대사한다.
+// 리더가 바뀌는 사이에 겹쳐도 schedule_key로 하루 한 번만 실행된다. 이미 실행된 날이면 처리 건수 0으로 끝난다.
+func (s *SchedulerService) RunDailyReconciliation(ctx context.Context, spec JobSpec) (models.JobResult, error) {
+	var result models.JobResult
+	now := time.Now()
+	windowEnd := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	windowStart := windowEnd.AddDate(0, 0, -1)
+	run, err := s.Reconciliation.RunScheduledReconciliation(ctx, windowStart, windowEnd, s.ReconciliationAutoFix)
+	if err != nil {
+		log.Logger.Error().Err(err).Time("window_start", windowStart).Msg("Daily reconciliation failed")
+		return result, err
+	}
+	if run != nil {
+		result.Processed = run.InvoiceCount
+	}
+	return result, nil
+}
 
-			// get pending payment requests
-			paymentRequests, err := s.Database.GetPendingPaymentRequests(ctx)
-			if err != nil {
-				log.Logger.Error().Err(err).Msg("s.Database.GetPendingPaymentRequests() got error")
-				time.Sleep(5 * time.Second) // DB 이슈 시 잠시 대기 후 재시도
-				continue
-			}
+// PruneJobRuns 보관 기간이 지난 job_runs를 BatchSize건씩 지운다. 지울 게 남아 있어도 타임아웃이 되면 다음 실행으로 넘긴다.
+func (s *SchedulerService) PruneJobRuns(ctx context.Context, spec JobSpec) (models.JobResult, error) {
+	var result models.JobResult
+	before := time.Now().Add(-s.jobRunRetention())
+	for ctx.Err() == nil {
+		deleted, err := s.Database.DeleteJobRunsBefore(ctx, before, spec.BatchSize)
+		if err != nil {
+			return result, err
+		}
+		result.Processed += int(deleted)
+		if deleted == 0 || deleted < int64(spec.BatchSize) {
+			break
+		}
+	}
+	if result.Processed > 0 {
+		log.Logger.Info().Int("deleted", result.Processed).Time("before", before).Msg("Pruned old job runs")
+	}
+	return result, nil
+}
 
-			// looping list of pending payment requests
-			for _, pr := range paymentRequests {
-				log.Logger.Debug().Int64("order_id", pr.OrderID).Msg("Processing payment request")
+// ProcessPendingPaymentRequests PENDING payment_requests를 최대 BatchSize건 잡아 인보이스 생성 (배치). 강의 방식: 스케줄러 안에서 직접 DB·게이트웨이 호출.
+func (s *SchedulerService) ProcessPendingPaymentRequests(ctx context.Context, spec JobSpec) (models.JobResult, error) {
+	var result models.JobResult
 
-				payerEmail := pr.UserEmail
-				if payerEmail == "" {
-					if s.UserClient == nil {
-						log.Logger.Error().Int64("order_id", pr.OrderID).Msg("User gRPC client is not initialized, skipping")
-						continue
-					}
-					userInfo, err := s.UserClient.GetUserInfoByUserId(ctx, pr.UserID)
-					if err != nil {
-						log.Logger.Error().Err(err).Int64("user_id", pr.UserID).Msg("Failed to get user info via gRPC")
-						if updateErr := s.Database.UpdateFailedPaymentRequest(ctx, pr.ID, "failed to get user email"); updateErr != nil {
-							log.Logger.Error().Err(updateErr).Int64("payment_request_id", pr.ID).Msg("Failed to update payment_request as failed")
-						}
-						continue
-					}
-					payerEmail = userInfo.Email
-					log.Logger.Info().Int64("user_id", pr.UserID).Str("email", payerEmail).Msg("Got user email via gRPC")
-				}
-				xenditReq := models.XenditInvoiceRequest{
-					ExternalID:  fmt.Sprintf("order-%d", pr.OrderID),
-					Amount:      pr.Amount,
-					Description: fmt.Sprintf("[FC] Pembayaran Order %d", pr.OrderID),
-					PayerEmail:  payerEmail,
-				}
+	// get pending payment requests
+	paymentRequests, err := s.Database.GetPendingPaymentRequests(ctx, s.InstanceID, s.claimLease(), spec.BatchSize)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("s.Database.GetPendingPaymentRequests() got error")
+		return result, err
+	}
 
-				// payment가 이미 있는지 확인 (중복 인보이스 방지)
-				paymentInfo, err := s.Database.GetPaymentByOrderID(ctx, pr.OrderID)
-				if err != nil && err != gorm.ErrRecordNotFound {
-					// 실제 DB 에러면 스킵
-					log.Logger.Error().Err(err).Int64("order_id", pr.OrderID).Msg("Failed to get payment by order_id")
-					continue
-				}
+	// looping list of pending payment requests
+	for _, pr := range paymentRequests {
+		log.Logger.Debug().Int64("order_id", pr.OrderID).Msg("Processing payment request")
 
-				// payment가 이미 있으면 (ID != 0)
-				if paymentInfo != nil && paymentInfo.ID != 0 {
-					if paymentInfo.Status == constant.PaymentStatusPaid {
-						log.Logger.Info().Int64("order_id", pr.OrderID).Msg("Payment already paid, skipping")
-					}
-					// 이미 인보이스 있음 → payment_request만 success 처리하고 스킵
-					if err := s.Database.UpdateSuccessPaymentRequest(ctx, pr.ID); err != nil {
-						log.Logger.Error().Err(err).Int64("payment_request_id", pr.ID).Msg("Failed to update payment_request as success")
-					}
-					continue
-				}
+		payerEmail := pr.UserEmail
+		if payerEmail == "" {
+			if s.UserClient == nil {
+				log.Logger.Error().Int64("order_id", pr.OrderID).Msg("User gRPC client is not initialized, skipping")
+				result.Failed++
+				continue
+			}
+			userInfo, err := s.UserClient.GetUserInfoByUserId(ctx, pr.UserID)
+			if err != nil {
+				log.Logger.Error().Err(err).Int64("user_id", pr.UserID).Msg("Failed to get user info via gRPC")
+				s.failPaymentRequest(ctx, pr, "failed to get user email", err)
+				result.Failed++
+				continue
+			}
+			payerEmail = userInfo.Email
+			log.Logger.Info().Int64("user_id", pr.UserID).Str("email", payerEmail).Msg("Got user email via gRPC")
+		}
+		tax, err := s.TaxRule.Apply(pr.Amount, pr.Items)
+		if err != nil {
+			log.Logger.Error().Err(err).Int64("order_id", pr.OrderID).Msg("Failed to apply tax rule")
+			// 금액/통화가 맞지 않는 요청은 다시 시도해도 같은 결과다.
+			s.deadLetterPaymentRequest(ctx, pr, err.Error())
+			result.Failed++
+			continue
+		}
+		checkoutReq := models.CheckoutRequest{
+			ExternalID:  fmt.Sprintf("order-%d", pr.OrderID),
+			Amount:      tax.Total,
+			Description: checkoutDescription(pr.OrderID, tax),
+			PayerEmail:  payerEmail,
+		}
 
-				// payment 없음 (ErrRecordNotFound) → 새로 인보이스 생성
+		// payment가 이미 있는지 확인 (중복 인보이스 방지)
+		paymentInfo, err := s.Database.GetPaymentByOrderID(ctx, pr.OrderID)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			// 실제 DB 에러면 스킵
+			log.Logger.Error().Err(err).Int64("order_id", pr.OrderID).Msg("Failed to get payment by order_id")
+			result.Failed++
+			continue
+		}
 
-				xenditInvoiceInfo, err := s.Xendit.CreateInvoice(ctx, xenditReq)
-				if err != nil {
-					log.Logger.Error().Err(err).Int64("order_id", pr.OrderID).Msg("Failed to create invoice")
-					s.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
-						OrderID: pr.OrderID,
-						UserID:  pr.UserID,
-						Event:   "INVOICE_CREATION_FAILED",
-						Actor:   "pending_request_processor",
-						Metadata: map[string]any{
-							"error": err.Error(),
-						},
-					})
-					if updateErr := s.Database.UpdateFailedPaymentRequest(ctx, pr.ID, err.Error()); updateErr != nil {
-						log.Logger.Error().Err(updateErr).Int64("payment_request_id", pr.ID).Msg("Failed to update payment_request as failed")
-					}
-					continue
-				}
+		// payment가 이미 있으면 (ID != 0)
+		if paymentInfo != nil && paymentInfo.ID != 0 {
+			if paymentInfo.Status == constant.PaymentStatusPaid {
+				log.Logger.Info().Int64("order_id", pr.OrderID).Msg("Payment already paid, skipping")
+			}
+			// 이미 인보이스 있음 → payment_request만 success 처리하고 스킵
+			if err := s.Database.UpdateSuccessPaymentRequest(ctx, pr.ID); err != nil {
+				log.Logger.Error().Err(err).Int64("payment_request_id", pr.ID).Msg("Failed to update payment_request as success")
+			}
+			result.Processed++
+			continue
+		}
 
-				// update status payment request success
-				if err := s.Database.UpdateSuccessPaymentRequest(ctx, pr.ID); err != nil {
-					log.Logger.Error().Err(err).Int64("payment_request_id", pr.ID).Msg("Failed to update payment_request as success")
-				}
+		// payment 없음 (ErrRecordNotFound) → 새로 인보이스 생성
+		var checkout *models.CheckoutResponse
+		gateway, err := s.Gateways.Get(pr.Provider)
+		if err == nil {
+			checkout, err = gateway.CreateCheckout(ctx, checkoutReq)
+		}
+		if err != nil {
+			log.Logger.Error().Err(err).Int64("order_id", pr.OrderID).Msg("Failed to create invoice")
+			s.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+				OrderID: pr.OrderID,
+				UserID:  pr.UserID,
+				Event:   "INVOICE_CREATION_FAILED",
+				Actor:   "pending_request_processor",
+				Metadata: map[string]any{
+					"error": err.Error(),
+				},
+			})
+			s.failPaymentRequest(ctx, pr, err.Error(), err)
+			result.Failed++
+			continue
+		}
 
-				// save data to table 'payments'
-				payment := &models.Payment{
-					OrderID:     pr.OrderID,
-					UserID:      pr.UserID,
-					ExternalID:  xenditReq.ExternalID,
-					Amount:      pr.Amount,
-					Status:      constant.PaymentStatusPending,
-					CreateTime:  time.Now(),
-					ExpiredTime: xenditInvoiceInfo.ExpireDate,
-				}
-				if err := s.Database.SavePayment(ctx, payment); err != nil {
-					log.Logger.Error().Err(err).Int64("order_id", pr.OrderID).Msg("Failed to save payment")
-				} else {
-					s.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
-						OrderID:    pr.OrderID,
-						PaymentID:  payment.ID,
-						UserID:     pr.UserID,
-						ExternalID: xenditReq.ExternalID,
-						Event:      "INVOICE_CREATED",
-						Actor:      "pending_request_processor",
-						Metadata: map[string]any{
-							"amount":     pr.Amount,
-							"invoice_id": xenditInvoiceInfo.ID,
-						},
-					})
-				}
+		// save data to table 'payments'
+		payment := &models.Payment{
+			OrderID:     pr.OrderID,
+			UserID:      pr.UserID,
+			ExternalID:  checkoutReq.ExternalID,
+			InvoiceID:   checkout.ID,
+			InvoiceURL:  checkout.InvoiceURL,
+			Provider:    gateway.Name(),
+			Status:      constant.PaymentStatusPending,
+			CreateTime:  time.Now(),
+			ExpiredTime: checkout.ExpireDate,
+			Items:       pr.Items,
+		}
+		tax.ApplyTo(payment)
+		if err := s.Database.SavePayment(ctx, payment); err != nil {
+			// payment 행이 없으면 요청을 닫지 않고 재시도 대상으로 남긴다.
+			log.Logger.Error().Err(err).Int64("order_id", pr.OrderID).Msg("Failed to save payment")
+			s.failPaymentRequest(ctx, pr, "failed to save payment: "+err.Error(), err)
+			result.Failed++
+			continue
+		}
 
-			}
+		// payment를 저장한 뒤에만 요청을 닫는다. 여기서 실패하면 다음 주기에 기존 payment를 보고 닫는다.
+		if err := s.Database.UpdateSuccessPaymentRequest(ctx, pr.ID); err != nil {
+			log.Logger.Error().Err(err).Int64("payment_request_id", pr.ID).Msg("Failed to update payment_request as success")
+		}
+		result.Processed++
+		s.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+			OrderID:    pr.OrderID,
+			PaymentID:  payment.ID,
+			UserID:     pr.UserID,
+			ExternalID: checkoutReq.ExternalID,
+			Event:      "INVOICE_CREATED",
+			Actor:      "pending_request_processor",
+			Metadata: map[string]any{
+				"amount":     payment.Amount,
+				"invoice_id": checkout.ID,
+				"provider":   gateway.Name(),
+			},
+		})
+	}
+	return result, nil
+}
 
-			time.Sleep(5 * time.Second) // jeda 5 detik per setiap polling
+// ReprocessFailedEvents failed_events의 NeedToCheck/Retry 건을 최대 BatchSize건 재발행한다. 시도 횟수는 MaxFailedEventAttempts로 제한된다.
+func (s *SchedulerService) ReprocessFailedEvents(ctx context.Context, spec JobSpec) (models.JobResult, error) {
+	var result models.JobResult
+	failedEvents, err := s.Database.GetRetryableFailedEvents(ctx, spec.BatchSize)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Failed to get retryable failed events")
+		return result, err
+	}
+	for i := range failedEvents {
+		if err := s.PaymentService.ReprocessFailedEvent(ctx, &failedEvents[i], "failed_event_reprocessor"); err != nil {
+			log.Logger.Warn().Err(err).Int64("failed_event_id", failedEvents[i].ID).Msg("Failed event reprocess attempt failed")
+			result.Failed++
+			continue
 		}
-	}()
+		result.Processed++
+	}
+	return result, nil
 }
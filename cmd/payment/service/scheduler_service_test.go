@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"paymentfc/cmd/payment/repository"
 	"paymentfc/constant"
 	"paymentfc/mocks"
 	"paymentfc/models"
@@ -26,11 +27,12 @@ func createTestSchedulerService(
 ) *SchedulerService {
 	return &SchedulerService{
 		Database:       mockDB,
-		Xendit:         mockXendit,
+		Gateways:       newTestGateways(mockXendit),
 		Publisher:      mockPublisher,
 		PaymentService: mockPaymentService,
 		AuditLog:       mockAuditLog,
 		UserClient:     mockUserClient,
+		InstanceID:     "scheduler-1",
 	}
 }
 
@@ -48,11 +50,11 @@ func TestSchedulerService_ProcessPendingPaymentRequests_Logic(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("skips when no pending requests", func(t *testing.T) {
-		mockDB.EXPECT().GetPendingPaymentRequests(ctx).Return([]models.PaymentRequest{}, nil)
+		mockDB.EXPECT().GetPendingPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 5).Return([]models.PaymentRequest{}, nil)
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		requests, err := scheduler.Database.GetPendingPaymentRequests(ctx)
+		requests, err := scheduler.Database.GetPendingPaymentRequests(ctx, scheduler.InstanceID, scheduler.claimLease(), 5)
 		assert.NoError(t, err)
 		assert.Len(t, requests, 0)
 	})
@@ -62,11 +64,11 @@ func TestSchedulerService_ProcessPendingPaymentRequests_Logic(t *testing.T) {
 			ID:        1,
 			OrderID:   100,
 			UserID:    10,
-			Amount:    50000,
+			Amount:    models.NewMoney(50000, "IDR"),
 			UserEmail: "test@example.com",
 		}
 
-		mockDB.EXPECT().GetPendingPaymentRequests(ctx).Return([]models.PaymentRequest{pr}, nil)
+		mockDB.EXPECT().GetPendingPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 5).Return([]models.PaymentRequest{pr}, nil)
 		mockDB.EXPECT().GetPaymentByOrderID(ctx, pr.OrderID).Return(nil, gorm.ErrRecordNotFound)
 		mockXendit.EXPECT().CreateInvoice(ctx, gomock.Any()).Return(&models.XenditInvoiceResponse{
 			ID:         "inv-1",
@@ -78,27 +80,30 @@ func TestSchedulerService_ProcessPendingPaymentRequests_Logic(t *testing.T) {
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		requests, _ := scheduler.Database.GetPendingPaymentRequests(ctx)
+		requests, _ := scheduler.Database.GetPendingPaymentRequests(ctx, scheduler.InstanceID, scheduler.claimLease(), 5)
 		for _, request := range requests {
 			paymentInfo, err := scheduler.Database.GetPaymentByOrderID(ctx, request.OrderID)
 			if err == gorm.ErrRecordNotFound || (paymentInfo != nil && paymentInfo.ID == 0) {
-				xenditReq := models.XenditInvoiceRequest{
+				checkoutReq := models.CheckoutRequest{
 					ExternalID: "order-100",
 					Amount:     request.Amount,
 					PayerEmail: request.UserEmail,
 				}
-				resp, err := scheduler.Xendit.CreateInvoice(ctx, xenditReq)
+				gateway, err := scheduler.Gateways.Get(request.Provider)
+				assert.NoError(t, err)
+				resp, err := gateway.CreateCheckout(ctx, checkoutReq)
 				if err == nil && resp != nil {
-					scheduler.Database.UpdateSuccessPaymentRequest(ctx, request.ID)
 					payment := &models.Payment{
 						OrderID:     request.OrderID,
 						UserID:      request.UserID,
-						ExternalID:  xenditReq.ExternalID,
+						ExternalID:  checkoutReq.ExternalID,
+						Provider:    gateway.Name(),
 						Amount:      request.Amount,
 						Status:      constant.PaymentStatusPending,
 						ExpiredTime: resp.ExpireDate,
 					}
 					scheduler.Database.SavePayment(ctx, payment)
+					scheduler.Database.UpdateSuccessPaymentRequest(ctx, request.ID)
 					scheduler.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
 						OrderID: request.OrderID,
 						Event:   "INVOICE_CREATED",
@@ -113,18 +118,18 @@ func TestSchedulerService_ProcessPendingPaymentRequests_Logic(t *testing.T) {
 			ID:        2,
 			OrderID:   200,
 			UserID:    20,
-			Amount:    75000,
+			Amount:    models.NewMoney(75000, "IDR"),
 			UserEmail: "",
 		}
 
-		mockDB.EXPECT().GetPendingPaymentRequests(ctx).Return([]models.PaymentRequest{pr}, nil)
+		mockDB.EXPECT().GetPendingPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 5).Return([]models.PaymentRequest{pr}, nil)
 		mockUserClient.EXPECT().GetUserInfoByUserId(ctx, pr.UserID).Return(&pb.GetUserInfoByUserIdResponse{
 			Email: "grpc@example.com",
 		}, nil)
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		requests, _ := scheduler.Database.GetPendingPaymentRequests(ctx)
+		requests, _ := scheduler.Database.GetPendingPaymentRequests(ctx, scheduler.InstanceID, scheduler.claimLease(), 5)
 		for _, request := range requests {
 			payerEmail := request.UserEmail
 			if payerEmail == "" {
@@ -142,7 +147,7 @@ func TestSchedulerService_ProcessPendingPaymentRequests_Logic(t *testing.T) {
 			ID:        3,
 			OrderID:   300,
 			UserID:    30,
-			Amount:    100000,
+			Amount:    models.NewMoney(100000, "IDR"),
 			UserEmail: "test@example.com",
 		}
 
@@ -152,13 +157,13 @@ func TestSchedulerService_ProcessPendingPaymentRequests_Logic(t *testing.T) {
 			Status:  constant.PaymentStatusPending,
 		}
 
-		mockDB.EXPECT().GetPendingPaymentRequests(ctx).Return([]models.PaymentRequest{pr}, nil)
+		mockDB.EXPECT().GetPendingPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 5).Return([]models.PaymentRequest{pr}, nil)
 		mockDB.EXPECT().GetPaymentByOrderID(ctx, pr.OrderID).Return(existingPayment, nil)
 		mockDB.EXPECT().UpdateSuccessPaymentRequest(ctx, pr.ID).Return(nil)
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		requests, _ := scheduler.Database.GetPendingPaymentRequests(ctx)
+		requests, _ := scheduler.Database.GetPendingPaymentRequests(ctx, scheduler.InstanceID, scheduler.claimLease(), 5)
 		for _, request := range requests {
 			paymentInfo, _ := scheduler.Database.GetPaymentByOrderID(ctx, request.OrderID)
 			if paymentInfo != nil && paymentInfo.ID != 0 {
@@ -172,22 +177,22 @@ func TestSchedulerService_ProcessPendingPaymentRequests_Logic(t *testing.T) {
 			ID:        4,
 			OrderID:   400,
 			UserID:    40,
-			Amount:    50000,
+			Amount:    models.NewMoney(50000, "IDR"),
 			UserEmail: "",
 		}
 
-		mockDB.EXPECT().GetPendingPaymentRequests(ctx).Return([]models.PaymentRequest{pr}, nil)
+		mockDB.EXPECT().GetPendingPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 5).Return([]models.PaymentRequest{pr}, nil)
 		mockUserClient.EXPECT().GetUserInfoByUserId(ctx, pr.UserID).Return(nil, errors.New("grpc error"))
-		mockDB.EXPECT().UpdateFailedPaymentRequest(ctx, pr.ID, "failed to get user email").Return(nil)
+		mockDB.EXPECT().UpdateFailedPaymentRequest(ctx, pr.ID, "failed to get user email", gomock.Any()).Return(nil)
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		requests, _ := scheduler.Database.GetPendingPaymentRequests(ctx)
+		requests, _ := scheduler.Database.GetPendingPaymentRequests(ctx, scheduler.InstanceID, scheduler.claimLease(), 5)
 		for _, request := range requests {
 			if request.UserEmail == "" {
 				_, err := scheduler.UserClient.GetUserInfoByUserId(ctx, request.UserID)
 				if err != nil {
-					scheduler.Database.UpdateFailedPaymentRequest(ctx, request.ID, "failed to get user email")
+					scheduler.Database.UpdateFailedPaymentRequest(ctx, request.ID, "failed to get user email", time.Now())
 				}
 			}
 		}
@@ -198,34 +203,36 @@ func TestSchedulerService_ProcessPendingPaymentRequests_Logic(t *testing.T) {
 			ID:        5,
 			OrderID:   500,
 			UserID:    50,
-			Amount:    50000,
+			Amount:    models.NewMoney(50000, "IDR"),
 			UserEmail: "test@example.com",
 		}
 
-		mockDB.EXPECT().GetPendingPaymentRequests(ctx).Return([]models.PaymentRequest{pr}, nil)
+		mockDB.EXPECT().GetPendingPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 5).Return([]models.PaymentRequest{pr}, nil)
 		mockDB.EXPECT().GetPaymentByOrderID(ctx, pr.OrderID).Return(nil, gorm.ErrRecordNotFound)
 		mockXendit.EXPECT().CreateInvoice(ctx, gomock.Any()).Return(nil, errors.New("xendit api error"))
 		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
-		mockDB.EXPECT().UpdateFailedPaymentRequest(ctx, pr.ID, "xendit api error").Return(nil)
+		mockDB.EXPECT().UpdateFailedPaymentRequest(ctx, pr.ID, "xendit api error", gomock.Any()).Return(nil)
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		requests, _ := scheduler.Database.GetPendingPaymentRequests(ctx)
+		requests, _ := scheduler.Database.GetPendingPaymentRequests(ctx, scheduler.InstanceID, scheduler.claimLease(), 5)
 		for _, request := range requests {
 			paymentInfo, err := scheduler.Database.GetPaymentByOrderID(ctx, request.OrderID)
 			if err == gorm.ErrRecordNotFound || (paymentInfo == nil) {
-				xenditReq := models.XenditInvoiceRequest{
+				checkoutReq := models.CheckoutRequest{
 					ExternalID: "order-500",
 					Amount:     request.Amount,
 					PayerEmail: request.UserEmail,
 				}
-				_, invoiceErr := scheduler.Xendit.CreateInvoice(ctx, xenditReq)
+				gateway, err := scheduler.Gateways.Get(request.Provider)
+				assert.NoError(t, err)
+				_, invoiceErr := gateway.CreateCheckout(ctx, checkoutReq)
 				if invoiceErr != nil {
 					scheduler.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
 						OrderID: request.OrderID,
 						Event:   "INVOICE_CREATION_FAILED",
 					})
-					scheduler.Database.UpdateFailedPaymentRequest(ctx, request.ID, invoiceErr.Error())
+					scheduler.Database.UpdateFailedPaymentRequest(ctx, request.ID, invoiceErr.Error(), time.Now())
 				}
 			}
 		}
@@ -251,7 +258,7 @@ func TestSchedulerService_ProcessFailedPaymentRequests_Logic(t *testing.T) {
 			{ID: 2, OrderID: 200, UserID: 20, RetryCount: 2},
 		}
 
-		mockDB.EXPECT().GetFailedPaymentRequests(ctx).Return(failedRequests, nil)
+		mockDB.EXPECT().GetFailedPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 5, 3).Return(failedRequests, nil)
 		mockDB.EXPECT().UpdatePendingPaymentRequest(ctx, int64(1)).Return(nil)
 		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
 		mockDB.EXPECT().UpdatePendingPaymentRequest(ctx, int64(2)).Return(nil)
@@ -259,7 +266,7 @@ func TestSchedulerService_ProcessFailedPaymentRequests_Logic(t *testing.T) {
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		requests, _ := scheduler.Database.GetFailedPaymentRequests(ctx)
+		requests, _ := scheduler.Database.GetFailedPaymentRequests(ctx, scheduler.InstanceID, scheduler.claimLease(), 5, 3)
 		for _, pr := range requests {
 			err := scheduler.Database.UpdatePendingPaymentRequest(ctx, pr.ID)
 			if err == nil {
@@ -276,17 +283,17 @@ func TestSchedulerService_ProcessFailedPaymentRequests_Logic(t *testing.T) {
 			{ID: 3, OrderID: 300, UserID: 30, RetryCount: 3},
 		}
 
-		mockDB.EXPECT().GetFailedPaymentRequests(ctx).Return(failedRequests, nil)
+		mockDB.EXPECT().GetFailedPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 5, 3).Return(failedRequests, nil)
 		mockDB.EXPECT().UpdatePendingPaymentRequest(ctx, int64(3)).Return(errors.New("db error"))
-		mockDB.EXPECT().UpdateFailedPaymentRequest(ctx, int64(3), "db error").Return(nil)
+		mockDB.EXPECT().UpdateFailedPaymentRequest(ctx, int64(3), "db error", gomock.Any()).Return(nil)
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		requests, _ := scheduler.Database.GetFailedPaymentRequests(ctx)
+		requests, _ := scheduler.Database.GetFailedPaymentRequests(ctx, scheduler.InstanceID, scheduler.claimLease(), 5, 3)
 		for _, pr := range requests {
 			err := scheduler.Database.UpdatePendingPaymentRequest(ctx, pr.ID)
 			if err != nil {
-				scheduler.Database.UpdateFailedPaymentRequest(ctx, pr.ID, err.Error())
+				scheduler.Database.UpdateFailedPaymentRequest(ctx, pr.ID, err.Error(), time.Now())
 			}
 		}
 	})
@@ -311,17 +318,27 @@ func TestSchedulerService_SweepExpiredPayments_Logic(t *testing.T) {
 			{ID: 2, OrderID: 200, ExternalID: "order-200", Status: constant.PaymentStatusPending},
 		}
 
-		mockDB.EXPECT().GetExpiredPendingPayments(ctx).Return(expiredPayments, nil)
-		mockDB.EXPECT().MarkExpired(ctx, int64(1)).Return(nil)
+		mockDB.EXPECT().GetExpiredPendingPayments(ctx, "scheduler-1", defaultClaimLease, 100).Return(expiredPayments, nil)
+		mockDB.EXPECT().TransitionPaymentStatus(ctx, models.PaymentStatusTransition{
+			PaymentID: 1, OrderID: 100, ToStatus: constant.PaymentStatusExpired, Actor: "expired_sweeper", Reason: "invoice expired",
+		}).Return(nil)
 		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
-		mockDB.EXPECT().MarkExpired(ctx, int64(2)).Return(nil)
+		mockDB.EXPECT().TransitionPaymentStatus(ctx, models.PaymentStatusTransition{
+			PaymentID: 2, OrderID: 200, ToStatus: constant.PaymentStatusExpired, Actor: "expired_sweeper", Reason: "invoice expired",
+		}).Return(nil)
 		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		payments, _ := scheduler.Database.GetExpiredPendingPayments(ctx)
+		payments, _ := scheduler.Database.GetExpiredPendingPayments(ctx, scheduler.InstanceID, scheduler.claimLease(), 100)
 		for _, payment := range payments {
-			err := scheduler.Database.MarkExpired(ctx, payment.ID)
+			err := scheduler.Database.TransitionPaymentStatus(ctx, models.PaymentStatusTransition{
+				PaymentID: payment.ID,
+				OrderID:   payment.OrderID,
+				ToStatus:  constant.PaymentStatusExpired,
+				Actor:     "expired_sweeper",
+				Reason:    "invoice expired",
+			})
 			if err == nil {
 				scheduler.AuditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
 					OrderID:    payment.OrderID,
@@ -334,16 +351,75 @@ func TestSchedulerService_SweepExpiredPayments_Logic(t *testing.T) {
 	})
 
 	t.Run("handles no expired payments", func(t *testing.T) {
-		mockDB.EXPECT().GetExpiredPendingPayments(ctx).Return([]models.Payment{}, nil)
+		mockDB.EXPECT().GetExpiredPendingPayments(ctx, "scheduler-1", defaultClaimLease, 100).Return([]models.Payment{}, nil)
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		payments, err := scheduler.Database.GetExpiredPendingPayments(ctx)
+		payments, err := scheduler.Database.GetExpiredPendingPayments(ctx, scheduler.InstanceID, scheduler.claimLease(), 100)
 		assert.NoError(t, err)
 		assert.Len(t, payments, 0)
 	})
 }
 
+func TestSchedulerService_SweepExpiredPendingPayments_ExpiresCheckoutFirst(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockXendit := mocks.NewMockXenditClient(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mocks.NewMockPaymentEventPublisher(ctrl), NewMockPaymentService(ctrl), mockAuditLog, mocks.NewMockUserClientInterface(ctrl))
+
+	ctx := context.Background()
+	spec := JobSpec{Name: constant.JobSweepExpiredPayments, BatchSize: 100}
+
+	mockDB.EXPECT().GetExpiredPendingPayments(ctx, "scheduler-1", defaultClaimLease, 100).Return([]models.Payment{
+		{ID: 1, OrderID: 100, ExternalID: "order-100", InvoiceID: "inv-100", Provider: constant.PaymentProviderXendit, Status: constant.PaymentStatusPending},
+		{ID: 2, OrderID: 200, ExternalID: "order-200", InvoiceID: "inv-200", Provider: constant.PaymentProviderXendit, Status: constant.PaymentStatusPending},
+	}, nil)
+	gomock.InOrder(
+		mockXendit.EXPECT().ExpireInvoice(ctx, "inv-100").Return(nil),
+		mockDB.EXPECT().TransitionPaymentStatus(ctx, gomock.Any()).DoAndReturn(
+			func(_ context.Context, param models.PaymentStatusTransition) error {
+				assert.Equal(t, int64(1), param.PaymentID)
+				assert.Equal(t, constant.PaymentStatusExpired, param.ToStatus)
+				return nil
+			}),
+	)
+	mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
+	// 게이트웨이에서 만료시키지 못한 건은 EXPIRED로 바꾸지 않는다.
+	mockXendit.EXPECT().ExpireInvoice(ctx, "inv-200").Return(&repository.GatewayStatusError{API: "xendit API", StatusCode: 503})
+
+	result, err := scheduler.SweepExpiredPendingPayments(ctx, spec)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobResult{Processed: 1, Failed: 1}, result)
+}
+
+func TestSchedulerService_PruneJobRuns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	scheduler := createTestSchedulerService(ctrl, mockDB, mocks.NewMockXenditClient(ctrl), mocks.NewMockPaymentEventPublisher(ctrl), NewMockPaymentService(ctrl), mocks.NewMockAuditLogRepository(ctrl), mocks.NewMockUserClientInterface(ctrl))
+	scheduler.JobRunRetention = 7 * 24 * time.Hour
+
+	ctx := context.Background()
+	spec := JobSpec{Name: constant.JobPruneJobRuns, BatchSize: 2}
+
+	// 배치가 가득 차면 덜 찬 배치가 나올 때까지 계속 지운다.
+	gomock.InOrder(
+		mockDB.EXPECT().DeleteJobRunsBefore(ctx, gomock.Any(), 2).DoAndReturn(func(_ context.Context, before time.Time, _ int) (int64, error) {
+			assert.WithinDuration(t, time.Now().Add(-7*24*time.Hour), before, time.Minute)
+			return 2, nil
+		}),
+		mockDB.EXPECT().DeleteJobRunsBefore(ctx, gomock.Any(), 2).Return(int64(1), nil),
+	)
+
+	result, err := scheduler.PruneJobRuns(ctx, spec)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobResult{Processed: 3}, result)
+}
+
 func TestSchedulerService_CheckPendingInvoices_Logic(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -362,15 +438,17 @@ func TestSchedulerService_CheckPendingInvoices_Logic(t *testing.T) {
 			{ID: 1, OrderID: 100, ExternalID: "order-100", Status: constant.PaymentStatusPending},
 		}
 
-		mockDB.EXPECT().GetPendingInvoices(ctx).Return(pendingInvoices, nil)
+		mockDB.EXPECT().GetPendingInvoices(ctx, 100).Return(pendingInvoices, nil)
 		mockXendit.EXPECT().CheckInvoiceStatus(ctx, "order-100").Return(constant.PaymentStatusPaid, nil)
 		mockPaymentService.EXPECT().ProcessPaymentSuccess(ctx, int64(100)).Return(nil)
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		invoices, _ := scheduler.Database.GetPendingInvoices(ctx)
+		invoices, _ := scheduler.Database.GetPendingInvoices(ctx, 100)
 		for _, invoice := range invoices {
-			status, err := scheduler.Xendit.CheckInvoiceStatus(ctx, invoice.ExternalID)
+			gateway, err := scheduler.Gateways.Get(invoice.Provider)
+			assert.NoError(t, err)
+			status, err := gateway.GetCheckoutStatus(ctx, invoice.ExternalID)
 			if err == nil && status == constant.PaymentStatusPaid {
 				scheduler.PaymentService.ProcessPaymentSuccess(ctx, invoice.OrderID)
 			}
@@ -382,16 +460,209 @@ func TestSchedulerService_CheckPendingInvoices_Logic(t *testing.T) {
 			{ID: 2, OrderID: 200, ExternalID: "order-200", Status: constant.PaymentStatusPending},
 		}
 
-		mockDB.EXPECT().GetPendingInvoices(ctx).Return(pendingInvoices, nil)
+		mockDB.EXPECT().GetPendingInvoices(ctx, 100).Return(pendingInvoices, nil)
 		mockXendit.EXPECT().CheckInvoiceStatus(ctx, "order-200").Return(constant.PaymentStatusPending, nil)
 
 		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mockPublisher, mockPaymentService, mockAuditLog, mockUserClient)
 
-		invoices, _ := scheduler.Database.GetPendingInvoices(ctx)
+		invoices, _ := scheduler.Database.GetPendingInvoices(ctx, 100)
 		for _, invoice := range invoices {
-			status, err := scheduler.Xendit.CheckInvoiceStatus(ctx, invoice.ExternalID)
+			gateway, err := scheduler.Gateways.Get(invoice.Provider)
+			assert.NoError(t, err)
+			status, err := gateway.GetCheckoutStatus(ctx, invoice.ExternalID)
 			assert.NoError(t, err)
 			assert.NotEqual(t, constant.PaymentStatusPaid, status)
 		}
 	})
 }
+
+func TestSchedulerService_RetryFailedPaymentRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	scheduler := createTestSchedulerService(ctrl, mockDB, mocks.NewMockXenditClient(ctrl), mocks.NewMockPaymentEventPublisher(ctrl), NewMockPaymentService(ctrl), mockAuditLog, mocks.NewMockUserClientInterface(ctrl))
+
+	ctx := context.Background()
+	spec := JobSpec{Name: constant.JobRetryFailedPaymentRequests, BatchSize: 10, MaxRetries: 5}
+
+	// 배치 크기와 재시도 한도는 하드코딩이 아니라 잡 설정에서 온다.
+	mockDB.EXPECT().GetFailedPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 10, 5).Return([]models.PaymentRequest{
+		{ID: 7, OrderID: 700, RetryCount: 1},
+		{ID: 8, OrderID: 800, RetryCount: 5},
+		{ID: 9, OrderID: 900, UserID: 90, RetryCount: 6, Notes: "xendit API returned status: 503"},
+	}, nil)
+	mockDB.EXPECT().UpdatePendingPaymentRequest(ctx, int64(7)).Return(nil)
+	mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
+	mockDB.EXPECT().UpdatePendingPaymentRequest(ctx, int64(8)).Return(errors.New("db error"))
+	mockDB.EXPECT().UpdateFailedPaymentRequest(ctx, int64(8), "db error", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, _ string, nextAttemptAt time.Time) error {
+			assert.True(t, nextAttemptAt.After(time.Now()))
+			return nil
+		})
+	// 재시도 한도를 넘긴 건은 PENDING으로 돌리지 않고 DEAD로 닫는다.
+	mockDB.EXPECT().DeadLetterPaymentRequest(ctx, int64(9), "retry limit exceeded: xendit API returned status: 503", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, _ string, event models.OutboxEvent) error {
+			assert.Equal(t, constant.KafkaTopicPaymentRequestFailed, event.EventType)
+			assert.Equal(t, int64(900), event.OrderID)
+			assert.Contains(t, event.Payload, `"status":"DEAD"`)
+			return nil
+		})
+	mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
+
+	result, err := scheduler.RetryFailedPaymentRequests(ctx, spec)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobResult{Processed: 1, Failed: 2}, result)
+}
+
+func TestSchedulerService_ProcessPendingPaymentRequests_ClassifiesFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	spec := JobSpec{Name: constant.JobProcessPaymentRequests, BatchSize: 5}
+	pr := models.PaymentRequest{ID: 11, OrderID: 1100, UserID: 110, Amount: models.NewMoney(50000, "IDR"), UserEmail: "test@example.com", RetryCount: 1}
+
+	t.Run("gateway 5xx is retried with backoff", func(t *testing.T) {
+		mockDB := mocks.NewMockPaymentDatabase(ctrl)
+		mockXendit := mocks.NewMockXenditClient(ctrl)
+		mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mocks.NewMockPaymentEventPublisher(ctrl), NewMockPaymentService(ctrl), mockAuditLog, mocks.NewMockUserClientInterface(ctrl))
+
+		mockDB.EXPECT().GetPendingPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 5).Return([]models.PaymentRequest{pr}, nil)
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, pr.OrderID).Return(nil, gorm.ErrRecordNotFound)
+		mockXendit.EXPECT().CreateInvoice(ctx, gomock.Any()).Return(nil, &repository.GatewayStatusError{API: "xendit API", StatusCode: 502})
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil)
+		mockDB.EXPECT().UpdateFailedPaymentRequest(ctx, pr.ID, "xendit API returned status: 502", gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ int64, _ string, nextAttemptAt time.Time) error {
+				// 두 번째 실패이므로 1~2분 뒤
+				assert.WithinRange(t, nextAttemptAt, time.Now().Add(time.Minute-time.Second), time.Now().Add(2*time.Minute))
+				return nil
+			})
+
+		result, err := scheduler.ProcessPendingPaymentRequests(ctx, spec)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Failed)
+	})
+
+	t.Run("gateway 4xx goes straight to DEAD", func(t *testing.T) {
+		mockDB := mocks.NewMockPaymentDatabase(ctrl)
+		mockXendit := mocks.NewMockXenditClient(ctrl)
+		mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mocks.NewMockPaymentEventPublisher(ctrl), NewMockPaymentService(ctrl), mockAuditLog, mocks.NewMockUserClientInterface(ctrl))
+
+		mockDB.EXPECT().GetPendingPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 5).Return([]models.PaymentRequest{pr}, nil)
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, pr.OrderID).Return(nil, gorm.ErrRecordNotFound)
+		mockXendit.EXPECT().CreateInvoice(ctx, gomock.Any()).Return(nil, &repository.GatewayStatusError{API: "xendit API", StatusCode: 400})
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).Return(nil).Times(2)
+		mockDB.EXPECT().DeadLetterPaymentRequest(ctx, pr.ID, "xendit API returned status: 400", gomock.Any()).Return(nil)
+
+		result, err := scheduler.ProcessPendingPaymentRequests(ctx, spec)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Failed)
+	})
+
+	t.Run("payment save failure keeps request retryable", func(t *testing.T) {
+		mockDB := mocks.NewMockPaymentDatabase(ctrl)
+		mockXendit := mocks.NewMockXenditClient(ctrl)
+		scheduler := createTestSchedulerService(ctrl, mockDB, mockXendit, mocks.NewMockPaymentEventPublisher(ctrl), NewMockPaymentService(ctrl), mocks.NewMockAuditLogRepository(ctrl), mocks.NewMockUserClientInterface(ctrl))
+
+		mockDB.EXPECT().GetPendingPaymentRequests(ctx, "scheduler-1", defaultClaimLease, 5).Return([]models.PaymentRequest{pr}, nil)
+		mockDB.EXPECT().GetPaymentByOrderID(ctx, pr.OrderID).Return(nil, gorm.ErrRecordNotFound)
+		mockXendit.EXPECT().CreateInvoice(ctx, gomock.Any()).Return(&models.XenditInvoiceResponse{ID: "inv-11", ExpireDate: time.Now().Add(24 * time.Hour)}, nil)
+		mockDB.EXPECT().SavePayment(ctx, gomock.Any()).Return(errors.New("connection reset"))
+		// UpdateSuccessPaymentRequest가 호출되면 gomock이 실패시킨다.
+		mockDB.EXPECT().UpdateFailedPaymentRequest(ctx, pr.ID, "failed to save payment: connection reset", gomock.Any()).Return(nil)
+
+		result, err := scheduler.ProcessPendingPaymentRequests(ctx, spec)
+		assert.NoError(t, err)
+		assert.Equal(t, models.JobResult{Failed: 1}, result)
+	})
+}
+
+func TestSchedulerService_Jobs(t *testing.T) {
+	names := func(jobs []Job) []string {
+		var result []string
+		for _, job := range jobs {
+			result = append(result, job.Spec.Name)
+		}
+		return result
+	}
+
+	s := &SchedulerService{}
+	assert.NotContains(t, names(s.Jobs()), constant.JobDailyReconciliation)
+	assert.Contains(t, names(s.Jobs()), constant.JobRelayOutboxEvents)
+	assert.Contains(t, names(s.Jobs()), constant.JobReprocessFailedEvents)
+
+	s = &SchedulerService{ReconciliationEnabled: true, ReconciliationRunHour: 2}
+	jobs := s.Jobs()
+	last := jobs[len(jobs)-1]
+	assert.Equal(t, constant.JobDailyReconciliation, last.Spec.Name)
+	assert.Equal(t, "0 2 * * *", last.Spec.Schedule)
+	assert.True(t, last.Spec.LeaderOnly)
+}
+
+func TestSchedulerService_ReprocessFailedEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockPaymentService := NewMockPaymentService(ctrl)
+	s := createTestSchedulerService(ctrl, mockDB, mocks.NewMockXenditClient(ctrl), mocks.NewMockPaymentEventPublisher(ctrl), mockPaymentService, mocks.NewMockAuditLogRepository(ctrl), mocks.NewMockUserClientInterface(ctrl))
+	ctx := context.Background()
+
+	mockDB.EXPECT().GetRetryableFailedEvents(ctx, 7).Return([]models.FailedEvent{{ID: 1}, {ID: 2}}, nil)
+	mockPaymentService.EXPECT().ReprocessFailedEvent(ctx, gomock.Any(), "failed_event_reprocessor").DoAndReturn(func(_ context.Context, ev *models.FailedEvent, _ string) error {
+		if ev.ID == 2 {
+			return errors.New("kafka unavailable")
+		}
+		return nil
+	}).Times(2)
+
+	result, err := s.ReprocessFailedEvents(ctx, JobSpec{Name: constant.JobReprocessFailedEvents, BatchSize: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobResult{Processed: 1, Failed: 1}, result)
+
+	mockDB.EXPECT().GetRetryableFailedEvents(ctx, 7).Return(nil, errors.New("db down"))
+	_, err = s.ReprocessFailedEvents(ctx, JobSpec{Name: constant.JobReprocessFailedEvents, BatchSize: 7})
+	assert.Error(t, err)
+}
+
+// fakeReconciliationService RunScheduledReconciliation만 기록한다.
+type fakeReconciliationService struct {
+	ReconciliationService
+	windowStart, windowEnd time.Time
+	autoFix                bool
+	run                    *models.ReconciliationRun
+}
+
+func (f *fakeReconciliationService) RunScheduledReconciliation(_ context.Context, windowStart, windowEnd time.Time, autoFix bool) (*models.ReconciliationRun, error) {
+	f.windowStart, f.windowEnd, f.autoFix = windowStart, windowEnd, autoFix
+	return f.run, nil
+}
+
+func TestSchedulerService_RunDailyReconciliation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reconciles the previous local day", func(t *testing.T) {
+		recon := &fakeReconciliationService{run: &models.ReconciliationRun{InvoiceCount: 12}}
+		s := &SchedulerService{Reconciliation: recon, ReconciliationAutoFix: true}
+
+		result, err := s.RunDailyReconciliation(ctx, JobSpec{Name: constant.JobDailyReconciliation})
+		assert.NoError(t, err)
+		assert.Equal(t, 12, result.Processed)
+
+		now := time.Now()
+		assert.Equal(t, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), recon.windowEnd)
+		assert.Equal(t, recon.windowEnd.AddDate(0, 0, -1), recon.windowStart)
+		assert.True(t, recon.autoFix)
+	})
+
+	t.Run("already reconciled day is idle", func(t *testing.T) {
+		s := &SchedulerService{Reconciliation: &fakeReconciliationService{}}
+		result, err := s.RunDailyReconciliation(ctx, JobSpec{Name: constant.JobDailyReconciliation})
+		assert.NoError(t, err)
+		assert.Equal(t, models.JobResult{}, result)
+	})
+}
@@ -2,8 +2,6 @@ package service
 
 import (
 	"context"
-	"fmt"
-	"math"
 	"paymentfc/cmd/payment/repository"
 	"paymentfc/constant"
 	"paymentfc/log"
@@ -15,15 +13,25 @@ type PaymentService interface {
 	ProcessPaymentSuccess(ctx context.Context, orderID int64) error
 	ProcessPaymentFailed(ctx context.Context, orderID int64) error
 	IsAlreadyPaid(ctx context.Context, orderID int64) (bool, error)
-	GetAmountByOrderID(ctx context.Context, orderID int64) (float64, error)
+	GetAmountByOrderID(ctx context.Context, orderID int64) (models.Money, error)
 	SavePaymentAnomaly(ctx context.Context, param *models.PaymentAnomaly) error
 	SaveFailedPublishEvent(ctx context.Context, param *models.FailedEvent) error
 	GetPaymentByOrderID(ctx context.Context, orderID int64) (*models.Payment, error)
+	GetUserPayment(ctx context.Context, userID, orderID int64) (*models.Payment, error)
+	ListUserPayments(ctx context.Context, filter models.PaymentFilter) (models.PaymentPage, error)
 	SavePaymentRequestFromEvent(ctx context.Context, event models.OrderCreatedEvent) error
-	GetFailedPaymentList(ctx context.Context) ([]models.PaymentRequest, error)
+	GetFailedPaymentList(ctx context.Context, maxRetries, limit int) ([]models.PaymentRequest, error)
 	ListAuditLogs(ctx context.Context, filter models.AuditLogFilter) (models.AuditLogPage, error)
 	GetAuditDailyReport(ctx context.Context, from, to time.Time) ([]models.AuditDailyReportItem, error)
 	WatchAuditInsertStream(ctx context.Context, out chan<- models.PaymentAuditLog) error
+	SaveWebhookEvent(ctx context.Context, param *models.WebhookEvent) (bool, error)
+	ClaimWebhookEvents(ctx context.Context, limit int) ([]models.WebhookEvent, error)
+	ClaimWebhookEventForReplay(ctx context.Context, id int64, requestedBy int64) (*models.WebhookEvent, error)
+	CompleteWebhookEvent(ctx context.Context, event *models.WebhookEvent, processErr error, retryable bool) error
+	ListFailedEvents(ctx context.Context, filter models.FailedEventFilter) (models.FailedEventPage, error)
+	RetryFailedEvent(ctx context.Context, id int64, requestedBy int64) (*models.FailedEvent, error)
+	CloseFailedEvent(ctx context.Context, id int64, requestedBy int64, reason string) (*models.FailedEvent, error)
+	ReprocessFailedEvent(ctx context.Context, event *models.FailedEvent, actor string) error
 }
 
 type paymentService struct {
@@ -52,45 +60,27 @@ func (s *paymentService) ProcessPaymentSuccess(ctx context.Context, orderID int6
 		return nil
 	}
 
-	// publish event kafka
-	err = retryPublishPayment(constant.MaxRetryPublish, func() error {
-		if err := s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
-			OrderID: orderID,
-			Event:   "PUBLISH_PAYMENT_SUCCESS",
-			Actor:   "payment",
-		}); err != nil {
-			log.Logger.Error().Err(err).Int64("order_id", orderID).Msg("Failed to save audit log")
-		}
-		return s.publisher.PublishPaymentStatus(ctx, orderID, constant.PaymentStatusPaid, "payment.success")
-	})
+	// 상태 전이가 거절되면(EXPIRED/FAILED 등) outbox도 함께 롤백되어 payment.success가 발행되지 않는다.
+	outbox, err := repository.NewPaymentStatusOutboxEvent(orderID, constant.PaymentStatusPaid, constant.KafkaTopicPaymentSuccess)
 	if err != nil {
-		log.Logger.Error().Err(err).Int64("order_id", orderID).Msg("s.publisher.PublishPaymentStatus() got error")
-		failed := &models.FailedEvent{
-			OrderID:    orderID,
-			ExternalID: fmt.Sprintf("order-%d", orderID),
-			FailedType: constant.FailedPublishEventPaymentSuccess,
-			Notes:      err.Error(),
-			Status:     constant.FailedPublishEventStatusNeedToCheck,
-			UpdateTime: time.Now(),
-		}
-		if saveErr := s.database.SaveFailedPublishEvent(ctx, failed); saveErr != nil {
-			log.Logger.Error().Err(saveErr).Int64("order_id", orderID).Msg("Failed to save failed_event")
-		}
 		return err
 	}
-
-	err = s.database.MarkPaid(orderID)
+	err = s.database.TransitionPaymentStatus(ctx, models.PaymentStatusTransition{
+		OrderID:  orderID,
+		ToStatus: constant.PaymentStatusPaid,
+		Actor:    "payment",
+		Reason:   "payment success confirmed",
+		Outbox:   []models.OutboxEvent{outbox},
+	})
 	if err != nil {
 		return err
-	} else {
-		err := s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
-			OrderID: orderID,
-			Event:   "MARK_PAID",
-			Actor:   "payment",
-		})
-		if err != nil {
-			log.Logger.Error().Err(err).Int64("order_id", orderID).Msg("Failed to save audit log")
-		}
+	}
+	if err := s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+		OrderID: orderID,
+		Event:   "MARK_PAID",
+		Actor:   "payment",
+	}); err != nil {
+		log.Logger.Error().Err(err).Int64("order_id", orderID).Msg("Failed to save audit log")
 	}
 
 	return nil
@@ -105,34 +95,28 @@ func (s *paymentService) ProcessPaymentFailed(ctx context.Context, orderID int64
 		return nil
 	}
 
-	err = retryPublishPayment(constant.MaxRetryPublish, func() error {
-		if err := s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
-			OrderID: orderID,
-			Event:   "PUBLISH_PAYMENT_FAILED",
-			Actor:   "payment",
-		}); err != nil {
-			log.Logger.Error().Err(err).Int64("order_id", orderID).Msg("Failed to save audit log")
-		}
-		return s.publisher.PublishPaymentStatus(ctx, orderID, constant.PaymentStatusFailed, "payment.failed")
-	})
+	outbox, err := repository.NewPaymentStatusOutboxEvent(orderID, constant.PaymentStatusFailed, constant.KafkaTopicPaymentFailed)
 	if err != nil {
 		return err
 	}
-	err = s.database.MarkFailed(orderID)
-	if err != nil {
-		return err
-	}
-	return nil
+	return s.database.TransitionPaymentStatus(ctx, models.PaymentStatusTransition{
+		PaymentID: paymentInfo.ID,
+		OrderID:   orderID,
+		ToStatus:  constant.PaymentStatusFailed,
+		Actor:     "payment",
+		Reason:    "payment failed",
+		Outbox:    []models.OutboxEvent{outbox},
+	})
 }
 
 func (s *paymentService) IsAlreadyPaid(ctx context.Context, orderID int64) (bool, error) {
 	return s.database.IsAlreadyPaid(ctx, orderID)
 }
 
-func (s *paymentService) GetAmountByOrderID(ctx context.Context, orderID int64) (float64, error) {
+func (s *paymentService) GetAmountByOrderID(ctx context.Context, orderID int64) (models.Money, error) {
 	payment, err := s.database.GetPaymentByOrderID(ctx, orderID)
 	if err != nil {
-		return 0, err
+		return models.Money{}, err
 	}
 	return payment.Amount, nil
 }
@@ -171,31 +155,26 @@ func (s *paymentService) SaveFailedPublishEvent(ctx context.Context, param *mode
 	return nil
 }
 
-// RetryPublishPayment runs fn up to max times with exponential backoff (2^i seconds); returns nil on first success or the last error.
-func retryPublishPayment(max int, fn func() error) error {
-	var err error
-	for i := 0; i < max; i++ {
-		err = fn()
-		if err == nil {
-			return nil
-		}
-		wait := time.Duration(math.Pow(2, float64(i))) * time.Second
-		log.Logger.Warn().Err(err).Int("retry", i+1).Str("wait", wait.String()).Msg("Kafka publish failed, retrying")
-		if i < max-1 {
-			time.Sleep(wait)
-		}
-	}
-	return err
-}
-
 func (s *paymentService) SavePaymentRequestFromEvent(ctx context.Context, event models.OrderCreatedEvent) error {
+	amount, err := event.Total()
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("order_id", event.OrderID).Msg("Invalid total_amount in event")
+		return err
+	}
+	items, err := event.LineItems()
+	if err != nil {
+		log.Logger.Error().Err(err).Int64("order_id", event.OrderID).Msg("Invalid products in event")
+		return err
+	}
 	pr := &models.PaymentRequest{
 		OrderID:    event.OrderID,
 		UserID:     event.UserID,
-		Amount:     event.TotalAmount,
+		Amount:     amount,
 		UserEmail:  "",
+		Provider:   event.Provider,
 		Status:     constant.PaymentStatusPending,
 		RetryCount: 0,
+		Items:      items,
 	}
 	if err := s.database.SavePaymentRequest(ctx, pr); err != nil {
 		return err
@@ -207,7 +186,7 @@ func (s *paymentService) SavePaymentRequestFromEvent(ctx context.Context, event
 		Event:   "PAYMENT_REQUEST_CREATED",
 		Actor:   "order_consumer",
 		Metadata: map[string]any{
-			"amount": event.TotalAmount,
+			"amount": amount,
 		},
 	})
 
@@ -219,8 +198,25 @@ func (s *paymentService) GetPaymentByOrderID(ctx context.Context, orderID int64)
 	return s.database.GetPaymentByOrderID(ctx, orderID)
 }
 
-func (s *paymentService) GetFailedPaymentList(ctx context.Context) ([]models.PaymentRequest, error) {
-	return s.database.GetFailedPaymentList(ctx)
+// GetUserPayment 본인 결제만 돌려준다. 다른 사용자의 주문은 gorm.ErrRecordNotFound.
+func (s *paymentService) GetUserPayment(ctx context.Context, userID, orderID int64) (*models.Payment, error) {
+	return s.database.GetUserPaymentByOrderID(ctx, userID, orderID)
+}
+
+func (s *paymentService) ListUserPayments(ctx context.Context, filter models.PaymentFilter) (models.PaymentPage, error) {
+	payments, err := s.database.ListUserPayments(ctx, filter)
+	if err != nil {
+		return models.PaymentPage{}, err
+	}
+	page := models.PaymentPage{Payments: payments}
+	if filter.Limit > 0 && len(payments) == filter.Limit {
+		page.NextCursor = payments[len(payments)-1].ID
+	}
+	return page, nil
+}
+
+func (s *paymentService) GetFailedPaymentList(ctx context.Context, maxRetries, limit int) ([]models.PaymentRequest, error) {
+	return s.database.GetFailedPaymentList(ctx, maxRetries, limit)
 }
 
 func (s *paymentService) ListAuditLogs(ctx context.Context, filter models.AuditLogFilter) (models.AuditLogPage, error) {
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"paymentfc/constant"
+	"paymentfc/log"
+	"paymentfc/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrWebhookEventInProgress replay 요청 시 워커가 이미 처리 중인 건
+var ErrWebhookEventInProgress = errors.New("webhook event is being processed")
+
+// webhookRetryBaseDelay 실패 후 재시도 간격 = base * 2^(attempts-1)
+const webhookRetryBaseDelay = 5 * time.Second
+
+func (s *paymentService) SaveWebhookEvent(ctx context.Context, param *models.WebhookEvent) (bool, error) {
+	return s.database.SaveWebhookEvent(ctx, param)
+}
+
+func (s *paymentService) ClaimWebhookEvents(ctx context.Context, limit int) ([]models.WebhookEvent, error) {
+	return s.database.ClaimWebhookEvents(ctx, limit)
+}
+
+// ClaimWebhookEventForReplay admin이 요청한 inbox 건을 상태와 관계없이 다시 처리하도록 가져온다.
+func (s *paymentService) ClaimWebhookEventForReplay(ctx context.Context, id int64, requestedBy int64) (*models.WebhookEvent, error) {
+	existing, err := s.database.GetWebhookEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	event, err := s.database.ClaimWebhookEvent(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookEventInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditLog.SaveAuditLog(ctx, &models.PaymentAuditLog{
+		UserID:     requestedBy,
+		ExternalID: event.ExternalID,
+		Event:      "WEBHOOK_REPLAY_REQUESTED",
+		Actor:      "admin",
+		Metadata: map[string]any{
+			"webhook_event_id": id,
+			"provider":         event.Provider,
+			"previous_status":  existing.Status,
+			"attempts":         event.Attempts,
+		},
+	}); err != nil {
+		log.Logger.Warn().Err(err).Int64("webhook_event_id", id).Msg("Failed to save webhook replay audit log")
+	}
+	return event, nil
+}
+
+// CompleteWebhookEvent 처리 결과를 inbox에 기록한다.
+// retryable이 아닌 에러는 IGNORED로 닫고, 나머지는 지수 백오프로 다음 시도 시각을 잡는다.
+func (s *paymentService) CompleteWebhookEvent(ctx context.Context, event *models.WebhookEvent, processErr error, retryable bool) error {
+	status := constant.WebhookEventStatusProcessed
+	lastError := ""
+	nextAttempt := time.Now()
+	if processErr != nil {
+		lastError = processErr.Error()
+		status = constant.WebhookEventStatusIgnored
+		if retryable {
+			status = constant.WebhookEventStatusFailed
+			nextAttempt = nextAttempt.Add(webhookRetryBaseDelay << min(max(event.Attempts-1, 0), 10))
+			if event.Attempts >= constant.MaxWebhookEventAttempts {
+				log.Logger.Error().Err(processErr).Int64("webhook_event_id", event.ID).Int("attempts", event.Attempts).Msg("Webhook event exhausted retries, replay required")
+			}
+		}
+	}
+	event.Status = status
+	event.LastError = lastError
+	event.NextAttemptTime = nextAttempt
+	return s.database.UpdateWebhookEventResult(ctx, event.ID, status, lastError, nextAttempt)
+}
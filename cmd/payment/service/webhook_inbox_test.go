@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/constant"
+	"paymentfc/mocks"
+	"paymentfc/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestPaymentService_CompleteWebhookEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	svc := NewPaymentService(mockDB, mocks.NewMockPaymentEventPublisher(ctrl), nil, mocks.NewMockAuditLogRepository(ctrl))
+	ctx := context.Background()
+
+	t.Run("marks processed on success", func(t *testing.T) {
+		event := &models.WebhookEvent{ID: 1, Attempts: 1}
+		mockDB.EXPECT().UpdateWebhookEventResult(ctx, int64(1), constant.WebhookEventStatusProcessed, "", gomock.Any()).Return(nil)
+
+		err := svc.CompleteWebhookEvent(ctx, event, nil, true)
+		assert.NoError(t, err)
+		assert.Equal(t, constant.WebhookEventStatusProcessed, event.Status)
+	})
+
+	t.Run("marks ignored on non-retryable error", func(t *testing.T) {
+		event := &models.WebhookEvent{ID: 2, Attempts: 1}
+		illegal := &repository.IllegalTransitionError{PaymentID: 1, From: constant.PaymentStatusPaid, To: constant.PaymentStatusExpired}
+		mockDB.EXPECT().UpdateWebhookEventResult(ctx, int64(2), constant.WebhookEventStatusIgnored, illegal.Error(), gomock.Any()).Return(nil)
+
+		err := svc.CompleteWebhookEvent(ctx, event, illegal, false)
+		assert.NoError(t, err)
+		assert.Equal(t, constant.WebhookEventStatusIgnored, event.Status)
+	})
+
+	t.Run("schedules retry with exponential backoff", func(t *testing.T) {
+		event := &models.WebhookEvent{ID: 3, Attempts: 3}
+		mockDB.EXPECT().UpdateWebhookEventResult(ctx, int64(3), constant.WebhookEventStatusFailed, "db down", gomock.Any()).Return(nil)
+
+		before := time.Now()
+		err := svc.CompleteWebhookEvent(ctx, event, errors.New("db down"), true)
+		assert.NoError(t, err)
+		assert.Equal(t, constant.WebhookEventStatusFailed, event.Status)
+		// attempts=3 -> 5s * 2^2
+		assert.WithinDuration(t, before.Add(20*time.Second), event.NextAttemptTime, time.Second)
+	})
+}
+
+func TestPaymentService_ClaimWebhookEventForReplay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockAuditLog := mocks.NewMockAuditLogRepository(ctrl)
+	svc := NewPaymentService(mockDB, mocks.NewMockPaymentEventPublisher(ctrl), nil, mockAuditLog)
+	ctx := context.Background()
+
+	t.Run("claims event and writes audit log", func(t *testing.T) {
+		mockDB.EXPECT().GetWebhookEvent(ctx, int64(10)).Return(&models.WebhookEvent{ID: 10, Status: constant.WebhookEventStatusIgnored}, nil)
+		mockDB.EXPECT().ClaimWebhookEvent(ctx, int64(10)).Return(&models.WebhookEvent{ID: 10, ExternalID: "order-10", Status: constant.WebhookEventStatusProcessing, Attempts: 2}, nil)
+		mockAuditLog.EXPECT().SaveAuditLog(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, log *models.PaymentAuditLog) error {
+			assert.Equal(t, "WEBHOOK_REPLAY_REQUESTED", log.Event)
+			assert.Equal(t, int64(7), log.UserID)
+			assert.Equal(t, constant.WebhookEventStatusIgnored, log.Metadata.(map[string]any)["previous_status"])
+			return nil
+		})
+
+		event, err := svc.ClaimWebhookEventForReplay(ctx, 10, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, constant.WebhookEventStatusProcessing, event.Status)
+	})
+
+	t.Run("returns not found for unknown event", func(t *testing.T) {
+		mockDB.EXPECT().GetWebhookEvent(ctx, int64(11)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.ClaimWebhookEventForReplay(ctx, 11, 7)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("returns in progress when worker holds the event", func(t *testing.T) {
+		mockDB.EXPECT().GetWebhookEvent(ctx, int64(12)).Return(&models.WebhookEvent{ID: 12, Status: constant.WebhookEventStatusProcessing}, nil)
+		mockDB.EXPECT().ClaimWebhookEvent(ctx, int64(12)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := svc.ClaimWebhookEventForReplay(ctx, 12, 7)
+		assert.ErrorIs(t, err, ErrWebhookEventInProgress)
+	})
+}
@@ -2,37 +2,100 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"paymentfc/cmd/payment/repository"
 	"paymentfc/constant"
 	usergrpc "paymentfc/grpc"
 	"paymentfc/log"
 	"paymentfc/models"
+	"paymentfc/pdf"
 	"time"
 )
 
+var (
+	// ErrOrderForbidden 요청자(JWT user_id)가 주문 소유자가 아님
+	ErrOrderForbidden = errors.New("order does not belong to the requester")
+	// ErrInvoiceAmountMismatch 요청 금액이 저장된 결제 요청(payment_requests) 금액과 다름
+	ErrInvoiceAmountMismatch = errors.New("invoice amount does not match the payment request")
+)
+
+// XenditService 인보이스(체크아웃) 생성/조회. 실제 호출은 주문/결제의 provider에 해당하는 게이트웨이로 보낸다.
 type XenditService interface {
-	CreateInvoice(ctx context.Context, param models.OrderCreatedEvent) (*models.XenditInvoiceResponse, error)
-	CreateInvoiceFromPaymentRequest(ctx context.Context, pr *models.PaymentRequest) (*models.XenditInvoiceResponse, error)
-	CheckInvoiceStatus(ctx context.Context, externalID string) (string, error)
+	CreateInvoice(ctx context.Context, param models.OrderCreatedEvent) (*models.CheckoutResponse, error)
+	CreateInvoiceForUser(ctx context.Context, param models.OrderCreatedEvent, userID int64) (*models.CheckoutResponse, error)
+	CreateInvoiceFromPaymentRequest(ctx context.Context, pr *models.PaymentRequest) (*models.CheckoutResponse, error)
+	CheckInvoiceStatus(ctx context.Context, provider, externalID string) (string, error)
+	ParseWebhook(ctx context.Context, provider string, header http.Header, body []byte) (*models.PaymentWebhookEvent, error)
 }
 
 type xenditService struct {
 	database   repository.PaymentDatabase
-	xendit     repository.XenditClient
+	gateways   *repository.GatewayRegistry
 	userClient usergrpc.UserClientInterface
+	taxRule    models.TaxRule
 }
 
-func NewXenditService(database repository.PaymentDatabase, xenditClient repository.XenditClient, userClient usergrpc.UserClientInterface) XenditService {
+func NewXenditService(database repository.PaymentDatabase, gateways *repository.GatewayRegistry, userClient usergrpc.UserClientInterface, taxRule models.TaxRule) XenditService {
 	return &xenditService{
 		database:   database,
-		xendit:     xenditClient,
+		gateways:   gateways,
 		userClient: userClient,
+		taxRule:    taxRule,
+	}
+}
+
+// CreateInvoiceForUser API 요청용. 본문의 user_id/total_amount를 믿지 않고 주문 소유자와 금액을
+// 저장된 payment_requests 행과 대조한 뒤 인보이스를 만든다. total_amount를 생략하면 저장된 금액으로 청구한다.
+// 품목(세금 면제 카테고리 포함)과 게이트웨이도 저장된 요청 값을 쓰고 본문 값은 무시한다.
+func (s *xenditService) CreateInvoiceForUser(ctx context.Context, param models.OrderCreatedEvent, userID int64) (*models.CheckoutResponse, error) {
+	if param.UserID != 0 && param.UserID != userID {
+		return nil, ErrOrderForbidden
+	}
+	pr, err := s.database.GetPaymentRequestByOrderID(ctx, param.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if pr.UserID != userID {
+		return nil, ErrOrderForbidden
+	}
+
+	if param.TotalAmount != "" {
+		if param.Currency == "" {
+			param.Currency = pr.Amount.Currency
+		}
+		amount, err := param.Total()
+		if err != nil {
+			return nil, err
+		}
+		if !amount.Equal(pr.Amount) {
+			log.Logger.Warn().Int64("order_id", param.OrderID).Int64("user_id", userID).
+				Str("requested", amount.String()).Str("expected", pr.Amount.String()).Msg("Rejected invoice request with mismatched amount")
+			return nil, ErrInvoiceAmountMismatch
+		}
 	}
+	return s.CreateInvoiceFromPaymentRequest(ctx, pr)
 }
 
-func (s *xenditService) CreateInvoice(ctx context.Context, param models.OrderCreatedEvent) (*models.XenditInvoiceResponse, error) {
+func (s *xenditService) CreateInvoice(ctx context.Context, param models.OrderCreatedEvent) (*models.CheckoutResponse, error) {
 	externalID := fmt.Sprintf("order-%d", param.OrderID)
+	amount, err := param.Total()
+	if err != nil {
+		return nil, err
+	}
+	items, err := param.LineItems()
+	if err != nil {
+		return nil, err
+	}
+	tax, err := s.taxRule.Apply(amount, items)
+	if err != nil {
+		return nil, err
+	}
+	gateway, err := s.gateways.Get(param.Provider)
+	if err != nil {
+		return nil, err
+	}
 
 	if s.userClient == nil {
 		return nil, fmt.Errorf("user gRPC client is not initialized")
@@ -43,14 +106,14 @@ func (s *xenditService) CreateInvoice(ctx context.Context, param models.OrderCre
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
 	payerEmail := userInfo.Email
-	req := models.XenditInvoiceRequest{
+	req := models.CheckoutRequest{
 		ExternalID:  externalID,
-		Amount:      param.TotalAmount,
-		Description: fmt.Sprintf("[FC] Pembayaran Order %d", param.OrderID),
+		Amount:      tax.Total,
+		Description: checkoutDescription(param.OrderID, tax),
 		PayerEmail:  payerEmail,
 	}
 
-	xenditInvoiceInfo, err := s.xendit.CreateInvoice(ctx, req)
+	xenditInvoiceInfo, err := gateway.CreateCheckout(ctx, req)
 	if err != nil {
 		log.Logger.Error().Err(err).Msgf("Failed to create invoice for order: %d", param.OrderID)
 		return nil, err
@@ -60,11 +123,15 @@ func (s *xenditService) CreateInvoice(ctx context.Context, param models.OrderCre
 		OrderID:     param.OrderID,
 		UserID:      param.UserID,
 		ExternalID:  externalID,
-		Amount:      param.TotalAmount,
+		InvoiceID:   xenditInvoiceInfo.ID,
+		InvoiceURL:  xenditInvoiceInfo.InvoiceURL,
+		Provider:    gateway.Name(),
 		Status:      constant.PaymentStatusPending,
 		CreateTime:  time.Now(),
 		ExpiredTime: xenditInvoiceInfo.ExpireDate,
+		Items:       items,
 	}
+	tax.ApplyTo(payment)
 	if err := s.database.SavePayment(ctx, payment); err != nil {
 		log.Logger.Error().Err(err).Msgf("Failed to save payment for order: %d", param.OrderID)
 		return nil, err
@@ -73,8 +140,16 @@ func (s *xenditService) CreateInvoice(ctx context.Context, param models.OrderCre
 	return xenditInvoiceInfo, nil
 }
 
-func (s *xenditService) CreateInvoiceFromPaymentRequest(ctx context.Context, pr *models.PaymentRequest) (*models.XenditInvoiceResponse, error) {
+func (s *xenditService) CreateInvoiceFromPaymentRequest(ctx context.Context, pr *models.PaymentRequest) (*models.CheckoutResponse, error) {
 	externalID := fmt.Sprintf("order-%d", pr.OrderID)
+	gateway, err := s.gateways.Get(pr.Provider)
+	if err != nil {
+		return nil, err
+	}
+	tax, err := s.taxRule.Apply(pr.Amount, pr.Items)
+	if err != nil {
+		return nil, err
+	}
 	payerEmail := pr.UserEmail
 	if payerEmail == "" {
 		if s.userClient == nil {
@@ -87,27 +162,32 @@ func (s *xenditService) CreateInvoiceFromPaymentRequest(ctx context.Context, pr
 		}
 		payerEmail = userInfo.Email
 	}
-	req := models.XenditInvoiceRequest{
+	req := models.CheckoutRequest{
 		ExternalID:  externalID,
-		Amount:      pr.Amount,
-		Description: fmt.Sprintf("[FC] Pembayaran Order %d", pr.OrderID),
+		Amount:      tax.Total,
+		Description: checkoutDescription(pr.OrderID, tax),
 		PayerEmail:  payerEmail,
 	}
 
-	resp, err := s.xendit.CreateInvoice(ctx, req)
+	resp, err := gateway.CreateCheckout(ctx, req)
 	if err != nil {
 		log.Logger.Error().Err(err).Msgf("Failed to create invoice for payment_request order_id: %d", pr.OrderID)
 		return nil, err
 	}
 
 	payment := &models.Payment{
-		OrderID:    pr.OrderID,
-		UserID:     pr.UserID,
-		ExternalID: externalID,
-		Amount:     pr.Amount,
-		Status:     constant.PaymentStatusPending,
-		CreateTime: time.Now(),
+		OrderID:     pr.OrderID,
+		UserID:      pr.UserID,
+		ExternalID:  externalID,
+		InvoiceID:   resp.ID,
+		InvoiceURL:  resp.InvoiceURL,
+		Provider:    gateway.Name(),
+		Status:      constant.PaymentStatusPending,
+		CreateTime:  time.Now(),
+		ExpiredTime: resp.ExpireDate,
+		Items:       pr.Items,
 	}
+	tax.ApplyTo(payment)
 	if err := s.database.SavePayment(ctx, payment); err != nil {
 		log.Logger.Error().Err(err).Msgf("Failed to save payment for order_id: %d", pr.OrderID)
 		return nil, err
@@ -116,6 +196,31 @@ func (s *xenditService) CreateInvoiceFromPaymentRequest(ctx context.Context, pr
 	return resp, nil
 }
 
-func (s *xenditService) CheckInvoiceStatus(ctx context.Context, externalID string) (string, error) {
-	return s.xendit.CheckInvoiceStatus(ctx, externalID)
+// checkoutDescription 결제 페이지에 보이는 설명. 세금이 있으면 포함된 PPN 세율을 함께 적는다.
+func checkoutDescription(orderID int64, tax models.TaxBreakdown) string {
+	description := fmt.Sprintf("[FC] Pembayaran Order %d", orderID)
+	if tax.Tax.IsPositive() {
+		description += fmt.Sprintf(" (termasuk PPN %s)", pdf.FormatRate(tax.RateBps))
+	}
+	return description
+}
+
+func (s *xenditService) CheckInvoiceStatus(ctx context.Context, provider, externalID string) (string, error) {
+	gateway, err := s.gateways.Get(provider)
+	if err != nil {
+		return "", err
+	}
+	return gateway.GetCheckoutStatus(ctx, externalID)
+}
+
+// ParseWebhook provider 서명을 검증하고 웹훅을 PaymentWebhookEvent로 정규화한다.
+func (s *xenditService) ParseWebhook(ctx context.Context, provider string, header http.Header, body []byte) (*models.PaymentWebhookEvent, error) {
+	if provider == "" {
+		return nil, repository.ErrUnknownGateway
+	}
+	gateway, err := s.gateways.Get(provider)
+	if err != nil {
+		return nil, err
+	}
+	return gateway.VerifyWebhook(header, body)
 }
@@ -2,7 +2,12 @@ package service
 
 import (
 	"context"
+	"crypto/sha512"
+	"encoding/hex"
 	"errors"
+	"net/http"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/constant"
 	"paymentfc/mocks"
 	"paymentfc/models"
 	pb "paymentfc/pb/proto"
@@ -13,6 +18,12 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
+// newTestGateways mock XenditClient를 감싼 xendit 게이트웨이 하나만 등록한 레지스트리
+func newTestGateways(client repository.XenditClient) *repository.GatewayRegistry {
+	gateways, _ := repository.NewGatewayRegistry(constant.PaymentProviderXendit, repository.NewXenditGateway(client, "test-token"))
+	return gateways
+}
+
 func TestXenditService_CreateInvoice(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -21,13 +32,13 @@ func TestXenditService_CreateInvoice(t *testing.T) {
 	mockXenditClient := mocks.NewMockXenditClient(ctrl)
 	mockUserClient := mocks.NewMockUserClientInterface(ctrl)
 
-	svc := NewXenditService(mockDB, mockXenditClient, mockUserClient)
+	svc := NewXenditService(mockDB, newTestGateways(mockXenditClient), mockUserClient, models.TaxRule{})
 	ctx := context.Background()
 
 	event := models.OrderCreatedEvent{
 		OrderID:     12345,
 		UserID:      100,
-		TotalAmount: 50000,
+		TotalAmount: "50000",
 	}
 
 	t.Run("success - creates invoice", func(t *testing.T) {
@@ -51,7 +62,7 @@ func TestXenditService_CreateInvoice(t *testing.T) {
 	})
 
 	t.Run("fails when user client is nil", func(t *testing.T) {
-		svcNoClient := NewXenditService(mockDB, mockXenditClient, nil)
+		svcNoClient := NewXenditService(mockDB, newTestGateways(mockXenditClient), nil, models.TaxRule{})
 
 		_, err := svcNoClient.CreateInvoice(ctx, event)
 		assert.Error(t, err)
@@ -94,6 +105,83 @@ func TestXenditService_CreateInvoice(t *testing.T) {
 	})
 }
 
+func TestXenditService_CreateInvoiceForUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockXenditClient := mocks.NewMockXenditClient(ctrl)
+	mockUserClient := mocks.NewMockUserClientInterface(ctrl)
+
+	svc := NewXenditService(mockDB, newTestGateways(mockXenditClient), mockUserClient, models.TaxRule{})
+	ctx := context.Background()
+
+	pr := &models.PaymentRequest{ID: 1, OrderID: 12345, UserID: 100, Amount: models.NewMoney(50000, "IDR")}
+
+	t.Run("charges stored amount for the owner", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentRequestByOrderID(ctx, int64(12345)).Return(pr, nil)
+		mockUserClient.EXPECT().GetUserInfoByUserId(ctx, int64(100)).Return(&pb.GetUserInfoByUserIdResponse{Email: "user@test.com"}, nil)
+		mockXenditClient.EXPECT().CreateInvoice(ctx, gomock.Any()).Return(&models.XenditInvoiceResponse{
+			ID:         "inv-12345",
+			InvoiceURL: "https://xendit.co/invoice/inv-12345",
+			Status:     "PENDING",
+			ExpireDate: time.Now().Add(24 * time.Hour),
+		}, nil)
+		mockDB.EXPECT().SavePayment(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, p *models.Payment) error {
+			assert.Equal(t, int64(100), p.UserID)
+			assert.Equal(t, pr.Amount, p.Amount)
+			return nil
+		})
+
+		resp, err := svc.CreateInvoiceForUser(ctx, models.OrderCreatedEvent{OrderID: 12345}, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, "inv-12345", resp.ID)
+	})
+
+	t.Run("rejects user_id in body that differs from token", func(t *testing.T) {
+		_, err := svc.CreateInvoiceForUser(ctx, models.OrderCreatedEvent{OrderID: 12345, UserID: 100}, 200)
+		assert.ErrorIs(t, err, ErrOrderForbidden)
+	})
+
+	t.Run("rejects order owned by another user", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentRequestByOrderID(ctx, int64(12345)).Return(pr, nil)
+
+		_, err := svc.CreateInvoiceForUser(ctx, models.OrderCreatedEvent{OrderID: 12345}, 200)
+		assert.ErrorIs(t, err, ErrOrderForbidden)
+	})
+
+	t.Run("rejects tampered amount", func(t *testing.T) {
+		mockDB.EXPECT().GetPaymentRequestByOrderID(ctx, int64(12345)).Return(pr, nil)
+
+		_, err := svc.CreateInvoiceForUser(ctx, models.OrderCreatedEvent{OrderID: 12345, UserID: 100, TotalAmount: "1000"}, 100)
+		assert.ErrorIs(t, err, ErrInvoiceAmountMismatch)
+	})
+
+	t.Run("ignores client products and provider", func(t *testing.T) {
+		taxed := NewXenditService(mockDB, newTestGateways(mockXenditClient), mockUserClient, models.TaxRule{RateBps: 1100, ExemptCategories: []string{"groceries"}})
+		stored := &models.PaymentRequest{
+			ID: 2, OrderID: 12346, UserID: 100, Amount: models.NewMoney(100000, "IDR"), Provider: constant.PaymentProviderXendit,
+			Items: []models.LineItem{{Name: "Laptop", Quantity: 1, UnitPrice: models.NewMoney(100000, "IDR"), Category: "electronics"}},
+		}
+		mockDB.EXPECT().GetPaymentRequestByOrderID(ctx, int64(12346)).Return(stored, nil)
+		mockUserClient.EXPECT().GetUserInfoByUserId(ctx, int64(100)).Return(&pb.GetUserInfoByUserIdResponse{Email: "user@test.com"}, nil)
+		mockXenditClient.EXPECT().CreateInvoice(ctx, gomock.Any()).Return(&models.XenditInvoiceResponse{ID: "inv-12346", ExpireDate: time.Now().Add(24 * time.Hour)}, nil)
+		mockDB.EXPECT().SavePayment(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, p *models.Payment) error {
+			assert.Equal(t, stored.Items, p.Items)
+			assert.Equal(t, constant.PaymentProviderXendit, p.Provider)
+			assert.Equal(t, int64(11000), p.Tax.Minor)
+			return nil
+		})
+
+		_, err := taxed.CreateInvoiceForUser(ctx, models.OrderCreatedEvent{
+			OrderID:  12346,
+			Provider: constant.PaymentProviderMidtrans,
+			Products: []models.ProductItem{{Name: "Laptop", Quantity: 1, Price: "100000", Category: "groceries"}},
+		}, 100)
+		assert.NoError(t, err)
+	})
+}
+
 func TestXenditService_CreateInvoiceFromPaymentRequest(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -102,7 +190,7 @@ func TestXenditService_CreateInvoiceFromPaymentRequest(t *testing.T) {
 	mockXenditClient := mocks.NewMockXenditClient(ctrl)
 	mockUserClient := mocks.NewMockUserClientInterface(ctrl)
 
-	svc := NewXenditService(mockDB, mockXenditClient, mockUserClient)
+	svc := NewXenditService(mockDB, newTestGateways(mockXenditClient), mockUserClient, models.TaxRule{})
 	ctx := context.Background()
 
 	t.Run("success with existing email", func(t *testing.T) {
@@ -110,7 +198,7 @@ func TestXenditService_CreateInvoiceFromPaymentRequest(t *testing.T) {
 			ID:        1,
 			OrderID:   12345,
 			UserID:    100,
-			Amount:    50000,
+			Amount:    models.NewMoney(50000, "IDR"),
 			UserEmail: "existing@test.com",
 		}
 
@@ -132,7 +220,7 @@ func TestXenditService_CreateInvoiceFromPaymentRequest(t *testing.T) {
 			ID:        1,
 			OrderID:   12345,
 			UserID:    100,
-			Amount:    50000,
+			Amount:    models.NewMoney(50000, "IDR"),
 			UserEmail: "",
 		}
 
@@ -154,13 +242,13 @@ func TestXenditService_CreateInvoiceFromPaymentRequest(t *testing.T) {
 	})
 
 	t.Run("fails when user client nil and no email", func(t *testing.T) {
-		svcNoClient := NewXenditService(mockDB, mockXenditClient, nil)
+		svcNoClient := NewXenditService(mockDB, newTestGateways(mockXenditClient), nil, models.TaxRule{})
 
 		pr := &models.PaymentRequest{
 			ID:        1,
 			OrderID:   12345,
 			UserID:    100,
-			Amount:    50000,
+			Amount:    models.NewMoney(50000, "IDR"),
 			UserEmail: "",
 		}
 
@@ -173,7 +261,7 @@ func TestXenditService_CreateInvoiceFromPaymentRequest(t *testing.T) {
 			ID:        1,
 			OrderID:   12345,
 			UserID:    100,
-			Amount:    50000,
+			Amount:    models.NewMoney(50000, "IDR"),
 			UserEmail: "",
 		}
 
@@ -192,14 +280,14 @@ func TestXenditService_CheckInvoiceStatus(t *testing.T) {
 	mockXenditClient := mocks.NewMockXenditClient(ctrl)
 	mockUserClient := mocks.NewMockUserClientInterface(ctrl)
 
-	svc := NewXenditService(mockDB, mockXenditClient, mockUserClient)
+	svc := NewXenditService(mockDB, newTestGateways(mockXenditClient), mockUserClient, models.TaxRule{})
 	ctx := context.Background()
 
 	t.Run("returns status successfully", func(t *testing.T) {
 		externalID := "order-12345"
 		mockXenditClient.EXPECT().CheckInvoiceStatus(ctx, externalID).Return("PAID", nil)
 
-		status, err := svc.CheckInvoiceStatus(ctx, externalID)
+		status, err := svc.CheckInvoiceStatus(ctx, "", externalID)
 		assert.NoError(t, err)
 		assert.Equal(t, "PAID", status)
 	})
@@ -208,7 +296,145 @@ func TestXenditService_CheckInvoiceStatus(t *testing.T) {
 		externalID := "order-12345"
 		mockXenditClient.EXPECT().CheckInvoiceStatus(ctx, externalID).Return("", errors.New("api error"))
 
-		_, err := svc.CheckInvoiceStatus(ctx, externalID)
+		_, err := svc.CheckInvoiceStatus(ctx, "", externalID)
 		assert.Error(t, err)
 	})
 }
+
+func TestXenditService_GatewaySelection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockXenditClient := mocks.NewMockXenditClient(ctrl)
+	mockUserClient := mocks.NewMockUserClientInterface(ctrl)
+
+	svc := NewXenditService(mockDB, newTestGateways(mockXenditClient), mockUserClient, models.TaxRule{})
+	ctx := context.Background()
+
+	t.Run("saves provider on payment", func(t *testing.T) {
+		pr := &models.PaymentRequest{
+			OrderID:   12345,
+			UserID:    100,
+			Amount:    models.NewMoney(50000, "IDR"),
+			UserEmail: "existing@test.com",
+		}
+		mockXenditClient.EXPECT().CreateInvoice(ctx, models.XenditInvoiceRequest{
+			ExternalID:  "order-12345",
+			Amount:      "50000",
+			Currency:    "IDR",
+			Description: "[FC] Pembayaran Order 12345",
+			PayerEmail:  "existing@test.com",
+		}).Return(&models.XenditInvoiceResponse{ID: "inv-12345", Status: "PENDING"}, nil)
+		mockDB.EXPECT().SavePayment(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, p *models.Payment) error {
+			assert.Equal(t, constant.PaymentProviderXendit, p.Provider)
+			assert.Equal(t, "inv-12345", p.InvoiceID)
+			return nil
+		})
+
+		resp, err := svc.CreateInvoiceFromPaymentRequest(ctx, pr)
+		assert.NoError(t, err)
+		assert.Equal(t, constant.PaymentProviderXendit, resp.Provider)
+	})
+
+	t.Run("unknown provider is rejected before calling gateway", func(t *testing.T) {
+		pr := &models.PaymentRequest{
+			OrderID:  12345,
+			Amount:   models.NewMoney(50000, "IDR"),
+			Provider: "stripe",
+		}
+
+		_, err := svc.CreateInvoiceFromPaymentRequest(ctx, pr)
+		assert.ErrorIs(t, err, repository.ErrUnknownGateway)
+	})
+
+	t.Run("settled invoice is reported as paid", func(t *testing.T) {
+		mockXenditClient.EXPECT().CheckInvoiceStatus(ctx, "order-1").Return("SETTLED", nil)
+
+		status, err := svc.CheckInvoiceStatus(ctx, constant.PaymentProviderXendit, "order-1")
+		assert.NoError(t, err)
+		assert.Equal(t, constant.PaymentStatusPaid, status)
+	})
+}
+
+func TestXenditService_ParseWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mocks.NewMockPaymentDatabase(ctrl)
+	mockXenditClient := mocks.NewMockXenditClient(ctrl)
+
+	gateways, err := repository.NewGatewayRegistry(constant.PaymentProviderXendit,
+		repository.NewXenditGateway(mockXenditClient, "test-token"),
+		repository.NewMidtransGateway("server-key", "", "", nil),
+	)
+	assert.NoError(t, err)
+	svc := NewXenditService(mockDB, gateways, nil, models.TaxRule{})
+	ctx := context.Background()
+
+	t.Run("xendit - valid token", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("x-callback-token", "test-token")
+		body := []byte(`{"external_id":"order-1","status":"PAID","amount":50000,"currency":"IDR"}`)
+
+		event, err := svc.ParseWebhook(ctx, constant.PaymentProviderXendit, header, body)
+		assert.NoError(t, err)
+		assert.Equal(t, models.PaymentWebhookEvent{
+			Provider:   constant.PaymentProviderXendit,
+			ExternalID: "order-1",
+			Status:     constant.PaymentStatusPaid,
+			Amount:     "50000",
+			Currency:   "IDR",
+		}, *event)
+	})
+
+	t.Run("xendit - invalid token", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("x-callback-token", "wrong")
+
+		_, err := svc.ParseWebhook(ctx, constant.PaymentProviderXendit, header, []byte(`{}`))
+		assert.ErrorIs(t, err, repository.ErrInvalidWebhookSignature)
+	})
+
+	t.Run("xendit - malformed body", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("x-callback-token", "test-token")
+
+		_, err := svc.ParseWebhook(ctx, constant.PaymentProviderXendit, header, []byte(`{`))
+		assert.ErrorIs(t, err, repository.ErrInvalidWebhookPayload)
+	})
+
+	midtransBody := func(status, signature string) []byte {
+		return []byte(`{"order_id":"order-2","status_code":"200","gross_amount":"75000.00","currency":"IDR","transaction_status":"` + status + `","signature_key":"` + signature + `"}`)
+	}
+	sum := sha512.Sum512([]byte("order-2" + "200" + "75000.00" + "server-key"))
+	signature := hex.EncodeToString(sum[:])
+
+	t.Run("midtrans - settlement normalizes to PAID", func(t *testing.T) {
+		event, err := svc.ParseWebhook(ctx, constant.PaymentProviderMidtrans, http.Header{}, midtransBody("settlement", signature))
+		assert.NoError(t, err)
+		assert.Equal(t, constant.PaymentProviderMidtrans, event.Provider)
+		assert.Equal(t, "order-2", event.ExternalID)
+		assert.Equal(t, constant.PaymentStatusPaid, event.Status)
+
+		amount, err := models.ParseMoney(event.Amount.String(), event.Currency)
+		assert.NoError(t, err)
+		assert.Equal(t, models.NewMoney(75000, "IDR"), amount)
+	})
+
+	t.Run("midtrans - expire normalizes to EXPIRED", func(t *testing.T) {
+		event, err := svc.ParseWebhook(ctx, constant.PaymentProviderMidtrans, http.Header{}, midtransBody("expire", signature))
+		assert.NoError(t, err)
+		assert.Equal(t, constant.PaymentStatusExpired, event.Status)
+	})
+
+	t.Run("midtrans - bad signature", func(t *testing.T) {
+		_, err := svc.ParseWebhook(ctx, constant.PaymentProviderMidtrans, http.Header{}, midtransBody("settlement", "deadbeef"))
+		assert.ErrorIs(t, err, repository.ErrInvalidWebhookSignature)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := svc.ParseWebhook(ctx, "stripe", http.Header{}, []byte(`{}`))
+		assert.ErrorIs(t, err, repository.ErrUnknownGateway)
+	})
+}
@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/models"
+)
+
+type AnomalyUsecase interface {
+	ListAnomalies(ctx context.Context, filter models.PaymentAnomalyFilter) (models.PaymentAnomalyPage, error)
+	GetAnomalyDetail(ctx context.Context, id int64) (*models.PaymentAnomalyDetail, error)
+	ResolveAnomaly(ctx context.Context, id int64, req models.ResolveAnomalyRequest, resolvedBy int64) (*models.PaymentAnomaly, error)
+}
+
+type anomalyUsecase struct {
+	anomalyService service.AnomalyService
+}
+
+func NewAnomalyUsecase(anomalyService service.AnomalyService) AnomalyUsecase {
+	return &anomalyUsecase{anomalyService: anomalyService}
+}
+
+func (u *anomalyUsecase) ListAnomalies(ctx context.Context, filter models.PaymentAnomalyFilter) (models.PaymentAnomalyPage, error) {
+	return u.anomalyService.ListAnomalies(ctx, filter)
+}
+
+func (u *anomalyUsecase) GetAnomalyDetail(ctx context.Context, id int64) (*models.PaymentAnomalyDetail, error) {
+	return u.anomalyService.GetAnomalyDetail(ctx, id)
+}
+
+func (u *anomalyUsecase) ResolveAnomaly(ctx context.Context, id int64, req models.ResolveAnomalyRequest, resolvedBy int64) (*models.PaymentAnomaly, error) {
+	return u.anomalyService.ResolveAnomaly(ctx, id, req, resolvedBy)
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/models"
+)
+
+type JobUsecase interface {
+	ListJobs(ctx context.Context) ([]models.JobStatus, error)
+	ListJobRuns(ctx context.Context, filter models.JobRunFilter) (models.JobRunPage, error)
+	PauseJob(ctx context.Context, name string, requestedBy int64) (*models.JobStatus, error)
+	ResumeJob(ctx context.Context, name string, requestedBy int64) (*models.JobStatus, error)
+	TriggerJob(ctx context.Context, name string, force bool, requestedBy int64) (*models.JobStatus, error)
+	JobSpec(name string) (service.JobSpec, error)
+}
+
+type jobUsecase struct {
+	jobService service.JobService
+}
+
+func NewJobUsecase(jobService service.JobService) JobUsecase {
+	return &jobUsecase{jobService: jobService}
+}
+
+func (u *jobUsecase) ListJobs(ctx context.Context) ([]models.JobStatus, error) {
+	return u.jobService.ListJobs(ctx)
+}
+
+func (u *jobUsecase) ListJobRuns(ctx context.Context, filter models.JobRunFilter) (models.JobRunPage, error) {
+	return u.jobService.ListJobRuns(ctx, filter)
+}
+
+func (u *jobUsecase) PauseJob(ctx context.Context, name string, requestedBy int64) (*models.JobStatus, error) {
+	return u.jobService.PauseJob(ctx, name, requestedBy)
+}
+
+func (u *jobUsecase) ResumeJob(ctx context.Context, name string, requestedBy int64) (*models.JobStatus, error) {
+	return u.jobService.ResumeJob(ctx, name, requestedBy)
+}
+
+func (u *jobUsecase) TriggerJob(ctx context.Context, name string, force bool, requestedBy int64) (*models.JobStatus, error) {
+	return u.jobService.TriggerJob(ctx, name, force, requestedBy)
+}
+
+func (u *jobUsecase) JobSpec(name string) (service.JobSpec, error) {
+	return u.jobService.JobSpec(name)
+}
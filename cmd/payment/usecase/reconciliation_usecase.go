@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/models"
+	"time"
+)
+
+type ReconciliationUsecase interface {
+	TriggerReconciliation(ctx context.Context, windowStart, windowEnd time.Time, autoFix bool, requestedBy int64) (*models.ReconciliationRun, error)
+	ListRuns(ctx context.Context, filter models.ReconciliationRunFilter) (models.ReconciliationRunPage, error)
+	GetRun(ctx context.Context, id int64) (*models.ReconciliationRun, error)
+	ListItems(ctx context.Context, filter models.ReconciliationItemFilter) (models.ReconciliationItemPage, error)
+}
+
+type reconciliationUsecase struct {
+	reconciliationService service.ReconciliationService
+}
+
+func NewReconciliationUsecase(reconciliationService service.ReconciliationService) ReconciliationUsecase {
+	return &reconciliationUsecase{reconciliationService: reconciliationService}
+}
+
+func (u *reconciliationUsecase) TriggerReconciliation(ctx context.Context, windowStart, windowEnd time.Time, autoFix bool, requestedBy int64) (*models.ReconciliationRun, error) {
+	return u.reconciliationService.TriggerReconciliation(ctx, windowStart, windowEnd, autoFix, requestedBy)
+}
+
+func (u *reconciliationUsecase) ListRuns(ctx context.Context, filter models.ReconciliationRunFilter) (models.ReconciliationRunPage, error) {
+	return u.reconciliationService.ListRuns(ctx, filter)
+}
+
+func (u *reconciliationUsecase) GetRun(ctx context.Context, id int64) (*models.ReconciliationRun, error) {
+	return u.reconciliationService.GetRun(ctx, id)
+}
+
+func (u *reconciliationUsecase) ListItems(ctx context.Context, filter models.ReconciliationItemFilter) (models.ReconciliationItemPage, error) {
+	return u.reconciliationService.ListItems(ctx, filter)
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/models"
+)
+
+type RefundUsecase interface {
+	CreateRefund(ctx context.Context, orderID int64, req models.RefundRequest, requestedBy int64) (*models.Refund, error)
+}
+
+type refundUsecase struct {
+	refundService service.RefundService
+}
+
+func NewRefundUsecase(refundService service.RefundService) RefundUsecase {
+	return &refundUsecase{refundService: refundService}
+}
+
+func (u *refundUsecase) CreateRefund(ctx context.Context, orderID int64, req models.RefundRequest, requestedBy int64) (*models.Refund, error) {
+	return u.refundService.CreateRefund(ctx, orderID, req, requestedBy)
+}
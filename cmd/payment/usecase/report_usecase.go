@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/models"
+)
+
+type ReportUsecase interface {
+	GetSettlementReport(ctx context.Context, query models.SettlementReportQuery) (*models.SettlementReport, error)
+	StreamSettlementReport(ctx context.Context, query models.SettlementReportQuery, fn func(models.SettlementReportRow) error) error
+}
+
+type reportUsecase struct {
+	reportService service.ReportService
+}
+
+func NewReportUsecase(reportService service.ReportService) ReportUsecase {
+	return &reportUsecase{reportService: reportService}
+}
+
+func (u *reportUsecase) GetSettlementReport(ctx context.Context, query models.SettlementReportQuery) (*models.SettlementReport, error) {
+	return u.reportService.GetSettlementReport(ctx, query)
+}
+
+func (u *reportUsecase) StreamSettlementReport(ctx context.Context, query models.SettlementReportQuery, fn func(models.SettlementReportRow) error) error {
+	return u.reportService.StreamSettlementReport(ctx, query, fn)
+}
@@ -3,11 +3,12 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"paymentfc/cmd/payment/repository"
 	"paymentfc/cmd/payment/service"
 	"paymentfc/constant"
 	"paymentfc/log"
 	"paymentfc/models"
-	"paymentfc/pdf"
 	"strconv"
 	"strings"
 	"time"
@@ -15,22 +16,36 @@ import (
 
 type PaymentUsecase interface {
 	ProcessPaymentSuccess(ctx context.Context, orderID int64) error
-	ProcessPaymentWebhook(ctx context.Context, payload models.XenditWebhookPayload) error
+	ProcessPaymentWebhook(ctx context.Context, payload models.PaymentWebhookEvent) error
 	ProcessPaymentRequest(ctx context.Context, event models.OrderCreatedEvent) error
 	ProcessStockReserved(ctx context.Context, event models.StockReservationEvent) error
-	DownloadInvoicePdf(ctx context.Context, orderID int64) (string, error)
-	GetFailedPaymentList(ctx context.Context) (models.FailedPaymentList, error)
+	GetInvoicePdf(ctx context.Context, orderID, userID int64, ifNoneMatch string) (*models.InvoicePdf, error)
+	GetFailedPaymentList(ctx context.Context, maxRetries, limit int) (models.FailedPaymentList, error)
+	GetUserPayment(ctx context.Context, userID, orderID int64) (*models.Payment, error)
+	ListUserPayments(ctx context.Context, filter models.PaymentFilter) (models.PaymentPage, error)
 	ListAuditLogs(ctx context.Context, filter models.AuditLogFilter) (models.AuditLogPage, error)
 	GetAuditDailyReport(ctx context.Context, from, to time.Time) ([]models.AuditDailyReportItem, error)
 	WatchAuditInsertStream(ctx context.Context, out chan<- models.PaymentAuditLog) error
+	ReceiveWebhook(ctx context.Context, event models.PaymentWebhookEvent, header http.Header, body []byte) (*models.WebhookEvent, bool, error)
+	ReplayWebhookEvent(ctx context.Context, id int64, requestedBy int64) (*models.WebhookEvent, error)
+	WebhookJob() service.Job
+	ListFailedEvents(ctx context.Context, filter models.FailedEventFilter) (models.FailedEventPage, error)
+	RetryFailedEvent(ctx context.Context, id int64, requestedBy int64) (*models.FailedEvent, error)
+	CloseFailedEvent(ctx context.Context, id int64, requestedBy int64, reason string) (*models.FailedEvent, error)
 }
 
 type paymentUsecase struct {
 	paymentService service.PaymentService
+	invoiceService service.InvoiceService
+	webhookNotify  chan struct{}
 }
 
-func NewPaymentUsecase(paymentService service.PaymentService) PaymentUsecase {
-	return &paymentUsecase{paymentService: paymentService}
+func NewPaymentUsecase(paymentService service.PaymentService, invoiceService service.InvoiceService) PaymentUsecase {
+	return &paymentUsecase{
+		paymentService: paymentService,
+		invoiceService: invoiceService,
+		webhookNotify:  make(chan struct{}, 1),
+	}
 }
 
 func (u *paymentUsecase) ProcessPaymentSuccess(ctx context.Context, orderID int64) error {
@@ -47,13 +62,13 @@ func extractOrderID(externalID string) (int64, error) {
 	return orderID, nil
 }
 
-func (u *paymentUsecase) ProcessPaymentWebhook(ctx context.Context, payload models.XenditWebhookPayload) error {
+func (u *paymentUsecase) ProcessPaymentWebhook(ctx context.Context, payload models.PaymentWebhookEvent) error {
 	switch payload.Status {
 	case constant.PaymentStatusPaid:
 		orderID, err := extractOrderID(payload.ExternalID)
 		if err != nil {
 			log.Logger.Error().Err(err).Msgf("Failed to extract order ID from external_id: %s", payload.ExternalID)
-			return err
+			return fmt.Errorf("%w: %v", ErrWebhookRejected, err)
 		}
 		paid, err := u.paymentService.IsAlreadyPaid(ctx, orderID)
 		if err != nil {
@@ -69,33 +84,65 @@ func (u *paymentUsecase) ProcessPaymentWebhook(ctx context.Context, payload mode
 			log.Logger.Error().Err(err).Msgf("Failed to get payment amount for order_id: %d", orderID)
 			return err
 		}
-		if payload.Amount > 0 && amount != payload.Amount {
-			log.Logger.Error().Msgf("Payment amount mismatch for order_id: %d, expected=%.2f, got=%.2f", orderID, amount, payload.Amount)
+		var paidAmount models.Money
+		if payload.Amount != "" {
+			currency := payload.Currency
+			if currency == "" {
+				currency = amount.Currency
+			}
+			paidAmount, err = models.ParseMoney(payload.Amount.String(), currency)
+			if err != nil {
+				log.Logger.Error().Err(err).Msgf("Failed to parse webhook amount for order_id: %d", orderID)
+				return fmt.Errorf("%w: %v", ErrWebhookRejected, err)
+			}
+		}
+		if paidAmount.IsPositive() && !paidAmount.Equal(amount) {
+			log.Logger.Error().Msgf("Payment amount mismatch for order_id: %d, expected=%s, got=%s", orderID, amount, paidAmount)
+			anomaly := &models.PaymentAnomaly{
+				OrderID:        orderID,
+				ExternalID:     payload.ExternalID,
+				AnomalyType:    constant.AnomalyTypeInvalidAmount,
+				ExpectedAmount: amount,
+				PaidAmount:     paidAmount,
+				Notes:          fmt.Sprintf("amount mismatch: expected=%s, got=%s", amount, paidAmount),
+				Status:         constant.PaymentAnomalyStatusNeedToCheck,
+				UpdateTime:     time.Now(),
+			}
+			if err := u.paymentService.SavePaymentAnomaly(ctx, anomaly); err != nil {
+				log.Logger.Error().Err(err).Msgf("Failed to save payment anomaly for order_id: %d", orderID)
+			}
+			return fmt.Errorf("%w: amount mismatch: order_id=%d, expected=%s, got=%s", ErrWebhookRejected, orderID, amount, paidAmount)
+		}
+		err = u.paymentService.ProcessPaymentSuccess(ctx, orderID)
+		if repository.IsIllegalTransition(err) {
+			// 이미 만료/실패 처리된 결제에 돈이 들어온 경우: 자동 처리하지 않고 이상 건으로 남긴다.
 			anomaly := &models.PaymentAnomaly{
 				OrderID:     orderID,
 				ExternalID:  payload.ExternalID,
-				AnomalyType: constant.AnomalyTypeInvalidAmount,
-				Notes:       fmt.Sprintf("amount mismatch: expected=%.2f, got=%.2f", amount, payload.Amount),
+				AnomalyType: constant.AnomalyTypeIllegalTransition,
+				Notes:       err.Error(),
 				Status:      constant.PaymentAnomalyStatusNeedToCheck,
 				UpdateTime:  time.Now(),
 			}
-			if err := u.paymentService.SavePaymentAnomaly(ctx, anomaly); err != nil {
-				log.Logger.Error().Err(err).Msgf("Failed to save payment anomaly for order_id: %d", orderID)
+			if saveErr := u.paymentService.SavePaymentAnomaly(ctx, anomaly); saveErr != nil {
+				log.Logger.Error().Err(saveErr).Msgf("Failed to save payment anomaly for order_id: %d", orderID)
 			}
-			return fmt.Errorf("amount mismatch: order_id=%d, expected=%.2f, got=%.2f", orderID, amount, payload.Amount)
 		}
-		return u.paymentService.ProcessPaymentSuccess(ctx, orderID)
+		return err
 	case constant.PaymentStatusFailed:
 		orderID, err := extractOrderID(payload.ExternalID)
 		if err != nil {
 			log.Logger.Error().Err(err).Msgf("Failed to extract order ID from external_id: %s", payload.ExternalID)
-			return err
+			return fmt.Errorf("%w: %v", ErrWebhookRejected, err)
 		}
 		return u.paymentService.ProcessPaymentFailed(ctx, orderID)
 	case constant.PaymentStatusPending:
 		// TODO: 결제 대기 처리
+	case constant.PaymentStatusExpired:
+		// 만료 전이는 expired sweeper가 담당한다.
+		log.Logger.Info().Str("provider", payload.Provider).Str("external_id", payload.ExternalID).Msg("Expired webhook received, left to sweeper")
 	default:
-		log.Logger.Error().Msgf("Unknown webhook status: %s", payload.Status)
+		log.Logger.Error().Msgf("Unknown webhook status: %s (provider: %s)", payload.Status, payload.Provider)
 	}
 	return nil
 }
@@ -110,24 +157,18 @@ func (u *paymentUsecase) ProcessStockReserved(ctx context.Context, event models.
 		OrderID:     event.OrderID,
 		UserID:      event.UserID,
 		TotalAmount: event.TotalAmount,
+		Currency:    event.Currency,
+		Provider:    event.Provider,
+		Products:    event.Products,
 	})
 }
 
-func (u *paymentUsecase) DownloadInvoicePdf(ctx context.Context, orderID int64) (string, error) {
-	payment, err := u.paymentService.GetPaymentByOrderID(ctx, orderID)
-	if err != nil {
-		return "", err
-	}
-
-	filePath := fmt.Sprintf("/go-commerce/invoice_%d.pdf", orderID)
-	if err := pdf.GenerateInvoicePdf(payment, filePath); err != nil {
-		return "", err
-	}
-	return filePath, nil
+func (u *paymentUsecase) GetInvoicePdf(ctx context.Context, orderID, userID int64, ifNoneMatch string) (*models.InvoicePdf, error) {
+	return u.invoiceService.GetInvoicePdf(ctx, orderID, userID, ifNoneMatch)
 }
 
-func (u *paymentUsecase) GetFailedPaymentList(ctx context.Context) (models.FailedPaymentList, error) {
-	paymentList, err := u.paymentService.GetFailedPaymentList(ctx)
+func (u *paymentUsecase) GetFailedPaymentList(ctx context.Context, maxRetries, limit int) (models.FailedPaymentList, error) {
+	paymentList, err := u.paymentService.GetFailedPaymentList(ctx, maxRetries, limit)
 	if err != nil {
 		return models.FailedPaymentList{}, err
 	}
@@ -137,6 +178,14 @@ func (u *paymentUsecase) GetFailedPaymentList(ctx context.Context) (models.Faile
 	}, nil
 }
 
+func (u *paymentUsecase) GetUserPayment(ctx context.Context, userID, orderID int64) (*models.Payment, error) {
+	return u.paymentService.GetUserPayment(ctx, userID, orderID)
+}
+
+func (u *paymentUsecase) ListUserPayments(ctx context.Context, filter models.PaymentFilter) (models.PaymentPage, error) {
+	return u.paymentService.ListUserPayments(ctx, filter)
+}
+
 func (u *paymentUsecase) ListAuditLogs(ctx context.Context, filter models.AuditLogFilter) (models.AuditLogPage, error) {
 	return u.paymentService.ListAuditLogs(ctx, filter)
 }
@@ -148,3 +197,15 @@ func (u *paymentUsecase) GetAuditDailyReport(ctx context.Context, from, to time.
 func (u *paymentUsecase) WatchAuditInsertStream(ctx context.Context, out chan<- models.PaymentAuditLog) error {
 	return u.paymentService.WatchAuditInsertStream(ctx, out)
 }
+
+func (u *paymentUsecase) ListFailedEvents(ctx context.Context, filter models.FailedEventFilter) (models.FailedEventPage, error) {
+	return u.paymentService.ListFailedEvents(ctx, filter)
+}
+
+func (u *paymentUsecase) RetryFailedEvent(ctx context.Context, id int64, requestedBy int64) (*models.FailedEvent, error) {
+	return u.paymentService.RetryFailedEvent(ctx, id, requestedBy)
+}
+
+func (u *paymentUsecase) CloseFailedEvent(ctx context.Context, id int64, requestedBy int64, reason string) (*models.FailedEvent, error) {
+	return u.paymentService.CloseFailedEvent(ctx, id, requestedBy, reason)
+}
@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/cmd/payment/service"
+	"paymentfc/constant"
+	bizmetrics "paymentfc/infrastructure/metrics"
+	"paymentfc/log"
+	"paymentfc/models"
+	"strings"
+	"time"
+)
+
+// ErrWebhookRejected 재시도해도 성공할 수 없는 웹훅 (external_id 형식 오류, 금액 불일치 등)
+var ErrWebhookRejected = errors.New("webhook rejected")
+
+// redactedWebhookHeaders 인증 값은 inbox에 남기지 않는다.
+var redactedWebhookHeaders = map[string]bool{
+	"X-Callback-Token": true,
+	"Authorization":    true,
+	"Cookie":           true,
+}
+
+// ReceiveWebhook 검증된 웹훅을 inbox에 저장하고 워커를 깨운다. 이미 받은 이벤트면 duplicate=true.
+func (u *paymentUsecase) ReceiveWebhook(ctx context.Context, event models.PaymentWebhookEvent, header http.Header, body []byte) (*models.WebhookEvent, bool, error) {
+	eventKey := event.EventID
+	if eventKey == "" {
+		// provider가 이벤트 id를 주지 않으면 같은 body의 재전송만 걸러낸다.
+		sum := sha256.Sum256(body)
+		eventKey = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	inbox := &models.WebhookEvent{
+		Provider:      event.Provider,
+		EventKey:      eventKey,
+		ExternalID:    event.ExternalID,
+		PaymentStatus: event.Status,
+		Amount:        event.Amount.String(),
+		Currency:      event.Currency,
+		Headers:       encodeWebhookHeaders(header),
+		Payload:       string(body),
+	}
+	duplicate, err := u.paymentService.SaveWebhookEvent(ctx, inbox)
+	if err != nil {
+		return nil, false, err
+	}
+	if !duplicate {
+		select {
+		case u.webhookNotify <- struct{}{}:
+		default:
+		}
+	}
+	return inbox, duplicate, nil
+}
+
+// ReplayWebhookEvent admin 요청으로 inbox 건을 즉시 다시 처리하고 결과가 반영된 행을 돌려준다.
+func (u *paymentUsecase) ReplayWebhookEvent(ctx context.Context, id int64, requestedBy int64) (*models.WebhookEvent, error) {
+	event, err := u.paymentService.ClaimWebhookEventForReplay(ctx, id, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.processWebhookEvent(ctx, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// WebhookJob inbox의 PENDING/FAILED 건을 처리하는 잡. 주기 실행 외에 웹훅을 받은 직후에도 깨어난다.
+func (u *paymentUsecase) WebhookJob() service.Job {
+	return service.Job{
+		Spec: service.JobSpec{Name: constant.JobProcessWebhookEvents, Schedule: "@every 5s", BatchSize: 20, Timeout: 2 * time.Minute},
+		Run:  u.processWebhookEvents,
+		Wake: u.webhookNotify,
+	}
+}
+
+// processWebhookEvents 짧은 배치가 나올 때까지 BatchSize건씩 잡아 처리한다. timeout이 지나면 남은 건은 다음 실행으로 넘긴다.
+func (u *paymentUsecase) processWebhookEvents(ctx context.Context, spec service.JobSpec) (models.JobResult, error) {
+	var result models.JobResult
+	for ctx.Err() == nil {
+		events, err := u.paymentService.ClaimWebhookEvents(ctx, spec.BatchSize)
+		if err != nil {
+			log.Logger.Error().Err(err).Msg("Failed to claim webhook events")
+			return result, err
+		}
+		for i := range events {
+			if err := u.processWebhookEvent(ctx, &events[i]); err != nil {
+				log.Logger.Error().Err(err).Int64("webhook_event_id", events[i].ID).Msg("Failed to record webhook event result")
+				result.Failed++
+				continue
+			}
+			result.Processed++
+		}
+		if len(events) < spec.BatchSize {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (u *paymentUsecase) processWebhookEvent(ctx context.Context, event *models.WebhookEvent) error {
+	processErr := u.ProcessPaymentWebhook(ctx, event.PaymentEvent())
+
+	outcome := "success"
+	retryable := true
+	switch {
+	case processErr == nil:
+	case repository.IsIllegalTransition(processErr):
+		outcome = "illegal_transition"
+		retryable = false
+		log.Logger.Warn().Err(processErr).Str("external_id", event.ExternalID).Msg("Ignored payment webhook with illegal status transition")
+	case errors.Is(processErr, ErrWebhookRejected):
+		outcome = "rejected"
+		retryable = false
+	default:
+		outcome = "process_error"
+		log.Logger.Error().Err(processErr).Int64("webhook_event_id", event.ID).Int("attempts", event.Attempts).Msg("Failed to process payment webhook")
+	}
+	bizmetrics.ObserveWebhook(event.Provider, outcome)
+
+	return u.paymentService.CompleteWebhookEvent(ctx, event, processErr, retryable)
+}
+
+func encodeWebhookHeaders(header http.Header) string {
+	redacted := make(map[string]string, len(header))
+	for k, v := range header {
+		if redactedWebhookHeaders[http.CanonicalHeaderKey(k)] {
+			redacted[k] = "[REDACTED]"
+			continue
+		}
+		redacted[k] = strings.Join(v, ", ")
+	}
+	b, _ := json.Marshal(redacted)
+	return string(b)
+}
@@ -2,13 +2,16 @@ package usecase
 
 import (
 	"context"
+	"net/http"
 	"paymentfc/cmd/payment/service"
 	"paymentfc/models"
 )
 
 type XenditUsecase interface {
-	CreateInvoice(ctx context.Context, param models.OrderCreatedEvent) (*models.XenditInvoiceResponse, error)
-	CheckInvoiceStatus(ctx context.Context, externalID string) (string, error)
+	CreateInvoice(ctx context.Context, param models.OrderCreatedEvent) (*models.CheckoutResponse, error)
+	CreateInvoiceForUser(ctx context.Context, param models.OrderCreatedEvent, userID int64) (*models.CheckoutResponse, error)
+	CheckInvoiceStatus(ctx context.Context, provider, externalID string) (string, error)
+	ParseWebhook(ctx context.Context, provider string, header http.Header, body []byte) (*models.PaymentWebhookEvent, error)
 }
 
 type xenditUsecase struct {
@@ -19,10 +22,18 @@ func NewXenditUsecase(xenditService service.XenditService) XenditUsecase {
 	return &xenditUsecase{xenditService: xenditService}
 }
 
-func (u *xenditUsecase) CreateInvoice(ctx context.Context, param models.OrderCreatedEvent) (*models.XenditInvoiceResponse, error) {
+func (u *xenditUsecase) CreateInvoice(ctx context.Context, param models.OrderCreatedEvent) (*models.CheckoutResponse, error) {
 	return u.xenditService.CreateInvoice(ctx, param)
 }
 
-func (u *xenditUsecase) CheckInvoiceStatus(ctx context.Context, externalID string) (string, error) {
-	return u.xenditService.CheckInvoiceStatus(ctx, externalID)
+func (u *xenditUsecase) CreateInvoiceForUser(ctx context.Context, param models.OrderCreatedEvent, userID int64) (*models.CheckoutResponse, error) {
+	return u.xenditService.CreateInvoiceForUser(ctx, param, userID)
+}
+
+func (u *xenditUsecase) CheckInvoiceStatus(ctx context.Context, provider, externalID string) (string, error) {
+	return u.xenditService.CheckInvoiceStatus(ctx, provider, externalID)
+}
+
+func (u *xenditUsecase) ParseWebhook(ctx context.Context, provider string, header http.Header, body []byte) (*models.PaymentWebhookEvent, error) {
+	return u.xenditService.ParseWebhook(ctx, provider, header, body)
 }
@@ -21,8 +21,13 @@ func LoadConfig() Config {
 	viper.AutomaticEnv()
 	viper.BindEnv("xendit.secret_api_key", "XENDIT_SECRET_API_KEY")
 	viper.BindEnv("xendit.webhook_token", "XENDIT_WEBHOOK_TOKEN")
+	viper.BindEnv("xendit.base_url", "XENDIT_BASE_URL")
+	viper.BindEnv("xendit.simulator.enabled", "XENDIT_SIMULATOR_ENABLED")
+	viper.BindEnv("midtrans.server_key", "MIDTRANS_SERVER_KEY")
+	viper.BindEnv("gateway.default", "PAYMENT_GATEWAY_DEFAULT")
 	viper.BindEnv("kafka.broker", "KAFKA_BROKER")
 	viper.BindEnv("mongo.uri", "MONGO_URI")
+	viper.BindEnv("scheduler.instance_id", "SCHEDULER_INSTANCE_ID")
 
 	if err := viper.Unmarshal(&cfg); err != nil {
 		log.Fatalf("Failed to unmarshal config: %v", err)
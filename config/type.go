@@ -1,16 +1,22 @@
 package config
 
 type Config struct {
-	App      AppConfig      `yaml:"app" validate:"required"`
-	Database DatabaseConfig `yaml:"database" validate:"required"`
-	Redis    RedisConfig    `yaml:"redis" validate:"required"`
-	Kafka    KafkaConfig    `yaml:"kafka" validate:"required"`
-	Xendit   XenditConfig   `yaml:"xendit"`
-	Mongo    MongoConfig    `yaml:"mongo"`
-	Toggle   ToggleConfig   `yaml:"toggle"`
-	GRPC     GRPCConfig     `yaml:"grpc"`
-	Vault    VaultConfig    `yaml:"vault"`
-	Tracing  TracingConfig  `yaml:"tracing"`
+	App            AppConfig            `yaml:"app" validate:"required"`
+	Database       DatabaseConfig       `yaml:"database" validate:"required"`
+	Redis          RedisConfig          `yaml:"redis" validate:"required"`
+	Kafka          KafkaConfig          `yaml:"kafka" validate:"required"`
+	Xendit         XenditConfig         `yaml:"xendit"`
+	Midtrans       MidtransConfig       `yaml:"midtrans"`
+	Gateway        GatewayConfig        `yaml:"gateway"`
+	Mongo          MongoConfig          `yaml:"mongo"`
+	Toggle         ToggleConfig         `yaml:"toggle"`
+	GRPC           GRPCConfig           `yaml:"grpc"`
+	Vault          VaultConfig          `yaml:"vault"`
+	Tracing        TracingConfig        `yaml:"tracing"`
+	Reconciliation ReconciliationConfig `yaml:"reconciliation"`
+	Invoice        InvoiceConfig        `yaml:"invoice"`
+	Tax            TaxConfig            `yaml:"tax"`
+	Scheduler      SchedulerConfig      `yaml:"scheduler"`
 }
 
 type TracingConfig struct {
@@ -31,6 +37,7 @@ type SecretVaultConfig struct {
 	JWTSecret      string               `json:"jwt_secret"`
 	GRPCCredential string               `json:"grpcCredentials"`
 	XenditSecret   XenditSecretConfig   `json:"xendit"`
+	MidtransSecret MidtransSecretConfig `json:"midtrans"`
 }
 
 type DatabaseSecretConfig struct {
@@ -46,6 +53,10 @@ type XenditSecretConfig struct {
 	WebhookToken string `json:"webhook_token"`
 }
 
+type MidtransSecretConfig struct {
+	ServerKey string `json:"server_key"`
+}
+
 type GRPCConfig struct {
 	UserServiceAddr string `yaml:"user_service_addr" mapstructure:"user_service_addr"`
 	Credentials     string `yaml:"credentials" mapstructure:"credentials"`
@@ -57,12 +68,91 @@ type ToggleConfig struct {
 }
 
 type XenditConfig struct {
-	XenditAPIKey       string `yaml:"secret_api_key" mapstructure:"secret_api_key" validate:"required"`
-	XenditWebhookToken string `yaml:"webhook_token" mapstructure:"webhook_token" validate:"required"`
+	XenditAPIKey       string                `yaml:"secret_api_key" mapstructure:"secret_api_key" validate:"required"`
+	XenditWebhookToken string                `yaml:"webhook_token" mapstructure:"webhook_token" validate:"required"`
+	BaseURL            string                `yaml:"base_url" mapstructure:"base_url"` // 비어 있으면 https://api.xendit.co
+	Simulator          XenditSimulatorConfig `yaml:"simulator" mapstructure:"simulator"`
+}
+
+// XenditSimulatorConfig 로컬/CI용 내장 Xendit 시뮬레이터. 켜면 base_url 대신 시뮬레이터를 호출한다.
+type XenditSimulatorConfig struct {
+	Enabled      bool   `yaml:"enabled" mapstructure:"enabled"`
+	Port         string `yaml:"port" mapstructure:"port"`
+	CallbackURL  string `yaml:"callback_url" mapstructure:"callback_url"` // 비어 있으면 http://localhost:{app.port}/v1/payment/webhook
+	Outcome      string `yaml:"outcome" mapstructure:"outcome"`           // pay, fail, expire, manual
+	DelaySeconds int    `yaml:"delay_seconds" mapstructure:"delay_seconds"`
+}
+
+// ReconciliationConfig 일일 Xendit 대사 잡. run_hour(서버 로컬 시각)에 전날 하루치를 대사한다.
+// run_hour는 daily_reconciliation 잡의 기본 스케줄이고 scheduler.jobs.daily_reconciliation.schedule이 있으면 그쪽을 쓴다.
+type ReconciliationConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	RunHour int  `yaml:"run_hour" mapstructure:"run_hour"`
+	AutoFix bool `yaml:"auto_fix" mapstructure:"auto_fix"` // true: Xendit PAID + 로컬 PENDING + 금액 일치 건을 PAID로 보정
+}
+
+// InvoiceConfig 인보이스 PDF 판매자 정보. timezone이 비어 있거나 잘못되면 WIB(UTC+7)로 표기한다.
+type InvoiceConfig struct {
+	CompanyName    string `yaml:"company_name" mapstructure:"company_name"`
+	CompanyAddress string `yaml:"company_address" mapstructure:"company_address"`
+	CompanyEmail   string `yaml:"company_email" mapstructure:"company_email"`
+	CompanyPhone   string `yaml:"company_phone" mapstructure:"company_phone"`
+	TaxID          string `yaml:"tax_id" mapstructure:"tax_id"` // NPWP
+	Timezone       string `yaml:"timezone" mapstructure:"timezone"`
+}
+
+// TaxConfig 인보이스 생성 시 적용하는 PPN. rate_bps는 basis point (1100 = 11%).
+type TaxConfig struct {
+	Enabled          bool     `yaml:"enabled" mapstructure:"enabled"`
+	RateBps          int64    `yaml:"rate_bps" mapstructure:"rate_bps"`
+	Inclusive        bool     `yaml:"inclusive" mapstructure:"inclusive"`                 // true: 주문 금액에 세금 포함(역산), false: 세금을 더해 청구
+	ExemptCategories []string `yaml:"exempt_categories" mapstructure:"exempt_categories"` // 면세 상품 카테고리
+}
+
+// SchedulerConfig 스케줄러 row claim 설정. instance_id가 비어 있으면 hostname-pid를 쓴다.
+// claim_lease_seconds 안에 처리를 끝내지 못한 건은 lease가 끝난 뒤 다른 인스턴스가 다시 잡는다.
+type SchedulerConfig struct {
+	InstanceID          string               `yaml:"instance_id" mapstructure:"instance_id"`
+	ClaimLeaseSeconds   int                  `yaml:"claim_lease_seconds" mapstructure:"claim_lease_seconds"`
+	LeaderElection      LeaderElectionConfig `yaml:"leader_election" mapstructure:"leader_election"`
+	Jobs                map[string]JobConfig `yaml:"jobs" mapstructure:"jobs"`                                     // 키는 잡 이름 (check_pending_invoices 등)
+	JobRunRetentionDays int                  `yaml:"job_run_retention_days" mapstructure:"job_run_retention_days"` // job_runs 보관 기간. 0이면 14일
+}
+
+// JobConfig 잡별 기본값 덮어쓰기. 0이나 빈 값인 항목은 기본값을 유지한다.
+// schedule은 "@every 5s" 같은 간격 또는 5필드 cron("*/10 * * * *")이다.
+type JobConfig struct {
+	Schedule       string `yaml:"schedule" mapstructure:"schedule"`
+	BatchSize      int    `yaml:"batch_size" mapstructure:"batch_size"`
+	TimeoutSeconds int    `yaml:"timeout_seconds" mapstructure:"timeout_seconds"`
+	MaxRetries     int    `yaml:"max_retries" mapstructure:"max_retries"`
+}
+
+// LeaderElectionConfig 싱글톤 잡(미결제 인보이스 확인, 만료 처리, 일일 대사)을 한 인스턴스에서만 돌리기 위한 Redis lease.
+// 꺼져 있으면 모든 인스턴스가 싱글톤 잡을 실행한다.
+type LeaderElectionConfig struct {
+	Enabled    bool   `yaml:"enabled" mapstructure:"enabled"`
+	Key        string `yaml:"key" mapstructure:"key"`
+	TTLSeconds int    `yaml:"ttl_seconds" mapstructure:"ttl_seconds"`
+}
+
+// MidtransConfig server_key가 비어 있으면 midtrans 게이트웨이를 등록하지 않는다.
+type MidtransConfig struct {
+	ServerKey   string `yaml:"server_key" mapstructure:"server_key"`
+	APIBaseURL  string `yaml:"api_base_url" mapstructure:"api_base_url"`
+	SnapBaseURL string `yaml:"snap_base_url" mapstructure:"snap_base_url"`
+	// TimeoutSeconds Midtrans API 호출 타임아웃. 0이면 10초.
+	TimeoutSeconds int `yaml:"timeout_seconds" mapstructure:"timeout_seconds"`
+}
+
+// GatewayConfig 주문 이벤트에 provider가 없을 때 쓰는 기본 결제 게이트웨이 (xendit, midtrans)
+type GatewayConfig struct {
+	Default string `yaml:"default" mapstructure:"default"`
 }
 
 type AppConfig struct {
-	Port string `yaml:"port" validate:"required"`
+	Port                   string `yaml:"port" validate:"required"`
+	ShutdownTimeoutSeconds int    `yaml:"shutdown_timeout_seconds" mapstructure:"shutdown_timeout_seconds"` // SIGTERM 후 HTTP 드레인/워커 종료 대기 시간
 }
 
 type DatabaseConfig struct {
@@ -83,6 +173,8 @@ type KafkaConfig struct {
 	Broker  string              `yaml:"broker" validate:"required"`
 	Topics  []map[string]string `yaml:"topics" validate:"required"`
 	GroupID string              `yaml:"group_id" validate:"required"`
+	// RetryDelaysSeconds 컨슈머 재시도 단계별 대기 시간. 단계마다 stock.reserved.retry.<대기 시간> 토픽을 쓰고, 모두 쓰면 DLQ로 보낸다. 비어 있으면 10초, 1분, 5분.
+	RetryDelaysSeconds []int `yaml:"retry_delays_seconds" mapstructure:"retry_delays_seconds"`
 }
 
 type MongoConfig struct {
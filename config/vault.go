@@ -73,6 +73,10 @@ func LoadVaultSecrets(cfg *Config) {
 		cfg.Xendit.XenditWebhookToken = secretConfig.XenditSecret.WebhookToken
 		log.Println("Xendit webhook token loaded from Vault")
 	}
+	if secretConfig.MidtransSecret.ServerKey != "" {
+		cfg.Midtrans.ServerKey = secretConfig.MidtransSecret.ServerKey
+		log.Println("Midtrans server key loaded from Vault")
+	}
 	if secretConfig.GRPCCredential != "" {
 		cfg.GRPC.Credentials = secretConfig.GRPCCredential
 		log.Println("gRPC credentials loaded from Vault")
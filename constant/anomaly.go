@@ -2,10 +2,26 @@ package constant
 
 const (
 	AnomalyTypeInvalidAmount = 1
+	// AnomalyTypeIllegalTransition 만료/실패 처리된 결제에 PAID 웹훅이 도착한 경우
+	AnomalyTypeIllegalTransition = 2
 )
 
 const (
-	PaymentAnomalyStatusSuccess     = 1
-	PaymentAnomalyStatusRetry       = 2
-	PaymentAnomalyStatusNeedToCheck = 99
+	PaymentAnomalyStatusSuccess = 1
+	// PaymentAnomalyStatusRetry 조치(결제 확정/환불)가 실패해 다시 처리해야 하는 건
+	PaymentAnomalyStatusRetry = 2
+	// PaymentAnomalyStatusResolving admin 조치가 진행 중인 건 (동시 처리 방지)
+	PaymentAnomalyStatusResolving = 3
+	// PaymentAnomalyStatusFalsePositive 확인 결과 문제가 없는 건
+	PaymentAnomalyStatusFalsePositive = 4
+	PaymentAnomalyStatusNeedToCheck   = 99
+)
+
+// payment anomaly 조치 유형
+const (
+	// AnomalyResolutionAcceptPaidAmount 받은 금액을 그대로 인정하고 결제를 확정한다.
+	AnomalyResolutionAcceptPaidAmount = "ACCEPT_PAID_AMOUNT"
+	// AnomalyResolutionRefundDifference 결제를 확정하고 초과 입금액을 환불한다.
+	AnomalyResolutionRefundDifference = "REFUND_DIFFERENCE"
+	AnomalyResolutionFalsePositive    = "FALSE_POSITIVE"
 )
@@ -1,11 +1,21 @@
 package constant
 
 const (
-	FailedPublishEventPaymentSuccess = 1
+	FailedPublishEventPaymentSuccess  = 1
+	FailedPublishEventPaymentRefunded = 2
+	FailedPublishEventPaymentFailed   = 3
+	FailedPublishEventPaymentExpired  = 4
+	// FailedPublishEventPaymentRequestFailed payment.request_failed. 사유가 outbox 원본에만 있어 원본 없이는 다시 만들 수 없다.
+	FailedPublishEventPaymentRequestFailed = 5
 )
 
 const (
-	FailedPublishEventStatusSuccess     = 1
-	FailedPublishEventStatusRetry       = 2
+	FailedPublishEventStatusSuccess = 1
+	FailedPublishEventStatusRetry   = 2
+	// FailedPublishEventStatusClosed admin이 재처리 없이 종료한 건
+	FailedPublishEventStatusClosed      = 3
 	FailedPublishEventStatusNeedToCheck = 99
 )
+
+// MaxFailedEventAttempts 재처리 잡이 자동으로 시도하는 최대 횟수. 넘으면 NeedToCheck로 두고 admin retry만 가능하다.
+const MaxFailedEventAttempts = 5
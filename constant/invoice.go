@@ -0,0 +1,8 @@
+package constant
+
+const (
+	// InvoiceNumberPrefix 인보이스 번호 형식: INV/YYYY/MM/000123
+	InvoiceNumberPrefix = "INV"
+	// InvoiceNumberTimezone 인보이스 번호의 월 구분 기준 시각 (결제 완료 시점의 WIB 월)
+	InvoiceNumberTimezone = "Asia/Jakarta"
+)
@@ -0,0 +1,27 @@
+package constant
+
+// 스케줄러 잡 실행(job_runs) 상태
+const (
+	JobRunStatusSucceeded = "SUCCEEDED"
+	JobRunStatusFailed    = "FAILED"
+	JobRunStatusTimedOut  = "TIMED_OUT"
+)
+
+// 잡 실행 트리거
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+// 스케줄러 잡 이름. /api/v1/admin/jobs/:name과 config의 jobs 키로 쓴다.
+const (
+	JobCheckPendingInvoices       = "check_pending_invoices"
+	JobProcessPaymentRequests     = "process_payment_requests"
+	JobRetryFailedPaymentRequests = "retry_failed_payment_requests"
+	JobSweepExpiredPayments       = "sweep_expired_payments"
+	JobRelayOutboxEvents          = "relay_outbox_events"
+	JobReprocessFailedEvents      = "reprocess_failed_events"
+	JobDailyReconciliation        = "daily_reconciliation"
+	JobProcessWebhookEvents       = "process_webhook_events"
+	JobPruneJobRuns               = "prune_job_runs"
+)
@@ -0,0 +1,13 @@
+package constant
+
+// outbox 발행 상태
+const (
+	OutboxStatusPending    = "PENDING"
+	OutboxStatusProcessing = "PROCESSING"
+	OutboxStatusSent       = "SENT"
+	// OutboxStatusDead 재시도 한도를 넘겨 failed_events로 넘긴 건
+	OutboxStatusDead = "DEAD"
+)
+
+// MaxOutboxAttempts 이 횟수만큼 발행에 실패하면 DEAD로 닫고 failed_events에 남긴다.
+const MaxOutboxAttempts = 10
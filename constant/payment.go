@@ -5,13 +5,30 @@ const (
 	PaymentStatusFailed  = "FAILED"
 	PaymentStatusPending = "PENDING"
 	PaymentStatusExpired = "EXPIRED"
+
+	PaymentStatusPartiallyRefunded = "PARTIALLY_REFUNDED"
+	PaymentStatusRefunded          = "REFUNDED"
+
+	// PaymentRequestStatusDead 재시도할 수 없거나 재시도 한도를 넘긴 payment_requests 종료 상태
+	PaymentRequestStatusDead = "DEAD"
 )
 
 const (
-	KafkaTopicPaymentSuccess = "payment.success"
-	KafkaTopicOrderCreated   = "order.created"
-	KafkaTopicStockReserved  = "stock.reserved"
+	KafkaTopicPaymentSuccess  = "payment.success"
+	KafkaTopicPaymentRefunded = "payment.refunded"
+	KafkaTopicPaymentFailed   = "payment.failed"
+	KafkaTopicPaymentExpired  = "payment.expired"
+	KafkaTopicOrderCreated    = "order.created"
+	KafkaTopicStockReserved   = "stock.reserved"
+	// stock.reserved 처리에 실패한 메시지는 대기 단계별 retry 토픽(stock.reserved.retry.10s, .1m, .5m)에서 재시도되고, 단계를 모두 쓰면 dlq로 간다.
+	KafkaTopicStockReservedRetry = "stock.reserved.retry" // 단계별 토픽 prefix
+	KafkaTopicStockReservedDLQ   = "stock.reserved.dlq"
+	// KafkaTopicPaymentRequestFailed 결제 요청이 DEAD가 되면 발행한다. order/stock 서비스가 예약을 해제한다.
+	KafkaTopicPaymentRequestFailed = "payment.request_failed"
 )
 
-// MaxRetryPublish payment.success Kafka 발행 최대 재시도 횟수
-const MaxRetryPublish = 3
+// 결제 게이트웨이(provider) 이름. payments.provider 컬럼과 웹훅 경로(/v1/payment/webhook/:provider)에 쓰인다.
+const (
+	PaymentProviderXendit   = "xendit"
+	PaymentProviderMidtrans = "midtrans"
+)
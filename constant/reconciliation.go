@@ -0,0 +1,37 @@
+package constant
+
+// reconciliation run 상태
+const (
+	ReconciliationRunStatusRunning   = "RUNNING"
+	ReconciliationRunStatusCompleted = "COMPLETED"
+	ReconciliationRunStatusFailed    = "FAILED"
+)
+
+// reconciliation run 실행 주체
+const (
+	ReconciliationTriggerScheduler = "scheduler"
+	ReconciliationTriggerAdmin     = "admin"
+)
+
+// Xendit 인보이스와 payments 행의 불일치 유형
+const (
+	// ReconciliationPaidPendingLocally Xendit에서는 PAID인데 로컬은 아직 PENDING (웹훅 유실)
+	ReconciliationPaidPendingLocally = "PAID_PENDING_LOCALLY"
+	// ReconciliationPaidExpiredLocally Xendit에서는 PAID인데 로컬은 EXPIRED/FAILED로 닫힌 건
+	ReconciliationPaidExpiredLocally = "PAID_EXPIRED_LOCALLY"
+	// ReconciliationNotPaidRemotely 로컬은 PAID 계열인데 Xendit에서는 결제되지 않은 건
+	ReconciliationNotPaidRemotely = "NOT_PAID_REMOTELY"
+	ReconciliationAmountMismatch  = "AMOUNT_MISMATCH"
+	// ReconciliationMissingLocal Xendit 인보이스에 대응하는 payments 행이 없는 건
+	ReconciliationMissingLocal = "MISSING_LOCAL"
+)
+
+// reconciliation item 자동 보정 결과
+const (
+	// ReconciliationFixManual 자동 보정 대상이 아니라 사람이 확인해야 하는 건
+	ReconciliationFixManual = "MANUAL"
+	// ReconciliationFixAvailable 안전하게 보정할 수 있지만 auto_fix가 꺼진 run이라 보정하지 않은 건
+	ReconciliationFixAvailable = "FIXABLE"
+	ReconciliationFixFixed     = "FIXED"
+	ReconciliationFixFailed    = "FAILED"
+)
@@ -0,0 +1,20 @@
+package constant
+
+const (
+	RefundStatusPending   = "PENDING"
+	RefundStatusSucceeded = "SUCCEEDED"
+	RefundStatusFailed    = "FAILED"
+)
+
+// Xendit refund API가 허용하는 reason 값
+const (
+	RefundReasonFraudulent          = "FRAUDULENT"
+	RefundReasonDuplicate           = "DUPLICATE"
+	RefundReasonRequestedByCustomer = "REQUESTED_BY_CUSTOMER"
+	RefundReasonCancellation        = "CANCELLATION"
+	RefundReasonOthers              = "OTHERS"
+)
+
+// RefundReasonOverpayment 초과 입금액 환불. refunds.reason에만 쓰이고 게이트웨이에는 OTHERS로 보낸다.
+// payment 금액 대비 누적 환불액에는 포함하지 않는다.
+const RefundReasonOverpayment = "OVERPAYMENT"
@@ -0,0 +1,8 @@
+package constant
+
+// settlement report 집계 단위 (Postgres date_trunc 단위와 같다)
+const (
+	ReportGroupByDay   = "day"
+	ReportGroupByWeek  = "week"
+	ReportGroupByMonth = "month"
+)
@@ -0,0 +1,9 @@
+package constant
+
+// 사용자 역할. JWT의 role 클레임 또는 user 서비스의 role 값과 같다. 값이 없으면 customer로 본다.
+const (
+	RoleCustomer = "customer"
+	RoleOps      = "ops"
+	RoleFinance  = "finance"
+	RoleAdmin    = "admin"
+)
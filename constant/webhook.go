@@ -0,0 +1,14 @@
+package constant
+
+// webhook_events(inbox) 처리 상태
+const (
+	WebhookEventStatusPending    = "PENDING"
+	WebhookEventStatusProcessing = "PROCESSING"
+	WebhookEventStatusProcessed  = "PROCESSED"
+	WebhookEventStatusFailed     = "FAILED"
+	// WebhookEventStatusIgnored 재시도해도 결과가 같은 건 (불가능한 상태 전이, 금액 불일치 등)
+	WebhookEventStatusIgnored = "IGNORED"
+)
+
+// MaxWebhookEventAttempts 이 횟수만큼 실패하면 워커가 더 이상 가져가지 않는다 (admin replay로만 재처리).
+const MaxWebhookEventAttempts = 5
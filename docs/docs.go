@@ -15,32 +15,32 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/audit-logs": {
+        "/api/v1/admin/anomalies": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "필터/커서 기반으로 결제 감사 로그를 조회합니다.",
+                "description": "payment_anomalies를 유형/상태/주문/기간으로 필터링해 커서(id) 기반으로 조회합니다.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "PAYMENT"
+                    "ADMIN"
                 ],
-                "summary": "감사 로그 조회",
+                "summary": "결제 이상 건 조회",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "이벤트명",
-                        "name": "event",
+                        "type": "integer",
+                        "description": "유형 (1=invalid_amount, 2=illegal_transition)",
+                        "name": "type",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "행위자",
-                        "name": "actor",
+                        "type": "integer",
+                        "description": "상태 (1=success, 2=retry, 3=resolving, 4=false_positive, 99=need_to_check)",
+                        "name": "status",
                         "in": "query"
                     },
                     {
@@ -50,33 +50,27 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "사용자 ID",
-                        "name": "user_id",
+                        "type": "string",
+                        "description": "생성 시작 시각(RFC3339 또는 YYYY-MM-DD)",
+                        "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "커서(ObjectID)",
-                        "name": "cursor",
+                        "description": "생성 종료 시각(RFC3339 또는 YYYY-MM-DD)",
+                        "name": "to",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "조회 개수",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "조회 시작 시각(RFC3339 또는 YYYY-MM-DD)",
-                        "name": "from",
+                        "description": "이전 페이지 next_cursor",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "조회 종료 시각(RFC3339 또는 YYYY-MM-DD)",
-                        "name": "to",
+                        "type": "integer",
+                        "description": "조회 개수 (기본 20, 최대 100)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -84,8 +78,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.PaymentAnomalyPage"
                         }
                     },
                     "401": {
@@ -95,6 +88,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -105,38 +105,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/audit-report/daily": {
+        "/api/v1/admin/anomalies/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "기간 내 감사 로그를 일자/이벤트 기준으로 집계합니다.",
+                "description": "이상 건과 해당 결제, 상태 변경 이력, 주문의 감사 로그를 함께 조회합니다.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "PAYMENT"
+                    "ADMIN"
                 ],
-                "summary": "감사 로그 일별 리포트",
+                "summary": "결제 이상 건 상세",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "조회 시작 시각(RFC3339 또는 YYYY-MM-DD)",
-                        "name": "from",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "조회 종료 시각(RFC3339 또는 YYYY-MM-DD)",
-                        "name": "to",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "payment_anomalies ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PaymentAnomalyDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -149,6 +150,20 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -159,30 +174,54 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/failed_payments": {
-            "get": {
+        "/api/v1/admin/anomalies/{id}/resolve": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "실패한 결제 목록을 조회합니다.",
+                "description": "ACCEPT_PAID_AMOUNT(결제 확정), REFUND_DIFFERENCE(결제 확정 후 초과 입금액 환불), FALSE_POSITIVE(문제 없음) 중 하나로 조치합니다. 조치가 실패하면 502와 함께 Retry 상태로 남긴 건을 돌려줍니다.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "PAYMENT"
+                    "ADMIN"
+                ],
+                "summary": "결제 이상 건 조치",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "payment_anomalies ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "조치 요청",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ResolveAnomalyRequest"
+                        }
+                    }
                 ],
-                "summary": "실패 결제 목록 조회",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object",
-                                "additionalProperties": true
-                            }
+                            "$ref": "#/definitions/models.PaymentAnomaly"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "401": {
@@ -192,8 +231,36 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -202,46 +269,81 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/invoice/{order_id}/pdf": {
+        "/api/v1/admin/failed-events": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "주문 ID에 대한 인보이스 PDF 파일을 다운로드합니다.",
+                "description": "failed_events를 상태/유형/주문/기간으로 필터링해 커서(id) 기반으로 조회합니다.",
                 "produces": [
-                    "application/pdf"
+                    "application/json"
                 ],
                 "tags": [
-                    "PAYMENT"
+                    "ADMIN"
                 ],
-                "summary": "인보이스 PDF 다운로드",
+                "summary": "발행 실패 이벤트 조회",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "상태 (1=success, 2=retry, 3=closed, 99=need_to_check)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "유형 (1=success, 2=refunded, 3=failed, 4=expired)",
+                        "name": "failed_type",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "주문 ID",
                         "name": "order_id",
-                        "in": "path",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "생성 시작 시각(RFC3339 또는 YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "생성 종료 시각(RFC3339 또는 YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "이전 페이지 next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "조회 개수 (기본 20, 최대 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/models.FailedEventPage"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -257,14 +359,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/payment/invoice": {
+        "/api/v1/admin/failed-events/{id}/close": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "주문 정보로 Xendit 인보이스를 생성합니다.",
+                "description": "재발행 없이 수동 확인 완료로 닫습니다.",
                 "consumes": [
                     "application/json"
                 ],
@@ -272,17 +374,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "PAYMENT"
+                    "ADMIN"
                 ],
-                "summary": "인보이스 생성",
+                "summary": "발행 실패 이벤트 종료",
                 "parameters": [
                     {
-                        "description": "인보이스 생성 요청",
+                        "type": "integer",
+                        "description": "failed_events ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "종료 사유",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.OrderCreatedEvent"
+                            "$ref": "#/definitions/models.CloseFailedEventRequest"
                         }
                     }
                 ],
@@ -290,8 +399,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.FailedEvent"
                         }
                     },
                     "400": {
@@ -308,31 +416,25 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/debug/mongo/stream": {
-            "get": {
-                "description": "MongoDB Change Stream 기반의 감사 로그 SSE 스트림입니다.",
-                "produces": [
-                    "text/event-stream"
-                ],
-                "tags": [
-                    "PAYMENT"
-                ],
-                "summary": "감사 로그 스트림",
-                "responses": {
-                    "200": {
-                        "description": "SSE stream",
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "string"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "500": {
@@ -345,43 +447,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/payment/webhook": {
+        "/api/v1/admin/failed-events/{id}/retry": {
             "post": {
-                "description": "Xendit 결제 이벤트 웹훅을 검증하고 처리합니다.",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "시도 횟수와 관계없이 즉시 재발행합니다. 재발행이 실패하면 502와 함께 갱신된 건을 돌려줍니다.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "PAYMENT"
+                    "ADMIN"
                 ],
-                "summary": "Xendit 웹훅 처리",
+                "summary": "발행 실패 이벤트 재발행",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Xendit callback token",
-                        "name": "x-callback-token",
-                        "in": "header",
+                        "type": "integer",
+                        "description": "failed_events ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "웹훅 페이로드",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.XenditWebhookPayload"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.FailedEvent"
                         }
                     },
                     "400": {
@@ -398,35 +492,2612 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "등록된 잡의 주기, 배치 크기, 타임아웃, 일시정지 여부와 이 인스턴스 기준 실행 상태를 조회합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "스케줄러 잡 목록",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.JobStatus"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/jobs/{name}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "모든 인스턴스에서 해당 잡의 스케줄 실행을 멈춥니다. 진행 중인 실행은 끝까지 돌고, 수동 실행은 계속 가능합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "잡 일시정지",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "잡 이름",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JobStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/jobs/{name}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "일시정지한 잡의 스케줄 실행을 다시 시작합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "잡 재개",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "잡 이름",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JobStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/jobs/{name}/runs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "job_runs를 상태로 필터링해 커서(id) 기반으로 조회합니다. 처리한 건이 없던 스케줄 실행은 기록하지 않습니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "잡 실행 이력",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "잡 이름",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "상태 (SUCCEEDED, FAILED, TIMED_OUT)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "이전 페이지 next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "조회 개수 (기본 20, 최대 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JobRunPage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/jobs/{name}/trigger": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "다음 스케줄을 기다리지 않고 요청을 받은 인스턴스에서 잡을 바로 한 번 실행합니다. 리더 전용 잡은 리더 인스턴스에서만, 일시정지된 잡은 force=true일 때만 실행되며, 결과는 실행 이력에서 확인합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "잡 수동 실행",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "잡 이름",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "일시정지된 잡도 실행",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.JobStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/kafka/stock-reserved/dlq/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "stock.reserved.dlq 메시지를 최대 limit개 읽어 원래 토픽으로 다시 발행합니다. 재시도 헤더는 지워져 재시도 단계를 처음부터 밟고, 재처리한 메시지만 커밋됩니다. 중간에 실패하면 그때까지 재처리한 개수와 에러를 500으로 돌려줍니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "stock.reserved DLQ 재처리",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "재처리 개수 (기본 100, 최대 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DLQReplayResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.DLQReplayResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/reconciliations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "reconciliation run을 상태로 필터링해 커서(id) 기반으로 조회합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "Xendit 대사 실행 목록",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "상태 (RUNNING, COMPLETED, FAILED)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "이전 페이지 next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "조회 개수 (기본 20, 최대 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReconciliationRunPage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "기간(from 이상, to 미만)에 생성된 Xendit 인보이스를 payments와 대사합니다. 백그라운드로 실행되며 run 조회로 결과를 확인합니다.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "Xendit 대사 실행",
+                "parameters": [
+                    {
+                        "description": "대사 기간 (최대 31일)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateReconciliationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReconciliationRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/reconciliations/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "run 상태와 인보이스/일치/불일치/보정 건수를 조회합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "Xendit 대사 실행 조회",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "reconciliation run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReconciliationRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/reconciliations/{id}/items": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "run에서 발견한 불일치 항목을 유형/보정 결과로 필터링해 커서(id) 기반으로 조회합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "Xendit 대사 불일치 항목 조회",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "reconciliation run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "불일치 유형 (PAID_PENDING_LOCALLY, PAID_EXPIRED_LOCALLY, NOT_PAID_REMOTELY, AMOUNT_MISMATCH, MISSING_LOCAL)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "보정 결과 (MANUAL, FIXABLE, FIXED, FAILED)",
+                        "name": "fix_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "이전 페이지 next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "조회 개수 (기본 20, 최대 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReconciliationItemPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/reports/settlement": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "payments/refunds를 기간(from 이상, to 미만)과 timezone 기준 day/week/month 단위, 통화별로 집계합니다. format=csv 또는 Accept: text/csv면 CSV로 스트리밍합니다.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "정산/매출 리포트",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "시작(RFC3339 또는 YYYY-MM-DD, 날짜는 timezone 기준). 기본 to - 30일",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "종료(RFC3339 또는 YYYY-MM-DD, 날짜는 timezone 기준). 기본 내일 0시",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "집계 단위 (day, week, month). 기본 day",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA timezone (예: Asia/Jakarta). 기본 UTC",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json 또는 csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SettlementReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/scheduler/leader": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "이 인스턴스가 싱글톤 잡(미결제 인보이스 확인, 만료 처리, 일일 대사) 리더인지와, Redis lease 키에서 읽은 현재 리더(leader)와 남은 lease 시간(lease_ttl_ms)을 조회합니다. 리더 선출이 꺼져 있으면 enabled=false, is_leader=true입니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "스케줄러 리더 상태",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LeaderStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "webhook inbox에 저장된 웹훅을 상태와 관계없이 즉시 다시 처리합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ADMIN"
+                ],
+                "summary": "웹훅 재처리",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "webhook event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/audit-logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "필터/커서 기반으로 결제 감사 로그를 조회합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PAYMENT"
+                ],
+                "summary": "감사 로그 조회",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "이벤트명",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "행위자",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "주문 ID",
+                        "name": "order_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "사용자 ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "커서(ObjectID)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "조회 개수",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "조회 시작 시각(RFC3339 또는 YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "조회 종료 시각(RFC3339 또는 YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/audit-report/daily": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "기간 내 감사 로그를 일자/이벤트 기준으로 집계합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PAYMENT"
+                ],
+                "summary": "감사 로그 일별 리포트",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "조회 시작 시각(RFC3339 또는 YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "조회 종료 시각(RFC3339 또는 YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/failed_payments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "재시도 잡이 다시 처리할 FAILED 결제 요청을 조회합니다. 재시도 한도와 조회 개수는 retry_failed_payment_requests 잡 설정(max_retries, batch_size)을 따르고 DEAD 요청은 빠집니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PAYMENT"
+                ],
+                "summary": "실패 결제 목록 조회",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/invoice/{order_id}/pdf": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "본인 주문의 인보이스 PDF를 메모리에서 렌더링(또는 캐시)해 내려줍니다. If-None-Match가 ETag와 같으면 304를 돌려줍니다.",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "PAYMENT"
+                ],
+                "summary": "인보이스 PDF 다운로드",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "주문 ID",
+                        "name": "order_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "이전 응답의 ETag",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment/invoice": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "주문 정보로 Xendit 인보이스를 생성합니다. 주문 소유자는 토큰의 user_id로 확인하고, 금액은 저장된 결제 요청(payment_requests)과 대조합니다. 품목과 게이트웨이는 저장된 결제 요청 값을 쓰며 본문의 products/provider는 무시합니다.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PAYMENT"
+                ],
+                "summary": "인보이스 생성",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "재시도 시 같은 응답을 돌려받기 위한 키 (24시간 보관)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "인보이스 생성 요청 (user_id는 생략 가능, total_amount를 생략하면 저장된 금액)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderCreatedEvent"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payment/{order_id}/refunds": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "결제 완료된 주문에 대해 전액 또는 부분 환불을 요청합니다. amount를 생략(0)하면 남은 금액 전액을 환불합니다.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PAYMENT"
+                ],
+                "summary": "환불 요청",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "주문 ID",
+                        "name": "order_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "환불 요청",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefundRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Refund"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "토큰의 user_id로 본인 결제만 상태/기간으로 필터링해 커서(id) 기반으로 조회합니다. invoice_url, expired_time, status를 포함합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PAYMENT"
+                ],
+                "summary": "내 결제 목록 조회",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "상태 (PENDING, PAID, EXPIRED, FAILED, PARTIALLY_REFUNDED, REFUNDED)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "생성 시작 시각(RFC3339 또는 YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "생성 종료 시각(RFC3339 또는 YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "이전 페이지 next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "조회 개수 (기본 20, 최대 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PaymentPage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/payments/{order_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "주문 ID의 결제 상태를 조회합니다. 다른 사용자의 주문은 404로 응답합니다.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PAYMENT"
+                ],
+                "summary": "내 결제 상세 조회",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "주문 ID",
+                        "name": "order_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/debug/mongo/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "MongoDB Change Stream 기반의 감사 로그 SSE 스트림입니다.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "PAYMENT"
+                ],
+                "summary": "감사 로그 스트림",
+                "responses": {
+                    "200": {
+                        "description": "SSE stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/payment/webhook": {
+            "post": {
+                "description": "Xendit 결제 이벤트 웹훅을 검증하고 처리합니다.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PAYMENT"
+                ],
+                "summary": "Xendit 웹훅 처리",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Xendit callback token",
+                        "name": "x-callback-token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "웹훅 페이로드",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.XenditWebhookPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/payment/webhook/{provider}": {
+            "post": {
+                "description": "provider별(xendit, midtrans) 웹훅 서명을 검증하고 공통 결제 웹훅 흐름으로 처리합니다.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PAYMENT"
+                ],
+                "summary": "결제 게이트웨이 웹훅 처리",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "게이트웨이 (xendit, midtrans)",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "provider 원본 웹훅 페이로드",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "models.CloseFailedEventRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateReconciliationRequest": {
+            "type": "object",
+            "required": [
+                "from",
+                "to"
+            ],
+            "properties": {
+                "auto_fix": {
+                    "type": "boolean"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DLQReplayResult": {
+            "type": "object",
+            "properties": {
+                "dlq_topic": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "replayed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FailedEvent": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "create_time": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "failed_type": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "next_attempt_time": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "outbox_id": {
+                    "description": "OutboxID relay가 넘긴 건이면 원본 outbox 메시지를 그대로 재발행한다 (0이면 failed_type으로 다시 만든다)",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "update_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FailedEventPage": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FailedEvent"
+                    }
+                },
+                "next_cursor": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.JobRun": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "instance_id": {
+                    "type": "string"
+                },
+                "items_failed": {
+                    "type": "integer"
+                },
+                "items_processed": {
+                    "type": "integer"
+                },
+                "job_name": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "trigger": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JobRunPage": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "integer"
+                },
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JobRun"
+                    }
+                }
+            }
+        },
+        "models.JobStatus": {
+            "type": "object",
+            "properties": {
+                "batch_size": {
+                    "type": "integer"
+                },
+                "last_run": {
+                    "$ref": "#/definitions/models.JobRun"
+                },
+                "leader_only": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_time": {
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "running": {
+                    "type": "boolean"
+                },
+                "schedule": {
+                    "type": "string"
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LeaderStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "instance_id": {
+                    "type": "string"
+                },
+                "is_leader": {
+                    "type": "boolean"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "leader": {
+                    "description": "lease를 잡고 있는 인스턴스. 비어 있으면 리더 없음",
+                    "type": "string"
+                },
+                "leader_since": {
+                    "type": "string"
+                },
+                "lease_ttl_ms": {
+                    "description": "lease 남은 시간",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LineItem": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "unit_price": {
+                    "$ref": "#/definitions/models.Money"
+                }
+            }
+        },
+        "models.Money": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "minor": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.OrderCreatedEvent": {
             "type": "object",
             "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "payment_method": {
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductItem"
+                    }
+                },
+                "provider": {
+                    "description": "결제 게이트웨이 (xendit, midtrans). 비어 있으면 기본 게이트웨이",
+                    "type": "string"
+                },
+                "shipping_address": {
+                    "type": "string"
+                },
+                "total_amount": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/models.Money"
+                },
+                "create_time": {
+                    "type": "string"
+                },
+                "expired_time": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_id": {
+                    "type": "string"
+                },
+                "invoice_number": {
+                    "description": "PAID 전이 시 발급 (INV/2026/10/000123). 미결제 건은 NULL",
+                    "type": "string"
+                },
+                "invoice_url": {
+                    "description": "고객이 결제할 게이트웨이 체크아웃 URL",
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LineItem"
+                    }
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tax": {
+                    "description": "Amount에 포함된 세액",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Money"
+                        }
+                    ]
+                },
+                "tax_base": {
+                    "description": "과세 표준 (DPP)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Money"
+                        }
+                    ]
+                },
+                "tax_inclusive": {
+                    "type": "boolean"
+                },
+                "tax_rate_bps": {
+                    "type": "integer"
+                },
+                "update_time": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PaymentAnomaly": {
+            "type": "object",
+            "properties": {
+                "anomaly_type": {
+                    "type": "integer"
+                },
+                "create_time": {
+                    "type": "string"
+                },
+                "expected_amount": {
+                    "description": "ExpectedAmount/PaidAmount 금액 불일치 건의 주문 금액과 실제 입금액 (그 외 유형은 0)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Money"
+                        }
+                    ]
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "paid_amount": {
+                    "$ref": "#/definitions/models.Money"
+                },
+                "refund_id": {
+                    "type": "integer"
+                },
+                "resolution": {
+                    "type": "string"
+                },
+                "resolve_reason": {
+                    "type": "string"
+                },
+                "resolved_by": {
+                    "type": "integer"
+                },
+                "resolved_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "update_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PaymentAnomalyDetail": {
+            "type": "object",
+            "properties": {
+                "anomaly": {
+                    "$ref": "#/definitions/models.PaymentAnomaly"
+                },
+                "audit_trail": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PaymentAuditLog"
+                    }
+                },
+                "payment": {
+                    "$ref": "#/definitions/models.Payment"
+                },
+                "status_history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PaymentStatusHistory"
+                    }
+                }
+            }
+        },
+        "models.PaymentAnomalyPage": {
+            "type": "object",
+            "properties": {
+                "anomalies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PaymentAnomaly"
+                    }
+                },
+                "next_cursor": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PaymentAuditLog": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "create_time": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metadata": {},
+                "order_id": {
+                    "type": "integer"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PaymentPage": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "integer"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Payment"
+                    }
+                }
+            }
+        },
+        "models.PaymentStatusHistory": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "create_time": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
                 "order_id": {
                     "type": "integer"
                 },
-                "payment_method": {
+                "payment_id": {
+                    "type": "integer"
+                },
+                "reason": {
                     "type": "string"
                 },
-                "shipping_address": {
+                "to_status": {
                     "type": "string"
                 },
-                "total_amount": {
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProductItem": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "면세 판정용 상품 카테고리",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
                     "type": "number"
                 },
-                "user_id": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReconciliationItem": {
+            "type": "object",
+            "properties": {
+                "create_time": {
+                    "type": "string"
+                },
+                "discrepancy_type": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "fix_error": {
+                    "type": "string"
+                },
+                "fix_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_id": {
+                    "type": "string"
+                },
+                "local_amount": {
+                    "$ref": "#/definitions/models.Money"
+                },
+                "local_status": {
+                    "type": "string"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "provider_amount": {
+                    "$ref": "#/definitions/models.Money"
+                },
+                "provider_status": {
+                    "type": "string"
+                },
+                "run_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReconciliationItemPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReconciliationItem"
+                    }
+                },
+                "next_cursor": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReconciliationRun": {
+            "type": "object",
+            "properties": {
+                "auto_fix": {
+                    "type": "boolean"
+                },
+                "discrepancy_count": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finish_time": {
+                    "type": "string"
+                },
+                "fixed_count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "invoice_count": {
+                    "type": "integer"
+                },
+                "matched_count": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "requested_by": {
+                    "type": "integer"
+                },
+                "schedule_key": {
+                    "description": "ScheduleKey 스케줄 실행의 중복 방지 키 (예: daily:2026-01-31). admin 실행은 NULL.",
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "trigger": {
+                    "type": "string"
+                },
+                "window_end": {
+                    "type": "string"
+                },
+                "window_start": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReconciliationRunPage": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "integer"
+                },
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReconciliationRun"
+                    }
+                }
+            }
+        },
+        "models.Refund": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/models.Money"
+                },
+                "create_time": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "payment_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "requested_by": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "update_time": {
+                    "type": "string"
+                },
+                "xendit_refund_id": {
+                    "description": "provider 환불 ID (컬럼명은 Xendit 시절 그대로)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RefundRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResolveAnomalyRequest": {
+            "type": "object",
+            "required": [
+                "action",
+                "reason"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SettlementReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "group_by": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SettlementReportRow"
+                    }
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "totals": {
+                    "description": "Totals 통화별 전체 기간 합계 (Period는 비어 있다)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SettlementReportRow"
+                    }
+                }
+            }
+        },
+        "models.SettlementReportRow": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "expired_count": {
+                    "type": "integer"
+                },
+                "expired_ratio": {
+                    "type": "number"
+                },
+                "failed_count": {
+                    "type": "integer"
+                },
+                "failed_ratio": {
+                    "type": "number"
+                },
+                "gross_paid_amount": {
+                    "$ref": "#/definitions/models.Money"
+                },
+                "net_amount": {
+                    "$ref": "#/definitions/models.Money"
+                },
+                "paid_count": {
+                    "type": "integer"
+                },
+                "partially_refunded_count": {
+                    "type": "integer"
+                },
+                "payment_count": {
+                    "type": "integer"
+                },
+                "pending_count": {
+                    "type": "integer"
+                },
+                "period": {
+                    "description": "day/week: YYYY-MM-DD(주는 월요일), month: YYYY-MM",
+                    "type": "string"
+                },
+                "refund_amount": {
+                    "$ref": "#/definitions/models.Money"
+                },
+                "refund_count": {
+                    "type": "integer"
+                },
+                "refunded_count": {
+                    "type": "integer"
+                },
+                "tax_amount": {
+                    "description": "결제 완료 건의 세액 합계 (총 결제액에 포함)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Money"
+                        }
+                    ]
+                },
+                "tax_base_amount": {
+                    "description": "결제 완료 건의 과세 표준 합계",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Money"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.WebhookEvent": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "event_key": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "headers": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_time": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "payment_status": {
+                    "type": "string"
+                },
+                "processed_time": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "received_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "update_time": {
+                    "type": "string"
                 }
             }
         },
@@ -434,12 +3105,19 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "amount": {
-                    "description": "웹훅에서 오면 총액 검증에 사용",
+                    "description": "웹훅에서 오면 총액 검증에 사용 (major unit, 정확한 비교를 위해 문자열 그대로 보관)",
                     "type": "number"
                 },
+                "currency": {
+                    "type": "string"
+                },
                 "external_id": {
                     "type": "string"
                 },
+                "id": {
+                    "description": "Xendit invoice id",
+                    "type": "string"
+                },
                 "status": {
                     "type": "string"
                 }
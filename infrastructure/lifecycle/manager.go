@@ -0,0 +1,88 @@
+// Package lifecycle 백그라운드 워커와 외부 리소스의 종료 순서를 관리한다.
+// 종료 시 워커 컨텍스트를 취소하고, 진행 중인 배치가 끝날 때까지 기다린 뒤 등록 순서대로 리소스를 닫는다.
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"paymentfc/log"
+)
+
+type worker struct {
+	name string
+	done <-chan struct{}
+}
+
+type closer struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+type Manager struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	workers []worker
+	closers []closer
+}
+
+func NewManager() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{ctx: ctx, cancel: cancel}
+}
+
+// Context 워커에 넘길 컨텍스트. Shutdown이 시작되면 취소된다.
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Track 워커가 완전히 멈추면 닫히는 done 채널을 등록한다.
+func (m *Manager) Track(name string, done <-chan struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workers = append(m.workers, worker{name: name, done: done})
+}
+
+// OnClose 워커가 모두 멈춘 뒤 호출할 종료 함수를 등록한다. 등록한 순서대로 호출된다.
+func (m *Manager) OnClose(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, closer{name: name, fn: fn})
+}
+
+// Shutdown 워커를 멈추고 timeout 안에서 기다린 뒤 리소스를 닫는다.
+// timeout이 지나도 멈추지 않은 워커는 로그만 남기고 리소스 정리를 진행한다.
+func (m *Manager) Shutdown(timeout time.Duration) {
+	m.cancel()
+
+	m.mu.Lock()
+	workers := append([]worker(nil), m.workers...)
+	closers := append([]closer(nil), m.closers...)
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, w := range workers {
+		select {
+		case <-w.done:
+			log.Logger.Info().Str("worker", w.name).Msg("Worker stopped")
+		case <-ctx.Done():
+			log.Logger.Warn().Str("worker", w.name).Msg("Worker did not stop before shutdown timeout")
+		}
+	}
+
+	// 워커 대기로 시간을 다 썼더라도 리소스는 닫아야 하므로 별도 타임아웃을 쓴다.
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), timeout)
+	defer closeCancel()
+	for _, c := range closers {
+		if err := c.fn(closeCtx); err != nil {
+			log.Logger.Error().Err(err).Str("resource", c.name).Msg("Failed to close resource")
+			continue
+		}
+		log.Logger.Info().Str("resource", c.name).Msg("Resource closed")
+	}
+}
@@ -0,0 +1,51 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_Shutdown(t *testing.T) {
+	t.Run("waits for workers then closes resources in order", func(t *testing.T) {
+		m := NewManager()
+		var order []string
+
+		done := make(chan struct{})
+		go func() {
+			<-m.Context().Done()
+			time.Sleep(10 * time.Millisecond) // 진행 중인 배치
+			order = append(order, "worker")
+			close(done)
+		}()
+		m.Track("worker", done)
+		m.OnClose("kafka writer", func(context.Context) error {
+			order = append(order, "kafka writer")
+			return nil
+		})
+		m.OnClose("database pool", func(context.Context) error {
+			order = append(order, "database pool")
+			return nil
+		})
+
+		m.Shutdown(time.Second)
+		assert.Equal(t, []string{"worker", "kafka writer", "database pool"}, order)
+	})
+
+	t.Run("closes resources even when a worker hangs", func(t *testing.T) {
+		m := NewManager()
+		closed := false
+		m.Track("stuck", make(chan struct{}))
+		m.OnClose("redis client", func(context.Context) error {
+			closed = true
+			return nil
+		})
+
+		start := time.Now()
+		m.Shutdown(20 * time.Millisecond)
+		assert.True(t, closed)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
@@ -15,3 +15,119 @@ var XenditWebhookProcessed = promauto.NewCounterVec(
 	},
 	[]string{"outcome"},
 )
+
+// PaymentWebhookProcessed provider별 웹훅 처리 결과.
+var PaymentWebhookProcessed = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "commerce",
+		Subsystem: "payment",
+		Name:      "webhook_processed_total",
+		Help:      "Payment gateway webhook invocations by provider and outcome",
+	},
+	[]string{"provider", "outcome"},
+)
+
+// ObserveWebhook provider별 카운터에 기록하고, 기존 Xendit 대시보드 호환을 위해 xendit은 기존 카운터에도 함께 기록한다.
+func ObserveWebhook(provider, outcome string) {
+	PaymentWebhookProcessed.WithLabelValues(provider, outcome).Inc()
+	if provider == "xendit" {
+		XenditWebhookProcessed.WithLabelValues(outcome).Inc()
+	}
+}
+
+// OutboxPublished outbox relay 발행 결과.
+var OutboxPublished = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "commerce",
+		Subsystem: "payment",
+		Name:      "outbox_published_total",
+		Help:      "Outbox relay publish attempts by event type and outcome",
+	},
+	[]string{"event_type", "outcome"},
+)
+
+// OutboxBacklog 아직 발행되지 않은 outbox 건수.
+var OutboxBacklog = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "commerce",
+		Subsystem: "payment",
+		Name:      "outbox_backlog",
+		Help:      "Number of outbox events waiting to be published",
+	},
+)
+
+// OutboxLagSeconds 가장 오래된 미발행 outbox 건이 기다린 시간.
+var OutboxLagSeconds = promauto.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "commerce",
+		Subsystem: "payment",
+		Name:      "outbox_lag_seconds",
+		Help:      "Age in seconds of the oldest unpublished outbox event",
+	},
+)
+
+// ReconciliationDiscrepancies 대사에서 발견한 불일치 건수 (유형, 보정 결과별).
+var ReconciliationDiscrepancies = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "commerce",
+		Subsystem: "payment",
+		Name:      "reconciliation_discrepancies_total",
+		Help:      "Reconciliation discrepancies by type and fix status",
+	},
+	[]string{"type", "fix_status"},
+)
+
+// SchedulerLeader 이 인스턴스가 싱글톤 잡 리더인지 여부 (1: 리더, 0: 팔로워).
+var SchedulerLeader = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "commerce",
+		Subsystem: "payment",
+		Name:      "scheduler_leader",
+		Help:      "Whether this instance currently holds the scheduler leader lease",
+	},
+	[]string{"instance"},
+)
+
+// SchedulerLeaderTransitions 리더십 획득/상실 횟수.
+var SchedulerLeaderTransitions = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "commerce",
+		Subsystem: "payment",
+		Name:      "scheduler_leader_transitions_total",
+		Help:      "Scheduler leadership changes by direction",
+	},
+	[]string{"direction"},
+)
+
+// JobRuns 잡 엔진 실행 결과 (잡, 상태별).
+var JobRuns = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "commerce",
+		Subsystem: "payment",
+		Name:      "job_runs_total",
+		Help:      "Job engine runs by job and status",
+	},
+	[]string{"job", "status"},
+)
+
+// PaymentRequestFailures 결제 요청 처리 실패 결과 (retry: 백오프 후 재시도, dead: 종료).
+var PaymentRequestFailures = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "commerce",
+		Subsystem: "payment",
+		Name:      "payment_request_failures_total",
+		Help:      "Payment request processing failures by outcome",
+	},
+	[]string{"outcome"},
+)
+
+// KafkaConsumerMessages 컨슈머 메시지 처리 결과 (handled, retried, dead_lettered).
+var KafkaConsumerMessages = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "commerce",
+		Subsystem: "payment",
+		Name:      "kafka_consumer_messages_total",
+		Help:      "Kafka consumer messages by topic and outcome",
+	},
+	[]string{"topic", "outcome"},
+)
@@ -0,0 +1,192 @@
+// Package schedule 잡 실행 주기 표현식을 해석한다.
+// "@every 5s" 같은 고정 간격과 분 단위 5필드 cron("*/10 * * * *", "0 2 * * 1-5")을 지원한다.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 주어진 시각 이후 다음 실행 시각을 돌려준다.
+type Schedule interface {
+	Next(after time.Time) time.Time
+	String() string
+}
+
+// Parse 표현식을 Schedule로 바꾼다. @hourly, @daily, @weekly, @monthly 별칭도 받는다.
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q: %w", expr, err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("invalid interval %q: must be at least 1s", expr)
+		}
+		return interval{every: d}, nil
+	}
+	switch expr {
+	case "@hourly":
+		expr = "0 * * * *"
+	case "@daily":
+		expr = "0 0 * * *"
+	case "@weekly":
+		expr = "0 0 * * 0"
+	case "@monthly":
+		expr = "0 0 1 * *"
+	}
+	return parseCron(expr)
+}
+
+type interval struct {
+	every time.Duration
+}
+
+func (i interval) Next(after time.Time) time.Time {
+	return after.Add(i.every)
+}
+
+func (i interval) String() string {
+	return "@every " + i.every.String()
+}
+
+// cron 분 단위 5필드 cron. 일(dom)과 요일(dow)이 모두 제한되면 표준 cron처럼 둘 중 하나만 맞아도 실행한다.
+// "*"로 시작하는 필드는 제한으로 치지 않는다.
+type cron struct {
+	expr                         string
+	minute, hour, dom, month     uint64
+	dow                          uint64
+	domRestricted, dowRestricted bool
+}
+
+type field struct {
+	min, max int
+}
+
+var (
+	minuteField = field{0, 59}
+	hourField   = field{0, 23}
+	domField    = field{1, 31}
+	monthField  = field{1, 12}
+	dowField    = field{0, 7} // 0과 7 모두 일요일
+)
+
+func parseCron(expr string) (Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("invalid cron %q: expected 5 fields", expr)
+	}
+	c := cron{expr: expr}
+	var err error
+	if c.minute, err = parseField(parts[0], minuteField); err != nil {
+		return nil, fmt.Errorf("invalid cron %q minute: %w", expr, err)
+	}
+	if c.hour, err = parseField(parts[1], hourField); err != nil {
+		return nil, fmt.Errorf("invalid cron %q hour: %w", expr, err)
+	}
+	if c.dom, err = parseField(parts[2], domField); err != nil {
+		return nil, fmt.Errorf("invalid cron %q day of month: %w", expr, err)
+	}
+	if c.month, err = parseField(parts[3], monthField); err != nil {
+		return nil, fmt.Errorf("invalid cron %q month: %w", expr, err)
+	}
+	if c.dow, err = parseField(parts[4], dowField); err != nil {
+		return nil, fmt.Errorf("invalid cron %q day of week: %w", expr, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	// robfig/cron처럼 "*"로 시작하는 필드("*/2")는 제한이 없는 것으로 본다.
+	c.domRestricted = !strings.HasPrefix(parts[2], "*")
+	c.dowRestricted = !strings.HasPrefix(parts[4], "*")
+	return c, nil
+}
+
+// parseField "*", "5", "1-5", "*/15", "10-40/10", "1,3,5"를 비트셋으로 바꾼다.
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("bad step %q", item)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(a)
+			hi, err2 = strconv.Atoi(b)
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("bad range %q", item)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("bad value %q", item)
+			}
+			lo = n
+			if !hasStep {
+				hi = n
+			}
+		}
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", item, f.min, f.max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// maxCronLookahead 이 기간 안에 맞는 시각이 없으면(예: 2월 30일) 실행하지 않는다.
+const maxCronLookahead = 5 * 366 * 24 * time.Hour
+
+func (c cron) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(maxCronLookahead)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c cron) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domRestricted && c.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+func (c cron) String() string {
+	return c.expr
+}
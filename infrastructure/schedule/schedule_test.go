@@ -0,0 +1,54 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Next(t *testing.T) {
+	base := time.Date(2026, 3, 13, 10, 7, 30, 0, time.UTC) // 금요일
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"@every 5s", base.Add(5 * time.Second)},
+		{"* * * * *", time.Date(2026, 3, 13, 10, 8, 0, 0, time.UTC)},
+		{"*/10 * * * *", time.Date(2026, 3, 13, 10, 10, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2026, 3, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"15,45 10 * * *", time.Date(2026, 3, 13, 10, 15, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)},
+		// dom과 dow가 모두 지정되면 둘 중 하나만 맞아도 실행
+		{"0 0 20 * 6", time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 */2 * *", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		// "*"로 시작하는 dom은 제한이 아니므로 홀수 날이면서 월요일이어야 한다
+		{"0 0 */2 * 1", time.Date(2026, 3, 23, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * */2", time.Date(2026, 6, 13, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			s, err := Parse(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, s.Next(base))
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "@every 10ms", "@every soon", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "0 0 0 * *"} {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCron_NoMatchReturnsZero(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
@@ -0,0 +1,563 @@
+// Package xenditsim 로컬 개발/통합 테스트용 Xendit API 시뮬레이터.
+// 인보이스 생성·조회·만료와 환불을 흉내 내고, 결과가 정해지면 /v1/payment/webhook으로 콜백을 보낸다.
+package xenditsim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"paymentfc/log"
+	"paymentfc/models"
+)
+
+// Outcome 인보이스 생성 후 시뮬레이터가 적용할 결과
+type Outcome string
+
+const (
+	OutcomePay    Outcome = "pay"
+	OutcomeFail   Outcome = "fail"
+	OutcomeExpire Outcome = "expire"
+	OutcomeManual Outcome = "manual" // 제어 API로 pay/fail/expire 하기 전까지 PENDING 유지
+)
+
+const (
+	StatusPending = "PENDING"
+	StatusPaid    = "PAID"
+	StatusFailed  = "FAILED"
+	StatusExpired = "EXPIRED"
+
+	callbackAttempts = 3
+)
+
+type Config struct {
+	CallbackURL     string        // 예: http://localhost:28083/v1/payment/webhook
+	CallbackToken   string        // x-callback-token 헤더 값 (xendit.webhook_token)
+	Outcome         Outcome       // 기본 결과 (비어 있으면 pay)
+	Delay           time.Duration // 결과 적용까지 대기 시간 (auto-pay after N seconds)
+	InvoiceDuration time.Duration // expiry_date 계산용 (비어 있으면 24h)
+}
+
+// Invoice Xendit v2 invoice 응답 형식
+type Invoice struct {
+	ID          string      `json:"id"`
+	ExternalID  string      `json:"external_id"`
+	Status      string      `json:"status"`
+	Amount      json.Number `json:"amount"`
+	Currency    string      `json:"currency"`
+	Description string      `json:"description"`
+	PayerEmail  string      `json:"payer_email"`
+	InvoiceURL  string      `json:"invoice_url"`
+	ExpiryDate  time.Time   `json:"expiry_date"`
+	PaidAt      *time.Time  `json:"paid_at,omitempty"`
+	Created     time.Time   `json:"created"`
+	Updated     time.Time   `json:"updated"`
+
+	seq int64 // 생성 순서 (목록 정렬용)
+}
+
+type scenario struct {
+	outcome Outcome
+	delay   time.Duration
+}
+
+type fault struct {
+	status int
+	count  int
+}
+
+type Simulator struct {
+	cfg    Config
+	mux    *http.ServeMux
+	client *http.Client
+
+	mu        sync.Mutex
+	seq       int64
+	invoices  map[string]*Invoice                     // id -> invoice
+	refunds   map[string]*models.XenditRefundResponse // reference_id(Idempotency-key) -> refund
+	scenarios map[string]scenario                     // external_id -> 결과 override
+	faults    []fault
+	timers    map[string]*time.Timer
+}
+
+func New(cfg Config) *Simulator {
+	if cfg.Outcome == "" {
+		cfg.Outcome = OutcomePay
+	}
+	if cfg.InvoiceDuration == 0 {
+		cfg.InvoiceDuration = 24 * time.Hour
+	}
+	s := &Simulator{
+		cfg:       cfg,
+		mux:       http.NewServeMux(),
+		client:    &http.Client{Timeout: 10 * time.Second},
+		invoices:  make(map[string]*Invoice),
+		refunds:   make(map[string]*models.XenditRefundResponse),
+		scenarios: make(map[string]scenario),
+		timers:    make(map[string]*time.Timer),
+	}
+
+	// Xendit API
+	s.mux.HandleFunc("POST /v2/invoices", s.api(s.handleCreateInvoice))
+	s.mux.HandleFunc("GET /v2/invoices", s.api(s.handleListInvoices))
+	s.mux.HandleFunc("POST /invoices/{id}/expire!", s.api(s.handleExpireInvoice))
+	s.mux.HandleFunc("POST /refunds", s.api(s.handleCreateRefund))
+
+	// 시뮬레이터 제어 API
+	s.mux.HandleFunc("GET /_simulator/invoices", s.handleControlListInvoices)
+	s.mux.HandleFunc("GET /_simulator/invoices/{id}", s.handleControlGetInvoice)
+	s.mux.HandleFunc("POST /_simulator/invoices/{id}/{action}", s.handleControlSettle)
+	s.mux.HandleFunc("POST /_simulator/scenarios", s.handleControlScenario)
+	s.mux.HandleFunc("POST /_simulator/faults", s.handleControlFault)
+	return s
+}
+
+func (s *Simulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// SetScenario externalID 인보이스에만 적용할 결과를 지정한다. 인보이스 생성 전에 호출해야 한다.
+func (s *Simulator) SetScenario(externalID string, outcome Outcome, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios[externalID] = scenario{outcome: outcome, delay: delay}
+}
+
+// InjectFault 다음 count개의 Xendit API 요청에 status(429, 500, 503 등)로 응답한다.
+func (s *Simulator) InjectFault(status, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, fault{status: status, count: count})
+}
+
+// Pay/Fail/Expire 인보이스 결과를 즉시 확정하고 웹훅을 보낸다.
+func (s *Simulator) Pay(invoiceID string) error    { return s.settle(invoiceID, StatusPaid) }
+func (s *Simulator) Fail(invoiceID string) error   { return s.settle(invoiceID, StatusFailed) }
+func (s *Simulator) Expire(invoiceID string) error { return s.settle(invoiceID, StatusExpired) }
+
+func (s *Simulator) Invoices() []Invoice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Invoice, 0, len(s.invoices))
+	for _, inv := range s.invoices {
+		out = append(out, *inv)
+	}
+	return out
+}
+
+// Close 예약된 자동 결과를 모두 취소한다.
+func (s *Simulator) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, t := range s.timers {
+		t.Stop()
+		delete(s.timers, id)
+	}
+}
+
+// api 인증 헤더 확인과 fault injection을 공통으로 처리한다.
+func (s *Simulator) api(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); !ok {
+			writeError(w, http.StatusUnauthorized, "INVALID_API_KEY", "API key is required")
+			return
+		}
+		if status := s.nextFault(); status != 0 {
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "1")
+				writeError(w, status, "RATE_LIMIT_EXCEEDED", "simulated rate limit")
+				return
+			}
+			writeError(w, status, "SERVER_ERROR", "simulated server error")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Simulator) nextFault() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.faults) == 0 {
+		return 0
+	}
+	f := &s.faults[0]
+	f.count--
+	status := f.status
+	if f.count <= 0 {
+		s.faults = s.faults[1:]
+	}
+	return status
+}
+
+func (s *Simulator) handleCreateInvoice(w http.ResponseWriter, r *http.Request) {
+	var req models.XenditInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "API_VALIDATION_ERROR", err.Error())
+		return
+	}
+	if req.ExternalID == "" || req.Amount == "" {
+		writeError(w, http.StatusBadRequest, "API_VALIDATION_ERROR", "external_id and amount are required")
+		return
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	if _, err := models.ParseMoney(req.Amount.String(), currency); err != nil {
+		writeError(w, http.StatusBadRequest, "API_VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	s.mu.Lock()
+	s.seq++
+	id := fmt.Sprintf("sim-inv-%d", s.seq)
+	inv := &Invoice{
+		seq:         s.seq,
+		ID:          id,
+		ExternalID:  req.ExternalID,
+		Status:      StatusPending,
+		Amount:      req.Amount,
+		Currency:    currency,
+		Description: req.Description,
+		PayerEmail:  req.PayerEmail,
+		InvoiceURL:  fmt.Sprintf("http://%s/_simulator/invoices/%s", r.Host, id),
+		ExpiryDate:  now.Add(s.cfg.InvoiceDuration),
+		Created:     now,
+		Updated:     now,
+	}
+	s.invoices[id] = inv
+	sc, ok := s.scenarios[req.ExternalID]
+	if !ok {
+		sc = scenario{outcome: s.cfg.Outcome, delay: s.cfg.Delay}
+	}
+	if status := outcomeStatus(sc.outcome); status != "" {
+		s.timers[id] = time.AfterFunc(sc.delay, func() {
+			if err := s.settle(id, status); err != nil {
+				log.Logger.Warn().Err(err).Str("invoice_id", id).Msg("Xendit simulator: scheduled outcome skipped")
+			}
+		})
+	}
+	resp := *inv
+	s.mu.Unlock()
+
+	log.Logger.Info().Str("invoice_id", id).Str("external_id", req.ExternalID).Str("outcome", string(sc.outcome)).Msg("Xendit simulator: invoice created")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleListInvoices external_id 조회와 created_after/created_before/limit/last_invoice_id 기간 조회를 지원한다.
+// Xendit처럼 최신순으로 돌려준다.
+func (s *Simulator) handleListInvoices(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	externalID := q.Get("external_id")
+	var after, before time.Time
+	if v := q.Get("created_after"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "API_VALIDATION_ERROR", "invalid created_after")
+			return
+		}
+		after = t
+	}
+	if v := q.Get("created_before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "API_VALIDATION_ERROR", "invalid created_before")
+			return
+		}
+		before = t
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	s.mu.Lock()
+	all := make([]Invoice, 0, len(s.invoices))
+	var lastSeq int64
+	for _, inv := range s.invoices {
+		if inv.ID == q.Get("last_invoice_id") {
+			lastSeq = inv.seq
+		}
+		if externalID != "" && inv.ExternalID != externalID {
+			continue
+		}
+		if !after.IsZero() && inv.Created.Before(after) {
+			continue
+		}
+		if !before.IsZero() && !inv.Created.Before(before) {
+			continue
+		}
+		all = append(all, *inv)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(all, func(i, j int) bool { return all[i].seq > all[j].seq })
+	out := []Invoice{}
+	for _, inv := range all {
+		if lastSeq != 0 && inv.seq >= lastSeq {
+			continue
+		}
+		if externalID == "" && len(out) == limit {
+			break
+		}
+		out = append(out, inv)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Simulator) handleExpireInvoice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.settle(id, StatusExpired); err != nil {
+		writeSettleError(w, err)
+		return
+	}
+	s.mu.Lock()
+	resp := *s.invoices[id]
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Simulator) handleCreateRefund(w http.ResponseWriter, r *http.Request) {
+	var req models.XenditRefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "API_VALIDATION_ERROR", err.Error())
+		return
+	}
+	key := r.Header.Get("Idempotency-key")
+	if key == "" {
+		key = req.ReferenceID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.refunds[key]; ok {
+		writeJSON(w, http.StatusOK, existing)
+		return
+	}
+	inv, ok := s.invoices[req.InvoiceID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "DATA_NOT_FOUND", "invoice not found")
+		return
+	}
+	if inv.Status != StatusPaid {
+		writeError(w, http.StatusBadRequest, "INELIGIBLE_TRANSACTION", "invoice is not paid")
+		return
+	}
+	amount, err := models.ParseMoney(req.Amount.String(), inv.Currency)
+	if err != nil || !amount.IsPositive() {
+		writeError(w, http.StatusBadRequest, "API_VALIDATION_ERROR", "invalid refund amount")
+		return
+	}
+	paid, _ := models.ParseMoney(inv.Amount.String(), inv.Currency)
+	refunded := models.NewMoney(0, inv.Currency)
+	for _, rf := range s.refunds {
+		if rf.InvoiceID == inv.ID && rf.Status == "SUCCEEDED" {
+			prev, _ := models.ParseMoney(rf.Amount.String(), inv.Currency)
+			refunded, _ = refunded.Add(prev)
+		}
+	}
+	if refunded.Minor+amount.Minor > paid.Minor {
+		writeError(w, http.StatusBadRequest, "REFUND_AMOUNT_EXCEEDED", "refund amount exceeds remaining amount")
+		return
+	}
+
+	s.seq++
+	refund := &models.XenditRefundResponse{
+		ID:          fmt.Sprintf("sim-rfd-%d", s.seq),
+		InvoiceID:   inv.ID,
+		ReferenceID: req.ReferenceID,
+		Amount:      amount.Number(),
+		Currency:    inv.Currency,
+		Status:      "SUCCEEDED",
+	}
+	s.refunds[key] = refund
+	writeJSON(w, http.StatusOK, refund)
+}
+
+func (s *Simulator) handleControlListInvoices(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Invoices())
+}
+
+func (s *Simulator) handleControlGetInvoice(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	inv, ok := s.invoices[r.PathValue("id")]
+	var resp Invoice
+	if ok {
+		resp = *inv
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "INVOICE_NOT_FOUND_ERROR", errInvoiceNotFound.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Simulator) handleControlSettle(w http.ResponseWriter, r *http.Request) {
+	status := outcomeStatus(Outcome(r.PathValue("action")))
+	if status == "" {
+		writeError(w, http.StatusBadRequest, "API_VALIDATION_ERROR", "action must be one of pay, fail, expire")
+		return
+	}
+	if err := s.settle(r.PathValue("id"), status); err != nil {
+		writeSettleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": status})
+}
+
+func (s *Simulator) handleControlScenario(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ExternalID   string  `json:"external_id"`
+		Outcome      Outcome `json:"outcome"`
+		DelaySeconds int     `json:"delay_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ExternalID == "" {
+		writeError(w, http.StatusBadRequest, "API_VALIDATION_ERROR", "external_id and outcome are required")
+		return
+	}
+	if req.Outcome != OutcomeManual && outcomeStatus(req.Outcome) == "" {
+		writeError(w, http.StatusBadRequest, "API_VALIDATION_ERROR", "outcome must be one of pay, fail, expire, manual")
+		return
+	}
+	s.SetScenario(req.ExternalID, req.Outcome, time.Duration(req.DelaySeconds)*time.Second)
+	writeJSON(w, http.StatusOK, req)
+}
+
+func (s *Simulator) handleControlFault(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Status int `json:"status"`
+		Count  int `json:"count"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Status < 400 {
+		writeError(w, http.StatusBadRequest, "API_VALIDATION_ERROR", "status must be an HTTP error code")
+		return
+	}
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	s.InjectFault(req.Status, req.Count)
+	writeJSON(w, http.StatusOK, req)
+}
+
+var (
+	errInvoiceNotFound   = errors.New("invoice not found")
+	errInvoiceNotPending = errors.New("invoice is not pending")
+)
+
+// settle PENDING 인보이스를 status로 확정하고 콜백을 보낸다.
+func (s *Simulator) settle(id, status string) error {
+	s.mu.Lock()
+	inv, ok := s.invoices[id]
+	if !ok {
+		s.mu.Unlock()
+		return errInvoiceNotFound
+	}
+	if inv.Status != StatusPending {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", errInvoiceNotPending, inv.Status)
+	}
+	if t, ok := s.timers[id]; ok {
+		t.Stop()
+		delete(s.timers, id)
+	}
+	now := time.Now().UTC()
+	inv.Status = status
+	inv.Updated = now
+	if status == StatusPaid {
+		inv.PaidAt = &now
+	}
+	payload := models.XenditWebhookPayload{
+		ID:         inv.ID,
+		ExternalID: inv.ExternalID,
+		Status:     inv.Status,
+		Amount:     inv.Amount,
+		Currency:   inv.Currency,
+	}
+	s.mu.Unlock()
+
+	log.Logger.Info().Str("invoice_id", id).Str("external_id", payload.ExternalID).Str("status", status).Msg("Xendit simulator: invoice settled")
+	s.sendCallback(id, payload)
+	return nil
+}
+
+func (s *Simulator) sendCallback(invoiceID string, payload models.XenditWebhookPayload) {
+	if s.cfg.CallbackURL == "" {
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("Xendit simulator: failed to marshal callback")
+		return
+	}
+	for attempt := 1; attempt <= callbackAttempts; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, s.cfg.CallbackURL, bytes.NewReader(body))
+		if err != nil {
+			log.Logger.Error().Err(err).Msg("Xendit simulator: failed to create callback request")
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-callback-token", s.cfg.CallbackToken)
+		resp, err := s.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return
+			}
+			err = fmt.Errorf("callback returned status: %d", resp.StatusCode)
+		}
+		log.Logger.Warn().Err(err).Str("invoice_id", invoiceID).Int("attempt", attempt).Msg("Xendit simulator: callback failed")
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+func outcomeStatus(o Outcome) string {
+	switch o {
+	case OutcomePay:
+		return StatusPaid
+	case OutcomeFail:
+		return StatusFailed
+	case OutcomeExpire:
+		return StatusExpired
+	}
+	return ""
+}
+
+func writeSettleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errInvoiceNotFound) {
+		writeError(w, http.StatusNotFound, "INVOICE_NOT_FOUND_ERROR", err.Error())
+		return
+	}
+	writeError(w, http.StatusBadRequest, "INVALID_INVOICE_STATUS", err.Error())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]string{"error_code": code, "message": message})
+}
+
+// ParseOutcome 설정 문자열("pay", "fail", "expire", "manual")을 Outcome으로 바꾼다.
+func ParseOutcome(v string) (Outcome, error) {
+	o := Outcome(strings.ToLower(strings.TrimSpace(v)))
+	switch o {
+	case "":
+		return OutcomePay, nil
+	case OutcomePay, OutcomeFail, OutcomeExpire, OutcomeManual:
+		return o, nil
+	}
+	return "", fmt.Errorf("unknown simulator outcome: %s", v)
+}
@@ -0,0 +1,188 @@
+package xenditsim_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/constant"
+	"paymentfc/infrastructure/xenditsim"
+	"paymentfc/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startSimulator 시뮬레이터와 웹훅 수신 서버를 띄우고, 실제 XenditClient를 시뮬레이터에 연결한다.
+func startSimulator(t *testing.T, outcome xenditsim.Outcome, delay time.Duration) (*xenditsim.Simulator, repository.XenditClient, <-chan *http.Request, <-chan []byte) {
+	t.Helper()
+	requests := make(chan *http.Request, 10)
+	bodies := make(chan []byte, 10)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(callback.Close)
+
+	sim := xenditsim.New(xenditsim.Config{
+		CallbackURL:   callback.URL + "/v1/payment/webhook",
+		CallbackToken: "cb-token",
+		Outcome:       outcome,
+		Delay:         delay,
+	})
+	t.Cleanup(sim.Close)
+	server := httptest.NewServer(sim)
+	t.Cleanup(server.Close)
+
+	return sim, repository.NewXenditClient("sk-test", server.URL), requests, bodies
+}
+
+func createInvoice(t *testing.T, client repository.XenditClient, externalID string) *models.XenditInvoiceResponse {
+	t.Helper()
+	resp, err := client.CreateInvoice(context.Background(), models.XenditInvoiceRequest{
+		ExternalID: externalID,
+		Amount:     "150000",
+		Currency:   "IDR",
+	})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestSimulator_AutoPayCallsWebhook(t *testing.T) {
+	_, client, requests, bodies := startSimulator(t, xenditsim.OutcomePay, 10*time.Millisecond)
+
+	inv := createInvoice(t, client, "order-1")
+	assert.Equal(t, constant.PaymentStatusPending, inv.Status)
+	assert.False(t, inv.ExpireDate.IsZero())
+
+	select {
+	case r := <-requests:
+		assert.Equal(t, "/v1/payment/webhook", r.URL.Path)
+		assert.Equal(t, "cb-token", r.Header.Get("x-callback-token"))
+	case <-time.After(3 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+
+	// 콜백 body는 실제 웹훅 경로(XenditGateway.VerifyWebhook)로 그대로 정규화할 수 있어야 한다.
+	header := http.Header{}
+	header.Set("x-callback-token", "cb-token")
+	event, err := repository.NewXenditGateway(client, "cb-token").VerifyWebhook(header, <-bodies)
+	require.NoError(t, err)
+	assert.Equal(t, "order-1", event.ExternalID)
+	assert.Equal(t, constant.PaymentStatusPaid, event.Status)
+	assert.Equal(t, "150000", event.Amount.String())
+
+	status, err := client.CheckInvoiceStatus(context.Background(), "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, constant.PaymentStatusPaid, status)
+}
+
+func TestSimulator_ScenarioOverrides(t *testing.T) {
+	sim, client, _, bodies := startSimulator(t, xenditsim.OutcomeManual, 0)
+
+	t.Run("fail", func(t *testing.T) {
+		sim.SetScenario("order-2", xenditsim.OutcomeFail, 0)
+		createInvoice(t, client, "order-2")
+
+		var payload models.XenditWebhookPayload
+		require.NoError(t, json.Unmarshal(<-bodies, &payload))
+		assert.Equal(t, constant.PaymentStatusFailed, payload.Status)
+	})
+
+	t.Run("manual invoice stays pending until expired", func(t *testing.T) {
+		inv := createInvoice(t, client, "order-3")
+		status, err := client.CheckInvoiceStatus(context.Background(), "order-3")
+		require.NoError(t, err)
+		assert.Equal(t, constant.PaymentStatusPending, status)
+
+		require.NoError(t, client.ExpireInvoice(context.Background(), inv.ID))
+		var payload models.XenditWebhookPayload
+		require.NoError(t, json.Unmarshal(<-bodies, &payload))
+		assert.Equal(t, constant.PaymentStatusExpired, payload.Status)
+
+		assert.Error(t, sim.Pay(inv.ID), "expired invoice cannot be paid")
+	})
+}
+
+func TestSimulator_InjectFault(t *testing.T) {
+	sim, client, _, _ := startSimulator(t, xenditsim.OutcomeManual, 0)
+
+	sim.InjectFault(http.StatusTooManyRequests, 1)
+	sim.InjectFault(http.StatusServiceUnavailable, 1)
+
+	_, err := client.CreateInvoice(context.Background(), models.XenditInvoiceRequest{ExternalID: "order-4", Amount: "1000"})
+	assert.ErrorContains(t, err, "429")
+	_, err = client.CreateInvoice(context.Background(), models.XenditInvoiceRequest{ExternalID: "order-4", Amount: "1000"})
+	assert.ErrorContains(t, err, "503")
+
+	createInvoice(t, client, "order-4")
+}
+
+func TestSimulator_Refund(t *testing.T) {
+	sim, client, _, bodies := startSimulator(t, xenditsim.OutcomeManual, 0)
+	ctx := context.Background()
+
+	inv := createInvoice(t, client, "order-5")
+
+	t.Run("rejects refund of unpaid invoice", func(t *testing.T) {
+		_, err := client.CreateRefund(ctx, models.XenditRefundRequest{InvoiceID: inv.ID, ReferenceID: "rf-0", Amount: "1000"})
+		assert.Error(t, err)
+	})
+
+	require.NoError(t, sim.Pay(inv.ID))
+	<-bodies
+
+	t.Run("partial refund is idempotent by reference id", func(t *testing.T) {
+		req := models.XenditRefundRequest{InvoiceID: inv.ID, ReferenceID: "rf-1", Amount: "100000", Currency: "IDR"}
+		first, err := client.CreateRefund(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, constant.RefundStatusSucceeded, first.Status)
+
+		again, err := client.CreateRefund(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, again.ID)
+	})
+
+	t.Run("rejects refund above remaining amount", func(t *testing.T) {
+		_, err := client.CreateRefund(ctx, models.XenditRefundRequest{InvoiceID: inv.ID, ReferenceID: "rf-2", Amount: "60000", Currency: "IDR"})
+		assert.Error(t, err)
+	})
+}
+
+func TestSimulator_ListInvoicesPages(t *testing.T) {
+	_, client, _, _ := startSimulator(t, xenditsim.OutcomeManual, 0)
+	ctx := context.Background()
+
+	for _, externalID := range []string{"order-1", "order-2", "order-3"} {
+		createInvoice(t, client, externalID)
+	}
+	window := models.XenditInvoiceListRequest{
+		CreatedAfter:  time.Now().Add(-time.Minute),
+		CreatedBefore: time.Now().Add(time.Minute),
+		Limit:         2,
+	}
+
+	first, err := client.ListInvoices(ctx, window)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Equal(t, "order-3", first[0].ExternalID)
+	assert.Equal(t, json.Number("150000"), first[0].Amount)
+
+	window.LastInvoiceID = first[1].ID
+	second, err := client.ListInvoices(ctx, window)
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.Equal(t, "order-1", second[0].ExternalID)
+
+	window.LastInvoiceID = ""
+	window.CreatedAfter = time.Now().Add(time.Minute)
+	empty, err := client.ListInvoices(ctx, window)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
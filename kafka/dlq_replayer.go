@@ -0,0 +1,80 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"paymentfc/log"
+	"paymentfc/models"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// dlqReplayIdleTimeout 이 시간 동안 새 메시지가 없으면 DLQ를 다 읽은 것으로 본다. 그룹 조인 시간도 포함된다.
+const dlqReplayIdleTimeout = 10 * time.Second
+
+// DLQReplayer DLQ 메시지를 원래 토픽으로 다시 발행한다. 별도 컨슈머 그룹으로 읽어 재처리한 메시지만 커밋한다.
+type DLQReplayer struct {
+	policy      RetryPolicy
+	writer      messageWriter
+	newReader   func() messageReader
+	idleTimeout time.Duration
+}
+
+func NewDLQReplayer(broker, groupID string, policy RetryPolicy, writer messageWriter) *DLQReplayer {
+	return &DLQReplayer{
+		policy: policy,
+		writer: writer,
+		newReader: func() messageReader {
+			return newGroupReader(broker, groupID, policy.DLQTopic)
+		},
+		idleTimeout: dlqReplayIdleTimeout,
+	}
+}
+
+// Replay 최대 limit개를 재처리한다. 발행에 실패하면 그 메시지는 커밋하지 않고 멈추며, 그때까지의 결과와 에러를 함께 돌려준다.
+func (d *DLQReplayer) Replay(ctx context.Context, limit int) (models.DLQReplayResult, error) {
+	result := models.DLQReplayResult{DLQTopic: d.policy.DLQTopic, Limit: limit}
+	r := d.newReader()
+	defer r.Close()
+
+	for result.Replayed < limit {
+		fetchCtx, cancel := context.WithTimeout(ctx, d.idleTimeout)
+		msg, err := r.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				break
+			}
+			return result, fmt.Errorf("fetch %s: %w", d.policy.DLQTopic, err)
+		}
+
+		replay := replayMessage(d.policy, msg)
+		if err := d.writer.WriteMessages(ctx, replay); err != nil {
+			return result, fmt.Errorf("publish to %s: %w", replay.Topic, err)
+		}
+		if err := r.CommitMessages(ctx, msg); err != nil {
+			// 이미 발행했으므로 다음 재처리에서 한 번 더 나갈 수 있다(at-least-once).
+			return result, fmt.Errorf("commit %s: %w", d.policy.DLQTopic, err)
+		}
+		result.Replayed++
+		log.Logger.Info().Str("topic", replay.Topic).Str("error", header(msg.Headers, HeaderError)).Int64("dlq_offset", msg.Offset).Msg("Replayed DLQ message")
+	}
+	return result, nil
+}
+
+// replayMessage 재시도 이력을 지워 원래 토픽의 새 메시지처럼 처음부터 재시도 단계를 밟게 한다.
+func replayMessage(policy RetryPolicy, msg kafka.Message) kafka.Message {
+	topic := header(msg.Headers, HeaderOriginalTopic)
+	if topic == "" {
+		topic = policy.Topic
+	}
+	headers := append([]kafka.Header(nil), msg.Headers...)
+	for _, key := range []string{HeaderRetryAttempt, HeaderNotBefore, HeaderError, HeaderFailedAt, HeaderOriginalTopic, HeaderOriginalPartition, HeaderOriginalOffset} {
+		headers = removeHeader(headers, key)
+	}
+	headers = setHeader(headers, HeaderReplayedAt, time.Now().UTC().Format(time.RFC3339))
+	return kafka.Message{Topic: topic, Key: msg.Key, Value: msg.Value, Headers: headers}
+}
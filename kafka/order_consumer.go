@@ -3,6 +3,7 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"paymentfc/log"
 	"paymentfc/models"
 
@@ -10,18 +11,24 @@ import (
 )
 
 // StartOrderConsumer start order consumer by given broker, topic, and handler.
-func StartOrderConsumer(broker, topic string, handler func(models.OrderCreatedEvent)) {
+// It stops reading once ctx is cancelled; the message being handled at that moment is finished first.
+func StartOrderConsumer(ctx context.Context, broker, topic string, handler func(models.OrderCreatedEvent)) <-chan struct{} {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers: []string{broker},
 		Topic:   topic,
 		GroupID: "paymentfc",
 	})
 
+	done := make(chan struct{})
 	go func(r *kafka.Reader) {
+		defer close(done)
 		defer r.Close()
 		for {
-			msg, err := r.ReadMessage(context.Background())
+			msg, err := r.ReadMessage(ctx)
 			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
 				log.Logger.Error().Err(err).Msg("Failed to read order.created message")
 				continue
 			}
@@ -35,31 +42,18 @@ func StartOrderConsumer(broker, topic string, handler func(models.OrderCreatedEv
 			handler(event)
 		}
 	}(reader)
+	return done
 }
 
-func StartStockReservedConsumer(broker, topic string, handler func(models.StockReservationEvent)) {
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: []string{broker},
-		Topic:   topic,
-		GroupID: "paymentfc",
-	})
-
-	go func(r *kafka.Reader) {
-		defer r.Close()
-		for {
-			msg, err := r.ReadMessage(context.Background())
-			if err != nil {
-				log.Logger.Error().Err(err).Msg("Failed to read stock.reserved message")
-				continue
-			}
-
-			var event models.StockReservationEvent
-			if err := json.Unmarshal(msg.Value, &event); err != nil {
-				log.Logger.Error().Err(err).Msg("Failed to unmarshal stock.reserved message")
-				continue
-			}
-
-			handler(event)
+// StartStockReservedConsumer start stock.reserved consumer with manual commits.
+// A message is committed only after handler succeeds or it has been moved to the retry topic / DLQ per policy.
+// Messages that cannot be decoded go straight to the DLQ.
+func StartStockReservedConsumer(ctx context.Context, broker, groupID string, policy RetryPolicy, writer messageWriter, handler func(context.Context, models.StockReservationEvent) error) <-chan struct{} {
+	return StartRetryingConsumer(ctx, broker, groupID, policy, writer, func(ctx context.Context, msg kafka.Message) error {
+		var event models.StockReservationEvent
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			return Permanent(fmt.Errorf("unmarshal stock.reserved message: %w", err))
 		}
-	}(reader)
+		return handler(ctx, event)
+	})
 }
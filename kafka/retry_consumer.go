@@ -0,0 +1,293 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	bizmetrics "paymentfc/infrastructure/metrics"
+	"paymentfc/log"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// 재시도/DLQ 메시지에 붙이는 헤더
+const (
+	HeaderRetryAttempt      = "x-retry-attempt"
+	HeaderNotBefore         = "x-not-before" // unix ms. 재시도 단계 컨슈머는 이 시각까지 기다렸다가 처리한다
+	HeaderError             = "x-error"
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+	HeaderFailedAt          = "x-failed-at"
+	HeaderReplayedAt        = "x-replayed-at"
+)
+
+// ErrPermanent 다시 처리해도 성공할 수 없는 메시지. 재시도 없이 바로 DLQ로 보낸다.
+var ErrPermanent = errors.New("permanent message failure")
+
+// Permanent 핸들러가 재시도하지 말아야 할 에러를 돌려줄 때 감싼다.
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
+}
+
+// MessageHandler 메시지 하나를 처리한다. nil을 돌려줘야 offset이 커밋된다.
+type MessageHandler func(ctx context.Context, msg kafka.Message) error
+
+// RetryPolicy 실패한 메시지를 Delays 단계별 재시도 토픽(RetryTopic.<대기 시간>, 예: stock.reserved.retry.1m)에 다시 넣고,
+// 단계를 모두 쓰면 DLQTopic으로 보낸다. 토픽마다 대기 시간이 같아서 앞 메시지보다 뒤 메시지가 먼저 준비되는 일이 없다.
+type RetryPolicy struct {
+	Topic      string
+	RetryTopic string // 재시도 토픽 prefix
+	DLQTopic   string
+	Delays     []time.Duration
+}
+
+// RetryTopics 단계별 재시도 토픽. 대기 시간이 같은 단계는 토픽을 함께 쓴다.
+func (p RetryPolicy) RetryTopics() []string {
+	var topics []string
+	seen := make(map[string]bool, len(p.Delays))
+	for _, d := range p.Delays {
+		topic := p.retryTopic(d)
+		if !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+func (p RetryPolicy) retryTopic(delay time.Duration) string {
+	return p.RetryTopic + "." + delayLabel(delay)
+}
+
+// delayLabel 1h, 5m, 10s처럼 나누어떨어지는 가장 큰 단위로 표기한다.
+func delayLabel(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d >= time.Minute && d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	default:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	}
+}
+
+type messageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+const (
+	publishRetryBaseDelay = 1 * time.Second
+	publishRetryMaxDelay  = 30 * time.Second
+)
+
+type retryingConsumer struct {
+	policy  RetryPolicy
+	writer  messageWriter
+	handler MessageHandler
+}
+
+// StartRetryingConsumer policy.Topic과 단계별 재시도 토픽을 각각 읽는 컨슈머를 띄운다.
+// 재시도 토픽은 토픽마다 groupID-retry-<대기 시간> 그룹으로 읽어 메인 그룹의 리밸런스와 섞이지 않게 한다.
+// 메시지는 처리에 성공했거나 재시도/DLQ 토픽으로 넘긴 뒤에만 커밋한다. writer는 메시지마다 토픽을 지정할 수 있어야 한다(Topic 미지정).
+func StartRetryingConsumer(ctx context.Context, broker, groupID string, policy RetryPolicy, writer messageWriter, handler MessageHandler) <-chan struct{} {
+	c := &retryingConsumer{policy: policy, writer: writer, handler: handler}
+	stages := []<-chan struct{}{c.start(ctx, newGroupReader(broker, groupID, policy.Topic), false)}
+	for _, topic := range policy.RetryTopics() {
+		retryGroupID := groupID + "-retry-" + strings.TrimPrefix(topic, policy.RetryTopic+".")
+		stages = append(stages, c.start(ctx, newGroupReader(broker, retryGroupID, topic), true))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, stageDone := range stages {
+			<-stageDone
+		}
+	}()
+	return done
+}
+
+func newGroupReader(broker, groupID, topic string) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{broker},
+		Topic:   topic,
+		GroupID: groupID,
+	})
+}
+
+func (c *retryingConsumer) start(ctx context.Context, r messageReader, retryStage bool) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer r.Close()
+		c.run(ctx, r, retryStage)
+	}()
+	return done
+}
+
+// run ctx가 취소되면 처리 중인 메시지를 끝내고 멈춘다. 커밋하지 못한 메시지는 재시작 후 다시 읽힌다.
+func (c *retryingConsumer) run(ctx context.Context, r messageReader, retryStage bool) {
+	for {
+		msg, err := r.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Logger.Error().Err(err).Str("topic", c.policy.Topic).Bool("retry_stage", retryStage).Msg("Failed to fetch Kafka message")
+			continue
+		}
+
+		if retryStage && !waitUntil(ctx, notBefore(msg)) {
+			return
+		}
+
+		handleCtx := context.WithoutCancel(ctx)
+		if err := c.process(ctx, handleCtx, msg); err != nil {
+			// 재시도/DLQ 토픽으로 넘기지 못했으므로 커밋하지 않는다.
+			log.Logger.Error().Err(err).Str("topic", msg.Topic).Int64("offset", msg.Offset).Msg("Stopping consumer without committing message")
+			return
+		}
+		if err := r.CommitMessages(handleCtx, msg); err != nil {
+			log.Logger.Error().Err(err).Str("topic", msg.Topic).Int64("offset", msg.Offset).Msg("Failed to commit Kafka message")
+		}
+	}
+}
+
+// process 핸들러를 실행하고 실패하면 다음 재시도 단계나 DLQ로 넘긴다. 넘기지 못하면 에러를 돌려준다.
+func (c *retryingConsumer) process(ctx, handleCtx context.Context, msg kafka.Message) error {
+	handleErr := c.handler(handleCtx, msg)
+	if handleErr == nil {
+		bizmetrics.KafkaConsumerMessages.WithLabelValues(c.policy.Topic, "handled").Inc()
+		return nil
+	}
+
+	attempt := retryAttempt(msg)
+	if errors.Is(handleErr, ErrPermanent) || attempt >= len(c.policy.Delays) {
+		log.Logger.Error().Err(handleErr).Str("topic", msg.Topic).Int64("offset", msg.Offset).Int("attempt", attempt).Msg("Sending Kafka message to DLQ")
+		if err := c.publish(ctx, handleCtx, deadLetterMessage(c.policy, msg, attempt, handleErr)); err != nil {
+			return err
+		}
+		bizmetrics.KafkaConsumerMessages.WithLabelValues(c.policy.Topic, "dead_lettered").Inc()
+		return nil
+	}
+
+	delay := c.policy.Delays[attempt]
+	log.Logger.Warn().Err(handleErr).Str("topic", msg.Topic).Int64("offset", msg.Offset).Int("attempt", attempt+1).Str("wait", delay.String()).Msg("Kafka message failed, scheduling retry")
+	if err := c.publish(ctx, handleCtx, retryMessage(c.policy, msg, attempt+1, time.Now().Add(delay), handleErr)); err != nil {
+		return err
+	}
+	bizmetrics.KafkaConsumerMessages.WithLabelValues(c.policy.Topic, "retried").Inc()
+	return nil
+}
+
+// publish 성공할 때까지 백오프하며 발행한다. ctx가 취소되면 포기한다.
+func (c *retryingConsumer) publish(ctx, writeCtx context.Context, msg kafka.Message) error {
+	for attempt := 0; ; attempt++ {
+		err := c.writer.WriteMessages(writeCtx, msg)
+		if err == nil {
+			return nil
+		}
+		delay := min(publishRetryBaseDelay<<min(attempt, 10), publishRetryMaxDelay)
+		log.Logger.Error().Err(err).Str("topic", msg.Topic).Str("wait", delay.String()).Msg("Failed to publish Kafka message, retrying")
+		if !waitUntil(ctx, time.Now().Add(delay)) {
+			return fmt.Errorf("publish to %s: %w", msg.Topic, err)
+		}
+	}
+}
+
+// retryMessage attempt번째 단계의 재시도 토픽으로 보낼 메시지. 원본 key/value를 유지하고 재시도 헤더를 새로 단다. 원본 토픽/위치는 처음 실패했을 때 값을 유지한다.
+func retryMessage(policy RetryPolicy, msg kafka.Message, attempt int, notBefore time.Time, cause error) kafka.Message {
+	headers := withOrigin(msg)
+	headers = setHeader(headers, HeaderRetryAttempt, strconv.Itoa(attempt))
+	headers = setHeader(headers, HeaderNotBefore, strconv.FormatInt(notBefore.UnixMilli(), 10))
+	headers = setHeader(headers, HeaderError, cause.Error())
+	return kafka.Message{Topic: policy.retryTopic(policy.Delays[attempt-1]), Key: msg.Key, Value: msg.Value, Headers: headers}
+}
+
+func deadLetterMessage(policy RetryPolicy, msg kafka.Message, attempt int, cause error) kafka.Message {
+	headers := withOrigin(msg)
+	headers = removeHeader(headers, HeaderNotBefore)
+	headers = setHeader(headers, HeaderRetryAttempt, strconv.Itoa(attempt))
+	headers = setHeader(headers, HeaderError, cause.Error())
+	headers = setHeader(headers, HeaderFailedAt, time.Now().UTC().Format(time.RFC3339))
+	return kafka.Message{Topic: policy.DLQTopic, Key: msg.Key, Value: msg.Value, Headers: headers}
+}
+
+func withOrigin(msg kafka.Message) []kafka.Header {
+	headers := append([]kafka.Header(nil), msg.Headers...)
+	if header(msg.Headers, HeaderOriginalTopic) == "" {
+		headers = setHeader(headers, HeaderOriginalTopic, msg.Topic)
+		headers = setHeader(headers, HeaderOriginalPartition, strconv.Itoa(msg.Partition))
+		headers = setHeader(headers, HeaderOriginalOffset, strconv.FormatInt(msg.Offset, 10))
+	}
+	return headers
+}
+
+func retryAttempt(msg kafka.Message) int {
+	n, _ := strconv.Atoi(header(msg.Headers, HeaderRetryAttempt))
+	return n
+}
+
+func notBefore(msg kafka.Message) time.Time {
+	ms, err := strconv.ParseInt(header(msg.Headers, HeaderNotBefore), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// waitUntil t까지 기다린다. 그 사이 ctx가 취소되면 false를 돌려준다.
+func waitUntil(ctx context.Context, t time.Time) bool {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func header(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func setHeader(headers []kafka.Header, key, value string) []kafka.Header {
+	for i := range headers {
+		if headers[i].Key == key {
+			headers[i].Value = []byte(value)
+			return headers
+		}
+	}
+	return append(headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func removeHeader(headers []kafka.Header, key string) []kafka.Header {
+	result := headers[:0]
+	for _, h := range headers {
+		if h.Key != key {
+			result = append(result, h)
+		}
+	}
+	return result
+}
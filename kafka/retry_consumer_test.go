@@ -0,0 +1,240 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeReader struct {
+	mu        sync.Mutex
+	messages  []kafka.Message
+	committed []kafka.Message
+}
+
+// FetchMessage 남은 메시지를 차례로 돌려주고, 다 읽으면 ctx가 끝날 때까지 기다린다.
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+	if len(r.messages) > 0 {
+		msg := r.messages[0]
+		r.messages = r.messages[1:]
+		r.mu.Unlock()
+		return msg, nil
+	}
+	r.mu.Unlock()
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed = append(r.committed, msgs...)
+	return nil
+}
+
+func (r *fakeReader) Close() error { return nil }
+
+type fakeWriter struct {
+	mu      sync.Mutex
+	err     error
+	written []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.written = append(w.written, msgs...)
+	return nil
+}
+
+var testPolicy = RetryPolicy{
+	Topic:      "stock.reserved",
+	RetryTopic: "stock.reserved.retry",
+	DLQTopic:   "stock.reserved.dlq",
+	Delays:     []time.Duration{10 * time.Second, time.Minute},
+}
+
+func TestRetryPolicy_RetryTopics(t *testing.T) {
+	assert.Equal(t, []string{"stock.reserved.retry.10s", "stock.reserved.retry.1m"}, testPolicy.RetryTopics())
+
+	policy := RetryPolicy{RetryTopic: "stock.reserved.retry", Delays: []time.Duration{90 * time.Second, 5 * time.Minute, 5 * time.Minute, 2 * time.Hour}}
+	assert.Equal(t, []string{"stock.reserved.retry.90s", "stock.reserved.retry.5m", "stock.reserved.retry.2h"}, policy.RetryTopics())
+}
+
+func TestRetryingConsumer_Process(t *testing.T) {
+	ctx := context.Background()
+	source := kafka.Message{Topic: "stock.reserved", Partition: 2, Offset: 41, Key: []byte("1001"), Value: []byte(`{"order_id":1001}`)}
+
+	t.Run("handled message is not republished", func(t *testing.T) {
+		w := &fakeWriter{}
+		c := &retryingConsumer{policy: testPolicy, writer: w, handler: func(context.Context, kafka.Message) error { return nil }}
+		assert.NoError(t, c.process(ctx, ctx, source))
+		assert.Empty(t, w.written)
+	})
+
+	t.Run("first failure goes to retry topic with first delay", func(t *testing.T) {
+		w := &fakeWriter{}
+		c := &retryingConsumer{policy: testPolicy, writer: w, handler: func(context.Context, kafka.Message) error { return errors.New("db down") }}
+		before := time.Now()
+		assert.NoError(t, c.process(ctx, ctx, source))
+
+		assert.Len(t, w.written, 1)
+		msg := w.written[0]
+		assert.Equal(t, "stock.reserved.retry.10s", msg.Topic)
+		assert.Equal(t, source.Key, msg.Key)
+		assert.Equal(t, source.Value, msg.Value)
+		assert.Equal(t, "1", header(msg.Headers, HeaderRetryAttempt))
+		assert.Equal(t, "db down", header(msg.Headers, HeaderError))
+		assert.Equal(t, "stock.reserved", header(msg.Headers, HeaderOriginalTopic))
+		assert.Equal(t, "2", header(msg.Headers, HeaderOriginalPartition))
+		assert.Equal(t, "41", header(msg.Headers, HeaderOriginalOffset))
+		assert.WithinDuration(t, before.Add(10*time.Second), notBefore(msg), time.Second)
+	})
+
+	t.Run("next failure moves to the next delay tier", func(t *testing.T) {
+		w := &fakeWriter{}
+		c := &retryingConsumer{policy: testPolicy, writer: w, handler: func(context.Context, kafka.Message) error { return errors.New("db down") }}
+		retried := retryMessage(testPolicy, source, 1, time.Now(), errors.New("db down"))
+		before := time.Now()
+		assert.NoError(t, c.process(ctx, ctx, retried))
+
+		assert.Len(t, w.written, 1)
+		msg := w.written[0]
+		assert.Equal(t, "stock.reserved.retry.1m", msg.Topic)
+		assert.Equal(t, "2", header(msg.Headers, HeaderRetryAttempt))
+		assert.Equal(t, "41", header(msg.Headers, HeaderOriginalOffset))
+		assert.WithinDuration(t, before.Add(time.Minute), notBefore(msg), time.Second)
+	})
+
+	t.Run("exhausted retries go to DLQ keeping original position", func(t *testing.T) {
+		w := &fakeWriter{}
+		c := &retryingConsumer{policy: testPolicy, writer: w, handler: func(context.Context, kafka.Message) error { return errors.New("db down") }}
+		retried := retryMessage(testPolicy, source, 2, time.Now(), errors.New("db down"))
+		retried.Partition, retried.Offset = 0, 7
+		assert.NoError(t, c.process(ctx, ctx, retried))
+
+		assert.Len(t, w.written, 1)
+		msg := w.written[0]
+		assert.Equal(t, "stock.reserved.dlq", msg.Topic)
+		assert.Equal(t, "2", header(msg.Headers, HeaderRetryAttempt))
+		assert.Equal(t, "41", header(msg.Headers, HeaderOriginalOffset))
+		assert.Empty(t, header(msg.Headers, HeaderNotBefore))
+		assert.NotEmpty(t, header(msg.Headers, HeaderFailedAt))
+	})
+
+	t.Run("permanent failure skips retries", func(t *testing.T) {
+		w := &fakeWriter{}
+		c := &retryingConsumer{policy: testPolicy, writer: w, handler: func(context.Context, kafka.Message) error {
+			return Permanent(errors.New("invalid total_amount"))
+		}}
+		assert.NoError(t, c.process(ctx, ctx, source))
+
+		assert.Len(t, w.written, 1)
+		assert.Equal(t, "stock.reserved.dlq", w.written[0].Topic)
+		assert.Equal(t, "0", header(w.written[0].Headers, HeaderRetryAttempt))
+	})
+}
+
+func TestRetryingConsumer_Run(t *testing.T) {
+	t.Run("commits after handling or routing", func(t *testing.T) {
+		r := &fakeReader{messages: []kafka.Message{{Topic: "stock.reserved", Offset: 1}, {Topic: "stock.reserved", Offset: 2}}}
+		w := &fakeWriter{}
+		c := &retryingConsumer{policy: testPolicy, writer: w, handler: func(_ context.Context, msg kafka.Message) error {
+			if msg.Offset == 2 {
+				return errors.New("gateway down")
+			}
+			return nil
+		}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := c.start(ctx, r, false)
+		assert.Eventually(t, func() bool {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			return len(r.committed) == 2
+		}, time.Second, 5*time.Millisecond)
+		cancel()
+		<-done
+
+		assert.Len(t, w.written, 1)
+		assert.Equal(t, "stock.reserved.retry.10s", w.written[0].Topic)
+	})
+
+	t.Run("does not commit when retry topic is unavailable", func(t *testing.T) {
+		r := &fakeReader{messages: []kafka.Message{{Topic: "stock.reserved", Offset: 1}}}
+		w := &fakeWriter{err: errors.New("broker unavailable")}
+		handled := make(chan struct{})
+		c := &retryingConsumer{policy: testPolicy, writer: w, handler: func(context.Context, kafka.Message) error {
+			close(handled)
+			return errors.New("db down")
+		}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := c.start(ctx, r, false)
+		<-handled
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("consumer did not stop after context cancel")
+		}
+		assert.Empty(t, r.committed)
+	})
+
+	t.Run("retry stage waits until not-before", func(t *testing.T) {
+		msg := retryMessage(testPolicy, kafka.Message{Topic: "stock.reserved"}, 1, time.Now().Add(time.Hour), errors.New("db down"))
+		r := &fakeReader{messages: []kafka.Message{msg}}
+		called := false
+		c := &retryingConsumer{policy: testPolicy, writer: &fakeWriter{}, handler: func(context.Context, kafka.Message) error {
+			called = true
+			return nil
+		}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		<-c.start(ctx, r, true)
+		assert.False(t, called)
+		assert.Empty(t, r.committed)
+	})
+}
+
+func TestDLQReplayer_Replay(t *testing.T) {
+	dead := deadLetterMessage(testPolicy, kafka.Message{Topic: "stock.reserved", Offset: 41, Key: []byte("1001"), Value: []byte(`{}`)}, 2, errors.New("db down"))
+	r := &fakeReader{messages: []kafka.Message{dead, dead, dead}}
+	w := &fakeWriter{}
+	d := &DLQReplayer{policy: testPolicy, writer: w, newReader: func() messageReader { return r }, idleTimeout: 20 * time.Millisecond}
+
+	result, err := d.Replay(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Replayed)
+	assert.Len(t, r.committed, 2)
+
+	msg := w.written[0]
+	assert.Equal(t, "stock.reserved", msg.Topic)
+	assert.Equal(t, []byte("1001"), msg.Key)
+	for _, key := range []string{HeaderRetryAttempt, HeaderError, HeaderFailedAt, HeaderOriginalTopic, HeaderOriginalOffset} {
+		assert.Empty(t, header(msg.Headers, key), key)
+	}
+	assert.NotEmpty(t, header(msg.Headers, HeaderReplayedAt))
+
+	// 남은 메시지보다 limit이 크면 idle timeout에서 멈춘다.
+	result, err = d.Replay(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Replayed)
+
+	w.err = errors.New("broker unavailable")
+	r.messages = []kafka.Message{dead}
+	result, err = d.Replay(context.Background(), 10)
+	assert.Error(t, err)
+	assert.Equal(t, 0, result.Replayed)
+	assert.Len(t, r.committed, 3)
+}
@@ -35,3 +35,11 @@ func (w *KafkaWriter) WriteMessage(ctx context.Context, key string, value interf
 	}
 	return w.writer.WriteMessages(ctx, msg)
 }
+
+// NewMessageWriter 메시지마다 Topic을 지정해 쓰는 writer. 같은 key는 같은 파티션으로 보내 주문별 순서를 유지한다.
+func NewMessageWriter(broker string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:     kafka.TCP(broker),
+		Balancer: &kafka.Hash{},
+	}
+}
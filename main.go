@@ -2,6 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
 	"paymentfc/cmd/payment/handler"
 	"paymentfc/cmd/payment/repository"
 	"paymentfc/cmd/payment/resource"
@@ -10,20 +15,28 @@ import (
 	"paymentfc/config"
 	"paymentfc/constant"
 	usergrpc "paymentfc/grpc"
+	"paymentfc/infrastructure/lifecycle"
+	"paymentfc/infrastructure/xenditsim"
 	"paymentfc/kafka"
 	"paymentfc/log"
 	"paymentfc/middleware"
 	"paymentfc/models"
+	"paymentfc/pdf"
 	"paymentfc/routes"
 	"paymentfc/tracing"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
-	kafkago "github.com/segmentio/kafka-go"
 
 	_ "paymentfc/docs"
 )
 
+// stockReservedGroupID 기존 ReadMessage 컨슈머와 같은 그룹을 써서 커밋된 offset을 이어받는다.
+// 재시도 토픽은 이 그룹이 아니라 paymentfc-retry-<대기 시간> 그룹으로 읽는다.
+const stockReservedGroupID = "paymentfc"
+
 // @title           PAYMENTFC API
 // @version         1.0
 // @description     Payment, Xendit invoice, webhook, and audit for Go Commerce.
@@ -47,79 +60,196 @@ func main() {
 	shutdownTracer, err := tracing.InitTracer(cfg.Tracing)
 	if err != nil {
 		log.Logger.Warn().Err(err).Msg("Failed to initialize tracing - continuing without tracing")
-	} else {
-		defer shutdownTracer(context.Background())
+		shutdownTracer = nil
 	}
 
 	db := resource.InitDB(cfg.Database)
 	mongoDB := resource.InitMongo(cfg.Mongo)
+	rdb := resource.InitRedis(cfg.Redis)
 
 	userClient, err := usergrpc.NewUserClient(cfg.GRPC.UserServiceAddr)
 	if err != nil {
 		log.Logger.Warn().Err(err).Msg("Failed to connect to user gRPC service - continuing without user service")
 	} else {
-		defer userClient.Close()
 		log.Logger.Info().Str("addr", cfg.GRPC.UserServiceAddr).Msg("User gRPC client initialized")
 	}
 
-	// AutoMigrate: payment, payment_anomalies, failed_events, payment_requests 테이블 자동 생성/업데이트
-	if err := db.AutoMigrate(&models.Payment{}, &models.PaymentAnomaly{}, &models.FailedEvent{}, &models.PaymentRequest{}); err != nil {
-		log.Logger.Fatal().Err(err).Msg("Failed to migrate database")
+	// numeric amount -> bigint minor unit 변환은 AutoMigrate가 컬럼 타입을 바꾸기 전에 먼저 수행
+	if err := resource.MigrateMoneyColumns(db); err != nil {
+		log.Logger.Fatal().Err(err).Msg("Failed to migrate money columns")
 	}
-	log.Logger.Info().Msg("Database migration completed - payment, payment_anomalies, failed_events, payment_requests tables created")
 
-	// Kafka Writer 생성 (payment.success 토픽 발행용)
-	kafkaWriter := &kafkago.Writer{
-		Addr:     kafkago.TCP(cfg.Kafka.Broker),
-		Topic:    constant.KafkaTopicPaymentSuccess,
-		Balancer: &kafkago.LeastBytes{},
+	// AutoMigrate: payment, payment_anomalies, failed_events, payment_requests, refunds, payment_status_history, webhook_events, outbox, reconciliation_runs, reconciliation_items, invoice_sequences, job_runs, job_states 테이블 자동 생성/업데이트
+	if err := db.AutoMigrate(&models.Payment{}, &models.PaymentAnomaly{}, &models.FailedEvent{}, &models.PaymentRequest{}, &models.Refund{}, &models.PaymentStatusHistory{}, &models.WebhookEvent{}, &models.OutboxEvent{}, &models.ReconciliationRun{}, &models.ReconciliationItem{}, &models.InvoiceSequence{}, &models.JobRun{}, &models.JobState{}); err != nil {
+		log.Logger.Fatal().Err(err).Msg("Failed to migrate database")
+	}
+	if err := resource.BackfillAnomalyAmounts(db); err != nil {
+		log.Logger.Fatal().Err(err).Msg("Failed to backfill payment anomaly amounts")
 	}
-	defer kafkaWriter.Close()
+	if err := resource.BackfillPaymentTaxAmounts(db); err != nil {
+		log.Logger.Fatal().Err(err).Msg("Failed to backfill payment tax amounts")
+	}
+	log.Logger.Info().Msg("Database migration completed - payment, payment_anomalies, failed_events, payment_requests, refunds, payment_status_history, webhook_events, outbox, reconciliation_runs, reconciliation_items, invoice_sequences, job_runs, job_states tables created")
+
+	// Kafka Writer 생성. 토픽은 메시지마다 지정한다 (payment.success, payment.refunded 등)
+	kafkaWriter := kafka.NewMessageWriter(cfg.Kafka.Broker)
 
 	// 의존성 주입
 	paymentDatabase := repository.NewPaymentDatabase(db)
 	paymentPublisher := repository.NewKafkaPublisher(kafkaWriter)
 	auditLogRepo := repository.NewAuditLogRepository(mongoDB)
-	xenditClient := repository.NewXenditClient(cfg.Xendit.XenditAPIKey)
-	xenditService := service.NewXenditService(paymentDatabase, xenditClient, userClient)
+	xenditBaseURL := cfg.Xendit.BaseURL
+	if cfg.Xendit.Simulator.Enabled {
+		xenditBaseURL = startXenditSimulator(cfg)
+	}
+	xenditClient := repository.NewXenditClient(cfg.Xendit.XenditAPIKey, xenditBaseURL)
+	paymentGateways := []repository.PaymentGateway{repository.NewXenditGateway(xenditClient, cfg.Xendit.XenditWebhookToken)}
+	if cfg.Midtrans.ServerKey != "" {
+		var midtransClient *http.Client
+		if cfg.Midtrans.TimeoutSeconds > 0 {
+			midtransClient = &http.Client{Timeout: time.Duration(cfg.Midtrans.TimeoutSeconds) * time.Second}
+		}
+		paymentGateways = append(paymentGateways, repository.NewMidtransGateway(cfg.Midtrans.ServerKey, cfg.Midtrans.APIBaseURL, cfg.Midtrans.SnapBaseURL, midtransClient))
+	}
+	defaultGateway := cfg.Gateway.Default
+	if defaultGateway == "" {
+		defaultGateway = constant.PaymentProviderXendit
+	}
+	gatewayRegistry, err := repository.NewGatewayRegistry(defaultGateway, paymentGateways...)
+	if err != nil {
+		log.Logger.Fatal().Err(err).Msg("Failed to initialize payment gateways")
+	}
+	taxRule := models.TaxRule{}
+	if cfg.Tax.Enabled {
+		taxRule = models.TaxRule{RateBps: cfg.Tax.RateBps, Inclusive: cfg.Tax.Inclusive, ExemptCategories: cfg.Tax.ExemptCategories}
+	}
+	xenditService := service.NewXenditService(paymentDatabase, gatewayRegistry, userClient, taxRule)
 	xenditUsecase := usecase.NewXenditUsecase(xenditService)
 
 	paymentService := service.NewPaymentService(paymentDatabase, paymentPublisher, xenditService, auditLogRepo)
-	paymentUsecase := usecase.NewPaymentUsecase(paymentService)
-	paymentHandler := handler.NewPaymentHandler(paymentUsecase, xenditUsecase, cfg.Xendit.XenditWebhookToken)
-
+	invoiceService := service.NewInvoiceService(paymentDatabase, repository.NewInvoiceCache(rdb), userClient, pdf.Company{
+		Name:    cfg.Invoice.CompanyName,
+		Address: cfg.Invoice.CompanyAddress,
+		Email:   cfg.Invoice.CompanyEmail,
+		Phone:   cfg.Invoice.CompanyPhone,
+		TaxID:   cfg.Invoice.TaxID,
+	}, invoiceLocation(cfg.Invoice.Timezone))
+	paymentUsecase := usecase.NewPaymentUsecase(paymentService, invoiceService)
+	refundService := service.NewRefundService(paymentDatabase, gatewayRegistry, paymentPublisher, auditLogRepo)
+	refundUsecase := usecase.NewRefundUsecase(refundService)
+	anomalyService := service.NewAnomalyService(paymentDatabase, paymentService, refundService, auditLogRepo)
+	anomalyUsecase := usecase.NewAnomalyUsecase(anomalyService)
+	reconciliationService := service.NewReconciliationService(paymentDatabase, xenditClient, paymentService, auditLogRepo)
+	reconciliationUsecase := usecase.NewReconciliationUsecase(reconciliationService)
+	reportUsecase := usecase.NewReportUsecase(service.NewReportService(paymentDatabase))
 	scheduler := service.SchedulerService{
-		Database:       paymentDatabase,
-		Xendit:         xenditClient,
-		Publisher:      paymentPublisher,
-		PaymentService: paymentService,
-		AuditLog:       auditLogRepo,
-		UserClient:     userClient,
-	}
-	scheduler.StartCheckPendingInvoices()
-	scheduler.StartProcessPendingPaymentRequests()
-	scheduler.StartProcessFailedPaymentRequests()
-	scheduler.StartSweepingExpiredPendingPayments()
-
-	// stock.reserved 컨슈머: 재고 예약 완료 이후 결제 요청을 생성한다.
-	kafka.StartStockReservedConsumer(cfg.Kafka.Broker, constant.KafkaTopicStockReserved, func(event models.StockReservationEvent) {
-		ctx := context.Background()
+		Database:        paymentDatabase,
+		Gateways:        gatewayRegistry,
+		Publisher:       paymentPublisher,
+		PaymentService:  paymentService,
+		AuditLog:        auditLogRepo,
+		UserClient:      userClient,
+		Reconciliation:  reconciliationService,
+		TaxRule:         taxRule,
+		InstanceID:      schedulerInstanceID(cfg.Scheduler.InstanceID),
+		ClaimLease:      time.Duration(cfg.Scheduler.ClaimLeaseSeconds) * time.Second,
+		JobRunRetention: time.Duration(cfg.Scheduler.JobRunRetentionDays) * 24 * time.Hour,
+
+		ReconciliationEnabled: cfg.Reconciliation.Enabled,
+		ReconciliationRunHour: cfg.Reconciliation.RunHour,
+		ReconciliationAutoFix: cfg.Reconciliation.AutoFix,
+	}
+	log.Logger.Info().Str("instance_id", scheduler.InstanceID).Msg("Scheduler instance identity resolved")
+
+	// 종료 순서: HTTP 드레인 -> 워커/컨슈머 정지(진행 중인 배치 완료) -> 아래 OnClose 등록 순서대로 리소스 정리
+	lc := lifecycle.NewManager()
+	workerCtx := lc.Context()
+	if leaderCfg := cfg.Scheduler.LeaderElection; leaderCfg.Enabled {
+		scheduler.Leader = service.NewLeaderElector(repository.NewLeaderLock(rdb), leaderCfg.Key, scheduler.InstanceID, leaderTTL(leaderCfg.TTLSeconds))
+		// 싱글톤 잡이 멈출 때까지 lease를 유지하다가 리소스 정리 첫 단계에서 반납한다.
+		leaderCtx, stopLeader := context.WithCancel(context.Background())
+		leaderDone := scheduler.Leader.Start(leaderCtx)
+		lc.OnClose("scheduler leader lease", func(ctx context.Context) error {
+			stopLeader()
+			select {
+			case <-leaderDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}
+	lc.OnClose("kafka writer", func(context.Context) error { return kafkaWriter.Close() })
+	retryWriter := kafka.NewMessageWriter(cfg.Kafka.Broker)
+	lc.OnClose("kafka retry writer", func(context.Context) error { return retryWriter.Close() })
+	if mongoDB != nil {
+		lc.OnClose("mongo client", func(ctx context.Context) error { return mongoDB.Client().Disconnect(ctx) })
+	}
+	lc.OnClose("redis client", func(context.Context) error { return rdb.Close() })
+	if userClient != nil {
+		lc.OnClose("user grpc client", func(context.Context) error { return userClient.Close() })
+	}
+	lc.OnClose("database pool", func(context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+	if shutdownTracer != nil {
+		lc.OnClose("tracer", shutdownTracer)
+	}
+
+	jobEngine := service.NewJobEngine(paymentDatabase, scheduler.Leader, scheduler.InstanceID)
+	for _, job := range append(scheduler.Jobs(), paymentUsecase.WebhookJob()) {
+		job.Spec = applyJobConfig(job.Spec, cfg.Scheduler.Jobs[job.Spec.Name])
+		if err := jobEngine.Register(job); err != nil {
+			log.Logger.Fatal().Err(err).Msg("Invalid scheduler job config")
+		}
+	}
+
+	stockReservedPolicy := kafka.RetryPolicy{
+		Topic:      constant.KafkaTopicStockReserved,
+		RetryTopic: constant.KafkaTopicStockReservedRetry,
+		DLQTopic:   constant.KafkaTopicStockReservedDLQ,
+		Delays:     kafkaRetryDelays(cfg.Kafka.RetryDelaysSeconds),
+	}
+	dlqReplayer := kafka.NewDLQReplayer(cfg.Kafka.Broker, stockReservedGroupID+"-dlq-replay", stockReservedPolicy, retryWriter)
+
+	paymentHandler := handler.NewPaymentHandler(paymentUsecase, xenditUsecase, refundUsecase, anomalyUsecase, reconciliationUsecase, reportUsecase, usecase.NewJobUsecase(jobEngine), scheduler.Leader, dlqReplayer)
+
+	lc.Track("job_engine", jobEngine.Start(workerCtx))
+
+	// stock.reserved 컨슈머: 재고 예약 완료 이후 결제 요청을 생성한다. 실패한 메시지는 에러를 돌려줘 재시도/DLQ로 넘긴다.
+	lc.Track("stock_reserved_consumer", kafka.StartStockReservedConsumer(workerCtx, cfg.Kafka.Broker, stockReservedGroupID, stockReservedPolicy, retryWriter, func(ctx context.Context, event models.StockReservationEvent) error {
+		orderEvent := models.OrderCreatedEvent{
+			OrderID:     event.OrderID,
+			UserID:      event.UserID,
+			TotalAmount: event.TotalAmount,
+			Currency:    event.Currency,
+			Provider:    event.Provider,
+			Products:    event.Products,
+		}
+		// 금액/상품이 잘못된 이벤트는 다시 처리해도 실패하므로 바로 DLQ로 보낸다.
+		if _, err := orderEvent.Total(); err != nil {
+			return kafka.Permanent(err)
+		}
+		if _, err := orderEvent.LineItems(); err != nil {
+			return kafka.Permanent(err)
+		}
 		if cfg.Toggle.DisableCreateInvoiceDirectly {
 			// 배치 방식: 저장만, 인보이스는 배치에서 생성
 			if err := paymentUsecase.ProcessStockReserved(ctx, event); err != nil {
-				log.Logger.Error().Err(err).Msgf("Failed to process payment request for order_id: %d", event.OrderID)
-			}
-		} else {
-			// 실시간: 바로 인보이스 생성
-			if _, err := xenditUsecase.CreateInvoice(ctx, models.OrderCreatedEvent{
-				OrderID:     event.OrderID,
-				UserID:      event.UserID,
-				TotalAmount: event.TotalAmount,
-			}); err != nil {
-				log.Logger.Error().Err(err).Msgf("Failed to create invoice for order_id: %d", event.OrderID)
+				return fmt.Errorf("process payment request for order_id %d: %w", event.OrderID, err)
 			}
+			return nil
 		}
-	})
+		// 실시간: 바로 인보이스 생성
+		if _, err := xenditUsecase.CreateInvoice(ctx, orderEvent); err != nil {
+			return fmt.Errorf("create invoice for order_id %d: %w", event.OrderID, err)
+		}
+		return nil
+	}))
 
 	port := cfg.App.Port
 	router := gin.Default()
@@ -131,8 +261,126 @@ func main() {
 	}
 
 	// 라우트 설정
-	routes.SetupRoutes(router, paymentHandler)
+	// 토큰에 role 클레임이 없으면 user 서비스에서 역할을 조회한다
+	var roleResolver middleware.RoleResolver
+	if userClient != nil {
+		roleResolver = middleware.UserServiceRoleResolver(userClient)
+	}
+	routes.SetupRoutes(router, paymentHandler, roleResolver, repository.NewIdempotencyStore(rdb))
+
+	srv := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		log.Logger.Info().Msgf("Server is running on port %s", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Logger.Fatal().Err(err).Msg("HTTP server stopped")
+		}
+	}()
+
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	<-signalCtx.Done()
 
-	log.Logger.Info().Msgf("Server is running on port %s", port)
-	router.Run(":" + port)
+	drainTimeout := shutdownTimeout(cfg.App.ShutdownTimeoutSeconds)
+	log.Logger.Info().Dur("timeout", drainTimeout).Msg("Shutdown signal received, draining")
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancelDrain()
+	if err := srv.Shutdown(drainCtx); err != nil {
+		log.Logger.Error().Err(err).Msg("HTTP server did not drain before timeout")
+	}
+	lc.Shutdown(drainTimeout)
+	log.Logger.Info().Msg("Shutdown complete")
+}
+
+// kafkaRetryDelays 컨슈머 재시도 단계. 설정이 없으면 10초, 1분, 5분.
+func kafkaRetryDelays(seconds []int) []time.Duration {
+	if len(seconds) == 0 {
+		return []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute}
+	}
+	delays := make([]time.Duration, 0, len(seconds))
+	for _, s := range seconds {
+		delays = append(delays, time.Duration(s)*time.Second)
+	}
+	return delays
+}
+
+// shutdownTimeout HTTP 드레인과 워커 종료 대기에 각각 쓰는 시간. 설정이 없으면 30초.
+func shutdownTimeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// startXenditSimulator 내장 Xendit 시뮬레이터를 띄우고 클라이언트가 사용할 base URL을 돌려준다.
+func startXenditSimulator(cfg config.Config) string {
+	simCfg := cfg.Xendit.Simulator
+	outcome, err := xenditsim.ParseOutcome(simCfg.Outcome)
+	if err != nil {
+		log.Logger.Fatal().Err(err).Msg("Invalid Xendit simulator config")
+	}
+	callbackURL := simCfg.CallbackURL
+	if callbackURL == "" {
+		callbackURL = fmt.Sprintf("http://localhost:%s/v1/payment/webhook", cfg.App.Port)
+	}
+	sim := xenditsim.New(xenditsim.Config{
+		CallbackURL:   callbackURL,
+		CallbackToken: cfg.Xendit.XenditWebhookToken,
+		Outcome:       outcome,
+		Delay:         time.Duration(simCfg.DelaySeconds) * time.Second,
+	})
+	go func() {
+		if err := http.ListenAndServe(":"+simCfg.Port, sim); err != nil {
+			log.Logger.Error().Err(err).Msg("Xendit simulator stopped")
+		}
+	}()
+	log.Logger.Warn().Str("port", simCfg.Port).Str("outcome", string(outcome)).Str("callback_url", callbackURL).Msg("Xendit simulator enabled - not calling real Xendit")
+	return "http://localhost:" + simCfg.Port
+}
+
+// invoiceLocation 인보이스 일시 표기 timezone. tzdata가 없는 이미지에서도 WIB로 표기되도록 고정 offset으로 대체한다.
+func invoiceLocation(name string) *time.Location {
+	if name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+		log.Logger.Warn().Str("timezone", name).Msg("Invalid invoice timezone, falling back to WIB")
+	}
+	return time.FixedZone("WIB", 7*60*60)
+}
+
+// schedulerInstanceID row claim에 기록할 인스턴스 이름. 설정이 없으면 hostname-pid (k8s에서는 pod 이름)를 쓴다.
+func schedulerInstanceID(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "paymentfc"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// leaderTTL 리더 lease 길이. 설정이 없거나 너무 짧으면 30초를 쓴다.
+func leaderTTL(seconds int) time.Duration {
+	if seconds < 3 {
+		return 30 * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// applyJobConfig config의 jobs.<name> 값 중 설정된 항목만 잡 기본값에 덮어쓴다.
+func applyJobConfig(spec service.JobSpec, override config.JobConfig) service.JobSpec {
+	if override.Schedule != "" {
+		spec.Schedule = override.Schedule
+	}
+	if override.BatchSize > 0 {
+		spec.BatchSize = override.BatchSize
+	}
+	if override.TimeoutSeconds > 0 {
+		spec.Timeout = time.Duration(override.TimeoutSeconds) * time.Second
+	}
+	if override.MaxRetries > 0 {
+		spec.MaxRetries = override.MaxRetries
+	}
+	return spec
 }
@@ -40,6 +40,9 @@ func AuthMiddleware(secret string) gin.HandlerFunc {
 			return
 		}
 		c.Set("user_id", claims["user_id"].(float64))
+		if role, ok := claims["role"].(string); ok {
+			c.Set("role", role)
+		}
 		c.Next()
 	}
 }
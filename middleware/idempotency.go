@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"paymentfc/cmd/payment/repository"
+	"paymentfc/log"
+	"paymentfc/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// idempotencyLockTTL 처리 중 레코드 수명. 프로세스가 죽어도 이 시간이 지나면 같은 키로 다시 시도할 수 있다.
+	idempotencyLockTTL = time.Minute
+	// idempotencyTTL 완료된 응답을 재생해 주는 기간
+	idempotencyTTL    = 24 * time.Hour
+	maxIdempotencyKey = 255
+)
+
+// Idempotency Idempotency-Key 헤더가 있으면 같은 사용자·경로·키의 요청을 한 번만 처리한다.
+// 완료된 요청은 저장된 응답을 그대로 돌려주고, 처리 중이면 409, 같은 키에 다른 본문이면 422로 응답한다.
+// 5xx 응답은 저장하지 않고 키를 풀어 재시도를 허용한다. Redis 장애 시에는 키 없이 처리한다.
+func Idempotency(store repository.IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idemKey := c.GetHeader(IdempotencyKeyHeader)
+		if idemKey == "" || store == nil {
+			c.Next()
+			return
+		}
+		if len(idemKey) > maxIdempotencyKey {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "idempotency key is too long"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		key := fmt.Sprintf("idempotency:%d:%s:%s:%s", int64(c.GetFloat64("user_id")), c.Request.Method, c.FullPath(), idemKey)
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		existing, err := store.Reserve(ctx, key, models.IdempotencyRecord{Fingerprint: fingerprint, CreateTime: time.Now()}, idempotencyLockTTL)
+		if err != nil {
+			log.Logger.Warn().Err(err).Str("idempotency_key", idemKey).Msg("Failed to reserve idempotency key, processing without it")
+			c.Next()
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "idempotency key was already used with a different request"})
+			case !existing.Completed():
+				c.JSON(http.StatusConflict, gin.H{"error": "request with this idempotency key is still in progress"})
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(existing.StatusCode, existing.ContentType, existing.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		// 클라이언트가 끊겨도 결과는 저장해야 하므로 요청 context를 쓰지 않는다.
+		saveCtx := context.WithoutCancel(ctx)
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			if err := store.Release(saveCtx, key); err != nil {
+				log.Logger.Warn().Err(err).Str("idempotency_key", idemKey).Msg("Failed to release idempotency key")
+			}
+			return
+		}
+		record := models.IdempotencyRecord{
+			Fingerprint: fingerprint,
+			StatusCode:  status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+			CreateTime:  time.Now(),
+		}
+		if err := store.Save(saveCtx, key, record, idempotencyTTL); err != nil {
+			log.Logger.Warn().Err(err).Str("idempotency_key", idemKey).Msg("Failed to save idempotent response")
+		}
+	}
+}
+
+func requestFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter 응답 본문을 함께 보관해 저장할 수 있게 한다.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
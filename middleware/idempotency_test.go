@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"paymentfc/mocks"
+	"paymentfc/models"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockIdempotencyStore(ctrl)
+	const body = `{"order_id":12345}`
+	calls := 0
+	status := http.StatusOK
+
+	router := gin.New()
+	router.POST("/invoice", Idempotency(store), func(c *gin.Context) {
+		calls++
+		c.JSON(status, gin.H{"id": "inv-12345"})
+	})
+	send := func(key, reqBody string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/invoice", strings.NewReader(reqBody))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	fingerprint := requestFingerprint(http.MethodPost, "/invoice", []byte(body))
+	key := "idempotency:0:POST:/invoice:k1"
+
+	t.Run("stores response of first request", func(t *testing.T) {
+		calls = 0
+		store.EXPECT().Reserve(gomock.Any(), key, gomock.Any(), idempotencyLockTTL).Return(nil, nil)
+		store.EXPECT().Save(gomock.Any(), key, gomock.Any(), idempotencyTTL).DoAndReturn(
+			func(_ any, _ string, record models.IdempotencyRecord, _ any) error {
+				assert.Equal(t, fingerprint, record.Fingerprint)
+				assert.Equal(t, http.StatusOK, record.StatusCode)
+				assert.JSONEq(t, `{"id":"inv-12345"}`, string(record.Body))
+				return nil
+			})
+
+		w := send("k1", body)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("replays completed response", func(t *testing.T) {
+		calls = 0
+		store.EXPECT().Reserve(gomock.Any(), key, gomock.Any(), idempotencyLockTTL).Return(&models.IdempotencyRecord{
+			Fingerprint: fingerprint, StatusCode: http.StatusOK, ContentType: "application/json", Body: []byte(`{"id":"inv-12345"}`),
+		}, nil)
+
+		w := send("k1", body)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+		assert.JSONEq(t, `{"id":"inv-12345"}`, w.Body.String())
+		assert.Zero(t, calls)
+	})
+
+	t.Run("rejects in-flight duplicate", func(t *testing.T) {
+		store.EXPECT().Reserve(gomock.Any(), key, gomock.Any(), idempotencyLockTTL).Return(&models.IdempotencyRecord{Fingerprint: fingerprint}, nil)
+
+		assert.Equal(t, http.StatusConflict, send("k1", body).Code)
+	})
+
+	t.Run("rejects key reuse with different body", func(t *testing.T) {
+		store.EXPECT().Reserve(gomock.Any(), key, gomock.Any(), idempotencyLockTTL).Return(&models.IdempotencyRecord{Fingerprint: fingerprint, StatusCode: http.StatusOK}, nil)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, send("k1", `{"order_id":999}`).Code)
+	})
+
+	t.Run("releases key on server error", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		defer func() { status = http.StatusOK }()
+		store.EXPECT().Reserve(gomock.Any(), key, gomock.Any(), idempotencyLockTTL).Return(nil, nil)
+		store.EXPECT().Release(gomock.Any(), key).Return(nil)
+
+		assert.Equal(t, http.StatusInternalServerError, send("k1", body).Code)
+	})
+
+	t.Run("processes without key when store fails", func(t *testing.T) {
+		calls = 0
+		store.EXPECT().Reserve(gomock.Any(), key, gomock.Any(), idempotencyLockTTL).Return(nil, errors.New("redis down"))
+
+		assert.Equal(t, http.StatusOK, send("k1", body).Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("passes through without header", func(t *testing.T) {
+		calls = 0
+		assert.Equal(t, http.StatusOK, send("", body).Code)
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"paymentfc/constant"
+	usergrpc "paymentfc/grpc"
+	"paymentfc/log"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleResolver 토큰에 role 클레임이 없을 때 user_id로 역할을 조회한다.
+type RoleResolver func(ctx context.Context, userID int64) (string, error)
+
+// UserServiceRoleResolver user 서비스(GetUserInfoByUserId)의 role 값을 쓴다.
+func UserServiceRoleResolver(client usergrpc.UserClientInterface) RoleResolver {
+	return func(ctx context.Context, userID int64) (string, error) {
+		userInfo, err := client.GetUserInfoByUserId(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		return userInfo.GetRole(), nil
+	}
+}
+
+// RequireRoles 역할이 roles 중 하나가 아니면 403으로 막는다. admin은 항상 통과한다.
+// AuthMiddleware 뒤에 둔다. 역할은 JWT role 클레임 → resolver 순으로 정하고, 둘 다 없으면 customer다.
+func RequireRoles(resolver RoleResolver, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := resolveRole(c, resolver)
+		if role != constant.RoleAdmin && !slices.Contains(roles, role) {
+			log.Logger.Warn().Int64("user_id", int64(c.GetFloat64("user_id"))).Str("role", role).
+				Str("path", c.FullPath()).Msg("Forbidden: role not permitted")
+			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient role"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func resolveRole(c *gin.Context, resolver RoleResolver) string {
+	role := c.GetString("role")
+	if role == "" && resolver != nil {
+		resolved, err := resolver(c.Request.Context(), int64(c.GetFloat64("user_id")))
+		if err != nil {
+			// 조회 실패 시 권한을 올려 주지 않도록 customer로 본다.
+			log.Logger.Warn().Err(err).Int64("user_id", int64(c.GetFloat64("user_id"))).Msg("Failed to resolve user role, treating as customer")
+		}
+		role = resolved
+	}
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		role = constant.RoleCustomer
+	}
+	c.Set("role", role)
+	return role
+}
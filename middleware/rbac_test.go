@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"paymentfc/constant"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(claimRole string, resolver RoleResolver, roles ...string) int {
+		router := gin.New()
+		router.GET("/", func(c *gin.Context) {
+			c.Set("user_id", float64(100))
+			if claimRole != "" {
+				c.Set("role", claimRole)
+			}
+			c.Next()
+		}, RequireRoles(resolver, roles...), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Code
+	}
+	resolveTo := func(role string, err error) RoleResolver {
+		return func(context.Context, int64) (string, error) { return role, err }
+	}
+
+	t.Run("allows role from token claim", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(constant.RoleFinance, nil, constant.RoleFinance))
+	})
+
+	t.Run("admin passes every route", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("ADMIN", nil, constant.RoleOps))
+	})
+
+	t.Run("customer is forbidden from ops routes", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve("", nil, constant.RoleOps))
+	})
+
+	t.Run("falls back to resolver without claim", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("", resolveTo(constant.RoleOps, nil), constant.RoleOps))
+	})
+
+	t.Run("treats resolver failure as customer", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve("", resolveTo("", errors.New("grpc unavailable")), constant.RoleOps))
+	})
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: paymentfc/cmd/payment/repository (interfaces: PaymentDatabase,XenditClient,PaymentEventPublisher,AuditLogRepository)
+// Source: paymentfc/cmd/payment/repository (interfaces: PaymentDatabase,XenditClient,PaymentEventPublisher,AuditLogRepository,InvoiceCache,IdempotencyStore,LeaderLock)
 
 package mocks
 
@@ -36,48 +36,48 @@ func (m *MockPaymentDatabase) EXPECT() *MockPaymentDatabaseMockRecorder {
 }
 
 // GetExpiredPendingPayments mocks base method.
-func (m *MockPaymentDatabase) GetExpiredPendingPayments(ctx context.Context) ([]models.Payment, error) {
+func (m *MockPaymentDatabase) GetExpiredPendingPayments(ctx context.Context, owner string, lease time.Duration, limit int) ([]models.Payment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetExpiredPendingPayments", ctx)
+	ret := m.ctrl.Call(m, "GetExpiredPendingPayments", ctx, owner, lease, limit)
 	ret0, _ := ret[0].([]models.Payment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetExpiredPendingPayments indicates an expected call of GetExpiredPendingPayments.
-func (mr *MockPaymentDatabaseMockRecorder) GetExpiredPendingPayments(ctx interface{}) *gomock.Call {
+func (mr *MockPaymentDatabaseMockRecorder) GetExpiredPendingPayments(ctx, owner, lease, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredPendingPayments", reflect.TypeOf((*MockPaymentDatabase)(nil).GetExpiredPendingPayments), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredPendingPayments", reflect.TypeOf((*MockPaymentDatabase)(nil).GetExpiredPendingPayments), ctx, owner, lease, limit)
 }
 
 // GetFailedPaymentRequests mocks base method.
-func (m *MockPaymentDatabase) GetFailedPaymentRequests(ctx context.Context) ([]models.PaymentRequest, error) {
+func (m *MockPaymentDatabase) GetFailedPaymentRequests(ctx context.Context, owner string, lease time.Duration, limit, maxRetries int) ([]models.PaymentRequest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFailedPaymentRequests", ctx)
+	ret := m.ctrl.Call(m, "GetFailedPaymentRequests", ctx, owner, lease, limit, maxRetries)
 	ret0, _ := ret[0].([]models.PaymentRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFailedPaymentRequests indicates an expected call of GetFailedPaymentRequests.
-func (mr *MockPaymentDatabaseMockRecorder) GetFailedPaymentRequests(ctx interface{}) *gomock.Call {
+func (mr *MockPaymentDatabaseMockRecorder) GetFailedPaymentRequests(ctx, owner, lease, limit, maxRetries interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailedPaymentRequests", reflect.TypeOf((*MockPaymentDatabase)(nil).GetFailedPaymentRequests), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailedPaymentRequests", reflect.TypeOf((*MockPaymentDatabase)(nil).GetFailedPaymentRequests), ctx, owner, lease, limit, maxRetries)
 }
 
 // GetFailedPaymentList mocks base method.
-func (m *MockPaymentDatabase) GetFailedPaymentList(ctx context.Context) ([]models.PaymentRequest, error) {
+func (m *MockPaymentDatabase) GetFailedPaymentList(ctx context.Context, maxRetries, limit int) ([]models.PaymentRequest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFailedPaymentList", ctx)
+	ret := m.ctrl.Call(m, "GetFailedPaymentList", ctx, maxRetries, limit)
 	ret0, _ := ret[0].([]models.PaymentRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFailedPaymentList indicates an expected call of GetFailedPaymentList.
-func (mr *MockPaymentDatabaseMockRecorder) GetFailedPaymentList(ctx interface{}) *gomock.Call {
+func (mr *MockPaymentDatabaseMockRecorder) GetFailedPaymentList(ctx, maxRetries, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailedPaymentList", reflect.TypeOf((*MockPaymentDatabase)(nil).GetFailedPaymentList), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailedPaymentList", reflect.TypeOf((*MockPaymentDatabase)(nil).GetFailedPaymentList), ctx, maxRetries, limit)
 }
 
 // GetPaymentByOrderID mocks base method.
@@ -96,33 +96,33 @@ func (mr *MockPaymentDatabaseMockRecorder) GetPaymentByOrderID(ctx, orderID inte
 }
 
 // GetPendingInvoices mocks base method.
-func (m *MockPaymentDatabase) GetPendingInvoices(ctx context.Context) ([]models.Payment, error) {
+func (m *MockPaymentDatabase) GetPendingInvoices(ctx context.Context, limit int) ([]models.Payment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPendingInvoices", ctx)
+	ret := m.ctrl.Call(m, "GetPendingInvoices", ctx, limit)
 	ret0, _ := ret[0].([]models.Payment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPendingInvoices indicates an expected call of GetPendingInvoices.
-func (mr *MockPaymentDatabaseMockRecorder) GetPendingInvoices(ctx interface{}) *gomock.Call {
+func (mr *MockPaymentDatabaseMockRecorder) GetPendingInvoices(ctx, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingInvoices", reflect.TypeOf((*MockPaymentDatabase)(nil).GetPendingInvoices), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingInvoices", reflect.TypeOf((*MockPaymentDatabase)(nil).GetPendingInvoices), ctx, limit)
 }
 
 // GetPendingPaymentRequests mocks base method.
-func (m *MockPaymentDatabase) GetPendingPaymentRequests(ctx context.Context) ([]models.PaymentRequest, error) {
+func (m *MockPaymentDatabase) GetPendingPaymentRequests(ctx context.Context, owner string, lease time.Duration, limit int) ([]models.PaymentRequest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPendingPaymentRequests", ctx)
+	ret := m.ctrl.Call(m, "GetPendingPaymentRequests", ctx, owner, lease, limit)
 	ret0, _ := ret[0].([]models.PaymentRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPendingPaymentRequests indicates an expected call of GetPendingPaymentRequests.
-func (mr *MockPaymentDatabaseMockRecorder) GetPendingPaymentRequests(ctx interface{}) *gomock.Call {
+func (mr *MockPaymentDatabaseMockRecorder) GetPendingPaymentRequests(ctx, owner, lease, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingPaymentRequests", reflect.TypeOf((*MockPaymentDatabase)(nil).GetPendingPaymentRequests), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingPaymentRequests", reflect.TypeOf((*MockPaymentDatabase)(nil).GetPendingPaymentRequests), ctx, owner, lease, limit)
 }
 
 // IsAlreadyPaid mocks base method.
@@ -140,48 +140,6 @@ func (mr *MockPaymentDatabaseMockRecorder) IsAlreadyPaid(ctx, orderID interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAlreadyPaid", reflect.TypeOf((*MockPaymentDatabase)(nil).IsAlreadyPaid), ctx, orderID)
 }
 
-// MarkExpired mocks base method.
-func (m *MockPaymentDatabase) MarkExpired(ctx context.Context, paymentID int64) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MarkExpired", ctx, paymentID)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// MarkExpired indicates an expected call of MarkExpired.
-func (mr *MockPaymentDatabaseMockRecorder) MarkExpired(ctx, paymentID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkExpired", reflect.TypeOf((*MockPaymentDatabase)(nil).MarkExpired), ctx, paymentID)
-}
-
-// MarkPaid mocks base method.
-func (m *MockPaymentDatabase) MarkPaid(orderID int64) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MarkPaid", orderID)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// MarkPaid indicates an expected call of MarkPaid.
-func (mr *MockPaymentDatabaseMockRecorder) MarkPaid(orderID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPaid", reflect.TypeOf((*MockPaymentDatabase)(nil).MarkPaid), orderID)
-}
-
-// MarkFailed mocks base method.
-func (m *MockPaymentDatabase) MarkFailed(orderID int64) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MarkFailed", orderID)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// MarkFailed indicates an expected call of MarkFailed.
-func (mr *MockPaymentDatabaseMockRecorder) MarkFailed(orderID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockPaymentDatabase)(nil).MarkFailed), orderID)
-}
-
 // SaveFailedPublishEvent mocks base method.
 func (m *MockPaymentDatabase) SaveFailedPublishEvent(ctx context.Context, param *models.FailedEvent) error {
 	m.ctrl.T.Helper()
@@ -239,17 +197,31 @@ func (mr *MockPaymentDatabaseMockRecorder) SavePaymentRequest(ctx, param interfa
 }
 
 // UpdateFailedPaymentRequest mocks base method.
-func (m *MockPaymentDatabase) UpdateFailedPaymentRequest(ctx context.Context, paymentRequestID int64, notes string) error {
+func (m *MockPaymentDatabase) UpdateFailedPaymentRequest(ctx context.Context, paymentRequestID int64, notes string, nextAttemptAt time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateFailedPaymentRequest", ctx, paymentRequestID, notes)
+	ret := m.ctrl.Call(m, "UpdateFailedPaymentRequest", ctx, paymentRequestID, notes, nextAttemptAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateFailedPaymentRequest indicates an expected call of UpdateFailedPaymentRequest.
-func (mr *MockPaymentDatabaseMockRecorder) UpdateFailedPaymentRequest(ctx, paymentRequestID, notes interface{}) *gomock.Call {
+func (mr *MockPaymentDatabaseMockRecorder) UpdateFailedPaymentRequest(ctx, paymentRequestID, notes, nextAttemptAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFailedPaymentRequest", reflect.TypeOf((*MockPaymentDatabase)(nil).UpdateFailedPaymentRequest), ctx, paymentRequestID, notes, nextAttemptAt)
+}
+
+// DeadLetterPaymentRequest mocks base method.
+func (m *MockPaymentDatabase) DeadLetterPaymentRequest(ctx context.Context, paymentRequestID int64, notes string, event models.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeadLetterPaymentRequest", ctx, paymentRequestID, notes, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeadLetterPaymentRequest indicates an expected call of DeadLetterPaymentRequest.
+func (mr *MockPaymentDatabaseMockRecorder) DeadLetterPaymentRequest(ctx, paymentRequestID, notes, event interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFailedPaymentRequest", reflect.TypeOf((*MockPaymentDatabase)(nil).UpdateFailedPaymentRequest), ctx, paymentRequestID, notes)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeadLetterPaymentRequest", reflect.TypeOf((*MockPaymentDatabase)(nil).DeadLetterPaymentRequest), ctx, paymentRequestID, notes, event)
 }
 
 // UpdatePendingPaymentRequest mocks base method.
@@ -280,6 +252,578 @@ func (mr *MockPaymentDatabaseMockRecorder) UpdateSuccessPaymentRequest(ctx, paym
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSuccessPaymentRequest", reflect.TypeOf((*MockPaymentDatabase)(nil).UpdateSuccessPaymentRequest), ctx, paymentRequestID)
 }
 
+// GetRefundedAmount mocks base method.
+func (m *MockPaymentDatabase) GetRefundedAmount(ctx context.Context, paymentID int64) (models.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundedAmount", ctx, paymentID)
+	ret0, _ := ret[0].(models.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundedAmount indicates an expected call of GetRefundedAmount.
+func (mr *MockPaymentDatabaseMockRecorder) GetRefundedAmount(ctx, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundedAmount", reflect.TypeOf((*MockPaymentDatabase)(nil).GetRefundedAmount), ctx, paymentID)
+}
+
+// ReserveOverpaymentRefund mocks base method.
+func (m *MockPaymentDatabase) ReserveOverpaymentRefund(ctx context.Context, param *models.Refund, limit models.Money) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveOverpaymentRefund", ctx, param, limit)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveOverpaymentRefund indicates an expected call of ReserveOverpaymentRefund.
+func (mr *MockPaymentDatabaseMockRecorder) ReserveOverpaymentRefund(ctx, param, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveOverpaymentRefund", reflect.TypeOf((*MockPaymentDatabase)(nil).ReserveOverpaymentRefund), ctx, param, limit)
+}
+
+// ReserveRefund mocks base method.
+func (m *MockPaymentDatabase) ReserveRefund(ctx context.Context, param *models.Refund) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveRefund", ctx, param)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveRefund indicates an expected call of ReserveRefund.
+func (mr *MockPaymentDatabaseMockRecorder) ReserveRefund(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveRefund", reflect.TypeOf((*MockPaymentDatabase)(nil).ReserveRefund), ctx, param)
+}
+
+// UpdateRefund mocks base method.
+func (m *MockPaymentDatabase) UpdateRefund(ctx context.Context, refundID int64, status, xenditRefundID, notes string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRefund", ctx, refundID, status, xenditRefundID, notes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRefund indicates an expected call of UpdateRefund.
+func (mr *MockPaymentDatabaseMockRecorder) UpdateRefund(ctx, refundID, status, xenditRefundID, notes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRefund", reflect.TypeOf((*MockPaymentDatabase)(nil).UpdateRefund), ctx, refundID, status, xenditRefundID, notes)
+}
+
+// GetPaymentStatusHistory mocks base method.
+func (m *MockPaymentDatabase) GetPaymentStatusHistory(ctx context.Context, paymentID int64) ([]models.PaymentStatusHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentStatusHistory", ctx, paymentID)
+	ret0, _ := ret[0].([]models.PaymentStatusHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentStatusHistory indicates an expected call of GetPaymentStatusHistory.
+func (mr *MockPaymentDatabaseMockRecorder) GetPaymentStatusHistory(ctx, paymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentStatusHistory", reflect.TypeOf((*MockPaymentDatabase)(nil).GetPaymentStatusHistory), ctx, paymentID)
+}
+
+// TransitionPaymentStatus mocks base method.
+func (m *MockPaymentDatabase) TransitionPaymentStatus(ctx context.Context, param models.PaymentStatusTransition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionPaymentStatus", ctx, param)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransitionPaymentStatus indicates an expected call of TransitionPaymentStatus.
+func (mr *MockPaymentDatabaseMockRecorder) TransitionPaymentStatus(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionPaymentStatus", reflect.TypeOf((*MockPaymentDatabase)(nil).TransitionPaymentStatus), ctx, param)
+}
+
+// ClaimWebhookEvent mocks base method.
+func (m *MockPaymentDatabase) ClaimWebhookEvent(ctx context.Context, id int64) (*models.WebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookEvent", ctx, id)
+	ret0, _ := ret[0].(*models.WebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookEvent indicates an expected call of ClaimWebhookEvent.
+func (mr *MockPaymentDatabaseMockRecorder) ClaimWebhookEvent(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookEvent", reflect.TypeOf((*MockPaymentDatabase)(nil).ClaimWebhookEvent), ctx, id)
+}
+
+// ClaimWebhookEvents mocks base method.
+func (m *MockPaymentDatabase) ClaimWebhookEvents(ctx context.Context, limit int) ([]models.WebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookEvents", ctx, limit)
+	ret0, _ := ret[0].([]models.WebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookEvents indicates an expected call of ClaimWebhookEvents.
+func (mr *MockPaymentDatabaseMockRecorder) ClaimWebhookEvents(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookEvents", reflect.TypeOf((*MockPaymentDatabase)(nil).ClaimWebhookEvents), ctx, limit)
+}
+
+// GetWebhookEvent mocks base method.
+func (m *MockPaymentDatabase) GetWebhookEvent(ctx context.Context, id int64) (*models.WebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookEvent", ctx, id)
+	ret0, _ := ret[0].(*models.WebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookEvent indicates an expected call of GetWebhookEvent.
+func (mr *MockPaymentDatabaseMockRecorder) GetWebhookEvent(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookEvent", reflect.TypeOf((*MockPaymentDatabase)(nil).GetWebhookEvent), ctx, id)
+}
+
+// SaveWebhookEvent mocks base method.
+func (m *MockPaymentDatabase) SaveWebhookEvent(ctx context.Context, param *models.WebhookEvent) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookEvent", ctx, param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveWebhookEvent indicates an expected call of SaveWebhookEvent.
+func (mr *MockPaymentDatabaseMockRecorder) SaveWebhookEvent(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookEvent", reflect.TypeOf((*MockPaymentDatabase)(nil).SaveWebhookEvent), ctx, param)
+}
+
+// UpdateWebhookEventResult mocks base method.
+func (m *MockPaymentDatabase) UpdateWebhookEventResult(ctx context.Context, id int64, status, lastError string, nextAttempt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookEventResult", ctx, id, status, lastError, nextAttempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookEventResult indicates an expected call of UpdateWebhookEventResult.
+func (mr *MockPaymentDatabaseMockRecorder) UpdateWebhookEventResult(ctx, id, status, lastError, nextAttempt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookEventResult", reflect.TypeOf((*MockPaymentDatabase)(nil).UpdateWebhookEventResult), ctx, id, status, lastError, nextAttempt)
+}
+
+// ClaimOutboxEvents mocks base method.
+func (m *MockPaymentDatabase) ClaimOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxEvents", ctx, limit)
+	ret0, _ := ret[0].([]models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxEvents indicates an expected call of ClaimOutboxEvents.
+func (mr *MockPaymentDatabaseMockRecorder) ClaimOutboxEvents(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxEvents", reflect.TypeOf((*MockPaymentDatabase)(nil).ClaimOutboxEvents), ctx, limit)
+}
+
+// GetOutboxStats mocks base method.
+func (m *MockPaymentDatabase) GetOutboxStats(ctx context.Context) (models.OutboxStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxStats", ctx)
+	ret0, _ := ret[0].(models.OutboxStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxStats indicates an expected call of GetOutboxStats.
+func (mr *MockPaymentDatabaseMockRecorder) GetOutboxStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxStats", reflect.TypeOf((*MockPaymentDatabase)(nil).GetOutboxStats), ctx)
+}
+
+// MarkOutboxEventFailed mocks base method.
+func (m *MockPaymentDatabase) MarkOutboxEventFailed(ctx context.Context, id int64, status, lastError string, nextAttempt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventFailed", ctx, id, status, lastError, nextAttempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventFailed indicates an expected call of MarkOutboxEventFailed.
+func (mr *MockPaymentDatabaseMockRecorder) MarkOutboxEventFailed(ctx, id, status, lastError, nextAttempt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventFailed", reflect.TypeOf((*MockPaymentDatabase)(nil).MarkOutboxEventFailed), ctx, id, status, lastError, nextAttempt)
+}
+
+// MarkOutboxEventSent mocks base method.
+func (m *MockPaymentDatabase) MarkOutboxEventSent(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventSent", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventSent indicates an expected call of MarkOutboxEventSent.
+func (mr *MockPaymentDatabaseMockRecorder) MarkOutboxEventSent(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventSent", reflect.TypeOf((*MockPaymentDatabase)(nil).MarkOutboxEventSent), ctx, id)
+}
+
+// GetFailedEvent mocks base method.
+func (m *MockPaymentDatabase) GetFailedEvent(ctx context.Context, id int64) (*models.FailedEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFailedEvent", ctx, id)
+	ret0, _ := ret[0].(*models.FailedEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFailedEvent indicates an expected call of GetFailedEvent.
+func (mr *MockPaymentDatabaseMockRecorder) GetFailedEvent(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailedEvent", reflect.TypeOf((*MockPaymentDatabase)(nil).GetFailedEvent), ctx, id)
+}
+
+// GetOutboxEvent mocks base method.
+func (m *MockPaymentDatabase) GetOutboxEvent(ctx context.Context, id int64) (*models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxEvent", ctx, id)
+	ret0, _ := ret[0].(*models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxEvent indicates an expected call of GetOutboxEvent.
+func (mr *MockPaymentDatabaseMockRecorder) GetOutboxEvent(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxEvent", reflect.TypeOf((*MockPaymentDatabase)(nil).GetOutboxEvent), ctx, id)
+}
+
+// GetRetryableFailedEvents mocks base method.
+func (m *MockPaymentDatabase) GetRetryableFailedEvents(ctx context.Context, limit int) ([]models.FailedEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRetryableFailedEvents", ctx, limit)
+	ret0, _ := ret[0].([]models.FailedEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRetryableFailedEvents indicates an expected call of GetRetryableFailedEvents.
+func (mr *MockPaymentDatabaseMockRecorder) GetRetryableFailedEvents(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRetryableFailedEvents", reflect.TypeOf((*MockPaymentDatabase)(nil).GetRetryableFailedEvents), ctx, limit)
+}
+
+// ListFailedEvents mocks base method.
+func (m *MockPaymentDatabase) ListFailedEvents(ctx context.Context, filter models.FailedEventFilter) ([]models.FailedEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFailedEvents", ctx, filter)
+	ret0, _ := ret[0].([]models.FailedEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFailedEvents indicates an expected call of ListFailedEvents.
+func (mr *MockPaymentDatabaseMockRecorder) ListFailedEvents(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFailedEvents", reflect.TypeOf((*MockPaymentDatabase)(nil).ListFailedEvents), ctx, filter)
+}
+
+// UpdateFailedEventResult mocks base method.
+func (m *MockPaymentDatabase) UpdateFailedEventResult(ctx context.Context, id int64, status, attempts int, notes string, nextAttempt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFailedEventResult", ctx, id, status, attempts, notes, nextAttempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFailedEventResult indicates an expected call of UpdateFailedEventResult.
+func (mr *MockPaymentDatabaseMockRecorder) UpdateFailedEventResult(ctx, id, status, attempts, notes, nextAttempt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFailedEventResult", reflect.TypeOf((*MockPaymentDatabase)(nil).UpdateFailedEventResult), ctx, id, status, attempts, notes, nextAttempt)
+}
+
+// GetPaymentAnomaly mocks base method.
+func (m *MockPaymentDatabase) GetPaymentAnomaly(ctx context.Context, id int64) (*models.PaymentAnomaly, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentAnomaly", ctx, id)
+	ret0, _ := ret[0].(*models.PaymentAnomaly)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentAnomaly indicates an expected call of GetPaymentAnomaly.
+func (mr *MockPaymentDatabaseMockRecorder) GetPaymentAnomaly(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentAnomaly", reflect.TypeOf((*MockPaymentDatabase)(nil).GetPaymentAnomaly), ctx, id)
+}
+
+// ListPaymentAnomalies mocks base method.
+func (m *MockPaymentDatabase) ListPaymentAnomalies(ctx context.Context, filter models.PaymentAnomalyFilter) ([]models.PaymentAnomaly, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaymentAnomalies", ctx, filter)
+	ret0, _ := ret[0].([]models.PaymentAnomaly)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaymentAnomalies indicates an expected call of ListPaymentAnomalies.
+func (mr *MockPaymentDatabaseMockRecorder) ListPaymentAnomalies(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaymentAnomalies", reflect.TypeOf((*MockPaymentDatabase)(nil).ListPaymentAnomalies), ctx, filter)
+}
+
+// UpdatePaymentAnomalyStatus mocks base method.
+func (m *MockPaymentDatabase) UpdatePaymentAnomalyStatus(ctx context.Context, id int64, fromStatuses []int, update models.PaymentAnomalyUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentAnomalyStatus", ctx, id, fromStatuses, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePaymentAnomalyStatus indicates an expected call of UpdatePaymentAnomalyStatus.
+func (mr *MockPaymentDatabaseMockRecorder) UpdatePaymentAnomalyStatus(ctx, id, fromStatuses, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentAnomalyStatus", reflect.TypeOf((*MockPaymentDatabase)(nil).UpdatePaymentAnomalyStatus), ctx, id, fromStatuses, update)
+}
+
+// CreateReconciliationRun mocks base method.
+func (m *MockPaymentDatabase) CreateReconciliationRun(ctx context.Context, param *models.ReconciliationRun) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReconciliationRun", ctx, param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReconciliationRun indicates an expected call of CreateReconciliationRun.
+func (mr *MockPaymentDatabaseMockRecorder) CreateReconciliationRun(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReconciliationRun", reflect.TypeOf((*MockPaymentDatabase)(nil).CreateReconciliationRun), ctx, param)
+}
+
+// FinishReconciliationRun mocks base method.
+func (m *MockPaymentDatabase) FinishReconciliationRun(ctx context.Context, param *models.ReconciliationRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishReconciliationRun", ctx, param)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishReconciliationRun indicates an expected call of FinishReconciliationRun.
+func (mr *MockPaymentDatabaseMockRecorder) FinishReconciliationRun(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishReconciliationRun", reflect.TypeOf((*MockPaymentDatabase)(nil).FinishReconciliationRun), ctx, param)
+}
+
+// GetPaymentsByExternalIDs mocks base method.
+func (m *MockPaymentDatabase) GetPaymentsByExternalIDs(ctx context.Context, externalIDs []string) ([]models.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentsByExternalIDs", ctx, externalIDs)
+	ret0, _ := ret[0].([]models.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentsByExternalIDs indicates an expected call of GetPaymentsByExternalIDs.
+func (mr *MockPaymentDatabaseMockRecorder) GetPaymentsByExternalIDs(ctx, externalIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentsByExternalIDs", reflect.TypeOf((*MockPaymentDatabase)(nil).GetPaymentsByExternalIDs), ctx, externalIDs)
+}
+
+// GetReconciliationRun mocks base method.
+func (m *MockPaymentDatabase) GetReconciliationRun(ctx context.Context, id int64) (*models.ReconciliationRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReconciliationRun", ctx, id)
+	ret0, _ := ret[0].(*models.ReconciliationRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReconciliationRun indicates an expected call of GetReconciliationRun.
+func (mr *MockPaymentDatabaseMockRecorder) GetReconciliationRun(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReconciliationRun", reflect.TypeOf((*MockPaymentDatabase)(nil).GetReconciliationRun), ctx, id)
+}
+
+// ListReconciliationItems mocks base method.
+func (m *MockPaymentDatabase) ListReconciliationItems(ctx context.Context, filter models.ReconciliationItemFilter) ([]models.ReconciliationItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReconciliationItems", ctx, filter)
+	ret0, _ := ret[0].([]models.ReconciliationItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReconciliationItems indicates an expected call of ListReconciliationItems.
+func (mr *MockPaymentDatabaseMockRecorder) ListReconciliationItems(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReconciliationItems", reflect.TypeOf((*MockPaymentDatabase)(nil).ListReconciliationItems), ctx, filter)
+}
+
+// ListReconciliationRuns mocks base method.
+func (m *MockPaymentDatabase) ListReconciliationRuns(ctx context.Context, filter models.ReconciliationRunFilter) ([]models.ReconciliationRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReconciliationRuns", ctx, filter)
+	ret0, _ := ret[0].([]models.ReconciliationRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReconciliationRuns indicates an expected call of ListReconciliationRuns.
+func (mr *MockPaymentDatabaseMockRecorder) ListReconciliationRuns(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReconciliationRuns", reflect.TypeOf((*MockPaymentDatabase)(nil).ListReconciliationRuns), ctx, filter)
+}
+
+// SaveReconciliationItems mocks base method.
+func (m *MockPaymentDatabase) SaveReconciliationItems(ctx context.Context, items []models.ReconciliationItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReconciliationItems", ctx, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReconciliationItems indicates an expected call of SaveReconciliationItems.
+func (mr *MockPaymentDatabaseMockRecorder) SaveReconciliationItems(ctx, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReconciliationItems", reflect.TypeOf((*MockPaymentDatabase)(nil).SaveReconciliationItems), ctx, items)
+}
+
+// StreamSettlementReport mocks base method.
+func (m *MockPaymentDatabase) StreamSettlementReport(ctx context.Context, query models.SettlementReportQuery, fn func(models.SettlementReportRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamSettlementReport", ctx, query, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamSettlementReport indicates an expected call of StreamSettlementReport.
+func (mr *MockPaymentDatabaseMockRecorder) StreamSettlementReport(ctx, query, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamSettlementReport", reflect.TypeOf((*MockPaymentDatabase)(nil).StreamSettlementReport), ctx, query, fn)
+}
+
+// GetUserPaymentByOrderID mocks base method.
+func (m *MockPaymentDatabase) GetUserPaymentByOrderID(ctx context.Context, userID, orderID int64) (*models.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPaymentByOrderID", ctx, userID, orderID)
+	ret0, _ := ret[0].(*models.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserPaymentByOrderID indicates an expected call of GetUserPaymentByOrderID.
+func (mr *MockPaymentDatabaseMockRecorder) GetUserPaymentByOrderID(ctx, userID, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPaymentByOrderID", reflect.TypeOf((*MockPaymentDatabase)(nil).GetUserPaymentByOrderID), ctx, userID, orderID)
+}
+
+// ListUserPayments mocks base method.
+func (m *MockPaymentDatabase) ListUserPayments(ctx context.Context, filter models.PaymentFilter) ([]models.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserPayments", ctx, filter)
+	ret0, _ := ret[0].([]models.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserPayments indicates an expected call of ListUserPayments.
+func (mr *MockPaymentDatabaseMockRecorder) ListUserPayments(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserPayments", reflect.TypeOf((*MockPaymentDatabase)(nil).ListUserPayments), ctx, filter)
+}
+
+// GetPaymentRequestByOrderID mocks base method.
+func (m *MockPaymentDatabase) GetPaymentRequestByOrderID(ctx context.Context, orderID int64) (*models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequestByOrderID", ctx, orderID)
+	ret0, _ := ret[0].(*models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequestByOrderID indicates an expected call of GetPaymentRequestByOrderID.
+func (mr *MockPaymentDatabaseMockRecorder) GetPaymentRequestByOrderID(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequestByOrderID", reflect.TypeOf((*MockPaymentDatabase)(nil).GetPaymentRequestByOrderID), ctx, orderID)
+}
+
+// DeleteJobRunsBefore mocks base method.
+func (m *MockPaymentDatabase) DeleteJobRunsBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteJobRunsBefore", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteJobRunsBefore indicates an expected call of DeleteJobRunsBefore.
+func (mr *MockPaymentDatabaseMockRecorder) DeleteJobRunsBefore(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteJobRunsBefore", reflect.TypeOf((*MockPaymentDatabase)(nil).DeleteJobRunsBefore), ctx, before, limit)
+}
+
+// GetJobStates mocks base method.
+func (m *MockPaymentDatabase) GetJobStates(ctx context.Context) ([]models.JobState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobStates", ctx)
+	ret0, _ := ret[0].([]models.JobState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobStates indicates an expected call of GetJobStates.
+func (mr *MockPaymentDatabaseMockRecorder) GetJobStates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobStates", reflect.TypeOf((*MockPaymentDatabase)(nil).GetJobStates), ctx)
+}
+
+// ListJobRuns mocks base method.
+func (m *MockPaymentDatabase) ListJobRuns(ctx context.Context, filter models.JobRunFilter) ([]models.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRuns", ctx, filter)
+	ret0, _ := ret[0].([]models.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRuns indicates an expected call of ListJobRuns.
+func (mr *MockPaymentDatabaseMockRecorder) ListJobRuns(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRuns", reflect.TypeOf((*MockPaymentDatabase)(nil).ListJobRuns), ctx, filter)
+}
+
+// SaveJobRun mocks base method.
+func (m *MockPaymentDatabase) SaveJobRun(ctx context.Context, param *models.JobRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveJobRun", ctx, param)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveJobRun indicates an expected call of SaveJobRun.
+func (mr *MockPaymentDatabaseMockRecorder) SaveJobRun(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveJobRun", reflect.TypeOf((*MockPaymentDatabase)(nil).SaveJobRun), ctx, param)
+}
+
+// SetJobPaused mocks base method.
+func (m *MockPaymentDatabase) SetJobPaused(ctx context.Context, jobName string, paused bool, updatedBy int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetJobPaused", ctx, jobName, paused, updatedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetJobPaused indicates an expected call of SetJobPaused.
+func (mr *MockPaymentDatabaseMockRecorder) SetJobPaused(ctx, jobName, paused, updatedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetJobPaused", reflect.TypeOf((*MockPaymentDatabase)(nil).SetJobPaused), ctx, jobName, paused, updatedBy)
+}
+
 // MockXenditClient is a mock of XenditClient interface.
 type MockXenditClient struct {
 	ctrl     *gomock.Controller
@@ -333,6 +877,50 @@ func (mr *MockXenditClientMockRecorder) CreateInvoice(ctx, request interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvoice", reflect.TypeOf((*MockXenditClient)(nil).CreateInvoice), ctx, request)
 }
 
+// CreateRefund mocks base method.
+func (m *MockXenditClient) CreateRefund(ctx context.Context, request models.XenditRefundRequest) (*models.XenditRefundResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefund", ctx, request)
+	ret0, _ := ret[0].(*models.XenditRefundResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefund indicates an expected call of CreateRefund.
+func (mr *MockXenditClientMockRecorder) CreateRefund(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefund", reflect.TypeOf((*MockXenditClient)(nil).CreateRefund), ctx, request)
+}
+
+// ExpireInvoice mocks base method.
+func (m *MockXenditClient) ExpireInvoice(ctx context.Context, invoiceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireInvoice", ctx, invoiceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpireInvoice indicates an expected call of ExpireInvoice.
+func (mr *MockXenditClientMockRecorder) ExpireInvoice(ctx, invoiceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireInvoice", reflect.TypeOf((*MockXenditClient)(nil).ExpireInvoice), ctx, invoiceID)
+}
+
+// ListInvoices mocks base method.
+func (m *MockXenditClient) ListInvoices(ctx context.Context, request models.XenditInvoiceListRequest) ([]models.XenditInvoiceResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvoices", ctx, request)
+	ret0, _ := ret[0].([]models.XenditInvoiceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvoices indicates an expected call of ListInvoices.
+func (mr *MockXenditClientMockRecorder) ListInvoices(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvoices", reflect.TypeOf((*MockXenditClient)(nil).ListInvoices), ctx, request)
+}
+
 // MockPaymentEventPublisher is a mock of PaymentEventPublisher interface.
 type MockPaymentEventPublisher struct {
 	ctrl     *gomock.Controller
@@ -370,6 +958,34 @@ func (mr *MockPaymentEventPublisherMockRecorder) PublishPaymentStatus(ctx, order
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishPaymentStatus", reflect.TypeOf((*MockPaymentEventPublisher)(nil).PublishPaymentStatus), ctx, orderID, status, topic)
 }
 
+// PublishPaymentRefunded mocks base method.
+func (m *MockPaymentEventPublisher) PublishPaymentRefunded(ctx context.Context, event models.PaymentRefundedEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishPaymentRefunded", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishPaymentRefunded indicates an expected call of PublishPaymentRefunded.
+func (mr *MockPaymentEventPublisherMockRecorder) PublishPaymentRefunded(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishPaymentRefunded", reflect.TypeOf((*MockPaymentEventPublisher)(nil).PublishPaymentRefunded), ctx, event)
+}
+
+// PublishOutboxEvent mocks base method.
+func (m *MockPaymentEventPublisher) PublishOutboxEvent(ctx context.Context, event models.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishOutboxEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishOutboxEvent indicates an expected call of PublishOutboxEvent.
+func (mr *MockPaymentEventPublisherMockRecorder) PublishOutboxEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishOutboxEvent", reflect.TypeOf((*MockPaymentEventPublisher)(nil).PublishOutboxEvent), ctx, event)
+}
+
 // MockAuditLogRepository is a mock of AuditLogRepository interface.
 type MockAuditLogRepository struct {
 	ctrl     *gomock.Controller
@@ -450,3 +1066,204 @@ func (mr *MockAuditLogRepositoryMockRecorder) WatchInsertStream(ctx, out interfa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchInsertStream", reflect.TypeOf((*MockAuditLogRepository)(nil).WatchInsertStream), ctx, out)
 }
+
+// MockInvoiceCache is a mock of InvoiceCache interface.
+type MockInvoiceCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvoiceCacheMockRecorder
+}
+
+// MockInvoiceCacheMockRecorder is the mock recorder for MockInvoiceCache.
+type MockInvoiceCacheMockRecorder struct {
+	mock *MockInvoiceCache
+}
+
+// NewMockInvoiceCache creates a new mock instance.
+func NewMockInvoiceCache(ctrl *gomock.Controller) *MockInvoiceCache {
+	mock := &MockInvoiceCache{ctrl: ctrl}
+	mock.recorder = &MockInvoiceCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvoiceCache) EXPECT() *MockInvoiceCacheMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockInvoiceCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockInvoiceCacheMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInvoiceCache)(nil).Get), ctx, key)
+}
+
+// Set mocks base method.
+func (m *MockInvoiceCache) Set(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, key, data, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockInvoiceCacheMockRecorder) Set(ctx, key, data, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockInvoiceCache)(nil).Set), ctx, key, data, ttl)
+}
+
+// MockIdempotencyStore is a mock of IdempotencyStore interface.
+type MockIdempotencyStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyStoreMockRecorder
+}
+
+// MockIdempotencyStoreMockRecorder is the mock recorder for MockIdempotencyStore.
+type MockIdempotencyStoreMockRecorder struct {
+	mock *MockIdempotencyStore
+}
+
+// NewMockIdempotencyStore creates a new mock instance.
+func NewMockIdempotencyStore(ctrl *gomock.Controller) *MockIdempotencyStore {
+	mock := &MockIdempotencyStore{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyStore) EXPECT() *MockIdempotencyStoreMockRecorder {
+	return m.recorder
+}
+
+// Release mocks base method.
+func (m *MockIdempotencyStore) Release(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIdempotencyStoreMockRecorder) Release(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIdempotencyStore)(nil).Release), ctx, key)
+}
+
+// Reserve mocks base method.
+func (m *MockIdempotencyStore) Reserve(ctx context.Context, key string, record models.IdempotencyRecord, ttl time.Duration) (*models.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, key, record, ttl)
+	ret0, _ := ret[0].(*models.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockIdempotencyStoreMockRecorder) Reserve(ctx, key, record, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockIdempotencyStore)(nil).Reserve), ctx, key, record, ttl)
+}
+
+// Save mocks base method.
+func (m *MockIdempotencyStore) Save(ctx context.Context, key string, record models.IdempotencyRecord, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, key, record, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockIdempotencyStoreMockRecorder) Save(ctx, key, record, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockIdempotencyStore)(nil).Save), ctx, key, record, ttl)
+}
+
+// MockLeaderLock is a mock of LeaderLock interface.
+type MockLeaderLock struct {
+	ctrl     *gomock.Controller
+	recorder *MockLeaderLockMockRecorder
+}
+
+// MockLeaderLockMockRecorder is the mock recorder for MockLeaderLock.
+type MockLeaderLockMockRecorder struct {
+	mock *MockLeaderLock
+}
+
+// NewMockLeaderLock creates a new mock instance.
+func NewMockLeaderLock(ctrl *gomock.Controller) *MockLeaderLock {
+	mock := &MockLeaderLock{ctrl: ctrl}
+	mock.recorder = &MockLeaderLockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLeaderLock) EXPECT() *MockLeaderLockMockRecorder {
+	return m.recorder
+}
+
+// Acquire mocks base method.
+func (m *MockLeaderLock) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Acquire", ctx, key, owner, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Acquire indicates an expected call of Acquire.
+func (mr *MockLeaderLockMockRecorder) Acquire(ctx, key, owner, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Acquire", reflect.TypeOf((*MockLeaderLock)(nil).Acquire), ctx, key, owner, ttl)
+}
+
+// Holder mocks base method.
+func (m *MockLeaderLock) Holder(ctx context.Context, key string) (string, time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Holder", ctx, key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Holder indicates an expected call of Holder.
+func (mr *MockLeaderLockMockRecorder) Holder(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Holder", reflect.TypeOf((*MockLeaderLock)(nil).Holder), ctx, key)
+}
+
+// Release mocks base method.
+func (m *MockLeaderLock) Release(ctx context.Context, key, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, key, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockLeaderLockMockRecorder) Release(ctx, key, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockLeaderLock)(nil).Release), ctx, key, owner)
+}
+
+// Renew mocks base method.
+func (m *MockLeaderLock) Renew(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Renew", ctx, key, owner, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Renew indicates an expected call of Renew.
+func (mr *MockLeaderLockMockRecorder) Renew(ctx, key, owner, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Renew", reflect.TypeOf((*MockLeaderLock)(nil).Renew), ctx, key, owner, ttl)
+}
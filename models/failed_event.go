@@ -4,12 +4,37 @@ import "time"
 
 // FailedEvent 이벤트 처리 실패 건 (Kafka 발행 실패 등) 재처리/수동 확인용
 type FailedEvent struct {
-	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
-	OrderID    int64     `json:"order_id" gorm:"type:bigint"`
-	ExternalID string    `json:"external_id" gorm:"type:text"`
-	FailedType int       `json:"failed_type" gorm:"type:integer"`
-	Notes      string    `json:"notes" gorm:"type:text"`
-	Status     int       `json:"status" gorm:"type:integer"`
-	CreateTime time.Time `json:"create_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
-	UpdateTime time.Time `json:"update_time" gorm:"type:timestamp"`
+	ID         int64  `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
+	OrderID    int64  `json:"order_id" gorm:"type:bigint"`
+	ExternalID string `json:"external_id" gorm:"type:text"`
+	FailedType int    `json:"failed_type" gorm:"type:integer"`
+	// OutboxID relay가 넘긴 건이면 원본 outbox 메시지를 그대로 재발행한다 (0이면 failed_type으로 다시 만든다)
+	OutboxID        int64     `json:"outbox_id,omitempty" gorm:"type:bigint"`
+	Notes           string    `json:"notes" gorm:"type:text"`
+	Status          int       `json:"status" gorm:"type:integer;index:idx_failed_events_status"`
+	Attempts        int       `json:"attempts" gorm:"type:int;not null;default:0"`
+	NextAttemptTime time.Time `json:"next_attempt_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	CreateTime      time.Time `json:"create_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdateTime      time.Time `json:"update_time" gorm:"type:timestamp"`
+}
+
+// FailedEventFilter admin 조회 조건. 0/빈 값은 조건에서 빠진다. Cursor는 이전 페이지 마지막 id.
+type FailedEventFilter struct {
+	Status     int
+	FailedType int
+	OrderID    int64
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Cursor     int64
+}
+
+type FailedEventPage struct {
+	Events     []FailedEvent `json:"events"`
+	NextCursor int64         `json:"next_cursor,omitempty"`
+}
+
+// CloseFailedEventRequest 수동 종료 요청
+type CloseFailedEventRequest struct {
+	Reason string `json:"reason" binding:"required"`
 }
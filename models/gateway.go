@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CheckoutRequest provider 중립 체크아웃(인보이스) 생성 요청
+type CheckoutRequest struct {
+	ExternalID  string
+	Amount      Money
+	Description string
+	PayerEmail  string
+}
+
+type CheckoutResponse struct {
+	Provider   string    `json:"provider"`
+	ID         string    `json:"id"`
+	InvoiceURL string    `json:"invoice_url"`
+	Status     string    `json:"status"`
+	ExpireDate time.Time `json:"expire_date"`
+}
+
+type GatewayRefundRequest struct {
+	CheckoutID  string // provider 체크아웃 ID (payments.invoice_id)
+	ExternalID  string // payments.external_id (order-123)
+	ReferenceID string // 환불 멱등 키
+	Amount      Money
+	Reason      string
+}
+
+type GatewayRefundResponse struct {
+	ID          string
+	Status      string // constant.RefundStatus*
+	FailureCode string
+}
+
+// PaymentWebhookEvent provider별 웹훅을 정규화한 결과. Status는 constant.PaymentStatus* 값이다.
+// EventID는 provider가 주는 이벤트 식별자(중복 수신 판단용)이며, 없으면 비어 있다.
+type PaymentWebhookEvent struct {
+	Provider   string      `json:"provider"`
+	EventID    string      `json:"event_id"`
+	ExternalID string      `json:"external_id"`
+	Status     string      `json:"status"`
+	Amount     json.Number `json:"amount" swaggertype:"number"`
+	Currency   string      `json:"currency"`
+}
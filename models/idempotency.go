@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// IdempotencyRecord Idempotency-Key로 저장하는 요청 지문과 최종 응답. 처리 중이면 StatusCode가 0이다.
+type IdempotencyRecord struct {
+	Fingerprint string    `json:"fingerprint"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        []byte    `json:"body,omitempty"`
+	CreateTime  time.Time `json:"create_time"`
+}
+
+// Completed 응답까지 저장됐는지 여부
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// InvoicePdf 렌더링된(또는 캐시된) 인보이스 PDF. NotModified면 Content는 비어 있다.
+type InvoicePdf struct {
+	ETag         string
+	LastModified time.Time
+	Content      []byte
+	NotModified  bool
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// InvoiceSequence 월별 인보이스 번호 카운터. PAID 전이 트랜잭션 안에서 행 잠금으로 증가시키므로 롤백되면 번호도 되돌아가 빈 번호가 생기지 않는다.
+type InvoiceSequence struct {
+	Period     string    `json:"period" gorm:"primaryKey;type:varchar(7)"` // YYYY/MM
+	LastNumber int64     `json:"last_number" gorm:"type:bigint;not null"`
+	UpdateTime time.Time `json:"update_time" gorm:"type:timestamp"`
+}
+
+func (InvoiceSequence) TableName() string {
+	return "invoice_sequences"
+}
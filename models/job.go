@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// JobRun 스케줄러 잡 한 번의 실행 기록. 보관 기간이 지난 기록은 prune_job_runs 잡이 지운다.
+type JobRun struct {
+	ID             int64     `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
+	JobName        string    `json:"job_name" gorm:"type:varchar(64);not null;index:idx_job_runs_name"`
+	Trigger        string    `json:"trigger" gorm:"type:varchar(16)"`
+	InstanceID     string    `json:"instance_id" gorm:"type:varchar(128)"`
+	Status         string    `json:"status" gorm:"type:varchar(16)"`
+	ItemsProcessed int       `json:"items_processed" gorm:"type:int;not null;default:0"`
+	ItemsFailed    int       `json:"items_failed" gorm:"type:int;not null;default:0"`
+	Error          string    `json:"error,omitempty" gorm:"type:text"`
+	StartTime      time.Time `json:"start_time" gorm:"type:timestamp;index:idx_job_runs_start_time"`
+	EndTime        time.Time `json:"end_time" gorm:"type:timestamp"`
+}
+
+// JobState 인스턴스 간에 공유하는 잡 일시정지 상태
+type JobState struct {
+	JobName    string    `json:"job_name" gorm:"primaryKey;type:varchar(64)"`
+	Paused     bool      `json:"paused" gorm:"not null;default:false"`
+	UpdatedBy  int64     `json:"updated_by" gorm:"type:bigint"`
+	UpdateTime time.Time `json:"update_time" gorm:"type:timestamp"`
+}
+
+// JobResult 잡 배치 함수가 돌려주는 처리 결과
+type JobResult struct {
+	Processed int
+	Failed    int
+}
+
+// JobStatus admin 조회용 잡 설정과 현재 상태. Running/NextRunTime/LastRun은 응답한 인스턴스 기준이다.
+type JobStatus struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	BatchSize      int        `json:"batch_size"`
+	TimeoutSeconds int        `json:"timeout_seconds"`
+	LeaderOnly     bool       `json:"leader_only"`
+	Paused         bool       `json:"paused"`
+	Running        bool       `json:"running"`
+	NextRunTime    *time.Time `json:"next_run_time,omitempty"`
+	LastRun        *JobRun    `json:"last_run,omitempty"`
+}
+
+type JobRunFilter struct {
+	JobName string
+	Status  string
+	Limit   int
+	Cursor  int64
+}
+
+type JobRunPage struct {
+	Runs       []JobRun `json:"runs"`
+	NextCursor int64    `json:"next_cursor,omitempty"`
+}
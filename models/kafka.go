@@ -0,0 +1,9 @@
+package models
+
+// DLQReplayResult DLQ 재처리 결과. Replayed는 원래 토픽으로 다시 발행하고 커밋한 메시지 수다.
+type DLQReplayResult struct {
+	DLQTopic string `json:"dlq_topic"`
+	Limit    int    `json:"limit"`
+	Replayed int    `json:"replayed"`
+	Error    string `json:"error,omitempty"`
+}
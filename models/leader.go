@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// LeaderStatus 싱글톤 잡 리더 선출 상태. LeaderSince는 이 인스턴스가 리더일 때만 채워진다.
+// Leader/LeaseTTLMs는 조회 시점에 Redis lease 키에서 읽은 값이라 이 인스턴스가 마지막으로 확인한 상태와 다를 수 있다.
+type LeaderStatus struct {
+	Enabled     bool       `json:"enabled"`
+	InstanceID  string     `json:"instance_id"`
+	IsLeader    bool       `json:"is_leader"`
+	LeaderSince *time.Time `json:"leader_since,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	Leader      string     `json:"leader"`       // lease를 잡고 있는 인스턴스. 비어 있으면 리더 없음
+	LeaseTTLMs  int64      `json:"lease_ttl_ms"` // lease 남은 시간
+}
@@ -0,0 +1,56 @@
+package models
+
+import "encoding/json"
+
+// Midtrans Snap/Core API 요청·응답. 금액은 major unit이며 IDR만 지원한다.
+type MidtransTransactionDetails struct {
+	OrderID     string      `json:"order_id"`
+	GrossAmount json.Number `json:"gross_amount"`
+}
+
+type MidtransCustomerDetails struct {
+	Email string `json:"email,omitempty"`
+}
+
+type MidtransExpiry struct {
+	Unit     string `json:"unit"`
+	Duration int    `json:"duration"`
+}
+
+type MidtransSnapRequest struct {
+	TransactionDetails MidtransTransactionDetails `json:"transaction_details"`
+	CustomerDetails    MidtransCustomerDetails    `json:"customer_details"`
+	Expiry             *MidtransExpiry            `json:"expiry,omitempty"`
+}
+
+type MidtransSnapResponse struct {
+	Token         string   `json:"token"`
+	RedirectURL   string   `json:"redirect_url"`
+	ErrorMessages []string `json:"error_messages"`
+}
+
+// MidtransNotification 상태 조회 응답과 HTTP notification(웹훅) 공통 형식
+type MidtransNotification struct {
+	OrderID           string `json:"order_id"`
+	TransactionID     string `json:"transaction_id"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+	StatusCode        string `json:"status_code"`
+	StatusMessage     string `json:"status_message"`
+	GrossAmount       string `json:"gross_amount"`
+	Currency          string `json:"currency"`
+	SignatureKey      string `json:"signature_key"`
+}
+
+type MidtransRefundRequest struct {
+	RefundKey string      `json:"refund_key"`
+	Amount    json.Number `json:"amount"`
+	Reason    string      `json:"reason"`
+}
+
+type MidtransRefundResponse struct {
+	StatusCode         string `json:"status_code"`
+	StatusMessage      string `json:"status_message"`
+	RefundKey          string `json:"refund_key"`
+	RefundChargebackID int64  `json:"refund_chargeback_id"`
+}
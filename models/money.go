@@ -0,0 +1,141 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency 통화가 지정되지 않은 금액(기존 데이터, 외부 이벤트)에 적용되는 통화
+const DefaultCurrency = "IDR"
+
+// currencyExponents 통화별 소수 자릿수. IDR은 ISO상 2자리지만 Xendit은 정수 금액만 받으므로 0으로 둔다.
+var currencyExponents = map[string]int{
+	"IDR": 0,
+	"VND": 0,
+	"PHP": 2,
+	"USD": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"THB": 2,
+}
+
+// Money 금액을 통화의 최소 단위(minor unit) 정수와 ISO 4217 통화 코드로 표현한다.
+// 예: IDR 150000 -> {150000, "IDR"}, USD 12.34 -> {1234, "USD"}
+type Money struct {
+	Minor    int64  `json:"minor" gorm:"column:amount;type:bigint"`
+	Currency string `json:"currency" gorm:"column:currency;type:varchar(3);default:'IDR'"`
+}
+
+func NewMoney(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: strings.ToUpper(currency)}
+}
+
+// CurrencyExponent returns the number of minor-unit digits for currency.
+func CurrencyExponent(currency string) (int, error) {
+	exp, ok := currencyExponents[strings.ToUpper(currency)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency: %s", currency)
+	}
+	return exp, nil
+}
+
+// ParseMoney parses a major-unit decimal string (e.g. "150000", "12.34") into exact minor units.
+// 통화 자릿수보다 소수점이 길면 반올림하지 않고 에러를 돌려준다.
+func ParseMoney(decimal string, currency string) (Money, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	currency = strings.ToUpper(currency)
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	raw := strings.TrimSpace(decimal)
+	neg := strings.HasPrefix(raw, "-")
+	raw = strings.TrimPrefix(raw, "-")
+	if !neg {
+		raw = strings.TrimPrefix(raw, "+")
+	}
+	intPart, fracPart, _ := strings.Cut(raw, ".")
+	// 부호는 맨 앞에 하나만 허용한다. "--5"를 ParseInt에 넘기면 -5가 되어 부호가 뒤집힌다.
+	if strings.Trim(intPart+fracPart, "0123456789") != "" {
+		return Money{}, fmt.Errorf("invalid amount %q", decimal)
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > exp {
+		return Money{}, fmt.Errorf("amount %s has more than %d decimal places for %s", decimal, exp, currency)
+	}
+	fracPart += strings.Repeat("0", exp-len(fracPart))
+
+	minor, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q: %w", decimal, err)
+	}
+	if neg {
+		minor = -minor
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// Decimal returns the amount in major units without losing precision (e.g. "12.34").
+func (m Money) Decimal() string {
+	exp, err := CurrencyExponent(m.Currency)
+	if err != nil || exp == 0 {
+		return strconv.FormatInt(m.Minor, 10)
+	}
+	sign := ""
+	minor := m.Minor
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	digits := fmt.Sprintf("%0*d", exp+1, minor)
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// Number returns the major-unit amount as a JSON number literal for external APIs.
+func (m Money) Number() json.Number {
+	return json.Number(m.Decimal())
+}
+
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Minor > 0
+}
+
+func (m Money) SameCurrency(o Money) bool {
+	return strings.EqualFold(m.Currency, o.Currency)
+}
+
+func (m Money) Equal(o Money) bool {
+	return m.SameCurrency(o) && m.Minor == o.Minor
+}
+
+// Add returns m + o. 통화가 다르면 더하지 않고 에러를 돌려준다.
+func (m Money) Add(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, fmt.Errorf("currency mismatch: %s vs %s", m.Currency, o.Currency)
+	}
+	return Money{Minor: m.Minor + o.Minor, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o. 통화가 다르면 빼지 않고 에러를 돌려준다.
+func (m Money) Sub(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, fmt.Errorf("currency mismatch: %s vs %s", m.Currency, o.Currency)
+	}
+	return Money{Minor: m.Minor - o.Minor, Currency: m.Currency}, nil
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	m, err := ParseMoney("150000", "IDR")
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(150000, "IDR"), m)
+
+	m, err = ParseMoney("12.3", "usd")
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(1230, "USD"), m)
+
+	m, err = ParseMoney("150000.00", "")
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(150000, DefaultCurrency), m)
+
+	m, err = ParseMoney("-1.5", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(-150, "USD"), m)
+
+	for _, tc := range []struct {
+		name     string
+		amount   string
+		currency string
+	}{
+		{"too many decimals", "0.001", "PHP"},
+		{"unknown currency", "10", "XXX"},
+		{"double minus", "--5", "IDR"},
+		{"plus then minus", "+-5", "IDR"},
+		{"minus then plus", "-+5", "IDR"},
+		{"sign in fraction", "1.-5", "USD"},
+		{"not a number", "12abc", "IDR"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseMoney(tc.amount, tc.currency)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMoney_Decimal(t *testing.T) {
+	assert.Equal(t, "150000", NewMoney(150000, "IDR").Decimal())
+	assert.Equal(t, "12.05", NewMoney(1205, "USD").Decimal())
+	assert.Equal(t, "0.07", NewMoney(7, "PHP").Decimal())
+	assert.Equal(t, "-1.50", NewMoney(-150, "USD").Decimal())
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	sum, err := NewMoney(1000, "IDR").Add(NewMoney(500, "IDR"))
+	assert.NoError(t, err)
+	assert.True(t, sum.Equal(NewMoney(1500, "IDR")))
+
+	_, err = NewMoney(1000, "IDR").Sub(NewMoney(500, "USD"))
+	assert.Error(t, err)
+}
@@ -1,23 +1,73 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OrderCreatedEvent TotalAmount는 orderfc가 보내는 major unit 숫자를 그대로 보관한다. 금액 계산은 Total()로 변환 후에 한다.
 type OrderCreatedEvent struct {
-	OrderID         int64   `json:"order_id"`
-	UserID          int64   `json:"user_id"`
-	TotalAmount     float64 `json:"total_amount"`
-	PaymentMethod   string  `json:"payment_method"`
-	ShippingAddress string  `json:"shipping_address"`
+	OrderID         int64         `json:"order_id"`
+	UserID          int64         `json:"user_id"`
+	TotalAmount     json.Number   `json:"total_amount" swaggertype:"number"`
+	Currency        string        `json:"currency,omitempty"`
+	PaymentMethod   string        `json:"payment_method"`
+	Provider        string        `json:"provider,omitempty"` // 결제 게이트웨이 (xendit, midtrans). 비어 있으면 기본 게이트웨이
+	ShippingAddress string        `json:"shipping_address"`
+	Products        []ProductItem `json:"products,omitempty"`
+}
+
+// Total returns the exact order total as Money (DefaultCurrency when the event has none).
+func (e OrderCreatedEvent) Total() (Money, error) {
+	return ParseMoney(e.TotalAmount.String(), e.Currency)
+}
+
+// LineItems converts Products into invoice line items priced in the event currency.
+// 단가가 없는 상품(구버전 orderfc)은 0원 품목으로 남긴다.
+func (e OrderCreatedEvent) LineItems() ([]LineItem, error) {
+	if len(e.Products) == 0 {
+		return nil, nil
+	}
+	currency := e.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	items := make([]LineItem, 0, len(e.Products))
+	for _, p := range e.Products {
+		unitPrice := NewMoney(0, currency)
+		if p.Price != "" {
+			var err error
+			if unitPrice, err = ParseMoney(p.Price.String(), currency); err != nil {
+				return nil, fmt.Errorf("invalid price for product %d: %w", p.ProductID, err)
+			}
+		}
+		items = append(items, LineItem{
+			ProductID: p.ProductID,
+			Name:      p.Name,
+			Category:  p.Category,
+			Quantity:  p.Quantity,
+			UnitPrice: unitPrice,
+		})
+	}
+	return items, nil
 }
 
+// ProductItem Name/Category/Price는 orderfc가 채워 보낼 때만 있다. Price는 단가(major unit).
 type ProductItem struct {
-	ProductID int64 `json:"product_id"`
-	Quantity  int   `json:"quantity"`
+	ProductID int64       `json:"product_id"`
+	Name      string      `json:"name,omitempty"`
+	Category  string      `json:"category,omitempty"` // 면세 판정용 상품 카테고리
+	Quantity  int         `json:"quantity"`
+	Price     json.Number `json:"price,omitempty" swaggertype:"number"`
 }
 
 type StockReservationEvent struct {
 	SchemaVersion int           `json:"schema_version"`
 	OrderID       int64         `json:"order_id"`
 	UserID        int64         `json:"user_id"`
-	TotalAmount   float64       `json:"total_amount"`
+	TotalAmount   json.Number   `json:"total_amount"`
+	Currency      string        `json:"currency,omitempty"`
+	Provider      string        `json:"provider,omitempty"`
 	Products      []ProductItem `json:"products"`
 	EventTime     string        `json:"event_time"`
 }
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// OutboxEvent 상태 변경과 같은 트랜잭션에 기록되는 Kafka 발행 대기 메시지. relay 워커가 발행 후 SENT로 바꾼다.
+type OutboxEvent struct {
+	ID              int64      `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
+	OrderID         int64      `json:"order_id" gorm:"type:bigint;index:idx_outbox_order"`
+	EventType       string     `json:"event_type" gorm:"type:varchar"`
+	MessageKey      string     `json:"message_key" gorm:"type:text"`
+	Payload         string     `json:"payload" gorm:"type:text"`
+	Status          string     `json:"status" gorm:"type:varchar;index:idx_outbox_status"`
+	Attempts        int        `json:"attempts" gorm:"type:int;not null;default:0"`
+	LastError       string     `json:"last_error" gorm:"type:text"`
+	NextAttemptTime time.Time  `json:"next_attempt_time" gorm:"type:timestamp;index:idx_outbox_status"`
+	CreateTime      time.Time  `json:"create_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	SentTime        *time.Time `json:"sent_time" gorm:"type:timestamp"`
+	UpdateTime      time.Time  `json:"update_time" gorm:"type:timestamp"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
+
+// OutboxStats relay 지표용 미발행 건수와 가장 오래된 미발행 건의 생성 시각
+type OutboxStats struct {
+	Backlog      int64
+	OldestCreate *time.Time
+}
@@ -3,28 +3,87 @@ package models
 import "time"
 
 type Payment struct {
-	ID          int64     `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
-	OrderID     int64     `json:"order_id" gorm:"type:bigint;index:idx_payments_order"`
-	UserID      int64     `json:"user_id" gorm:"type:bigint;index:idx_payments_user"`
-	ExternalID  string    `json:"external_id" gorm:"type:text;uniqueIndex;not null"`
-	Amount      float64   `json:"amount" gorm:"type:numeric"`
-	Status      string    `json:"status" gorm:"type:varchar;index:idx_payments_status_time"`
-	CreateTime  time.Time `json:"create_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_payments_status_time"`
-	UpdateTime  time.Time `json:"update_time" gorm:"type:timestamp"`
-	ExpiredTime time.Time `json:"expired_time" gorm:"type:timestamp"`
+	ID            int64      `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
+	OrderID       int64      `json:"order_id" gorm:"type:bigint;index:idx_payments_order"`
+	UserID        int64      `json:"user_id" gorm:"type:bigint;index:idx_payments_user"`
+	ExternalID    string     `json:"external_id" gorm:"type:text;uniqueIndex;not null"`
+	InvoiceID     string     `json:"invoice_id" gorm:"type:text"`
+	InvoiceURL    string     `json:"invoice_url" gorm:"type:text"`                                 // 고객이 결제할 게이트웨이 체크아웃 URL
+	InvoiceNumber *string    `json:"invoice_number,omitempty" gorm:"type:varchar(32);uniqueIndex"` // PAID 전이 시 발급 (INV/2026/10/000123). 미결제 건은 NULL
+	Provider      string     `json:"provider" gorm:"type:varchar(32);not null;default:'xendit'"`
+	Amount        Money      `json:"amount" gorm:"embedded"`
+	TaxBase       Money      `json:"tax_base" gorm:"embedded;embeddedPrefix:tax_base_"` // 과세 표준 (DPP)
+	Tax           Money      `json:"tax" gorm:"embedded;embeddedPrefix:tax_"`           // Amount에 포함된 세액
+	TaxRateBps    int64      `json:"tax_rate_bps" gorm:"type:bigint;not null;default:0"`
+	TaxInclusive  bool       `json:"tax_inclusive" gorm:"not null;default:false"`
+	Status        string     `json:"status" gorm:"type:varchar;index:idx_payments_status_time"`
+	CreateTime    time.Time  `json:"create_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_payments_status_time"`
+	UpdateTime    time.Time  `json:"update_time" gorm:"type:timestamp"`
+	ExpiredTime   time.Time  `json:"expired_time" gorm:"type:timestamp"`
+	Version       int64      `json:"version" gorm:"type:bigint;not null;default:0"`
+	Items         []LineItem `json:"items,omitempty" gorm:"type:jsonb;serializer:json"`
+	ClaimedBy     string     `json:"-" gorm:"type:varchar(128)"` // 만료 sweeper가 잡은 인스턴스
+	LeaseUntil    *time.Time `json:"-" gorm:"type:timestamp"`
 }
 
 type PaymentRequest struct {
-	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
-	OrderID    int64     `json:"order_id" gorm:"type:bigint;not null;uniqueIndex:idx_payreq_order"`
-	UserID     int64     `json:"user_id" gorm:"type:bigint"`
-	Amount     float64   `json:"amount" gorm:"type:numeric"`
-	UserEmail  string    `json:"user_email" gorm:"type:varchar"`
-	Status     string    `json:"status" gorm:"type:varchar;index:idx_payreq_status_time"`
-	RetryCount int       `json:"retry_count" gorm:"type:int"`
-	Notes      string    `json:"notes" gorm:"type:text"`
-	CreateTime time.Time `json:"create_time" gorm:"type:timestamp;autoCreateTime;index:idx_payreq_status_time"`
-	UpdateTime time.Time `json:"update_time" gorm:"type:timestamp;autoUpdateTime"`
+	ID         int64      `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
+	OrderID    int64      `json:"order_id" gorm:"type:bigint;not null;uniqueIndex:idx_payreq_order"`
+	UserID     int64      `json:"user_id" gorm:"type:bigint"`
+	Amount     Money      `json:"amount" gorm:"embedded"`
+	UserEmail  string     `json:"user_email" gorm:"type:varchar"`
+	Provider   string     `json:"provider" gorm:"type:varchar(32)"` // 비어 있으면 설정의 기본 게이트웨이
+	Status     string     `json:"status" gorm:"type:varchar;index:idx_payreq_status_time"`
+	RetryCount int        `json:"retry_count" gorm:"type:int"`
+	Notes      string     `json:"notes" gorm:"type:text"`
+	Items      []LineItem `json:"items,omitempty" gorm:"type:jsonb;serializer:json"`
+	ClaimedBy  string     `json:"-" gorm:"type:varchar(128)"` // 처리 중인 스케줄러 인스턴스. lease_until이 지나면 다른 인스턴스가 다시 잡는다
+	LeaseUntil *time.Time `json:"-" gorm:"type:timestamp"`
+	// NextAttemptAt FAILED 건을 다시 PENDING으로 돌릴 수 있는 시각 (지수 백오프 + jitter). NULL이면 바로 재시도 대상이다.
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" gorm:"type:timestamp"`
+	CreateTime    time.Time  `json:"create_time" gorm:"type:timestamp;autoCreateTime;index:idx_payreq_status_time"`
+	UpdateTime    time.Time  `json:"update_time" gorm:"type:timestamp;autoUpdateTime"`
+}
+
+// PaymentRequestFailedEvent payment.request_failed Kafka 이벤트 페이로드
+type PaymentRequestFailedEvent struct {
+	OrderID          int64  `json:"order_id"`
+	UserID           int64  `json:"user_id"`
+	PaymentRequestID int64  `json:"payment_request_id"`
+	Amount           Money  `json:"amount"`
+	RetryCount       int    `json:"retry_count"`
+	Reason           string `json:"reason"`
+	Status           string `json:"status"`
+}
+
+// LineItem 인보이스 품목. 결제 요청을 만든 stock.reserved 이벤트의 products를 보관해 PDF를 나중에 다시 만들 수 있게 한다.
+type LineItem struct {
+	ProductID int64  `json:"product_id"`
+	Name      string `json:"name,omitempty"`
+	Category  string `json:"category,omitempty"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice Money  `json:"unit_price"`
+}
+
+// Total returns UnitPrice * Quantity.
+func (i LineItem) Total() Money {
+	return Money{Minor: i.UnitPrice.Minor * int64(i.Quantity), Currency: i.UnitPrice.Currency}
+}
+
+// PaymentFilter 고객 결제 조회 조건. UserID는 토큰의 user_id로 항상 채운다.
+// 나머지는 0/빈 값이면 조건에서 빠진다. Cursor는 이전 페이지 마지막 id.
+type PaymentFilter struct {
+	UserID int64
+	Status string
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Cursor int64
+}
+
+type PaymentPage struct {
+	Payments   []Payment `json:"payments"`
+	NextCursor int64     `json:"next_cursor,omitempty"`
 }
 
 type FailedPaymentList struct {
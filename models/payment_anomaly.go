@@ -4,12 +4,60 @@ import "time"
 
 // PaymentAnomaly 결제 이상 건 (금액 불일치 등) 수동 확인용
 type PaymentAnomaly struct {
-	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
-	OrderID    int64     `json:"order_id" gorm:"type:bigint"`
-	ExternalID string    `json:"external_id" gorm:"type:text"`
-	AnomalyType int      `json:"anomaly_type" gorm:"type:integer"`
-	Notes      string    `json:"notes" gorm:"type:text"`
-	Status     int       `json:"status" gorm:"type:integer"`
-	CreateTime time.Time `json:"create_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
-	UpdateTime time.Time `json:"update_time" gorm:"type:timestamp"`
+	ID          int64  `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
+	OrderID     int64  `json:"order_id" gorm:"type:bigint"`
+	ExternalID  string `json:"external_id" gorm:"type:text"`
+	AnomalyType int    `json:"anomaly_type" gorm:"type:integer"`
+	// ExpectedAmount/PaidAmount 금액 불일치 건의 주문 금액과 실제 입금액 (그 외 유형은 0)
+	ExpectedAmount Money      `json:"expected_amount" gorm:"embedded;embeddedPrefix:expected_"`
+	PaidAmount     Money      `json:"paid_amount" gorm:"embedded;embeddedPrefix:paid_"`
+	Notes          string     `json:"notes" gorm:"type:text"`
+	Status         int        `json:"status" gorm:"type:integer"`
+	Resolution     string     `json:"resolution,omitempty" gorm:"type:varchar(32)"`
+	ResolvedBy     int64      `json:"resolved_by,omitempty" gorm:"type:bigint"`
+	ResolveReason  string     `json:"resolve_reason,omitempty" gorm:"type:text"`
+	RefundID       int64      `json:"refund_id,omitempty" gorm:"type:bigint"`
+	ResolvedTime   *time.Time `json:"resolved_time,omitempty" gorm:"type:timestamp"`
+	CreateTime     time.Time  `json:"create_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdateTime     time.Time  `json:"update_time" gorm:"type:timestamp"`
+}
+
+// PaymentAnomalyFilter admin 조회 조건. 0/빈 값은 조건에서 빠진다. Cursor는 이전 페이지 마지막 id.
+type PaymentAnomalyFilter struct {
+	AnomalyType int
+	Status      int
+	OrderID     int64
+	From        time.Time
+	To          time.Time
+	Limit       int
+	Cursor      int64
+}
+
+type PaymentAnomalyPage struct {
+	Anomalies  []PaymentAnomaly `json:"anomalies"`
+	NextCursor int64            `json:"next_cursor,omitempty"`
+}
+
+// PaymentAnomalyDetail 이상 건 + 해당 결제, 상태 변경 이력, 감사 로그
+type PaymentAnomalyDetail struct {
+	Anomaly       PaymentAnomaly         `json:"anomaly"`
+	Payment       *Payment               `json:"payment,omitempty"`
+	StatusHistory []PaymentStatusHistory `json:"status_history"`
+	AuditTrail    []PaymentAuditLog      `json:"audit_trail"`
+}
+
+// ResolveAnomalyRequest 조치 요청. Action은 constant.AnomalyResolution*
+type ResolveAnomalyRequest struct {
+	Action string `json:"action" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// PaymentAnomalyUpdate 상태 변경 내용. Status가 완료 상태(Success/FalsePositive)면 resolved_time이 기록된다.
+type PaymentAnomalyUpdate struct {
+	Status        int
+	Resolution    string
+	ResolvedBy    int64
+	ResolveReason string
+	RefundID      int64
+	Notes         string
 }
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// PaymentStatusHistory payments.status 변경 이력 (append-only)
+type PaymentStatusHistory struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
+	PaymentID  int64     `json:"payment_id" gorm:"type:bigint;not null;index:idx_payment_status_history_payment"`
+	OrderID    int64     `json:"order_id" gorm:"type:bigint"`
+	FromStatus string    `json:"from_status" gorm:"type:varchar"`
+	ToStatus   string    `json:"to_status" gorm:"type:varchar"`
+	Version    int64     `json:"version" gorm:"type:bigint"`
+	Actor      string    `json:"actor" gorm:"type:varchar"`
+	Reason     string    `json:"reason" gorm:"type:text"`
+	CreateTime time.Time `json:"create_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+func (PaymentStatusHistory) TableName() string {
+	return "payment_status_history"
+}
+
+// PaymentStatusTransition 상태 전이 요청. PaymentID가 없으면 OrderID로 대상 payment를 찾는다.
+type PaymentStatusTransition struct {
+	PaymentID int64
+	OrderID   int64
+	ToStatus  string
+	Actor     string
+	Reason    string
+	// Outbox 전이와 같은 트랜잭션에 기록할 발행 메시지 (전이가 거절되면 함께 롤백된다)
+	Outbox []OutboxEvent
+	// SameStatusNoop 이미 ToStatus인데 전이 표에 없는 전이(REFUNDED -> REFUNDED)를 에러 대신 no-op으로 처리한다.
+	// 상태와 이력은 그대로 두고 Outbox만 기록한다.
+	SameStatusNoop bool
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// ReconciliationRun 한 기간(window)의 Xendit 인보이스를 payments와 대사한 실행 기록
+type ReconciliationRun struct {
+	ID          int64  `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
+	Provider    string `json:"provider" gorm:"type:varchar(32)"`
+	Trigger     string `json:"trigger" gorm:"type:varchar"`
+	RequestedBy int64  `json:"requested_by" gorm:"type:bigint"`
+	// ScheduleKey 스케줄 실행의 중복 방지 키 (예: daily:2026-01-31). admin 실행은 NULL.
+	ScheduleKey      *string    `json:"schedule_key,omitempty" gorm:"type:varchar;uniqueIndex"`
+	WindowStart      time.Time  `json:"window_start" gorm:"type:timestamp"`
+	WindowEnd        time.Time  `json:"window_end" gorm:"type:timestamp"`
+	AutoFix          bool       `json:"auto_fix" gorm:"not null;default:false"`
+	Status           string     `json:"status" gorm:"type:varchar;index"`
+	InvoiceCount     int        `json:"invoice_count" gorm:"type:int;not null;default:0"`
+	MatchedCount     int        `json:"matched_count" gorm:"type:int;not null;default:0"`
+	DiscrepancyCount int        `json:"discrepancy_count" gorm:"type:int;not null;default:0"`
+	FixedCount       int        `json:"fixed_count" gorm:"type:int;not null;default:0"`
+	Error            string     `json:"error,omitempty" gorm:"type:text"`
+	StartTime        time.Time  `json:"start_time" gorm:"type:timestamp"`
+	FinishTime       *time.Time `json:"finish_time" gorm:"type:timestamp"`
+}
+
+// ReconciliationItem run에서 발견한 불일치 한 건. 일치한 인보이스는 저장하지 않는다.
+type ReconciliationItem struct {
+	ID              int64     `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
+	RunID           int64     `json:"run_id" gorm:"type:bigint;not null;index:idx_recon_items_run"`
+	OrderID         int64     `json:"order_id" gorm:"type:bigint"`
+	PaymentID       int64     `json:"payment_id" gorm:"type:bigint"`
+	ExternalID      string    `json:"external_id" gorm:"type:text"`
+	InvoiceID       string    `json:"invoice_id" gorm:"type:text"`
+	DiscrepancyType string    `json:"discrepancy_type" gorm:"type:varchar"`
+	ProviderStatus  string    `json:"provider_status" gorm:"type:varchar"`
+	LocalStatus     string    `json:"local_status" gorm:"type:varchar"`
+	ProviderAmount  Money     `json:"provider_amount" gorm:"embedded;embeddedPrefix:provider_"`
+	LocalAmount     Money     `json:"local_amount" gorm:"embedded;embeddedPrefix:local_"`
+	FixStatus       string    `json:"fix_status" gorm:"type:varchar"`
+	FixError        string    `json:"fix_error,omitempty" gorm:"type:text"`
+	CreateTime      time.Time `json:"create_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+}
+
+// CreateReconciliationRequest admin 수동 대사 요청. from/to는 RFC3339 또는 YYYY-MM-DD.
+type CreateReconciliationRequest struct {
+	From    string `json:"from" binding:"required"`
+	To      string `json:"to" binding:"required"`
+	AutoFix bool   `json:"auto_fix"`
+}
+
+type ReconciliationRunFilter struct {
+	Status string
+	Limit  int
+	Cursor int64
+}
+
+type ReconciliationRunPage struct {
+	Runs       []ReconciliationRun `json:"runs"`
+	NextCursor int64               `json:"next_cursor,omitempty"`
+}
+
+type ReconciliationItemFilter struct {
+	RunID           int64
+	DiscrepancyType string
+	FixStatus       string
+	Limit           int
+	Cursor          int64
+}
+
+type ReconciliationItemPage struct {
+	Items      []ReconciliationItem `json:"items"`
+	NextCursor int64                `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Refund 결제 건에 대한 환불 (전액/부분). 한 payment에 여러 건이 쌓일 수 있다.
+type Refund struct {
+	ID             int64     `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
+	PaymentID      int64     `json:"payment_id" gorm:"type:bigint;not null;index:idx_refunds_payment"`
+	OrderID        int64     `json:"order_id" gorm:"type:bigint;index:idx_refunds_order"`
+	ExternalID     string    `json:"external_id" gorm:"type:text;uniqueIndex"`
+	XenditRefundID string    `json:"xendit_refund_id" gorm:"type:text"` // provider 환불 ID (컬럼명은 Xendit 시절 그대로)
+	Amount         Money     `json:"amount" gorm:"embedded"`
+	Reason         string    `json:"reason" gorm:"type:text"`
+	Status         string    `json:"status" gorm:"type:varchar"`
+	RequestedBy    int64     `json:"requested_by" gorm:"type:bigint"`
+	Notes          string    `json:"notes" gorm:"type:text"`
+	CreateTime     time.Time `json:"create_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	UpdateTime     time.Time `json:"update_time" gorm:"type:timestamp"`
+}
+
+// RefundRequest 환불 API 요청 바디. Amount(major unit 소수 문자열/숫자)를 생략하거나 0이면 남은 금액 전액 환불.
+type RefundRequest struct {
+	Amount json.Number `json:"amount" swaggertype:"number"`
+	Reason string      `json:"reason"`
+}
+
+// PaymentRefundedEvent payment.refunded Kafka 이벤트 페이로드
+type PaymentRefundedEvent struct {
+	OrderID        int64  `json:"order_id"`
+	PaymentID      int64  `json:"payment_id"`
+	RefundID       int64  `json:"refund_id"`
+	Amount         Money  `json:"amount"`
+	RefundedAmount Money  `json:"refunded_amount"`
+	FullyRefunded  bool   `json:"fully_refunded"`
+	Status         string `json:"status"`
+}
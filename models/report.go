@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// SettlementReportQuery [From, To) 기간의 payments/refunds를 Timezone 기준 GroupBy(day, week, month) 단위로 집계한다.
+type SettlementReportQuery struct {
+	From     time.Time
+	To       time.Time
+	GroupBy  string
+	Timezone string
+}
+
+// SettlementReportRow 기간(period) x 통화별 집계. 결제 건수/금액은 payments.create_time, 환불은 refunds.create_time 기준이다.
+// 총 결제액은 PAID/PARTIALLY_REFUNDED/REFUNDED 건의 주문 금액 합계이며, 환불액은 성공한 환불만(초과 입금 환불 제외) 더한다.
+type SettlementReportRow struct {
+	Period                 string  `json:"period"` // day/week: YYYY-MM-DD(주는 월요일), month: YYYY-MM
+	Currency               string  `json:"currency"`
+	PaymentCount           int64   `json:"payment_count"`
+	PendingCount           int64   `json:"pending_count"`
+	PaidCount              int64   `json:"paid_count"`
+	PartiallyRefundedCount int64   `json:"partially_refunded_count"`
+	RefundedCount          int64   `json:"refunded_count"`
+	ExpiredCount           int64   `json:"expired_count"`
+	FailedCount            int64   `json:"failed_count"`
+	ExpiredRatio           float64 `json:"expired_ratio"`
+	FailedRatio            float64 `json:"failed_ratio"`
+	GrossPaidAmount        Money   `json:"gross_paid_amount"`
+	TaxBaseAmount          Money   `json:"tax_base_amount"` // 결제 완료 건의 과세 표준 합계
+	TaxAmount              Money   `json:"tax_amount"`      // 결제 완료 건의 세액 합계 (총 결제액에 포함)
+	RefundCount            int64   `json:"refund_count"`
+	RefundAmount           Money   `json:"refund_amount"`
+	NetAmount              Money   `json:"net_amount"`
+}
+
+type SettlementReport struct {
+	From     time.Time             `json:"from"`
+	To       time.Time             `json:"to"`
+	GroupBy  string                `json:"group_by"`
+	Timezone string                `json:"timezone"`
+	Rows     []SettlementReportRow `json:"rows"`
+	// Totals 통화별 전체 기간 합계 (Period는 비어 있다)
+	Totals []SettlementReportRow `json:"totals"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import "strings"
+
+// TaxRule 인보이스 생성 시 적용하는 부가세(PPN) 규칙. RateBps는 basis point (1100 = 11%).
+// Inclusive면 주문 금액에 세금이 포함된 것으로 보고 역산하고, 아니면 과세 금액에 세금을 더해 청구한다.
+type TaxRule struct {
+	RateBps          int64
+	Inclusive        bool
+	ExemptCategories []string
+}
+
+// TaxBreakdown 규칙 적용 결과. Total은 결제 게이트웨이에 청구할 금액이다.
+type TaxBreakdown struct {
+	Base      Money
+	Tax       Money
+	Total     Money
+	RateBps   int64
+	Inclusive bool
+}
+
+func (r TaxRule) IsExempt(category string) bool {
+	for _, c := range r.ExemptCategories {
+		if category != "" && strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply 주문 금액에서 면세 품목(단가가 있는 품목만)을 뺀 나머지를 과세 대상으로 보고 세금을 계산한다.
+// 배송비 등 품목 외 금액은 과세 대상에 포함된다. 금액은 minor unit에서 반올림한다.
+func (r TaxRule) Apply(orderTotal Money, items []LineItem) (TaxBreakdown, error) {
+	zero := NewMoney(0, orderTotal.Currency)
+	breakdown := TaxBreakdown{Base: zero, Tax: zero, Total: orderTotal, RateBps: r.RateBps, Inclusive: r.Inclusive}
+	if r.RateBps <= 0 {
+		breakdown.RateBps = 0
+		return breakdown, nil
+	}
+
+	taxable := orderTotal
+	for _, item := range items {
+		if !r.IsExempt(item.Category) || item.UnitPrice.IsZero() {
+			continue
+		}
+		var err error
+		if taxable, err = taxable.Sub(item.Total()); err != nil {
+			return TaxBreakdown{}, err
+		}
+	}
+	if taxable.Minor < 0 {
+		taxable = zero
+	}
+
+	if r.Inclusive {
+		breakdown.Tax = Money{Minor: roundDiv(taxable.Minor*r.RateBps, 10000+r.RateBps), Currency: taxable.Currency}
+		breakdown.Base = Money{Minor: taxable.Minor - breakdown.Tax.Minor, Currency: taxable.Currency}
+		return breakdown, nil
+	}
+	breakdown.Base = taxable
+	breakdown.Tax = Money{Minor: roundDiv(taxable.Minor*r.RateBps, 10000), Currency: taxable.Currency}
+	breakdown.Total = Money{Minor: orderTotal.Minor + breakdown.Tax.Minor, Currency: orderTotal.Currency}
+	return breakdown, nil
+}
+
+// roundDiv n/d를 반올림(half up)한다. n >= 0, d > 0.
+func roundDiv(n, d int64) int64 {
+	return (n*2 + d) / (d * 2)
+}
+
+// ApplyTo 청구 금액과 세금 내역을 payment에 기록한다.
+func (b TaxBreakdown) ApplyTo(p *Payment) {
+	p.Amount = b.Total
+	p.TaxBase = b.Base
+	p.Tax = b.Tax
+	p.TaxRateBps = b.RateBps
+	p.TaxInclusive = b.Inclusive
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxRule_Apply(t *testing.T) {
+	t.Run("inclusive extracts tax from order total", func(t *testing.T) {
+		b, err := TaxRule{RateBps: 1100, Inclusive: true}.Apply(NewMoney(111000, "IDR"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, NewMoney(11000, "IDR"), b.Tax)
+		assert.Equal(t, NewMoney(100000, "IDR"), b.Base)
+		assert.Equal(t, NewMoney(111000, "IDR"), b.Total)
+	})
+
+	t.Run("exclusive adds tax on top", func(t *testing.T) {
+		b, err := TaxRule{RateBps: 1100}.Apply(NewMoney(100000, "IDR"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, NewMoney(11000, "IDR"), b.Tax)
+		assert.Equal(t, NewMoney(100000, "IDR"), b.Base)
+		assert.Equal(t, NewMoney(111000, "IDR"), b.Total)
+	})
+
+	t.Run("exempt categories are excluded from the taxable amount", func(t *testing.T) {
+		items := []LineItem{
+			{ProductID: 1, Category: "Groceries", Quantity: 2, UnitPrice: NewMoney(25000, "IDR")},
+			{ProductID: 2, Category: "electronics", Quantity: 1, UnitPrice: NewMoney(100000, "IDR")},
+		}
+		b, err := TaxRule{RateBps: 1100, ExemptCategories: []string{"groceries"}}.Apply(NewMoney(150000, "IDR"), items)
+		require.NoError(t, err)
+		assert.Equal(t, NewMoney(100000, "IDR"), b.Base)
+		assert.Equal(t, NewMoney(11000, "IDR"), b.Tax)
+		assert.Equal(t, NewMoney(161000, "IDR"), b.Total)
+	})
+
+	t.Run("zero rate leaves amount untouched", func(t *testing.T) {
+		b, err := TaxRule{}.Apply(NewMoney(100000, "IDR"), nil)
+		require.NoError(t, err)
+		assert.True(t, b.Tax.IsZero())
+		assert.Equal(t, NewMoney(100000, "IDR"), b.Total)
+	})
+}
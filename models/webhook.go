@@ -1,7 +1,11 @@
 package models
 
+import "encoding/json"
+
 type XenditWebhookPayload struct {
-	ExternalID string  `json:"external_id"`
-	Status     string  `json:"status"`
-	Amount     float64 `json:"amount"` // 웹훅에서 오면 총액 검증에 사용
+	ID         string      `json:"id"` // Xendit invoice id
+	ExternalID string      `json:"external_id"`
+	Status     string      `json:"status"`
+	Amount     json.Number `json:"amount" swaggertype:"number"` // 웹훅에서 오면 총액 검증에 사용 (major unit, 정확한 비교를 위해 문자열 그대로 보관)
+	Currency   string      `json:"currency"`
 }
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent 수신한 결제 웹훅 inbox. (provider, event_key)로 중복 수신을 막고, 워커가 비동기로 처리한다.
+type WebhookEvent struct {
+	ID              int64      `json:"id" gorm:"primaryKey;autoIncrement;type:bigserial"`
+	Provider        string     `json:"provider" gorm:"type:varchar(32);not null;uniqueIndex:idx_webhook_events_key"`
+	EventKey        string     `json:"event_key" gorm:"type:text;not null;uniqueIndex:idx_webhook_events_key"`
+	ExternalID      string     `json:"external_id" gorm:"type:text;index:idx_webhook_events_external"`
+	PaymentStatus   string     `json:"payment_status" gorm:"type:varchar"`
+	Amount          string     `json:"amount" gorm:"type:text"`
+	Currency        string     `json:"currency" gorm:"type:varchar(3)"`
+	Headers         string     `json:"headers" gorm:"type:text"`
+	Payload         string     `json:"payload" gorm:"type:text"`
+	Status          string     `json:"status" gorm:"type:varchar;index:idx_webhook_events_status"`
+	Attempts        int        `json:"attempts" gorm:"type:int;not null;default:0"`
+	LastError       string     `json:"last_error" gorm:"type:text"`
+	NextAttemptTime time.Time  `json:"next_attempt_time" gorm:"type:timestamp;index:idx_webhook_events_status"`
+	ReceivedTime    time.Time  `json:"received_time" gorm:"type:timestamp;default:CURRENT_TIMESTAMP"`
+	ProcessedTime   *time.Time `json:"processed_time" gorm:"type:timestamp"`
+	UpdateTime      time.Time  `json:"update_time" gorm:"type:timestamp"`
+}
+
+// PaymentEvent 저장된 정규화 필드로 ProcessPaymentWebhook 입력을 만든다.
+func (e WebhookEvent) PaymentEvent() PaymentWebhookEvent {
+	return PaymentWebhookEvent{
+		Provider:   e.Provider,
+		EventID:    e.EventKey,
+		ExternalID: e.ExternalID,
+		Status:     e.PaymentStatus,
+		Amount:     json.Number(e.Amount),
+		Currency:   e.Currency,
+	}
+}
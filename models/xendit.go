@@ -1,17 +1,53 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
+// Xendit API는 금액을 major unit 숫자로 주고받는다. float 변환 없이 Money.Number()/ParseMoney로 변환한다.
 type XenditInvoiceRequest struct {
-	ExternalID  string  `json:"external_id"`
-	Amount      float64 `json:"amount"`
-	Description string  `json:"description"`
-	PayerEmail  string  `json:"payer_email"`
+	ExternalID  string      `json:"external_id"`
+	Amount      json.Number `json:"amount"`
+	Currency    string      `json:"currency,omitempty"`
+	Description string      `json:"description"`
+	PayerEmail  string      `json:"payer_email"`
 }
 
 type XenditInvoiceResponse struct {
-	ID         string    `json:"id"`
-	ExpireDate time.Time `json:"expire_date"`
-	InvoiceURL string    `json:"invoice_url"`
-	Status     string    `json:"status"`
+	ID         string      `json:"id"`
+	ExternalID string      `json:"external_id"`
+	Amount     json.Number `json:"amount"`
+	Currency   string      `json:"currency"`
+	ExpireDate time.Time   `json:"expiry_date"`
+	InvoiceURL string      `json:"invoice_url"`
+	Status     string      `json:"status"`
+	PaidAt     *time.Time  `json:"paid_at,omitempty"`
+	Created    time.Time   `json:"created"`
+}
+
+// XenditInvoiceListRequest GET /v2/invoices 기간 조회. 결과는 최신순이며 LastInvoiceID로 다음 페이지를 가져온다.
+type XenditInvoiceListRequest struct {
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Limit         int
+	LastInvoiceID string
+}
+
+type XenditRefundRequest struct {
+	InvoiceID   string      `json:"invoice_id"`
+	ReferenceID string      `json:"reference_id"`
+	Amount      json.Number `json:"amount"`
+	Currency    string      `json:"currency,omitempty"`
+	Reason      string      `json:"reason"`
+}
+
+type XenditRefundResponse struct {
+	ID          string      `json:"id"`
+	InvoiceID   string      `json:"invoice_id"`
+	ReferenceID string      `json:"reference_id"`
+	Amount      json.Number `json:"amount"`
+	Currency    string      `json:"currency"`
+	Status      string      `json:"status"`
+	FailureCode string      `json:"failure_code"`
 }
@@ -0,0 +1,67 @@
+package pdf
+
+import (
+	"paymentfc/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatMoney 인보이스 표기용 금액 문자열. IDR은 "Rp 1.500.000"(id-ID), 그 외 통화는 "USD 1,234.56".
+func FormatMoney(m models.Money) string {
+	currency := m.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	decimal := models.Money{Minor: m.Minor, Currency: currency}.Decimal()
+	sign := ""
+	if strings.HasPrefix(decimal, "-") {
+		sign = "-"
+		decimal = decimal[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(decimal, ".")
+
+	prefix, group, point := currency+" ", ",", "."
+	if currency == "IDR" {
+		prefix, group, point = "Rp ", ".", ","
+	}
+	out := sign + prefix + groupThousands(intPart, group)
+	if hasFrac {
+		out += point + fracPart
+	}
+	return out
+}
+
+func groupThousands(digits, sep string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// FormatRate basis point 세율을 퍼센트 문자열로 바꾼다 (1100 -> "11%", 1150 -> "11.5%").
+func FormatRate(bps int64) string {
+	return strconv.FormatFloat(float64(bps)/100, 'f', -1, 64) + "%"
+}
+
+// FormatDate 인보이스 표기용 일시 (예: "02 Jan 2026 14:05 WIB"). zero time은 "-".
+func FormatDate(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return t.Format("02 Jan 2006 15:04 MST")
+}
@@ -0,0 +1,62 @@
+package pdf
+
+import (
+	"paymentfc/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "Rp 150.000", FormatMoney(models.NewMoney(150000, "IDR")))
+	assert.Equal(t, "Rp 1.250.000", FormatMoney(models.NewMoney(1250000, "IDR")))
+	assert.Equal(t, "Rp 500", FormatMoney(models.NewMoney(500, "IDR")))
+	assert.Equal(t, "-Rp 25.000", FormatMoney(models.NewMoney(-25000, "IDR")))
+	assert.Equal(t, "Rp 0", FormatMoney(models.Money{}))
+	assert.Equal(t, "USD 1,234.56", FormatMoney(models.NewMoney(123456, "USD")))
+}
+
+func TestFormatDate(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	assert.Equal(t, "31 Jan 2026 07:30 WIB", FormatDate(time.Date(2026, 1, 31, 0, 30, 0, 0, time.UTC), wib))
+	assert.Equal(t, "-", FormatDate(time.Time{}, wib))
+}
+
+func TestComputeTotals(t *testing.T) {
+	t.Run("priced items with shipping", func(t *testing.T) {
+		totals := ComputeTotals(&models.Payment{
+			Amount: models.NewMoney(170000, "IDR"),
+			Items: []models.LineItem{
+				{ProductID: 1, Quantity: 2, UnitPrice: models.NewMoney(50000, "IDR")},
+				{ProductID: 2, Quantity: 1, UnitPrice: models.NewMoney(60000, "IDR")},
+			},
+		})
+		assert.Equal(t, models.NewMoney(160000, "IDR"), totals.Subtotal)
+		assert.Equal(t, models.NewMoney(10000, "IDR"), totals.Other)
+		assert.Equal(t, models.NewMoney(170000, "IDR"), totals.Total)
+	})
+
+	t.Run("unpriced items fall back to payment amount", func(t *testing.T) {
+		totals := ComputeTotals(&models.Payment{
+			Amount: models.NewMoney(170000, "IDR"),
+			Items:  []models.LineItem{{ProductID: 1, Quantity: 2, UnitPrice: models.NewMoney(0, "IDR")}},
+		})
+		assert.Equal(t, models.NewMoney(170000, "IDR"), totals.Subtotal)
+		assert.True(t, totals.Other.IsZero())
+	})
+
+	t.Run("exclusive tax is shown on top of subtotal", func(t *testing.T) {
+		totals := ComputeTotals(&models.Payment{
+			Amount:     models.NewMoney(111000, "IDR"),
+			TaxBase:    models.NewMoney(100000, "IDR"),
+			Tax:        models.NewMoney(11000, "IDR"),
+			TaxRateBps: 1100,
+			Items:      []models.LineItem{{ProductID: 1, Quantity: 1, UnitPrice: models.NewMoney(100000, "IDR")}},
+		})
+		assert.Equal(t, models.NewMoney(100000, "IDR"), totals.Subtotal)
+		assert.True(t, totals.Other.IsZero())
+		assert.Equal(t, models.NewMoney(11000, "IDR"), totals.Tax)
+		assert.Equal(t, models.NewMoney(111000, "IDR"), totals.Total)
+	})
+}
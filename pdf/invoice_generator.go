@@ -2,38 +2,337 @@ package pdf
 
 import (
 	"fmt"
+	"io"
+	"paymentfc/constant"
 	"paymentfc/models"
+	"strings"
 	"time"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
-func GenerateInvoicePdf(payment *models.Payment, outputPath string) error {
-	pdf := gofpdf.New("P", "mm", "A4", "arial")
-	pdf.AddPage()
-
-	pdf.SetFont("Arial", "B", 16)
-	pdf.Cell(40, 10, "GO-Commerce Invoice Details")
-
-	pdf.Ln(20)
-	pdf.SetFont("Arial", "B", 12)
-
-	pdf.Cell(40, 10, fmt.Sprintf("Invoice Date: %s", time.Now().Format("2006-01-02")))
-	pdf.Ln(20)
-	pdf.Cell(40, 10, fmt.Sprintf("Payment ID: %d", payment.ID))
-	pdf.Ln(20)
-	pdf.Cell(40, 10, fmt.Sprintf("Order ID: %d", payment.OrderID))
-	pdf.Ln(20)
-	pdf.Cell(40, 10, fmt.Sprintf("User ID: %d", payment.UserID))
-	pdf.Ln(20)
-	pdf.Cell(40, 10, fmt.Sprintf("Amount: %f", payment.Amount))
-	pdf.Ln(20)
-	pdf.Cell(40, 10, fmt.Sprintf("Status: %s", payment.Status))
-	pdf.Ln(20)
-	pdf.Cell(40, 10, fmt.Sprintf("Create Time: %s", payment.CreateTime))
-	pdf.Ln(20)
-	pdf.Cell(40, 10, fmt.Sprintf("Expired Time: %s", payment.ExpiredTime))
-	pdf.Ln(20)
-
-	return pdf.OutputFileAndClose(outputPath)
+// Company 인보이스 상단에 찍는 판매자 정보
+type Company struct {
+	Name    string
+	Address string
+	Email   string
+	Phone   string
+	TaxID   string // NPWP
+}
+
+// Customer 인보이스 수신자. user 서비스 조회에 실패하면 비어 있을 수 있다.
+type Customer struct {
+	Name  string
+	Email string
+}
+
+// Invoice PDF 한 장을 그리는 데 필요한 데이터
+type Invoice struct {
+	Company  Company
+	Customer Customer
+	Payment  *models.Payment
+	Location *time.Location // 일시 표기 timezone. nil이면 UTC
+	IssuedAt time.Time
+}
+
+// Totals 인보이스 하단 합계. Other는 품목 합계와 (세금 제외) 결제 금액의 차이(배송비 등)다.
+// 세금 포함 방식이면 Subtotal/Other에 세금이 들어 있고 Tax는 참고용으로만 표시한다.
+type Totals struct {
+	Subtotal     models.Money
+	Other        models.Money
+	TaxBase      models.Money
+	Tax          models.Money
+	TaxRateBps   int64
+	TaxInclusive bool
+	Total        models.Money
+}
+
+const (
+	pageMargin  = 15.0
+	lineHeight  = 6.0
+	contentWide = 180.0
+)
+
+// itemColumns No, Item, Qty, Unit price, Amount 열 너비 (합계 contentWide)
+var itemColumns = []float64{10, 85, 15, 35, 35}
+
+// ComputeTotals 품목 합계로 소계를 구한다. 품목이 없거나 단가가 빠진 품목이 있으면 결제 금액(별도 세금 제외)을 소계로 본다.
+func ComputeTotals(payment *models.Payment) Totals {
+	total := payment.Amount
+	zero := models.NewMoney(0, total.Currency)
+	totals := Totals{
+		Other:        zero,
+		TaxBase:      payment.TaxBase,
+		Tax:          payment.Tax,
+		TaxRateBps:   payment.TaxRateBps,
+		TaxInclusive: payment.TaxInclusive,
+		Total:        total,
+	}
+	if totals.Tax.Currency == "" {
+		totals.Tax, totals.TaxBase = zero, zero
+	}
+	beforeTax := total
+	if !payment.TaxInclusive {
+		beforeTax, _ = total.Sub(totals.Tax)
+	}
+	totals.Subtotal = beforeTax
+	if len(payment.Items) == 0 {
+		return totals
+	}
+	subtotal := zero
+	for _, item := range payment.Items {
+		if item.UnitPrice.IsZero() || !item.UnitPrice.SameCurrency(total) {
+			return totals
+		}
+		subtotal, _ = subtotal.Add(item.Total())
+	}
+	totals.Subtotal = subtotal
+	totals.Other, _ = beforeTax.Sub(subtotal)
+	return totals
+}
+
+// WriteInvoicePdf renders the invoice as PDF into w.
+func WriteInvoicePdf(w io.Writer, invoice Invoice) error {
+	if invoice.Payment == nil {
+		return fmt.Errorf("invoice payment is nil")
+	}
+	if invoice.IssuedAt.IsZero() {
+		invoice.IssuedAt = time.Now()
+	}
+	doc := gofpdf.New("P", "mm", "A4", "")
+	invoice = translateInvoice(invoice, doc.UnicodeTranslatorFromDescriptor(""))
+	doc.SetMargins(pageMargin, pageMargin, pageMargin)
+	doc.SetAutoPageBreak(true, pageMargin)
+	doc.SetTitle(fmt.Sprintf("Invoice Order %d", invoice.Payment.OrderID), true)
+	// 같은 데이터면 같은 바이트가 나오도록 메타데이터 일시를 발행일로 고정한다
+	doc.SetCreationDate(invoice.IssuedAt)
+	doc.SetModificationDate(invoice.IssuedAt)
+	doc.SetCatalogSort(true)
+	doc.AddPage()
+
+	writeHeader(doc, invoice)
+	writeParties(doc, invoice)
+	writeItems(doc, invoice.Payment)
+	writeTotals(doc, ComputeTotals(invoice.Payment))
+	writeFooter(doc)
+	writeWatermark(doc, invoice.Payment.Status)
+
+	return doc.Output(w)
+}
+
+func writeHeader(doc *gofpdf.Fpdf, invoice Invoice) {
+	company := invoice.Company
+	if company.Name == "" {
+		company.Name = "GO-Commerce"
+	}
+	doc.SetFont("Helvetica", "B", 18)
+	doc.CellFormat(contentWide/2, 9, company.Name, "", 0, "L", false, 0, "")
+	doc.SetTextColor(90, 90, 90)
+	doc.CellFormat(contentWide/2, 9, "INVOICE", "", 1, "R", false, 0, "")
+	doc.SetTextColor(0, 0, 0)
+
+	doc.SetFont("Helvetica", "", 9)
+	for _, line := range []string{company.Address, joinNonEmpty(" | ", company.Email, company.Phone), prefixed("NPWP: ", company.TaxID)} {
+		if line != "" {
+			doc.CellFormat(contentWide, 4.5, line, "", 1, "L", false, 0, "")
+		}
+	}
+	doc.Ln(3)
+	doc.SetDrawColor(200, 200, 200)
+	doc.Line(pageMargin, doc.GetY(), pageMargin+contentWide, doc.GetY())
+	doc.Ln(5)
+}
+
+func writeParties(doc *gofpdf.Fpdf, invoice Invoice) {
+	payment := invoice.Payment
+	customerName := invoice.Customer.Name
+	if customerName == "" {
+		customerName = fmt.Sprintf("User #%d", payment.UserID)
+	}
+	left := []string{customerName, invoice.Customer.Email}
+	right := [][2]string{}
+	if payment.InvoiceNumber != nil {
+		right = append(right, [2]string{"Invoice No.", *payment.InvoiceNumber})
+	}
+	right = append(right, [][2]string{
+		{"Reference", payment.ExternalID},
+		{"Order ID", fmt.Sprintf("%d", payment.OrderID)},
+		{"Issued", FormatDate(invoice.IssuedAt, invoice.Location)},
+		{"Due", FormatDate(payment.ExpiredTime, invoice.Location)},
+		{"Status", payment.Status},
+	}...)
+
+	top := doc.GetY()
+	doc.SetFont("Helvetica", "B", 10)
+	doc.CellFormat(contentWide/2, lineHeight, "Billed to", "", 1, "L", false, 0, "")
+	doc.SetFont("Helvetica", "", 10)
+	for _, line := range left {
+		if line != "" {
+			doc.CellFormat(contentWide/2, lineHeight, line, "", 1, "L", false, 0, "")
+		}
+	}
+	leftBottom := doc.GetY()
+
+	doc.SetY(top)
+	for _, kv := range right {
+		doc.SetX(pageMargin + contentWide/2)
+		doc.SetFont("Helvetica", "B", 10)
+		doc.CellFormat(30, lineHeight, kv[0], "", 0, "L", false, 0, "")
+		doc.SetFont("Helvetica", "", 10)
+		doc.CellFormat(contentWide/2-30, lineHeight, kv[1], "", 1, "R", false, 0, "")
+	}
+	doc.SetY(max(leftBottom, doc.GetY()) + 6)
+}
+
+func writeItems(doc *gofpdf.Fpdf, payment *models.Payment) {
+	headers := []string{"No", "Item", "Qty", "Unit price", "Amount"}
+	aligns := []string{"C", "L", "C", "R", "R"}
+	doc.SetFont("Helvetica", "B", 10)
+	doc.SetFillColor(240, 240, 240)
+	for i, h := range headers {
+		doc.CellFormat(itemColumns[i], 8, h, "B", 0, aligns[i], true, 0, "")
+	}
+	doc.Ln(-1)
+
+	doc.SetFont("Helvetica", "", 10)
+	if len(payment.Items) == 0 {
+		// 품목이 저장되지 않은 기존 결제는 주문 전체를 한 줄로 표시한다
+		row := []string{"1", fmt.Sprintf("Order #%d", payment.OrderID), "1", FormatMoney(payment.Amount), FormatMoney(payment.Amount)}
+		writeItemRow(doc, row, aligns)
+		return
+	}
+	for i, item := range payment.Items {
+		name := item.Name
+		if name == "" {
+			name = fmt.Sprintf("Product #%d", item.ProductID)
+		}
+		unitPrice, amount := "-", "-"
+		if !item.UnitPrice.IsZero() {
+			unitPrice, amount = FormatMoney(item.UnitPrice), FormatMoney(item.Total())
+		}
+		row := []string{fmt.Sprintf("%d", i+1), fitText(doc, name, itemColumns[1]-2), fmt.Sprintf("%d", item.Quantity), unitPrice, amount}
+		writeItemRow(doc, row, aligns)
+	}
+}
+
+func writeItemRow(doc *gofpdf.Fpdf, row []string, aligns []string) {
+	for i, v := range row {
+		doc.CellFormat(itemColumns[i], 7, v, "B", 0, aligns[i], false, 0, "")
+	}
+	doc.Ln(-1)
+}
+
+func writeTotals(doc *gofpdf.Fpdf, totals Totals) {
+	doc.Ln(3)
+	labelX := pageMargin + contentWide - 90
+	rows := [][2]string{{"Subtotal", FormatMoney(totals.Subtotal)}}
+	if !totals.Other.IsZero() {
+		rows = append(rows, [2]string{"Other charges", FormatMoney(totals.Other)})
+	}
+	if totals.TaxRateBps > 0 {
+		taxLabel := "PPN " + FormatRate(totals.TaxRateBps)
+		if totals.TaxInclusive {
+			taxLabel += " (included)"
+		}
+		rows = append(rows, [2]string{"Tax base (DPP)", FormatMoney(totals.TaxBase)}, [2]string{taxLabel, FormatMoney(totals.Tax)})
+	} else {
+		rows = append(rows, [2]string{"Tax", FormatMoney(totals.Tax)})
+	}
+	doc.SetFont("Helvetica", "", 10)
+	for _, r := range rows {
+		doc.SetX(labelX)
+		doc.CellFormat(55, lineHeight, r[0], "", 0, "L", false, 0, "")
+		doc.CellFormat(35, lineHeight, r[1], "", 1, "R", false, 0, "")
+	}
+	doc.SetX(labelX)
+	doc.SetFont("Helvetica", "B", 11)
+	doc.CellFormat(55, 8, "Total", "T", 0, "L", false, 0, "")
+	doc.CellFormat(35, 8, FormatMoney(totals.Total), "T", 1, "R", false, 0, "")
+}
+
+func writeFooter(doc *gofpdf.Fpdf) {
+	doc.Ln(12)
+	doc.SetFont("Helvetica", "I", 8)
+	doc.SetTextColor(120, 120, 120)
+	doc.MultiCell(contentWide, 4, "This invoice is generated electronically and is valid without a signature.", "", "C", false)
+	doc.SetTextColor(0, 0, 0)
+}
+
+// writeWatermark 결제 완료/만료 상태를 페이지 중앙에 반투명 대각선 도장으로 찍는다.
+func writeWatermark(doc *gofpdf.Fpdf, status string) {
+	var text string
+	var r, g, b int
+	switch status {
+	case constant.PaymentStatusPaid, constant.PaymentStatusPartiallyRefunded:
+		text, r, g, b = "PAID", 0, 150, 70
+	case constant.PaymentStatusRefunded:
+		text, r, g, b = "REFUNDED", 110, 110, 110
+	case constant.PaymentStatusExpired:
+		text, r, g, b = "EXPIRED", 200, 30, 30
+	default:
+		return
+	}
+	pageW, pageH := doc.GetPageSize()
+	doc.SetFont("Helvetica", "B", 80)
+	doc.SetTextColor(r, g, b)
+	textW := doc.GetStringWidth(text)
+	for page := 1; page <= doc.PageCount(); page++ {
+		doc.SetPage(page)
+		doc.SetAlpha(0.15, "Normal")
+		doc.TransformBegin()
+		doc.TransformRotate(35, pageW/2, pageH/2)
+		doc.Text(pageW/2-textW/2, pageH/2+10, text)
+		doc.TransformEnd()
+		doc.SetAlpha(1, "Normal")
+	}
+	doc.SetTextColor(0, 0, 0)
+}
+
+// translateInvoice 코어 폰트(cp1252)로 찍을 수 있게 외부 입력 문자열을 변환한다.
+func translateInvoice(invoice Invoice, tr func(string) string) Invoice {
+	invoice.Company = Company{
+		Name:    tr(invoice.Company.Name),
+		Address: tr(invoice.Company.Address),
+		Email:   tr(invoice.Company.Email),
+		Phone:   tr(invoice.Company.Phone),
+		TaxID:   tr(invoice.Company.TaxID),
+	}
+	invoice.Customer = Customer{Name: tr(invoice.Customer.Name), Email: tr(invoice.Customer.Email)}
+	payment := *invoice.Payment
+	payment.Items = make([]models.LineItem, len(invoice.Payment.Items))
+	for i, item := range invoice.Payment.Items {
+		item.Name = tr(item.Name)
+		payment.Items[i] = item
+	}
+	invoice.Payment = &payment
+	return invoice
+}
+
+// fitText width(mm)를 넘는 문자열을 말줄임표로 자른다.
+func fitText(doc *gofpdf.Fpdf, s string, width float64) string {
+	if doc.GetStringWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && doc.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+func prefixed(prefix, s string) string {
+	if s == "" {
+		return ""
+	}
+	return prefix + s
 }
@@ -3,8 +3,10 @@ package routes
 import (
 	"net/http"
 	"paymentfc/cmd/payment/handler"
+	"paymentfc/cmd/payment/repository"
 	"paymentfc/cmd/payment/resource"
 	"paymentfc/config"
+	"paymentfc/constant"
 	"paymentfc/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -13,12 +15,13 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRoutes(router *gin.Engine, paymentHandler *handler.PaymentHandler) {
+func SetupRoutes(router *gin.Engine, paymentHandler *handler.PaymentHandler, roleResolver middleware.RoleResolver, idempotencyStore repository.IdempotencyStore) {
 	router.Use(middleware.RequestLogger())
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	router.GET("/ping", paymentHandler.Ping())
 	router.POST("/v1/payment/webhook", paymentHandler.HandleXenditWebhook)
+	router.POST("/v1/payment/webhook/:provider", paymentHandler.HandleGatewayWebhook)
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status":  "healthy",
@@ -26,34 +29,67 @@ func SetupRoutes(router *gin.Engine, paymentHandler *handler.PaymentHandler) {
 		})
 	})
 
-	router.GET("/debug/queries", func(c *gin.Context) {
-		if resource.DBMonitor == nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "monitor not initialized"})
-			return
-		}
-		c.JSON(http.StatusOK, resource.DBMonitor.GetDebugInfo())
-	})
-	router.GET("/debug/mongo/audit-logs", paymentHandler.HandleAuditLogs)
-	router.GET("/debug/mongo/audit-report/daily", paymentHandler.HandleAuditDailyReport)
-	router.GET("/debug/mongo/stream", paymentHandler.HandleAuditLogStream)
+	authenticate := middleware.AuthMiddleware(config.GetJwtSecret())
+	// 역할별 권한. admin은 모든 라우트를 통과한다.
+	customer := middleware.RequireRoles(roleResolver, constant.RoleCustomer, constant.RoleOps, constant.RoleFinance)
+	ops := middleware.RequireRoles(roleResolver, constant.RoleOps)
+	opsOrFinance := middleware.RequireRoles(roleResolver, constant.RoleOps, constant.RoleFinance)
+	finance := middleware.RequireRoles(roleResolver, constant.RoleFinance)
 
-	router.GET("/debug/kafka", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"service":           "paymentfc",
-			"messages_produced": 0,
-			"messages_consumed": 0,
-			"dlq_count":         0,
-			"consumer_stats":    gin.H{},
+	debug := router.Group("/debug")
+	debug.Use(authenticate, middleware.RequireRoles(roleResolver, constant.RoleAdmin))
+	{
+		debug.GET("/queries", func(c *gin.Context) {
+			if resource.DBMonitor == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "monitor not initialized"})
+				return
+			}
+			c.JSON(http.StatusOK, resource.DBMonitor.GetDebugInfo())
 		})
-	})
+		debug.GET("/mongo/audit-logs", paymentHandler.HandleAuditLogs)
+		debug.GET("/mongo/audit-report/daily", paymentHandler.HandleAuditDailyReport)
+		debug.GET("/mongo/stream", paymentHandler.HandleAuditLogStream)
+
+		debug.GET("/kafka", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{
+				"service":           "paymentfc",
+				"messages_produced": 0,
+				"messages_consumed": 0,
+				"dlq_count":         0,
+				"consumer_stats":    gin.H{},
+			})
+		})
+	}
 
 	private := router.Group("/api")
-	private.Use(middleware.AuthMiddleware(config.GetJwtSecret()))
+	private.Use(authenticate)
 	{
-		private.POST("/v1/payment/invoice", paymentHandler.CreateInvoice)
-		private.GET("/v1/invoice/:order_id/pdf", paymentHandler.HandleDownloadInvoicePdf)
-		private.GET("/v1/failed_payments", paymentHandler.HandleFailedPayments)
-		private.GET("/v1/audit-logs", paymentHandler.HandleAuditLogs)
-		private.GET("/v1/audit-report/daily", paymentHandler.HandleAuditDailyReport)
+		private.POST("/v1/payment/invoice", customer, middleware.Idempotency(idempotencyStore), paymentHandler.CreateInvoice)
+		private.GET("/v1/invoice/:order_id/pdf", customer, paymentHandler.HandleDownloadInvoicePdf)
+		private.GET("/v1/payments", customer, paymentHandler.HandleListMyPayments)
+		private.GET("/v1/payments/:order_id", customer, paymentHandler.HandleGetMyPayment)
+		private.POST("/v1/payment/:order_id/refunds", opsOrFinance, paymentHandler.HandleCreateRefund)
+		private.GET("/v1/failed_payments", ops, paymentHandler.HandleFailedPayments)
+		private.GET("/v1/audit-logs", opsOrFinance, paymentHandler.HandleAuditLogs)
+		private.GET("/v1/audit-report/daily", opsOrFinance, paymentHandler.HandleAuditDailyReport)
+		private.POST("/v1/admin/webhooks/:id/replay", ops, paymentHandler.HandleReplayWebhook)
+		private.GET("/v1/admin/failed-events", ops, paymentHandler.HandleListFailedEvents)
+		private.POST("/v1/admin/failed-events/:id/retry", ops, paymentHandler.HandleRetryFailedEvent)
+		private.POST("/v1/admin/failed-events/:id/close", ops, paymentHandler.HandleCloseFailedEvent)
+		private.GET("/v1/admin/anomalies", opsOrFinance, paymentHandler.HandleListAnomalies)
+		private.GET("/v1/admin/anomalies/:id", opsOrFinance, paymentHandler.HandleGetAnomaly)
+		private.POST("/v1/admin/anomalies/:id/resolve", opsOrFinance, paymentHandler.HandleResolveAnomaly)
+		private.POST("/v1/admin/reconciliations", opsOrFinance, paymentHandler.HandleTriggerReconciliation)
+		private.GET("/v1/admin/reconciliations", opsOrFinance, paymentHandler.HandleListReconciliationRuns)
+		private.GET("/v1/admin/reconciliations/:id", opsOrFinance, paymentHandler.HandleGetReconciliationRun)
+		private.GET("/v1/admin/reconciliations/:id/items", opsOrFinance, paymentHandler.HandleListReconciliationItems)
+		private.GET("/v1/admin/reports/settlement", finance, paymentHandler.HandleSettlementReport)
+		private.GET("/v1/admin/scheduler/leader", ops, paymentHandler.HandleSchedulerLeaderStatus)
+		private.GET("/v1/admin/jobs", ops, paymentHandler.HandleListJobs)
+		private.GET("/v1/admin/jobs/:name/runs", ops, paymentHandler.HandleListJobRuns)
+		private.POST("/v1/admin/jobs/:name/pause", ops, paymentHandler.HandlePauseJob)
+		private.POST("/v1/admin/jobs/:name/resume", ops, paymentHandler.HandleResumeJob)
+		private.POST("/v1/admin/jobs/:name/trigger", ops, paymentHandler.HandleTriggerJob)
+		private.POST("/v1/admin/kafka/stock-reserved/dlq/replay", ops, paymentHandler.HandleReplayStockReservedDLQ)
 	}
 }